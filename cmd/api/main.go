@@ -1,11 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/jobs"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/logging"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/messaging"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/webhooks"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http"
 )
 
@@ -23,19 +38,141 @@ func main() {
 	}
 	defer db.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migrate: %v", err)
+		}
+		return
+	}
+
 	// Run migrations
-	if err := sql.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if cfg.AutoMigrate {
+		if err := sql.RunMigrations(db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
 	}
 
 	// Initialize repositories
 	productRepo := sql.NewProductRepository(db)
 	locationRepo := sql.NewLocationRepository(db)
 	stockRepo := sql.NewStockRepository(db)
+	snapshotRepo := sql.NewSnapshotRepository(db)
+	containerRepo := sql.NewContainerRepository(db)
+	serialRepo := sql.NewSerialRepository(db)
+	putawayRuleRepo := sql.NewPutawayRuleRepository(db)
+	replenishmentRepo := sql.NewReplenishmentRepository(db)
+	reorderRepo := sql.NewReorderRepository(db)
+	webhookRepo := sql.NewWebhookRepository(db)
+	outboxRepo := sql.NewOutboxRepository(db)
+	projectionRepo := sql.NewProjectionRepository(db)
+	repairRepo := sql.NewRepairRepository(db)
+	idempotencyRepo := sql.NewIdempotencyRepository(db)
 	txManager := sql.NewTransactionManager(db)
 
+	// Start background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := logging.NewLogger()
+	scheduler := jobs.NewScheduler(logger)
+
+	balanceService := stock.NewBalanceService(stockRepo, snapshotRepo)
+	scheduler.Every(ctx, "stock-snapshot", cfg.SnapshotInterval, func(ctx context.Context) error {
+		_, err := balanceService.TakeSnapshot(ctx)
+		return err
+	})
+
+	// The scheduled job and the admin endpoint share one lock, so runs never overlap
+	reconcileLock := sql.NewAdvisoryLock(db, "stock-reconciliation")
+	reconcileCmd := commands.NewReconcileStockCommand(
+		stock.NewReconciler(productRepo, stockRepo, snapshotRepo).
+			WithBalances(projectionRepo).
+			WithRepairLog(repairRepo),
+		txManager,
+	).WithLock(reconcileLock)
+	scheduler.Every(ctx, "stock-reconciliation", cfg.ReconcileInterval, func(ctx context.Context) error {
+		report, err := reconcileCmd.Execute(ctx, &dto.ReconcileRequest{Repair: cfg.ReconcileRepair})
+		if errors.Is(err, commands.ErrReconciliationRunning) {
+			logger.Infof("stock reconciliation skipped: another run holds the lock")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, d := range report.Discrepancies {
+			logger.Errorf("stock discrepancy: scope=%s product=%d location=%d stored=%d ledger=%d repaired=%t reason=%q",
+				d.Scope, d.ProductID, d.LocationID, d.Stored, d.Ledger, d.Repaired, d.Reason)
+		}
+		return nil
+	})
+
+	replenishCmd := commands.NewRunReplenishmentCommand(
+		replenishment.NewEngine(replenishmentRepo, stockRepo),
+		txManager,
+	)
+	scheduler.Every(ctx, "replenishment", cfg.ReplenishInterval, func(ctx context.Context) error {
+		tasks, err := replenishCmd.Execute(ctx, &dto.RunReplenishmentRequest{Reference: "SCHEDULED"})
+		if err != nil {
+			return err
+		}
+		if len(tasks) > 0 {
+			logger.Infof("replenishment created %d tasks", len(tasks))
+		}
+		return nil
+	})
+
+	sinks := []outbox.Sink{messaging.NewWebhookSink(webhook.NewPublisher(webhookRepo))}
+	if cfg.NATSURL != "" {
+		natsSink, err := messaging.NewNATSSink(cfg.NATSURL, cfg.NATSSubjectPrefix, 5*time.Second)
+		if err != nil {
+			log.Fatalf("Failed to configure NATS: %v", err)
+		}
+		sinks = append(sinks, natsSink)
+	}
+	if cfg.KafkaRESTURL != "" {
+		sinks = append(sinks, messaging.NewKafkaSink(cfg.KafkaRESTURL, cfg.KafkaTopic, 10*time.Second))
+	}
+	if cfg.OutboxLog {
+		sinks = append(sinks, messaging.NewLogSink(logger))
+	}
+	relay := outbox.NewRelay(outboxRepo, sinks...).
+		WithLock(sql.NewAdvisoryLock(db, "outbox-relay")).
+		WithMaxAttempts(cfg.OutboxMaxAttempts)
+	scheduler.Every(ctx, "outbox-relay", cfg.OutboxInterval, func(ctx context.Context) error {
+		_, err := relay.PublishPending(ctx)
+		return err
+	})
+
+	retryPolicy := webhook.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.WebhookMaxAttempts
+	dispatcher := webhook.NewDispatcher(webhookRepo, webhooks.NewHTTPSender(10*time.Second), retryPolicy).
+		WithLock(sql.NewAdvisoryLock(db, "webhook-delivery"))
+	scheduler.Every(ctx, "webhook-delivery", cfg.WebhookInterval, func(ctx context.Context) error {
+		_, err := dispatcher.DeliverDue(ctx)
+		return err
+	})
+
+	idempotencyService := idempotency.NewService(idempotencyRepo).WithTTL(cfg.IdempotencyTTL)
+	scheduler.Every(ctx, "idempotency-purge", time.Hour, func(ctx context.Context) error {
+		_, err := idempotencyService.Purge(ctx)
+		return err
+	})
+
 	// Setup HTTP server
-	router := http.SetupRouter(cfg, productRepo, locationRepo, stockRepo, txManager)
+	router := http.SetupRouter(cfg, productRepo, locationRepo, stockRepo, txManager,
+		http.WithSnapshotRepository(snapshotRepo),
+		http.WithContainerRepository(containerRepo),
+		http.WithSerialRepository(serialRepo),
+		http.WithPutawayRuleRepository(putawayRuleRepo),
+		http.WithReplenishmentRepository(replenishmentRepo),
+		http.WithReorderRepository(reorderRepo),
+		http.WithWebhookRepository(webhookRepo),
+		http.WithOutboxRepository(outboxRepo),
+		http.WithProjectionRepository(projectionRepo),
+		http.WithRepairRepository(repairRepo),
+		http.WithReconciliationLock(reconcileLock),
+		http.WithIdempotencyRepository(idempotencyRepo),
+	)
 
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.ServerPort)
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	dbsql "database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+)
+
+const migrateUsage = "usage: wms-api migrate up|down|status|to <version>"
+
+// runMigrate handles `wms-api migrate up|down|status|to N`
+func runMigrate(db *dbsql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := sql.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var steps []sql.MigrationStep
+	switch args[0] {
+	case "up":
+		steps, err = migrator.Up(ctx)
+	case "down":
+		steps, err = migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		target, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil || target < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		steps, err = migrator.To(ctx, target)
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	// Report what ran even when a later step failed
+	for _, step := range steps {
+		action := "applied"
+		if step.Revert {
+			action = "reverted"
+		}
+		fmt.Printf("%s %04d_%s\n", action, step.Version, step.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return nil
+}
+
+// printMigrationStatus lists every migration and when it was applied
+func printMigrationStatus(ctx context.Context, migrator *sql.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	dbsql "database/sql"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+)
+
+// app holds the commands and queries wmsctl works through, wired the same way
+// as the API so that changes made here raise the same events and alerts
+type app struct {
+	db *dbsql.DB
+
+	productRepo  product.Repository
+	locationRepo location.Repository
+	stockRepo    stock.Repository
+
+	createProduct   *commands.CreateProductCommand
+	updateProduct   *commands.UpdateProductCommand
+	createLocation  *commands.CreateLocationCommand
+	updateLocation  *commands.UpdateLocationCommand
+	recordMovement  *commands.RecordStockMovementCommand
+	reverseMove     *commands.ReverseStockMovementCommand
+	reconcile       *commands.ReconcileStockCommand
+	importProducts  *commands.ImportProductsCommand
+	importLocations *commands.ImportLocationsCommand
+
+	listProducts  *queries.ListProductsQuery
+	listLocations *queries.ListLocationsQuery
+	listMovements *queries.ListStockMovementsQuery
+	stockAsOf     *queries.GetStockAsOfQuery
+	listBalances  *queries.ListStockBalancesQuery
+	reorderReport *queries.GetReorderSuggestionsQuery
+	export        *queries.ExportQuery
+}
+
+// newApp wires the application layer over the database
+func newApp(db *dbsql.DB) *app {
+	productRepo := sql.NewProductRepository(db)
+	locationRepo := sql.NewLocationRepository(db)
+	stockRepo := sql.NewStockRepository(db)
+	snapshotRepo := sql.NewSnapshotRepository(db)
+	projectionRepo := sql.NewProjectionRepository(db)
+	reorderRepo := sql.NewReorderRepository(db)
+	txManager := sql.NewTransactionManager(db)
+
+	events := outbox.NewOutbox(sql.NewOutboxRepository(db))
+	monitor := replenishment.NewMonitor(reorderRepo, locationRepo, stockRepo)
+
+	stockService := stock.NewService(productRepo, locationRepo, stockRepo).
+		WithBalances(projectionRepo).
+		WithSerials(sql.NewSerialRepository(db))
+	containerRepo := sql.NewContainerRepository(db)
+	putawayService := putaway.NewService(productRepo, locationRepo, stockRepo, stockService).
+		WithRules(sql.NewPutawayRuleRepository(db)).
+		WithLots(containerRepo)
+	recordCmd := commands.NewRecordStockMovementCommand(stockService, txManager).
+		WithPutaway(putawayService).
+		WithAlerts(monitor).
+		WithEvents(events).
+		WithScans(scanning.NewResolver(productRepo, locationRepo).WithContainers(containerRepo))
+	reconciler := stock.NewReconciler(productRepo, stockRepo, snapshotRepo).
+		WithBalances(projectionRepo).
+		WithRepairLog(sql.NewRepairRepository(db))
+
+	return &app{
+		db:           db,
+		productRepo:  productRepo,
+		locationRepo: locationRepo,
+		stockRepo:    stockRepo,
+
+		createProduct:   commands.NewCreateProductCommand(productRepo, txManager).WithEvents(events),
+		updateProduct:   commands.NewUpdateProductCommand(productRepo),
+		createLocation:  commands.NewCreateLocationCommand(locationRepo),
+		updateLocation:  commands.NewUpdateLocationCommand(locationRepo),
+		recordMovement:  recordCmd,
+		reverseMove:     commands.NewReverseStockMovementCommand(recordCmd, stockRepo, txManager),
+		reconcile:       commands.NewReconcileStockCommand(reconciler, txManager).WithLock(sql.NewAdvisoryLock(db, "stock-reconciliation")),
+		importProducts:  commands.NewImportProductsCommand(productRepo, txManager).WithEvents(events),
+		importLocations: commands.NewImportLocationsCommand(locationRepo, txManager),
+
+		listProducts:  queries.NewListProductsQuery(productRepo),
+		listLocations: queries.NewListLocationsQuery(locationRepo),
+		listMovements: queries.NewListStockMovementsQuery(stockRepo),
+		stockAsOf:     queries.NewGetStockAsOfQuery(stock.NewBalanceService(stockRepo, snapshotRepo)),
+		listBalances:  queries.NewListStockBalancesQuery(projectionRepo),
+		reorderReport: queries.NewGetReorderSuggestionsQuery(monitor, productRepo),
+		export:        queries.NewExportQuery(productRepo, locationRepo, stockRepo).WithProjections(projectionRepo),
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// subcommand splits args into the action name and its arguments
+func subcommand(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usageErrorf("missing action")
+	}
+	return args[0], args[1:], nil
+}
+
+// newFlags creates the flag set of one action; -h prints its flags to stderr
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("wmsctl "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseWithTarget parses flags that follow a single positional argument, e.g.
+// `products update 12 -brand Acme`, and returns that argument
+func parseWithTarget(fs *flag.FlagSet, args []string) (string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		if err := fs.Parse(args); err != nil {
+			return "", err
+		}
+		return "", usageErrorf("%s needs an argument", fs.Name())
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return "", err
+	}
+	if fs.NArg() > 0 {
+		return "", usageErrorf("unexpected argument %q", fs.Arg(0))
+	}
+	return args[0], nil
+}
+
+// setFlags returns the names of the flags given on the command line
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// parseID parses a positive numeric ID
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid ID %q", s)
+	}
+	return id, nil
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// findProduct looks a product up by ID, or by SKU when ref is not numeric
+func findProduct(ctx context.Context, a *app, ref string) (*product.Product, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return a.productRepo.GetByID(ctx, id)
+	}
+	return a.productRepo.GetBySKU(ctx, ref)
+}
+
+// findLocation looks a location up by ID, or by code when ref is not numeric
+func findLocation(ctx context.Context, a *app, ref string) (*location.Location, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return a.locationRepo.GetByID(ctx, id)
+	}
+	return a.locationRepo.GetByCode(ctx, ref)
+}
+
+// openOutput returns stdout, or the named file created for writing
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+// nopCloser keeps stdout open when an output is closed
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
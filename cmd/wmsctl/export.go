@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+)
+
+// runExport handles `wmsctl export products|locations|movements|balances|lots
+// [-format csv|ndjson|parquet] [-file path]`. It writes the same files as the
+// export endpoints; products and locations can be imported again.
+func runExport(ctx context.Context, a *app, out *printer, args []string) error {
+	kind, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlags("export " + kind)
+	path := fs.String("file", "-", "file to write, - for stdout")
+	formatName := fs.String("format", "csv", "csv, ndjson or parquet")
+
+	var movements dto.StockMovementFilter
+	var balances dto.BalanceFilter
+	var from, to string
+	switch kind {
+	case "movements":
+		fs.Int64Var(&movements.ProductID, "product", 0, "only movements of this product ID")
+		fs.Int64Var(&movements.LocationID, "location", 0, "only movements at this location ID")
+		fs.StringVar(&movements.Type, "type", "", "only IN or OUT movements")
+		fs.StringVar(&from, "from", "", "only movements created at or after this RFC 3339 time")
+		fs.StringVar(&to, "to", "", "only movements created at or before this RFC 3339 time")
+	case "balances", "lots":
+		fs.Int64Var(&balances.ProductID, "product", 0, "only balances of this product ID")
+		fs.Int64Var(&balances.LocationID, "location", 0, "only balances at this location ID")
+		if kind == "lots" {
+			fs.StringVar(&balances.Lot, "lot", "", "only this lot")
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := spreadsheet.ParseFormat(*formatName)
+	if err != nil {
+		return usageErrorf("%v", err)
+	}
+	if movements.From, err = parseExportTime("from", from); err != nil {
+		return err
+	}
+	if movements.To, err = parseExportTime("to", to); err != nil {
+		return err
+	}
+
+	var export func(w queries.ExportWriter) (int64, error)
+	switch kind {
+	case "products":
+		export = func(w queries.ExportWriter) (int64, error) { return a.export.Products(ctx, w) }
+	case "locations":
+		export = func(w queries.ExportWriter) (int64, error) { return a.export.Locations(ctx, w) }
+	case "movements":
+		export = func(w queries.ExportWriter) (int64, error) { return a.export.Movements(ctx, &movements, w) }
+	case "balances":
+		export = func(w queries.ExportWriter) (int64, error) { return a.export.Balances(ctx, &balances, w) }
+	case "lots":
+		export = func(w queries.ExportWriter) (int64, error) { return a.export.Lots(ctx, &balances, w) }
+	default:
+		return usageErrorf("cannot export %q", kind)
+	}
+
+	file, err := openOutput(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := &fileExportWriter{file: file, format: format}
+	count, err := export(w)
+	if err != nil {
+		return err
+	}
+	if err := w.out.Close(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// Keep stdout clean for the file itself
+	if *path != "-" {
+		return out.message("exported %d %s to %s", count, kind, *path)
+	}
+	return nil
+}
+
+// parseExportTime reads an optional RFC 3339 flag value
+func parseExportTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -%s %q, want RFC 3339", flag, value)
+	}
+	return t, nil
+}
+
+// fileExportWriter writes export rows to a file in the chosen format
+type fileExportWriter struct {
+	file   io.Writer
+	format spreadsheet.Format
+	out    spreadsheet.Writer
+}
+
+// Begin starts the file
+func (w *fileExportWriter) Begin(columns []dto.ExportColumn) error {
+	cols := make([]spreadsheet.Column, len(columns))
+	for i, c := range columns {
+		cols[i] = spreadsheet.Column{Name: c.Name, Type: spreadsheet.ColumnType(c.Type)}
+	}
+
+	out, err := spreadsheet.NewWriter(w.file, w.format, cols)
+	if err != nil {
+		return err
+	}
+	w.out = out
+	return nil
+}
+
+// WriteRow writes one row
+func (w *fileExportWriter) WriteRow(values []interface{}) error {
+	return w.out.WriteRow(values)
+}
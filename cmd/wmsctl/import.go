@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+)
+
+// runImport handles `wmsctl import products|locations <file> [-dry-run]`. The
+// file may be CSV or XLSX; - reads it from stdin. Rows are matched on SKU or
+// location code and saved together in one transaction, or not at all.
+func runImport(ctx context.Context, a *app, out *printer, args []string) error {
+	kind, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	var execute func(ctx context.Context, table [][]string, dryRun bool) (*dto.ImportReportResponse, error)
+	switch kind {
+	case "products":
+		execute = a.importProducts.Execute
+	case "locations":
+		execute = a.importLocations.Execute
+	default:
+		return usageErrorf("cannot import %q", kind)
+	}
+
+	fs := newFlags("import " + kind)
+	dryRun := fs.Bool("dry-run", false, "validate every row without saving anything")
+	path, err := parseWithTarget(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	table, err := spreadsheet.Read(in)
+	if err != nil {
+		return err
+	}
+	report, err := execute(ctx, table, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if err := out.print(report, []string{"ROW", "KEY", "ACTION", "ERRORS"}, func(row func(cells ...interface{})) {
+		for _, r := range report.Results {
+			row(r.Row, r.Key, r.Action, strings.Join(r.Errors, "; "))
+		}
+	}); err != nil {
+		return err
+	}
+
+	switch {
+	case report.Failed > 0:
+		return fmt.Errorf("%d of %d rows failed, nothing was saved", report.Failed, report.Rows)
+	case out.json:
+		return nil
+	case report.DryRun:
+		return out.message("dry run: %d to create, %d to update, nothing was saved", report.Created, report.Updated)
+	}
+	return out.message("created %d and updated %d %s", report.Created, report.Updated, report.Kind)
+}
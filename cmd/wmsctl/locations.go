@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+)
+
+// runLocations handles `wmsctl locations list|get|create|update|delete`
+func runLocations(ctx context.Context, a *app, out *printer, args []string) error {
+	action, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		fs := newFlags("locations list")
+		limit := fs.Int("limit", 50, "maximum number of locations")
+		offset := fs.Int("offset", 0, "number of locations to skip")
+		after := fs.String("after", "", "continue after the last page printed, instead of -offset")
+		var filter dto.LocationFilter
+		fs.StringVar(&filter.CodePrefix, "code-prefix", "", "only codes starting with this")
+		fs.StringVar(&filter.Warehouse, "warehouse", "", "only locations in this warehouse")
+		fs.StringVar(&filter.TemperatureZone, "zone", "", "only AMBIENT, CHILLED or FROZEN locations")
+		fs.StringVar(&filter.Search, "search", "", "text to look for in code, name and warehouse")
+		fs.StringVar(&filter.Sort, "sort", "", "fields to sort by, - for descending, e.g. code")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		result, err := a.listLocations.Execute(ctx, &filter, dto.PageRequest{Limit: *limit, Offset: *offset, After: *after})
+		if err != nil {
+			return err
+		}
+		if err := printLocations(out, result, result.Data...); err != nil {
+			return err
+		}
+		return out.more(result.NextCursor)
+
+	case "get":
+		ref, err := parseWithTarget(newFlags("locations get"), args)
+		if err != nil {
+			return err
+		}
+		loc, err := findLocation(ctx, a, ref)
+		if err != nil {
+			return err
+		}
+		result := dto.NewLocationResponse(loc)
+		return printLocations(out, result, result)
+
+	case "create":
+		fs := newFlags("locations create")
+		var req dto.LocationRequest
+		locationFlags(fs, &req)
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		result, err := a.createLocation.Execute(ctx, &req)
+		if err != nil {
+			return err
+		}
+		return printLocations(out, result, result)
+
+	case "update":
+		if len(args) == 0 {
+			return usageErrorf("locations update needs a location ID or code")
+		}
+		loc, err := findLocation(ctx, a, args[0])
+		if err != nil {
+			return err
+		}
+
+		// Flags default to the stored settings, so only the ones given change them
+		current := dto.NewLocationResponse(loc)
+		req := dto.LocationRequest{
+			Code:            current.Code,
+			Name:            current.Name,
+			Capacity:        current.Capacity,
+			MaxVolumeCM3:    current.MaxVolumeCM3,
+			MaxWeightGrams:  current.MaxWeightGrams,
+			PalletPositions: current.PalletPositions,
+			TemperatureZone: current.TemperatureZone,
+			Warehouse:       current.Warehouse,
+		}
+		fs := newFlags("locations update")
+		version := fs.Int64("version", 0, "only update if the location is still at this version")
+		locationFlags(fs, &req)
+		if _, err := parseWithTarget(fs, args); err != nil {
+			return err
+		}
+
+		result, err := a.updateLocation.Execute(ctx, loc.ID, *version, &req)
+		if err != nil {
+			return err
+		}
+		return printLocations(out, result, result)
+
+	case "delete":
+		fs := newFlags("locations delete")
+		version := fs.Int64("version", 0, "only delete if the location is still at this version")
+		ref, err := parseWithTarget(fs, args)
+		if err != nil {
+			return err
+		}
+		loc, err := findLocation(ctx, a, ref)
+		if err != nil {
+			return err
+		}
+		if *version == 0 {
+			*version = loc.Version
+		}
+
+		if err := a.locationRepo.Delete(ctx, loc.ID, *version); err != nil {
+			return err
+		}
+		return out.message("deleted location %d (%s)", loc.ID, loc.Code)
+	}
+
+	return usageErrorf("unknown locations action %q", action)
+}
+
+// locationFlags registers the location setting flags shared by create and
+// update, defaulting to the values already in req
+func locationFlags(fs *flag.FlagSet, req *dto.LocationRequest) {
+	fs.StringVar(&req.Code, "code", req.Code, "location code")
+	fs.StringVar(&req.Name, "name", req.Name, "location name")
+	fs.Int64Var(&req.Capacity, "capacity", req.Capacity, "capacity in base units")
+	fs.Int64Var(&req.MaxVolumeCM3, "max-volume-cm3", req.MaxVolumeCM3, "maximum volume in cm3, 0 for no limit")
+	fs.Int64Var(&req.MaxWeightGrams, "max-weight-grams", req.MaxWeightGrams, "maximum weight in grams, 0 for no limit")
+	fs.Int64Var(&req.PalletPositions, "pallet-positions", req.PalletPositions, "pallet positions, 0 for no limit")
+	fs.StringVar(&req.TemperatureZone, "zone", req.TemperatureZone, "AMBIENT, CHILLED or FROZEN")
+	fs.StringVar(&req.Warehouse, "warehouse", req.Warehouse, "warehouse the location belongs to")
+}
+
+// printLocations prints locations as a table, or v as JSON
+func printLocations(out *printer, v interface{}, locations ...*dto.LocationResponse) error {
+	header := []string{"ID", "CODE", "NAME", "CAPACITY", "ZONE", "WAREHOUSE", "VERSION"}
+	return out.print(v, header, func(row func(cells ...interface{})) {
+		for _, l := range locations {
+			row(l.ID, l.Code, l.Name, l.Capacity, l.TemperatureZone, l.Warehouse, l.Version)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+// Command wmsctl administers the warehouse from the shell. It works on the
+// same commands and queries as the API, so every change it makes is validated,
+// posted to the ledger and published exactly as if it came in over HTTP.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+)
+
+const usage = `usage: wmsctl [-o table|json] <command> [arguments]
+
+commands:
+  products   list|get|create|update|delete    manage products
+  locations  list|get|create|update|delete    manage locations
+  movements  list|get|post|reverse            post and reverse stock movements
+  users      token                            issue API tokens
+  migrate    up|down|status|to <version>      run database migrations
+  reconcile                                   compare the ledger with stored quantities
+  import     products|locations <file>        create or update from CSV or XLSX
+  export     products|locations|movements|balances|lots
+                                              write CSV, NDJSON or Parquet to stdout or -file
+  report     stock|balances|lots|reorder      print stock reports
+
+Run "wmsctl <command> -h" for the flags of a command.`
+
+// usageError reports a malformed command line; main prints the usage after it
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usageErrorf formats a usage error
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	global := flag.NewFlagSet("wmsctl", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	format := global.String("o", "table", "output format: table or json")
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	out, err := newPrinter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "wmsctl:", err)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, out, args[0], args[1:]); err != nil {
+		var usageErr *usageError
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			os.Exit(2)
+		case errors.As(err, &usageErr):
+			fmt.Fprintf(os.Stderr, "wmsctl: %v\n\n%s\n", err, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "wmsctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the configuration and dispatches to the named command
+func run(ctx context.Context, out *printer, name string, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Issuing tokens only needs the secret, not the database
+	if name == "users" {
+		return runUsers(cfg, out, args)
+	}
+
+	handlers := map[string]func(ctx context.Context, a *app, out *printer, args []string) error{
+		"products":  runProducts,
+		"locations": runLocations,
+		"movements": runMovements,
+		"migrate":   runMigrate,
+		"reconcile": runReconcile,
+		"import":    runImport,
+		"export":    runExport,
+		"report":    runReport,
+	}
+	handler, ok := handlers[name]
+	if !ok {
+		return usageErrorf("unknown command %q", name)
+	}
+
+	db, err := sql.NewDB(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return handler(ctx, newApp(db), out, args)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+)
+
+// migrationResult is one migration in `migrate` output
+type migrationResult struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Action    string     `json:"action,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// runMigrate handles `wmsctl migrate up|down|status|to N`
+func runMigrate(ctx context.Context, a *app, out *printer, args []string) error {
+	action, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := sql.NewMigrator(a.db)
+	if err != nil {
+		return err
+	}
+
+	var steps []sql.MigrationStep
+	switch action {
+	case "up":
+		steps, err = migrator.Up(ctx)
+	case "down":
+		steps, err = migrator.Down(ctx)
+	case "to":
+		if len(args) != 1 {
+			return usageErrorf("migrate to needs a version")
+		}
+		target, parseErr := strconv.ParseInt(args[0], 10, 64)
+		if parseErr != nil || target < 0 {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		steps, err = migrator.To(ctx, target)
+	case "status":
+		return printMigrationStatus(ctx, migrator, out)
+	default:
+		return usageErrorf("unknown migrate action %q", action)
+	}
+
+	// Report what ran even when a later step failed
+	results := make([]migrationResult, 0, len(steps))
+	for _, step := range steps {
+		action := "applied"
+		if step.Revert {
+			action = "reverted"
+		}
+		results = append(results, migrationResult{Version: step.Version, Name: step.Name, Action: action})
+	}
+	printErr := out.print(results, []string{"VERSION", "NAME", "ACTION"}, func(row func(cells ...interface{})) {
+		for _, r := range results {
+			row(fmt.Sprintf("%04d", r.Version), r.Name, r.Action)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return printErr
+}
+
+// printMigrationStatus lists every migration and when it was applied
+func printMigrationStatus(ctx context.Context, migrator *sql.Migrator, out *printer) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make([]migrationResult, 0, len(statuses))
+	for _, s := range statuses {
+		results = append(results, migrationResult{Version: s.Version, Name: s.Name, AppliedAt: s.AppliedAt})
+	}
+	return out.print(results, []string{"VERSION", "NAME", "APPLIED AT"}, func(row func(cells ...interface{})) {
+		for _, r := range results {
+			applied := "pending"
+			if r.AppliedAt != nil {
+				applied = r.AppliedAt.Format(time.RFC3339)
+			}
+			row(fmt.Sprintf("%04d", r.Version), r.Name, applied)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// runMovements handles `wmsctl movements list|get|post|reverse`
+func runMovements(ctx context.Context, a *app, out *printer, args []string) error {
+	action, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		fs := newFlags("movements list")
+		limit := fs.Int("limit", 50, "maximum number of movements")
+		offset := fs.Int("offset", 0, "number of movements to skip")
+		after := fs.String("after", "", "continue after the last page printed, instead of -offset")
+		var filter dto.StockMovementFilter
+		fs.Int64Var(&filter.ProductID, "product", 0, "only movements of this product ID")
+		fs.Int64Var(&filter.LocationID, "location", 0, "only movements at this location ID")
+		fs.StringVar(&filter.Type, "type", "", "IN or OUT")
+		fs.StringVar(&filter.ReferenceType, "ref-type", "", "only movements with this reference type")
+		fs.StringVar(&filter.ReferenceID, "ref-id", "", "only movements with this reference ID")
+		fs.StringVar(&filter.DocumentNumber, "doc", "", "only movements with this document number")
+		fs.StringVar(&filter.Search, "search", "", "text to look for in reference, document number, notes and lot")
+		fs.StringVar(&filter.Sort, "sort", "", "fields to sort by, - for descending, e.g. -quantity")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		result, err := a.listMovements.Execute(ctx, &filter, dto.PageRequest{Limit: *limit, Offset: *offset, After: *after})
+		if err != nil {
+			return err
+		}
+		if err := printMovements(out, result, result.Data...); err != nil {
+			return err
+		}
+		return out.more(result.NextCursor)
+
+	case "get":
+		ref, err := parseWithTarget(newFlags("movements get"), args)
+		if err != nil {
+			return err
+		}
+		id, err := parseID(ref)
+		if err != nil {
+			return err
+		}
+		movement, err := a.stockRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		result := dto.NewStockMovementResponse(movement)
+		return printMovements(out, result, result)
+
+	case "post":
+		fs := newFlags("movements post")
+		productRef := fs.String("product", "", "product ID or SKU; omit with -scan")
+		locationRef := fs.String("location", "", "location ID or code; omit with -auto-assign")
+		serials := fs.String("serials", "", "comma-separated serials for serialized products")
+		var req dto.RecordStockMovementRequest
+		fs.StringVar(&req.Type, "type", "", "IN or OUT (required)")
+		fs.Int64Var(&req.Quantity, "quantity", 0, "quantity in -uom units (required)")
+		fs.StringVar(&req.UOM, "uom", "", "EACH, INNER, CASE or PALLET")
+		fs.StringVar(&req.Lot, "lot", "", "lot or batch")
+		fs.StringVar(&req.ReferenceType, "ref-type", "", "PO, ORDER, RMA, TRANSFER or COUNT")
+		fs.StringVar(&req.ReferenceID, "ref-id", "", "reference ID")
+		fs.StringVar(&req.DocumentNumber, "doc", "", "document number")
+		fs.StringVar(&req.Notes, "notes", "", "notes")
+		fs.BoolVar(&req.AutoAssign, "auto-assign", false, "put an IN movement away to the best location")
+		fs.StringVar(&req.Scan, "scan", "", "raw product or GS1 pallet label scan standing in for -product")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if (*productRef == "" && req.Scan == "") || req.Type == "" {
+			return usageErrorf("-type and -product or -scan are required")
+		}
+		if req.Quantity <= 0 && req.Scan == "" {
+			return usageErrorf("a positive -quantity is required unless -scan carries a count")
+		}
+		// Adjustments and reversals are only posted by their own commands
+		switch stock.ReferenceType(req.ReferenceType) {
+		case stock.ReferenceTypeAdjustment, stock.ReferenceTypeReversal:
+			return usageErrorf("-ref-type %s cannot be posted directly", req.ReferenceType)
+		}
+
+		if *productRef != "" {
+			prod, err := findProduct(ctx, a, *productRef)
+			if err != nil {
+				return err
+			}
+			req.ProductID = prod.ID
+		}
+		if *locationRef != "" {
+			loc, err := findLocation(ctx, a, *locationRef)
+			if err != nil {
+				return err
+			}
+			req.LocationID = loc.ID
+		}
+		req.Serials = splitList(*serials)
+
+		result, err := a.recordMovement.Execute(ctx, &req)
+		if err != nil {
+			return err
+		}
+		return printMovements(out, result, result)
+
+	case "reverse":
+		fs := newFlags("movements reverse")
+		serials := fs.String("serials", "", "comma-separated serials, required for serialized products")
+		var req dto.ReverseStockMovementRequest
+		fs.StringVar(&req.Notes, "notes", "", "why the movement is reversed")
+		ref, err := parseWithTarget(fs, args)
+		if err != nil {
+			return err
+		}
+		id, err := parseID(ref)
+		if err != nil {
+			return err
+		}
+		req.Serials = splitList(*serials)
+
+		result, err := a.reverseMove.Execute(ctx, id, &req)
+		if err != nil {
+			return err
+		}
+		return printMovements(out, result, result)
+	}
+
+	return usageErrorf("unknown movements action %q", action)
+}
+
+// printMovements prints movements as a table, or v as JSON
+func printMovements(out *printer, v interface{}, movements ...*dto.StockMovementResponse) error {
+	header := []string{"ID", "PRODUCT", "LOCATION", "TYPE", "QUANTITY", "LOT", "REFERENCE", "DOCUMENT", "CREATED AT"}
+	return out.print(v, header, func(row func(cells ...interface{})) {
+		for _, m := range movements {
+			reference := m.ReferenceType
+			if m.ReferenceID != "" {
+				reference += " " + m.ReferenceID
+			}
+			row(m.ID, m.ProductID, m.LocationID, m.Type, m.Quantity, m.Lot, reference, m.DocumentNumber,
+				m.CreatedAt.Format(time.RFC3339))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes command results as an aligned table or as JSON
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// newPrinter creates a printer for the table or json output format
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, want table or json", format)
+}
+
+// print writes v as indented JSON, or as a table with the given header whose
+// rows are written by rows, one tab-separated line per row
+func (p *printer) print(v interface{}, header []string, rows func(row func(cells ...interface{}))) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	rows(func(cells ...interface{}) {
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			parts[i] = fmt.Sprint(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(parts, "\t"))
+	})
+	return tw.Flush()
+}
+
+// more tells table readers how to get the next page of a listing; JSON output
+// already carries the cursor
+func (p *printer) more(cursor string) error {
+	if p.json || cursor == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(p.w, "more rows follow, continue with -after %s\n", cursor)
+	return err
+}
+
+// message writes a one-line confirmation, or {"message": ...} as JSON
+func (p *printer) message(format string, args ...interface{}) error {
+	text := fmt.Sprintf(format, args...)
+	if p.json {
+		return json.NewEncoder(p.w).Encode(map[string]string{"message": text})
+	}
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// runProducts handles `wmsctl products list|get|create|update|delete`
+func runProducts(ctx context.Context, a *app, out *printer, args []string) error {
+	action, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		fs := newFlags("products list")
+		limit := fs.Int("limit", 50, "maximum number of products")
+		offset := fs.Int("offset", 0, "number of products to skip")
+		after := fs.String("after", "", "continue after the last page printed, instead of -offset")
+		uom := fs.String("uom", "", "also show quantities in this pack level")
+		var filter dto.ProductFilter
+		fs.StringVar(&filter.SKUPrefix, "sku-prefix", "", "only SKUs starting with this")
+		fs.StringVar(&filter.Category, "category", "", "only products in this category")
+		fs.StringVar(&filter.Status, "status", "", "only ACTIVE or INACTIVE products")
+		fs.StringVar(&filter.Search, "search", "", "text to look for in SKU, description, category and brand")
+		fs.StringVar(&filter.Sort, "sort", "", "fields to sort by, - for descending, e.g. -quantity,sku_name")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		result, err := a.listProducts.Execute(ctx, &filter, dto.PageRequest{Limit: *limit, Offset: *offset, After: *after}, product.PackLevel(*uom))
+		if err != nil {
+			return err
+		}
+		if err := printProducts(out, result, result.Data...); err != nil {
+			return err
+		}
+		return out.more(result.NextCursor)
+
+	case "get":
+		ref, err := parseWithTarget(newFlags("products get"), args)
+		if err != nil {
+			return err
+		}
+		prod, err := findProduct(ctx, a, ref)
+		if err != nil {
+			return err
+		}
+		result := dto.NewProductResponse(prod)
+		return printProducts(out, result, result)
+
+	case "create":
+		fs := newFlags("products create")
+		var req dto.CreateProductRequest
+		fs.StringVar(&req.SKUName, "sku", "", "SKU name (required)")
+		productFlags(fs, &req.Description, &req.Category, &req.Brand, &req.BaseUOM, &req.Status, &req.Serialized)
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if req.SKUName == "" {
+			return usageErrorf("-sku is required")
+		}
+
+		result, err := a.createProduct.Execute(ctx, &req)
+		if err != nil {
+			return err
+		}
+		return printProducts(out, result, result)
+
+	case "update":
+		fs := newFlags("products update")
+		version := fs.Int64("version", 0, "only update if the product is still at this version")
+		sku := fs.String("sku", "", "new SKU name")
+		var data dto.CreateProductRequest
+		productFlags(fs, &data.Description, &data.Category, &data.Brand, &data.BaseUOM, &data.Status, &data.Serialized)
+		ref, err := parseWithTarget(fs, args)
+		if err != nil {
+			return err
+		}
+		prod, err := findProduct(ctx, a, ref)
+		if err != nil {
+			return err
+		}
+
+		// Only the flags that were given change the product
+		var req dto.UpdateProductRequest
+		set := setFlags(fs)
+		if set["sku"] {
+			req.SKUName = sku
+		}
+		if set["description"] {
+			req.Description = &data.Description
+		}
+		if set["category"] {
+			req.Category = &data.Category
+		}
+		if set["brand"] {
+			req.Brand = &data.Brand
+		}
+		if set["base-uom"] {
+			req.BaseUOM = &data.BaseUOM
+		}
+		if set["status"] {
+			req.Status = &data.Status
+		}
+		if set["serialized"] {
+			req.Serialized = &data.Serialized
+		}
+
+		result, err := a.updateProduct.Execute(ctx, prod.ID, *version, &req)
+		if err != nil {
+			return err
+		}
+		return printProducts(out, result, result)
+
+	case "delete":
+		fs := newFlags("products delete")
+		version := fs.Int64("version", 0, "only delete if the product is still at this version")
+		ref, err := parseWithTarget(fs, args)
+		if err != nil {
+			return err
+		}
+		prod, err := findProduct(ctx, a, ref)
+		if err != nil {
+			return err
+		}
+		if *version == 0 {
+			*version = prod.Version
+		}
+
+		if err := a.productRepo.Delete(ctx, prod.ID, *version); err != nil {
+			return err
+		}
+		return out.message("deleted product %d (%s)", prod.ID, prod.SKUName)
+	}
+
+	return usageErrorf("unknown products action %q", action)
+}
+
+// productFlags registers the master data flags shared by create and update
+func productFlags(fs *flag.FlagSet, description, category, brand, baseUOM, status *string, serialized *bool) {
+	fs.StringVar(description, "description", "", "description")
+	fs.StringVar(category, "category", "", "category")
+	fs.StringVar(brand, "brand", "", "brand")
+	fs.StringVar(baseUOM, "base-uom", "", "base unit of measure, e.g. EA")
+	fs.StringVar(status, "status", "", "ACTIVE or INACTIVE")
+	fs.BoolVar(serialized, "serialized", false, "track a serial number per unit")
+}
+
+// printProducts prints products as a table, or v as JSON
+func printProducts(out *printer, v interface{}, products ...*dto.ProductResponse) error {
+	header := []string{"ID", "SKU", "QUANTITY", "CATEGORY", "BRAND", "STATUS", "VERSION"}
+	return out.print(v, header, func(row func(cells ...interface{})) {
+		for _, p := range products {
+			row(p.ID, p.SKUName, p.Quantity, p.Category, p.Brand, p.Status, p.Version)
+		}
+	})
+}
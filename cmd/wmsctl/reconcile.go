@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+)
+
+// runReconcile handles `wmsctl reconcile [-repair]`
+func runReconcile(ctx context.Context, a *app, out *printer, args []string) error {
+	fs := newFlags("reconcile")
+	var req dto.ReconcileRequest
+	fs.BoolVar(&req.Repair, "repair", false, "reset drifted stored quantities and balances to the ledger")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := a.reconcile.Execute(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	header := []string{"SCOPE", "PRODUCT", "LOCATION", "STORED", "LEDGER", "DIFFERENCE", "REPAIRED", "REASON"}
+	if err := out.print(report, header, func(row func(cells ...interface{})) {
+		for _, d := range report.Discrepancies {
+			row(d.Scope, d.ProductID, d.LocationID, d.Stored, d.Ledger, d.Difference, d.Repaired, d.Reason)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if out.json {
+		return nil
+	}
+	return out.message("checked %d products and %d locations in %s, %d discrepancies",
+		report.ProductsChecked, report.LocationsChecked,
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond), len(report.Discrepancies))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+)
+
+// runReport handles `wmsctl report stock|balances|lots|reorder`
+func runReport(ctx context.Context, a *app, out *printer, args []string) error {
+	action, args, err := subcommand(args)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "stock":
+		fs := newFlags("report stock")
+		at := fs.String("at", "", "point in time as RFC 3339, default now")
+		byLocation := fs.Bool("by-location", false, "break totals down per location")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		timestamp := time.Now()
+		if *at != "" {
+			if timestamp, err = time.Parse(time.RFC3339, *at); err != nil {
+				return fmt.Errorf("invalid -at %q, want RFC 3339", *at)
+			}
+		}
+
+		result, err := a.stockAsOf.Execute(ctx, timestamp)
+		if err != nil {
+			return err
+		}
+		if *byLocation {
+			return printLocationStock(out, result, result.Locations)
+		}
+		return out.print(result, []string{"PRODUCT", "QUANTITY"}, func(row func(cells ...interface{})) {
+			for _, p := range result.Products {
+				row(p.ProductID, p.Quantity)
+			}
+		})
+
+	case "balances", "lots":
+		fs := newFlags("report " + action)
+		var filter dto.BalanceFilter
+		fs.Int64Var(&filter.ProductID, "product", 0, "only this product ID")
+		fs.Int64Var(&filter.LocationID, "location", 0, "only this location ID")
+		if action == "lots" {
+			fs.StringVar(&filter.Lot, "lot", "", "only this lot")
+		}
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		if action == "balances" {
+			result, err := a.listBalances.Locations(ctx, &filter)
+			if err != nil {
+				return err
+			}
+			return printLocationStock(out, result, result)
+		}
+
+		result, err := a.listBalances.Lots(ctx, &filter)
+		if err != nil {
+			return err
+		}
+		return out.print(result, []string{"PRODUCT", "LOCATION", "LOT", "QUANTITY"}, func(row func(cells ...interface{})) {
+			for _, l := range result {
+				row(l.ProductID, l.LocationID, l.Lot, l.Quantity)
+			}
+		})
+
+	case "reorder":
+		if err := newFlags("report reorder").Parse(args); err != nil {
+			return err
+		}
+
+		result, err := a.reorderReport.Execute(ctx)
+		if err != nil {
+			return err
+		}
+		header := []string{"PRODUCT", "SKU", "WAREHOUSE", "AVAILABLE", "REORDER POINT", "SAFETY STOCK", "SEVERITY", "SUGGESTED"}
+		return out.print(result, header, func(row func(cells ...interface{})) {
+			for _, s := range result {
+				row(s.ProductID, s.SKUName, s.Warehouse, s.Available, s.ReorderPoint, s.SafetyStock, s.Severity, s.SuggestedQty)
+			}
+		})
+	}
+
+	return usageErrorf("unknown report %q", action)
+}
+
+// printLocationStock prints per-location quantities as a table, or v as JSON
+func printLocationStock(out *printer, v interface{}, balances []*dto.LocationStockResponse) error {
+	return out.print(v, []string{"PRODUCT", "LOCATION", "QUANTITY"}, func(row func(cells ...interface{})) {
+		for _, b := range balances {
+			row(b.ProductID, b.LocationID, b.Quantity)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// AdjustStockCommand handles explicit quantity corrections for a product
+type AdjustStockCommand struct {
+	recordCmd *RecordStockMovementCommand
+}
+
+// NewAdjustStockCommand creates a new adjust stock command
+func NewAdjustStockCommand(recordCmd *RecordStockMovementCommand) *AdjustStockCommand {
+	return &AdjustStockCommand{
+		recordCmd: recordCmd,
+	}
+}
+
+// Execute posts the correction as an ADJUSTMENT movement, so it is validated
+// and recorded exactly like any other stock movement
+func (c *AdjustStockCommand) Execute(ctx context.Context, productID int64, req *dto.AdjustStockRequest) (*dto.StockMovementResponse, error) {
+	if req.Delta == 0 {
+		return nil, stock.ErrInvalidQuantity
+	}
+
+	movementType := stock.MovementTypeIN
+	quantity := req.Delta
+	if quantity < 0 {
+		movementType = stock.MovementTypeOUT
+		quantity = -quantity
+	}
+
+	return c.recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{
+		ProductID:      productID,
+		LocationID:     req.LocationID,
+		Type:           string(movementType),
+		Quantity:       quantity,
+		ReferenceType:  string(stock.ReferenceTypeAdjustment),
+		DocumentNumber: req.DocumentNumber,
+		Notes:          req.Reason,
+		Serials:        req.Serials,
+	})
+}
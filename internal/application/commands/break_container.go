@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+)
+
+// BreakContainerCommand handles breaking a container down into loose stock
+type BreakContainerCommand struct {
+	containerRepo    container.Repository
+	containerService *container.Service
+	txManager        application.TransactionManager
+}
+
+// NewBreakContainerCommand creates a new break container command
+func NewBreakContainerCommand(containerRepo container.Repository, txManager application.TransactionManager) *BreakContainerCommand {
+	return &BreakContainerCommand{
+		containerRepo:    containerRepo,
+		containerService: container.NewService(containerRepo),
+		txManager:        txManager,
+	}
+}
+
+// Execute breaks the container identified by LPN or SSCC. Its own contents become
+// loose stock and nested containers become top-level containers at the same location.
+func (c *BreakContainerCommand) Execute(ctx context.Context, code string) (*dto.ContainerResponse, error) {
+	var broken *container.Container
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		cont, err := c.containerService.Find(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		children, err := c.containerRepo.GetChildren(ctx, cont.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := cont.Break(); err != nil {
+			return err
+		}
+		if err := c.containerRepo.Update(ctx, cont); err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			child.ParentID = nil
+			if err := c.containerRepo.Update(ctx, child); err != nil {
+				return err
+			}
+		}
+
+		broken = cont
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewContainerResponse(&container.Tree{Container: broken}), nil
+}
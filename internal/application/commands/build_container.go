@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// BuildContainerCommand handles packing loose stock and other containers into a new container
+type BuildContainerCommand struct {
+	containerRepo    container.Repository
+	containerService *container.Service
+	productRepo      product.Repository
+	locationRepo     location.Repository
+	stockRepo        stock.Repository
+	txManager        application.TransactionManager
+}
+
+// NewBuildContainerCommand creates a new build container command
+func NewBuildContainerCommand(
+	containerRepo container.Repository,
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	txManager application.TransactionManager,
+) *BuildContainerCommand {
+	return &BuildContainerCommand{
+		containerRepo:    containerRepo,
+		containerService: container.NewService(containerRepo),
+		productRepo:      productRepo,
+		locationRepo:     locationRepo,
+		stockRepo:        stockRepo,
+		txManager:        txManager,
+	}
+}
+
+// Execute builds the container. Stock stays at its location, so no movements are
+// written; the contents must come from stock not already held in a container.
+func (c *BuildContainerCommand) Execute(ctx context.Context, req *dto.BuildContainerRequest) (*dto.ContainerResponse, error) {
+	cont, err := container.NewContainer(req.LPN, req.SSCC, container.Type(req.Type), req.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree *container.Tree
+	err = runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		if _, err := c.locationRepo.GetByID(ctx, req.LocationID); err != nil {
+			return err
+		}
+
+		if _, err := c.containerRepo.GetByLPN(ctx, cont.LPN); err == nil {
+			return container.ErrDuplicateLPN
+		} else if err != container.ErrContainerNotFound {
+			return err
+		}
+
+		requested := make(map[int64]int64)
+		for _, item := range req.Contents {
+			prod, err := c.productRepo.GetByID(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			// Container contents carry no serials, so moving them could not update serial history
+			if prod.Serialized {
+				return container.ErrSerializedContent
+			}
+			if err := cont.AddContent(item.ProductID, item.Lot, item.Quantity); err != nil {
+				return err
+			}
+			requested[item.ProductID] += item.Quantity
+		}
+
+		for productID, qty := range requested {
+			loose, err := c.looseQuantity(ctx, req.LocationID, productID)
+			if err != nil {
+				return err
+			}
+			if loose < qty {
+				return container.ErrInsufficientLoose
+			}
+		}
+
+		// Children must be found before the parent is saved so a bad LPN fails early
+		var children []*container.Container
+		for _, lpn := range req.ChildLPNs {
+			child, err := c.containerService.Find(ctx, lpn)
+			if err != nil {
+				return err
+			}
+			children = append(children, child)
+		}
+
+		if err := c.containerRepo.Create(ctx, cont); err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if err := cont.Nest(child); err != nil {
+				return err
+			}
+			if err := c.containerRepo.Update(ctx, child); err != nil {
+				return err
+			}
+		}
+
+		tree, err = c.containerService.LoadTree(ctx, cont)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewContainerResponse(tree), nil
+}
+
+// looseQuantity is the product's ledger balance at a location minus what active
+// containers there already hold
+func (c *BuildContainerCommand) looseQuantity(ctx context.Context, locationID, productID int64) (int64, error) {
+	movements, err := c.stockRepo.GetByLocation(ctx, locationID)
+	if err != nil {
+		return 0, err
+	}
+
+	var balance int64
+	for _, m := range movements {
+		if m.ProductID != productID {
+			continue
+		}
+		if m.IsInbound() {
+			balance += m.Quantity
+		} else {
+			balance -= m.Quantity
+		}
+	}
+
+	packed, err := c.containerRepo.QuantityAt(ctx, locationID, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance - packed, nil
+}
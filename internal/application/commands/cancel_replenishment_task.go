@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// CancelReplenishmentTaskCommand handles dropping a replenishment task without moving stock
+type CancelReplenishmentTaskCommand struct {
+	replenishmentRepo replenishment.Repository
+	txManager         application.TransactionManager
+}
+
+// NewCancelReplenishmentTaskCommand creates a new cancel replenishment task command
+func NewCancelReplenishmentTaskCommand(replenishmentRepo replenishment.Repository, txManager application.TransactionManager) *CancelReplenishmentTaskCommand {
+	return &CancelReplenishmentTaskCommand{
+		replenishmentRepo: replenishmentRepo,
+		txManager:         txManager,
+	}
+}
+
+// Execute cancels the task; its quantity stops counting towards the pick face
+// on the next replenishment run
+func (c *CancelReplenishmentTaskCommand) Execute(ctx context.Context, id int64) (*dto.ReplenishmentTaskResponse, error) {
+	var task *replenishment.Task
+
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		var err error
+		task, err = c.replenishmentRepo.GetTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := task.Cancel(); err != nil {
+			return err
+		}
+		return c.replenishmentRepo.UpdateTask(ctx, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewReplenishmentTaskResponse(task), nil
+}
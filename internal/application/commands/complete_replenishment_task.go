@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// CompleteReplenishmentTaskCommand handles confirming a replenishment task was carried out
+type CompleteReplenishmentTaskCommand struct {
+	replenishmentRepo replenishment.Repository
+	stockService      *stock.Service
+	txManager         application.TransactionManager
+	monitor           *replenishment.Monitor
+	events            *outbox.Outbox
+}
+
+// NewCompleteReplenishmentTaskCommand creates a new complete replenishment task command
+func NewCompleteReplenishmentTaskCommand(
+	replenishmentRepo replenishment.Repository,
+	stockService *stock.Service,
+	txManager application.TransactionManager,
+) *CompleteReplenishmentTaskCommand {
+	return &CompleteReplenishmentTaskCommand{
+		replenishmentRepo: replenishmentRepo,
+		stockService:      stockService,
+		txManager:         txManager,
+	}
+}
+
+// WithAlerts enables low-stock alerts after each completed task
+func (c *CompleteReplenishmentTaskCommand) WithAlerts(monitor *replenishment.Monitor) *CompleteReplenishmentTaskCommand {
+	c.monitor = monitor
+	return c
+}
+
+// WithEvents records movement and low-stock events in the outbox
+func (c *CompleteReplenishmentTaskCommand) WithEvents(events *outbox.Outbox) *CompleteReplenishmentTaskCommand {
+	c.events = events
+	return c
+}
+
+// Execute completes the task and writes its TRANSFER pair: OUT at the reserve
+// location and IN at the pick face, both referenced as REPL-<task id>
+func (c *CompleteReplenishmentTaskCommand) Execute(ctx context.Context, id int64, req *dto.CompleteReplenishmentTaskRequest) (*dto.CompleteReplenishmentTaskResponse, error) {
+	var task *replenishment.Task
+	var movements []*stock.StockMovement
+
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		var err error
+		task, err = c.replenishmentRepo.GetTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := task.Complete(); err != nil {
+			return err
+		}
+
+		out, err := c.transfer(ctx, task, task.FromLocationID, stock.MovementTypeOUT, req)
+		if err != nil {
+			return err
+		}
+		in, err := c.transfer(ctx, task, task.ToLocationID, stock.MovementTypeIN, req)
+		if err != nil {
+			return err
+		}
+		movements = append(movements, out, in)
+
+		if err := c.replenishmentRepo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+
+		return recordMovements(ctx, c.events, movements...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checkStockLevels(ctx, c.txManager, c.monitor, c.events, task.ProductID)
+
+	responses := make([]*dto.StockMovementResponse, 0, len(movements))
+	for _, m := range movements {
+		responses = append(responses, dto.NewStockMovementResponse(m))
+	}
+
+	return &dto.CompleteReplenishmentTaskResponse{
+		Task:      dto.NewReplenishmentTaskResponse(task),
+		Movements: responses,
+	}, nil
+}
+
+// transfer records one leg of a replenishment task
+func (c *CompleteReplenishmentTaskCommand) transfer(
+	ctx context.Context,
+	task *replenishment.Task,
+	locationID int64,
+	movementType stock.MovementType,
+	req *dto.CompleteReplenishmentTaskRequest,
+) (*stock.StockMovement, error) {
+	movement, err := stock.NewStockMovement(task.ProductID, locationID, movementType, task.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	referenceID := fmt.Sprintf("REPL-%d", task.ID)
+	if err := movement.SetReference(stock.ReferenceTypeTransfer, referenceID, req.DocumentNumber, req.Notes); err != nil {
+		return nil, err
+	}
+	movement.Serials = req.Serials
+
+	if err := c.stockService.RecordMovement(ctx, movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+)
+
+// CreateLocationCommand handles location creation
+type CreateLocationCommand struct {
+	locationRepo location.Repository
+}
+
+// NewCreateLocationCommand creates a new create location command
+func NewCreateLocationCommand(locationRepo location.Repository) *CreateLocationCommand {
+	return &CreateLocationCommand{
+		locationRepo: locationRepo,
+	}
+}
+
+// Execute executes the create location command
+func (c *CreateLocationCommand) Execute(ctx context.Context, req *dto.LocationRequest) (*dto.LocationResponse, error) {
+	loc, err := location.NewLocation(req.Code, req.Name, req.Capacity)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyLocationSettings(loc, req); err != nil {
+		return nil, err
+	}
+
+	if err := c.locationRepo.Create(ctx, loc); err != nil {
+		return nil, err
+	}
+
+	return dto.NewLocationResponse(loc), nil
+}
+
+// applyLocationSettings copies the request's optional settings onto loc
+func applyLocationSettings(loc *location.Location, req *dto.LocationRequest) error {
+	loc.Warehouse = req.Warehouse
+
+	if err := loc.SetLimits(req.Limits()); err != nil {
+		return err
+	}
+
+	return loc.SetTemperatureZone(location.TemperatureZone(req.TemperatureZone))
+}
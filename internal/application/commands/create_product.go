@@ -3,38 +3,90 @@ package commands
 import (
 	"context"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
 )
 
 // CreateProductCommand handles product creation
 type CreateProductCommand struct {
 	productRepo product.Repository
+	txManager   application.TransactionManager
+	events      *outbox.Outbox
 }
 
-// NewCreateProductCommand creates a new create product command
-func NewCreateProductCommand(productRepo product.Repository) *CreateProductCommand {
+// NewCreateProductCommand creates a new create product command. txManager may
+// be nil, in which case the product and its event are not saved in a transaction.
+func NewCreateProductCommand(productRepo product.Repository, txManager application.TransactionManager) *CreateProductCommand {
 	return &CreateProductCommand{
 		productRepo: productRepo,
+		txManager:   txManager,
 	}
 }
 
+// WithEvents records a product-created event in the outbox
+func (c *CreateProductCommand) WithEvents(events *outbox.Outbox) *CreateProductCommand {
+	c.events = events
+	return c
+}
+
 // Execute executes the create product command
 func (c *CreateProductCommand) Execute(ctx context.Context, req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
-	// Create product entity
-	prod, err := product.NewProduct(req.SKUName, req.Quantity)
+	if req.Quantity != nil {
+		return nil, product.ErrOpeningStock
+	}
+
+	// Create product entity. It starts empty: stock only enters through the ledger.
+	prod, err := product.NewProduct(req.SKUName, product.MasterData{
+		Description:   req.Description,
+		Category:      req.Category,
+		Brand:         req.Brand,
+		BaseUOM:       req.BaseUOM,
+		Barcodes:      dto.ToBarcodes(req.Barcodes),
+		Packs:         dto.ToPacks(req.Packs),
+		Dimensions:    dto.ToDimensions(req.Dimensions),
+		WeightGrams:   req.WeightGrams,
+		ShelfLifeDays: req.ShelfLifeDays,
+		Status:        product.Status(req.Status),
+		Serialized:    req.Serialized,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := ensureBarcodesUnassigned(ctx, c.productRepo, prod); err != nil {
+		return nil, err
+	}
+
 	// Save to repository
-	if err := c.productRepo.Create(ctx, prod); err != nil {
+	err = runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		if err := c.productRepo.Create(ctx, prod); err != nil {
+			return err
+		}
+		return recordEvent(ctx, c.events, prod.ID, webhook.EventProductCreated, dto.NewProductResponse(prod))
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &dto.ProductResponse{
-		ID:       prod.ID,
-		SKUName:  prod.SKUName,
-		Quantity: prod.Quantity,
-	}, nil
+	return dto.NewProductResponse(prod), nil
+}
+
+// ensureBarcodesUnassigned checks that no other product already carries one of p's barcodes
+func ensureBarcodesUnassigned(ctx context.Context, productRepo product.Repository, p *product.Product) error {
+	for _, b := range p.Barcodes {
+		existing, err := productRepo.GetByBarcode(ctx, b.Value)
+		if err == product.ErrProductNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if existing.ID != p.ID {
+			return product.ErrDuplicateBarcode
+		}
+	}
+	return nil
 }
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// aggregateProduct is the aggregate type of product, stock and alert events.
+// Stock events belong to their product, so one product's events are relayed
+// in the order they happened.
+const aggregateProduct = "product"
+
+// recordEvent stores a product event in the outbox. Call it inside the
+// transaction of the change; a nil outbox records nothing.
+func recordEvent(ctx context.Context, events *outbox.Outbox, productID int64, eventType webhook.EventType, data interface{}) error {
+	if events == nil {
+		return nil
+	}
+
+	message, err := outbox.NewMessage(aggregateProduct, productID, string(eventType), data)
+	if err != nil {
+		return err
+	}
+	return events.Record(ctx, message)
+}
+
+// recordMovements stores a movement-recorded event per movement, plus a
+// shipment-confirmed event for outbound movements against an order
+func recordMovements(ctx context.Context, events *outbox.Outbox, movements ...*stock.StockMovement) error {
+	if events == nil {
+		return nil
+	}
+
+	var messages []*outbox.Message
+	for _, m := range movements {
+		data := dto.NewStockMovementResponse(m)
+
+		message, err := outbox.NewMessage(aggregateProduct, m.ProductID, string(webhook.EventMovementRecorded), data)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+
+		if m.IsOutbound() && m.ReferenceType == stock.ReferenceTypeOrder {
+			message, err := outbox.NewMessage(aggregateProduct, m.ProductID, string(webhook.EventShipmentConfirmed), data)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, message)
+		}
+	}
+
+	return events.Record(ctx, messages...)
+}
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+)
+
+// Actions reported for imported rows
+const (
+	importActionCreate = "create"
+	importActionUpdate = "update"
+)
+
+// maxImportRows bounds one import, since all of its rows share a transaction
+const maxImportRows = 50000
+
+// exportOnlyColumns are written by exports but not read on import, so an
+// exported file can be edited and imported again
+var exportOnlyColumns = map[string]bool{"id": true, "quantity": true, "version": true}
+
+// errImportRolledBack aborts the import transaction after a row failed to save
+var errImportRolledBack = errors.New("import rolled back")
+
+// importTable is an import file split into its columns and data rows
+type importTable struct {
+	columns map[string]int
+	rows    []importRow
+}
+
+// importRow is one data row with its line number in the file
+type importRow struct {
+	number int
+	cells  []string
+}
+
+// newImportTable reads the header row of table, which must name key and may
+// only name known columns. Blank rows are skipped but keep their line numbers.
+func newImportTable(table [][]string, known []string, key string) (*importTable, error) {
+	header := 0
+	for header < len(table) && isBlankRow(table[header]) {
+		header++
+	}
+	if header == len(table) {
+		return nil, errors.New("import file is empty")
+	}
+
+	allowed := make(map[string]bool, len(known))
+	for _, column := range known {
+		allowed[column] = true
+	}
+
+	t := &importTable{columns: make(map[string]int)}
+	for i, name := range table[header] {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if name == "" || exportOnlyColumns[name] {
+			continue
+		}
+		if !allowed[name] {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", name, strings.Join(known, ", "))
+		}
+		if _, seen := t.columns[name]; seen {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		t.columns[name] = i
+	}
+	if _, ok := t.columns[key]; !ok {
+		return nil, fmt.Errorf("missing required column %q", key)
+	}
+
+	for i := header + 1; i < len(table); i++ {
+		if isBlankRow(table[i]) {
+			continue
+		}
+		t.rows = append(t.rows, importRow{number: i + 1, cells: table[i]})
+	}
+	if len(t.rows) == 0 {
+		return nil, errors.New("import file has no data rows")
+	}
+	if len(t.rows) > maxImportRows {
+		return nil, fmt.Errorf("import file has %d rows, the limit is %d", len(t.rows), maxImportRows)
+	}
+
+	return t, nil
+}
+
+// isBlankRow reports whether every cell of a row is empty
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// rowValues reads the typed cells of one row and collects parse errors.
+// A column missing from the file keeps the current value; an empty cell in a
+// column that is present clears it.
+type rowValues struct {
+	table *importTable
+	row   importRow
+	errs  []string
+}
+
+// cell returns the trimmed text of a column and whether the file has that column
+func (v *rowValues) cell(column string) (string, bool) {
+	i, ok := v.table.columns[column]
+	if !ok {
+		return "", false
+	}
+	if i >= len(v.row.cells) {
+		return "", true
+	}
+	return strings.TrimSpace(v.row.cells[i]), true
+}
+
+func (v *rowValues) text(column, current string) string {
+	if value, ok := v.cell(column); ok {
+		return value
+	}
+	return current
+}
+
+func (v *rowValues) int64(column string, current int64) int64 {
+	value, ok := v.cell(column)
+	if !ok {
+		return current
+	}
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		v.failf("%s: %q is not a whole number", column, value)
+	}
+	return n
+}
+
+func (v *rowValues) bool(column string, current bool) bool {
+	value, ok := v.cell(column)
+	if !ok {
+		return current
+	}
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		v.failf("%s: %q is not true or false", column, value)
+	}
+	return b
+}
+
+// pairs reads a list like "CASE:12;PALLET:480", calling add for each pair
+func (v *rowValues) pairs(column string, add func(name, value string) error) bool {
+	value, ok := v.cell(column)
+	if !ok {
+		return false
+	}
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, val, found := strings.Cut(item, ":")
+		if !found {
+			v.failf("%s: %q should look like TYPE:VALUE", column, item)
+			continue
+		}
+		if err := add(strings.ToUpper(strings.TrimSpace(name)), strings.TrimSpace(val)); err != nil {
+			v.failf("%s: %v", column, err)
+		}
+	}
+	return true
+}
+
+func (v *rowValues) failf(format string, args ...interface{}) {
+	v.errs = append(v.errs, fmt.Sprintf(format, args...))
+}
+
+// importChange is a validated row waiting to be saved
+type importChange struct {
+	result *dto.ImportRowResult
+	save   func(ctx context.Context) error
+}
+
+// applyImport counts the results and, unless the import is a dry run or a row
+// failed validation, saves every change in one transaction. A row that fails to
+// save rolls back all the others and is reported like a validation error.
+func applyImport(ctx context.Context, txManager application.TransactionManager, report *dto.ImportReportResponse, changes []importChange) error {
+	report.Rows = len(report.Results)
+	for _, result := range report.Results {
+		switch {
+		case len(result.Errors) > 0:
+			report.Failed++
+		case result.Action == importActionCreate:
+			report.Created++
+		case result.Action == importActionUpdate:
+			report.Updated++
+		}
+	}
+	if report.DryRun || report.Failed > 0 {
+		return nil
+	}
+
+	err := runInTx(ctx, txManager, func(ctx context.Context) error {
+		for _, change := range changes {
+			if err := change.save(ctx); err != nil {
+				change.result.Errors = append(change.result.Errors, err.Error())
+				return errImportRolledBack
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errImportRolledBack) {
+		report.Failed++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Applied = true
+	return nil
+}
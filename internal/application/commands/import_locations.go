@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+)
+
+// locationImportColumns are the columns a location import may have; code is required
+var locationImportColumns = []string{
+	"code", "name", "capacity", "max_volume_cm3", "max_weight_grams", "pallet_positions",
+	"temperature_zone", "warehouse",
+}
+
+// ImportLocationsCommand handles bulk location creation and updates
+type ImportLocationsCommand struct {
+	locationRepo location.Repository
+	txManager    application.TransactionManager
+}
+
+// NewImportLocationsCommand creates a new import locations command. txManager
+// may be nil, in which case rows are not saved in a single transaction.
+func NewImportLocationsCommand(locationRepo location.Repository, txManager application.TransactionManager) *ImportLocationsCommand {
+	return &ImportLocationsCommand{
+		locationRepo: locationRepo,
+		txManager:    txManager,
+	}
+}
+
+// Execute validates every row of table, whose first row names the columns, and
+// unless dryRun saves them all in one transaction. A row creates the location
+// with its code or updates the existing one.
+func (c *ImportLocationsCommand) Execute(ctx context.Context, table [][]string, dryRun bool) (*dto.ImportReportResponse, error) {
+	t, err := newImportTable(table, locationImportColumns, "code")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImportReportResponse{Kind: "locations", DryRun: dryRun}
+	codes := make(map[string]int)
+
+	var changes []importChange
+	for _, row := range t.rows {
+		change, err := c.prepare(ctx, t, row, codes)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, change.result)
+		if len(change.result.Errors) == 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	if err := applyImport(ctx, c.txManager, report, changes); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// prepare validates one row against the stored location and the rows before it.
+// Only repository failures are returned as errors; row problems go in the result.
+func (c *ImportLocationsCommand) prepare(ctx context.Context, t *importTable, row importRow, codes map[string]int) (importChange, error) {
+	values := &rowValues{table: t, row: row}
+	code, _ := values.cell("code")
+	result := &dto.ImportRowResult{Row: row.number, Key: code}
+	change := importChange{result: result}
+
+	if first, seen := codes[code]; seen && code != "" {
+		result.Errors = []string{fmt.Sprintf("code %s is already on row %d", code, first)}
+		return change, nil
+	}
+	codes[code] = row.number
+
+	existing, err := c.locationRepo.GetByCode(ctx, code)
+	if err != nil && err != location.ErrLocationNotFound {
+		return change, err
+	}
+	current := &dto.LocationResponse{}
+	if err == nil {
+		current = dto.NewLocationResponse(existing)
+	} else {
+		existing = nil
+	}
+
+	req := &dto.LocationRequest{
+		Code:            code,
+		Name:            values.text("name", current.Name),
+		Capacity:        values.int64("capacity", current.Capacity),
+		MaxVolumeCM3:    values.int64("max_volume_cm3", current.MaxVolumeCM3),
+		MaxWeightGrams:  values.int64("max_weight_grams", current.MaxWeightGrams),
+		PalletPositions: values.int64("pallet_positions", current.PalletPositions),
+		TemperatureZone: strings.ToUpper(values.text("temperature_zone", current.TemperatureZone)),
+		Warehouse:       values.text("warehouse", current.Warehouse),
+	}
+	if len(values.errs) > 0 {
+		result.Errors = values.errs
+		return change, nil
+	}
+
+	// Every row must describe a valid location, whether or not it exists yet
+	loc, err := location.NewLocation(req.Code, req.Name, req.Capacity)
+	if err == nil {
+		err = applyLocationSettings(loc, req)
+	}
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return change, nil
+	}
+
+	if existing != nil {
+		updated := *existing
+		updated.Name = loc.Name
+		updated.Capacity = loc.Capacity
+		if err := applyLocationSettings(&updated, req); err != nil {
+			result.Errors = []string{err.Error()}
+			return change, nil
+		}
+
+		result.Action = importActionUpdate
+		change.save = func(ctx context.Context) error {
+			return c.locationRepo.Update(ctx, &updated)
+		}
+		return change, nil
+	}
+
+	result.Action = importActionCreate
+	change.save = func(ctx context.Context) error {
+		return c.locationRepo.Create(ctx, loc)
+	}
+	return change, nil
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// productImportColumns are the columns a product import may have; sku_name is required
+var productImportColumns = []string{
+	"sku_name", "description", "category", "brand", "base_uom", "barcodes", "packs",
+	"length_mm", "width_mm", "height_mm", "weight_grams", "shelf_life_days", "status", "serialized",
+}
+
+// ImportProductsCommand handles bulk product creation and updates
+type ImportProductsCommand struct {
+	productRepo product.Repository
+	txManager   application.TransactionManager
+	events      *outbox.Outbox
+}
+
+// NewImportProductsCommand creates a new import products command. txManager may
+// be nil, in which case rows are not saved in a single transaction.
+func NewImportProductsCommand(productRepo product.Repository, txManager application.TransactionManager) *ImportProductsCommand {
+	return &ImportProductsCommand{
+		productRepo: productRepo,
+		txManager:   txManager,
+	}
+}
+
+// WithEvents records a product-created event in the outbox for each new product
+func (c *ImportProductsCommand) WithEvents(events *outbox.Outbox) *ImportProductsCommand {
+	c.events = events
+	return c
+}
+
+// Execute validates every row of table, whose first row names the columns, and
+// unless dryRun saves them all in one transaction. A row creates the product
+// with its SKU or updates the existing one. Quantities are not imported; stock
+// is received through movements.
+func (c *ImportProductsCommand) Execute(ctx context.Context, table [][]string, dryRun bool) (*dto.ImportReportResponse, error) {
+	t, err := newImportTable(table, productImportColumns, "sku_name")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.ImportReportResponse{Kind: "products", DryRun: dryRun}
+	skus := make(map[string]int)
+	barcodes := make(map[string]int)
+
+	var changes []importChange
+	for _, row := range t.rows {
+		change, err := c.prepare(ctx, t, row, skus, barcodes)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, change.result)
+		if len(change.result.Errors) == 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	if err := applyImport(ctx, c.txManager, report, changes); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// prepare validates one row against the stored product and the rows before it.
+// Only repository failures are returned as errors; row problems go in the result.
+func (c *ImportProductsCommand) prepare(ctx context.Context, t *importTable, row importRow, skus, barcodes map[string]int) (importChange, error) {
+	values := &rowValues{table: t, row: row}
+	sku, _ := values.cell("sku_name")
+	result := &dto.ImportRowResult{Row: row.number, Key: sku}
+	change := importChange{result: result}
+
+	if first, seen := skus[sku]; seen && sku != "" {
+		result.Errors = []string{fmt.Sprintf("sku_name %s is already on row %d", sku, first)}
+		return change, nil
+	}
+	skus[sku] = row.number
+
+	existing, err := c.productRepo.GetBySKU(ctx, sku)
+	if err != nil && err != product.ErrProductNotFound {
+		return change, err
+	}
+	var current product.MasterData
+	if err == nil {
+		current = existing.MasterData
+	} else {
+		existing = nil
+	}
+
+	data := product.MasterData{
+		Description: values.text("description", current.Description),
+		Category:    values.text("category", current.Category),
+		Brand:       values.text("brand", current.Brand),
+		BaseUOM:     values.text("base_uom", current.BaseUOM),
+		Barcodes:    current.Barcodes,
+		Packs:       current.Packs,
+		Dimensions: product.Dimensions{
+			LengthMM: values.int64("length_mm", current.Dimensions.LengthMM),
+			WidthMM:  values.int64("width_mm", current.Dimensions.WidthMM),
+			HeightMM: values.int64("height_mm", current.Dimensions.HeightMM),
+		},
+		WeightGrams:   values.int64("weight_grams", current.WeightGrams),
+		ShelfLifeDays: int(values.int64("shelf_life_days", int64(current.ShelfLifeDays))),
+		Status:        product.Status(values.text("status", string(current.Status))),
+		Serialized:    values.bool("serialized", current.Serialized),
+	}
+	if data.Status != "" {
+		data.Status = product.Status(strings.ToUpper(string(data.Status)))
+	}
+
+	var codes []product.Barcode
+	if values.pairs("barcodes", func(kind, value string) error {
+		codes = append(codes, product.Barcode{Type: product.BarcodeType(kind), Value: value})
+		return nil
+	}) {
+		data.Barcodes = codes
+	}
+	var packs []product.Pack
+	if values.pairs("packs", func(level, units string) error {
+		n, err := strconv.ParseInt(units, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number of units", units)
+		}
+		packs = append(packs, product.Pack{Level: product.PackLevel(level), UnitsPerPack: n})
+		return nil
+	}) {
+		data.Packs = packs
+	}
+
+	if len(values.errs) > 0 {
+		result.Errors = values.errs
+		return change, nil
+	}
+
+	// Every row must describe a valid product, whether or not it exists yet
+	prod, err := product.NewProduct(sku, data)
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return change, nil
+	}
+	for _, b := range prod.Barcodes {
+		if first, seen := barcodes[b.Value]; seen {
+			result.Errors = append(result.Errors, fmt.Sprintf("barcode %s is already on row %d", b.Value, first))
+		}
+		barcodes[b.Value] = row.number
+	}
+
+	if existing != nil {
+		updated := *existing
+		if err := updated.SetMasterData(prod.MasterData); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		prod = &updated
+	}
+	if err := ensureBarcodesUnassigned(ctx, c.productRepo, prod); err == product.ErrDuplicateBarcode {
+		result.Errors = append(result.Errors, err.Error())
+	} else if err != nil {
+		return change, err
+	}
+
+	if existing != nil {
+		result.Action = importActionUpdate
+		change.save = func(ctx context.Context) error {
+			return c.productRepo.Update(ctx, prod)
+		}
+		return change, nil
+	}
+
+	result.Action = importActionCreate
+	change.save = func(ctx context.Context) error {
+		if err := c.productRepo.Create(ctx, prod); err != nil {
+			return err
+		}
+		return recordEvent(ctx, c.events, prod.ID, webhook.EventProductCreated, dto.NewProductResponse(prod))
+	}
+	return change, nil
+}
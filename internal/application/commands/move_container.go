@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// MoveContainerCommand handles moving a container and everything inside it
+type MoveContainerCommand struct {
+	containerRepo    container.Repository
+	containerService *container.Service
+	stockService     *stock.Service
+	txManager        application.TransactionManager
+	monitor          *replenishment.Monitor
+	events           *outbox.Outbox
+}
+
+// NewMoveContainerCommand creates a new move container command
+func NewMoveContainerCommand(
+	containerRepo container.Repository,
+	stockService *stock.Service,
+	txManager application.TransactionManager,
+) *MoveContainerCommand {
+	return &MoveContainerCommand{
+		containerRepo:    containerRepo,
+		containerService: container.NewService(containerRepo),
+		stockService:     stockService,
+		txManager:        txManager,
+	}
+}
+
+// WithAlerts enables low-stock alerts after each committed move; moves between
+// warehouses change per-warehouse levels
+func (c *MoveContainerCommand) WithAlerts(monitor *replenishment.Monitor) *MoveContainerCommand {
+	c.monitor = monitor
+	return c
+}
+
+// WithEvents records movement and low-stock events in the outbox
+func (c *MoveContainerCommand) WithEvents(events *outbox.Outbox) *MoveContainerCommand {
+	c.events = events
+	return c
+}
+
+// Execute moves the container identified by LPN or SSCC. For every product and lot
+// in the container tree it writes a TRANSFER pair: OUT at the old location and IN at
+// the new one. A nested container that is moved on its own leaves its parent.
+func (c *MoveContainerCommand) Execute(ctx context.Context, code string, req *dto.MoveContainerRequest) (*dto.MoveContainerResponse, error) {
+	var tree *container.Tree
+	var movements []*stock.StockMovement
+
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		cont, err := c.containerService.Find(ctx, code)
+		if err != nil {
+			return err
+		}
+		if !cont.IsActive() {
+			return container.ErrContainerBroken
+		}
+		if cont.LocationID == req.ToLocationID {
+			return container.ErrSameLocation
+		}
+
+		tree, err = c.containerService.LoadTree(ctx, cont)
+		if err != nil {
+			return err
+		}
+
+		for _, content := range tree.LotTotals() {
+			out, err := c.transfer(ctx, content, cont.LocationID, stock.MovementTypeOUT, cont.LPN, req)
+			if err != nil {
+				return err
+			}
+			in, err := c.transfer(ctx, content, req.ToLocationID, stock.MovementTypeIN, cont.LPN, req)
+			if err != nil {
+				return err
+			}
+			movements = append(movements, out, in)
+		}
+
+		cont.ParentID = nil
+		var updateErr error
+		tree.Walk(func(node *container.Container) {
+			node.LocationID = req.ToLocationID
+			if updateErr == nil {
+				updateErr = c.containerRepo.Update(ctx, node)
+			}
+		})
+		if updateErr != nil {
+			return updateErr
+		}
+
+		return recordMovements(ctx, c.events, movements...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.StockMovementResponse, 0, len(movements))
+	productIDs := make([]int64, 0, len(movements))
+	for _, m := range movements {
+		responses = append(responses, dto.NewStockMovementResponse(m))
+		productIDs = append(productIDs, m.ProductID)
+	}
+	checkStockLevels(ctx, c.txManager, c.monitor, c.events, productIDs...)
+
+	return &dto.MoveContainerResponse{
+		Container: dto.NewContainerResponse(tree),
+		Movements: responses,
+	}, nil
+}
+
+// transfer records one leg of a container move
+func (c *MoveContainerCommand) transfer(
+	ctx context.Context,
+	content container.Content,
+	locationID int64,
+	movementType stock.MovementType,
+	lpn string,
+	req *dto.MoveContainerRequest,
+) (*stock.StockMovement, error) {
+	movement, err := stock.NewStockMovement(content.ProductID, locationID, movementType, content.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	movement.Lot = content.Lot
+
+	if err := movement.SetReference(stock.ReferenceTypeTransfer, lpn, req.DocumentNumber, req.Notes); err != nil {
+		return nil, err
+	}
+
+	if err := c.stockService.RecordMovement(ctx, movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
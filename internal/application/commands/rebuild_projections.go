@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// RebuildProjectionsCommand regenerates every ledger projection from scratch
+type RebuildProjectionsCommand struct {
+	projector *stock.Projector
+	txManager application.TransactionManager
+}
+
+// NewRebuildProjectionsCommand creates a new rebuild projections command
+func NewRebuildProjectionsCommand(projector *stock.Projector, txManager application.TransactionManager) *RebuildProjectionsCommand {
+	return &RebuildProjectionsCommand{
+		projector: projector,
+		txManager: txManager,
+	}
+}
+
+// Execute executes the rebuild projections command. The rebuild runs in one
+// transaction, so readers see the old projections until it commits.
+func (c *RebuildProjectionsCommand) Execute(ctx context.Context) (*dto.RebuildReportResponse, error) {
+	var report *stock.RebuildReport
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		var err error
+		report, err = c.projector.Rebuild(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RebuildReportResponse{
+		Projections:     report.Projections,
+		Movements:       report.Movements,
+		ThroughSequence: report.ThroughSequence,
+		StartedAt:       report.StartedAt,
+		FinishedAt:      report.FinishedAt,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// ErrReconciliationRunning is returned when another reconciliation holds the lock
+var ErrReconciliationRunning = errors.New("a reconciliation is already running")
+
+// ReconcileStockCommand handles ledger/aggregate reconciliation runs
+type ReconcileStockCommand struct {
+	reconciler *stock.Reconciler
+	txManager  application.TransactionManager
+	lock       application.Lock
+}
+
+// NewReconcileStockCommand creates a new reconcile stock command
+func NewReconcileStockCommand(reconciler *stock.Reconciler, txManager application.TransactionManager) *ReconcileStockCommand {
+	return &ReconcileStockCommand{
+		reconciler: reconciler,
+		txManager:  txManager,
+	}
+}
+
+// WithLock keeps runs from overlapping, including runs on other instances
+func (c *ReconcileStockCommand) WithLock(lock application.Lock) *ReconcileStockCommand {
+	c.lock = lock
+	return c
+}
+
+// Execute executes the reconcile stock command. It returns
+// ErrReconciliationRunning when another run holds the lock.
+func (c *ReconcileStockCommand) Execute(ctx context.Context, req *dto.ReconcileRequest) (*dto.ReconciliationReportResponse, error) {
+	opts := stock.ReconcileOptions{Repair: req.Repair}
+
+	var report *stock.ReconciliationReport
+	run := func(ctx context.Context) error {
+		return runInTx(ctx, c.txManager, func(ctx context.Context) error {
+			var err error
+			report, err = c.reconciler.Reconcile(ctx, opts)
+			return err
+		})
+	}
+
+	if c.lock == nil {
+		if err := run(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		acquired, err := c.lock.TryRun(ctx, run)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return nil, ErrReconciliationRunning
+		}
+	}
+
+	result := &dto.ReconciliationReportResponse{
+		StartedAt:        report.StartedAt,
+		FinishedAt:       report.FinishedAt,
+		ProductsChecked:  report.ProductsChecked,
+		LocationsChecked: report.LocationsChecked,
+		Discrepancies:    []*dto.DiscrepancyResponse{},
+	}
+	for _, d := range report.Discrepancies {
+		result.Discrepancies = append(result.Discrepancies, &dto.DiscrepancyResponse{
+			Scope:      string(d.Scope),
+			ProductID:  d.ProductID,
+			LocationID: d.LocationID,
+			Stored:     d.Stored,
+			Ledger:     d.Ledger,
+			Difference: d.Difference(),
+			Reason:     d.Reason,
+			Repaired:   d.Repaired,
+		})
+	}
+
+	return result, nil
+}
@@ -3,71 +3,193 @@ package commands
 import (
 	"context"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 )
 
 // RecordStockMovementCommand handles stock movement recording
 type RecordStockMovementCommand struct {
-	stockService *stock.Service
-	productRepo  product.Repository
-	txManager    interface{} // TransactionManager
+	stockService   *stock.Service
+	txManager      application.TransactionManager
+	putawayService *putaway.Service
+	monitor        *replenishment.Monitor
+	events         *outbox.Outbox
+	resolver       *scanning.Resolver
 }
 
-// NewRecordStockMovementCommand creates a new record stock movement command
+// NewRecordStockMovementCommand creates a new record stock movement command.
+// txManager may be nil, in which case the movement and its projections are
+// not wrapped in a transaction.
 func NewRecordStockMovementCommand(
 	stockService *stock.Service,
-	productRepo product.Repository,
-	txManager interface{},
+	txManager application.TransactionManager,
 ) *RecordStockMovementCommand {
 	return &RecordStockMovementCommand{
 		stockService: stockService,
-		productRepo:  productRepo,
 		txManager:    txManager,
 	}
 }
 
+// WithPutaway enables auto-assigning the location of inbound movements
+func (c *RecordStockMovementCommand) WithPutaway(putawayService *putaway.Service) *RecordStockMovementCommand {
+	c.putawayService = putawayService
+	return c
+}
+
+// WithAlerts enables low-stock alerts after each committed movement
+func (c *RecordStockMovementCommand) WithAlerts(monitor *replenishment.Monitor) *RecordStockMovementCommand {
+	c.monitor = monitor
+	return c
+}
+
+// WithEvents records movement, shipment and low-stock events in the outbox
+func (c *RecordStockMovementCommand) WithEvents(events *outbox.Outbox) *RecordStockMovementCommand {
+	c.events = events
+	return c
+}
+
+// WithScans lets movements name their product and location by raw scans
+func (c *RecordStockMovementCommand) WithScans(resolver *scanning.Resolver) *RecordStockMovementCommand {
+	c.resolver = resolver
+	return c
+}
+
 // Execute executes the record stock movement command
 func (c *RecordStockMovementCommand) Execute(ctx context.Context, req *dto.RecordStockMovementRequest) (*dto.StockMovementResponse, error) {
+	if req.Scan != "" || req.LocationScan != "" {
+		resolved, err := c.resolveScans(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		req = resolved
+	}
+
 	// Create stock movement entity
 	movementType := stock.MovementType(req.Type)
-	movement, err := stock.NewStockMovement(req.ProductID, req.LocationID, movementType, req.Quantity)
+
+	locationID := req.LocationID
+	if req.AutoAssign && locationID == 0 {
+		assigned, err := c.assignLocation(ctx, movementType, req)
+		if err != nil {
+			return nil, err
+		}
+		locationID = assigned
+	}
+
+	movement, err := stock.NewStockMovement(req.ProductID, locationID, movementType, req.Quantity)
 	if err != nil {
 		return nil, err
 	}
 
-	// Record movement with business rule validation
-	if err := c.stockService.RecordMovement(ctx, movement); err != nil {
+	if err := movement.SetReference(
+		stock.ReferenceType(req.ReferenceType),
+		req.ReferenceID,
+		req.DocumentNumber,
+		req.Notes,
+	); err != nil {
 		return nil, err
 	}
 
-	// Update product quantity
-	prod, err := c.productRepo.GetByID(ctx, req.ProductID)
+	if err := movement.SetUOM(product.PackLevel(req.UOM)); err != nil {
+		return nil, err
+	}
+	movement.Serials = req.Serials
+	movement.Lot = req.Lot
+
+	// The ledger entry, its projections and its events must change together
+	err = runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		// Record movement with business rule validation
+		if err := c.stockService.RecordMovement(ctx, movement); err != nil {
+			return err
+		}
+
+		return recordMovements(ctx, c.events, movement)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if movement.IsInbound() {
-		if err := prod.IncreaseStock(req.Quantity); err != nil {
+	checkStockLevels(ctx, c.txManager, c.monitor, c.events, movement.ProductID)
+
+	return dto.NewStockMovementResponse(movement), nil
+}
+
+// assignLocation picks the top putaway suggestion for an inbound movement
+func (c *RecordStockMovementCommand) assignLocation(ctx context.Context, movementType stock.MovementType, req *dto.RecordStockMovementRequest) (int64, error) {
+	if movementType != stock.MovementTypeIN {
+		return 0, putaway.ErrInboundOnly
+	}
+	if c.putawayService == nil {
+		return 0, putaway.ErrAutoAssignDisabled
+	}
+
+	suggestions, err := c.putawayService.Suggest(ctx, putaway.Request{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UOM:       product.PackLevel(req.UOM),
+		Lot:       req.Lot,
+		Limit:     1,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return suggestions[0].Location.ID, nil
+}
+
+// resolveScans returns a copy of req with the product, location, lot and
+// quantity its scans stand for filled in. Values given explicitly win, but a
+// product or lot that contradicts the scan is rejected.
+func (c *RecordStockMovementCommand) resolveScans(ctx context.Context, req *dto.RecordStockMovementRequest) (*dto.RecordStockMovementRequest, error) {
+	if c.resolver == nil {
+		return nil, scanning.ErrScanningDisabled
+	}
+	resolved := *req
+
+	if req.LocationScan != "" {
+		loc, err := c.resolver.Location(ctx, req.LocationScan)
+		if err != nil {
 			return nil, err
 		}
-	} else {
-		if err := prod.DecreaseStock(req.Quantity); err != nil {
-			return nil, err
+		if req.LocationID != 0 && req.LocationID != loc.ID {
+			return nil, scanning.ErrLocationMismatch
 		}
+		resolved.LocationID = loc.ID
 	}
 
-	if err := c.productRepo.Update(ctx, prod); err != nil {
+	if req.Scan == "" {
+		return &resolved, nil
+	}
+	res, err := c.resolver.Resolve(ctx, req.Scan)
+	if err != nil {
 		return nil, err
 	}
+	if res.Product == nil {
+		return nil, scanning.ErrUnresolvedScan
+	}
+	if req.ProductID != 0 && req.ProductID != res.Product.ID {
+		return nil, scanning.ErrProductMismatch
+	}
+	resolved.ProductID = res.Product.ID
+
+	if res.Lot != "" {
+		if req.Lot != "" && req.Lot != res.Lot {
+			return nil, scanning.ErrLotMismatch
+		}
+		resolved.Lot = res.Lot
+	}
+	if resolved.Quantity == 0 {
+		resolved.Quantity = res.Count
+	}
+	if resolved.LocationID == 0 && !req.AutoAssign && res.Container != nil && res.Container.IsActive() {
+		resolved.LocationID = res.Container.LocationID
+	}
 
-	return &dto.StockMovementResponse{
-		ID:         movement.ID,
-		ProductID:  movement.ProductID,
-		LocationID: movement.LocationID,
-		Type:       string(movement.Type),
-		Quantity:   movement.Quantity,
-		CreatedAt:  movement.CreatedAt,
-	}, nil
+	return &resolved, nil
 }
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// ReverseStockMovementCommand cancels out a recorded movement with an opposite one
+type ReverseStockMovementCommand struct {
+	recordCmd *RecordStockMovementCommand
+	stockRepo stock.Repository
+	txManager application.TransactionManager
+}
+
+// NewReverseStockMovementCommand creates a new reverse stock movement command
+func NewReverseStockMovementCommand(
+	recordCmd *RecordStockMovementCommand,
+	stockRepo stock.Repository,
+	txManager application.TransactionManager,
+) *ReverseStockMovementCommand {
+	return &ReverseStockMovementCommand{
+		recordCmd: recordCmd,
+		stockRepo: stockRepo,
+		txManager: txManager,
+	}
+}
+
+// Execute posts the opposite of movement id as a REVERSAL movement referencing
+// it. The ledger stays append-only; the original entry is left untouched. A
+// movement can be reversed once, and reversals cannot be reversed. Serialized
+// products need the moved serials passed again, as the ledger does not hold them.
+func (c *ReverseStockMovementCommand) Execute(ctx context.Context, id int64, req *dto.ReverseStockMovementRequest) (*dto.StockMovementResponse, error) {
+	var result *dto.StockMovementResponse
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		// Holds back other movements, so two reversals of the same entry cannot race
+		if _, err := c.stockRepo.LastSequence(ctx); err != nil {
+			return err
+		}
+
+		original, err := c.stockRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if original.ReferenceType == stock.ReferenceTypeReversal {
+			return stock.ErrReversalNotReversible
+		}
+
+		reference := strconv.FormatInt(original.ID, 10)
+		existing, err := c.stockRepo.Count(ctx, stock.ListSpec{Filter: stock.Filter{
+			ReferenceType: stock.ReferenceTypeReversal,
+			ReferenceID:   reference,
+		}})
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return stock.ErrAlreadyReversed
+		}
+
+		movementType := stock.MovementTypeIN
+		if original.Type == stock.MovementTypeIN {
+			movementType = stock.MovementTypeOUT
+		}
+
+		notes := req.Notes
+		if notes == "" {
+			notes = fmt.Sprintf("reversal of movement %d", original.ID)
+		}
+
+		result, err = c.recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{
+			ProductID:      original.ProductID,
+			LocationID:     original.LocationID,
+			Type:           string(movementType),
+			Quantity:       original.Quantity,
+			ReferenceType:  string(stock.ReferenceTypeReversal),
+			ReferenceID:    reference,
+			DocumentNumber: original.DocumentNumber,
+			Notes:          notes,
+			Serials:        req.Serials,
+			Lot:            original.Lot,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
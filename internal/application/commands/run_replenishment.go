@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// RunReplenishmentCommand handles creating replenishment tasks for pick faces below their minimum
+type RunReplenishmentCommand struct {
+	engine    *replenishment.Engine
+	txManager application.TransactionManager
+}
+
+// NewRunReplenishmentCommand creates a new run replenishment command
+func NewRunReplenishmentCommand(engine *replenishment.Engine, txManager application.TransactionManager) *RunReplenishmentCommand {
+	return &RunReplenishmentCommand{
+		engine:    engine,
+		txManager: txManager,
+	}
+}
+
+// Execute runs the engine and returns the tasks it created. The run is one
+// transaction so a failure leaves no partial set of tasks behind.
+func (c *RunReplenishmentCommand) Execute(ctx context.Context, req *dto.RunReplenishmentRequest) ([]*dto.ReplenishmentTaskResponse, error) {
+	var tasks []*replenishment.Task
+
+	err := runInTx(ctx, c.txManager, func(ctx context.Context) error {
+		var err error
+		tasks, err = c.engine.Run(ctx, replenishment.Scope{
+			ProductIDs: req.ProductIDs,
+			Reference:  req.Reference,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ReplenishmentTaskResponse, 0, len(tasks))
+	for _, t := range tasks {
+		responses = append(responses, dto.NewReplenishmentTaskResponse(t))
+	}
+	return responses, nil
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// SavePickFaceCommand handles setting the min/max of a SKU at a pick location
+type SavePickFaceCommand struct {
+	replenishmentRepo replenishment.Repository
+	productRepo       product.Repository
+	locationRepo      location.Repository
+}
+
+// NewSavePickFaceCommand creates a new save pick face command
+func NewSavePickFaceCommand(
+	replenishmentRepo replenishment.Repository,
+	productRepo product.Repository,
+	locationRepo location.Repository,
+) *SavePickFaceCommand {
+	return &SavePickFaceCommand{
+		replenishmentRepo: replenishmentRepo,
+		productRepo:       productRepo,
+		locationRepo:      locationRepo,
+	}
+}
+
+// Execute creates or replaces the pick face setting
+func (c *SavePickFaceCommand) Execute(ctx context.Context, req *dto.PickFaceRequest) (*dto.PickFaceResponse, error) {
+	pf, err := replenishment.NewPickFace(req.ProductID, req.LocationID, req.Min, req.Max)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.productRepo.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+	if _, err := c.locationRepo.GetByID(ctx, req.LocationID); err != nil {
+		return nil, err
+	}
+
+	if err := c.replenishmentRepo.SavePickFace(ctx, pf); err != nil {
+		return nil, err
+	}
+
+	return dto.NewPickFaceResponse(pf), nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// SaveReorderPolicyCommand handles setting the reorder point of a SKU
+type SaveReorderPolicyCommand struct {
+	reorderRepo replenishment.ReorderRepository
+	productRepo product.Repository
+	monitor     *replenishment.Monitor
+	txManager   application.TransactionManager
+	events      *outbox.Outbox
+}
+
+// NewSaveReorderPolicyCommand creates a new save reorder policy command
+func NewSaveReorderPolicyCommand(
+	reorderRepo replenishment.ReorderRepository,
+	productRepo product.Repository,
+	monitor *replenishment.Monitor,
+	txManager application.TransactionManager,
+) *SaveReorderPolicyCommand {
+	return &SaveReorderPolicyCommand{
+		reorderRepo: reorderRepo,
+		productRepo: productRepo,
+		monitor:     monitor,
+		txManager:   txManager,
+	}
+}
+
+// WithEvents records low-stock events in the outbox
+func (c *SaveReorderPolicyCommand) WithEvents(events *outbox.Outbox) *SaveReorderPolicyCommand {
+	c.events = events
+	return c
+}
+
+// Execute creates or replaces the policy, then checks the product's stock
+// against it so a SKU that is already short gets its alert straight away
+func (c *SaveReorderPolicyCommand) Execute(ctx context.Context, req *dto.ReorderPolicyRequest) (*dto.ReorderPolicyResponse, error) {
+	policy, err := replenishment.NewReorderPolicy(req.ProductID, req.Warehouse, req.ReorderPoint, req.SafetyStock, req.ReorderQty)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.productRepo.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := c.reorderRepo.SavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	checkStockLevels(ctx, c.txManager, c.monitor, c.events, policy.ProductID)
+
+	return dto.NewReorderPolicyResponse(policy), nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"log"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// checkStockLevels raises or resolves low-stock alerts for products whose stock
+// just changed, recording a low-stock event with each alert raised or escalated.
+// It runs after the movements are committed, so a failed check is logged rather
+// than failing the request; the product's next movement checks again.
+func checkStockLevels(
+	ctx context.Context,
+	txManager application.TransactionManager,
+	monitor *replenishment.Monitor,
+	events *outbox.Outbox,
+	productIDs ...int64,
+) {
+	if monitor == nil {
+		return
+	}
+
+	checked := make(map[int64]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if checked[productID] {
+			continue
+		}
+		checked[productID] = true
+
+		// An alert and its event are saved together
+		err := runInTx(ctx, txManager, func(ctx context.Context) error {
+			raised, err := monitor.Check(ctx, productID)
+			if err != nil {
+				return err
+			}
+			for _, alert := range raised {
+				if err := recordEvent(ctx, events, productID, webhook.EventLowStock, dto.NewStockAlertResponse(alert)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to check stock levels of product %d: %v", productID, err)
+		}
+	}
+}
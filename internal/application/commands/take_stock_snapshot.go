@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// TakeStockSnapshotCommand handles capturing a balance snapshot
+type TakeStockSnapshotCommand struct {
+	balanceService *stock.BalanceService
+}
+
+// NewTakeStockSnapshotCommand creates a new take stock snapshot command
+func NewTakeStockSnapshotCommand(balanceService *stock.BalanceService) *TakeStockSnapshotCommand {
+	return &TakeStockSnapshotCommand{
+		balanceService: balanceService,
+	}
+}
+
+// Execute executes the take stock snapshot command
+func (c *TakeStockSnapshotCommand) Execute(ctx context.Context) (*dto.SnapshotResponse, error) {
+	snapshot, err := c.balanceService.TakeSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SnapshotResponse{
+		ID:           snapshot.ID,
+		TakenAt:      snapshot.TakenAt,
+		LastSequence: snapshot.LastSequence,
+		BalanceCount: len(snapshot.Balances),
+	}, nil
+}
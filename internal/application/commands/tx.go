@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+)
+
+// runInTx runs fn inside a transaction when a transaction manager is configured
+func runInTx(ctx context.Context, txManager application.TransactionManager, fn func(ctx context.Context) error) error {
+	if txManager == nil {
+		return fn(ctx)
+	}
+	return txManager.WithTx(ctx, fn)
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+)
+
+// UpdateLocationCommand handles replacing a location's settings
+type UpdateLocationCommand struct {
+	locationRepo location.Repository
+}
+
+// NewUpdateLocationCommand creates a new update location command
+func NewUpdateLocationCommand(locationRepo location.Repository) *UpdateLocationCommand {
+	return &UpdateLocationCommand{
+		locationRepo: locationRepo,
+	}
+}
+
+// Execute executes the update location command. A non-zero version must match
+// the stored one or ErrConcurrentModification is returned.
+func (c *UpdateLocationCommand) Execute(ctx context.Context, id, version int64, req *dto.LocationRequest) (*dto.LocationResponse, error) {
+	loc, err := c.locationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && loc.Version != version {
+		return nil, location.ErrConcurrentModification
+	}
+
+	loc.Code = req.Code
+	loc.Name = req.Name
+	loc.Capacity = req.Capacity
+	if err := applyLocationSettings(loc, req); err != nil {
+		return nil, err
+	}
+
+	if err := c.locationRepo.Update(ctx, loc); err != nil {
+		return nil, err
+	}
+
+	return dto.NewLocationResponse(loc), nil
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
 )
 
-// UpdateProductCommand handles product update
+// UpdateProductCommand handles patching product master data
 type UpdateProductCommand struct {
 	productRepo product.Repository
 }
@@ -19,20 +19,69 @@ func NewUpdateProductCommand(productRepo product.Repository) *UpdateProductComma
 	}
 }
 
-// Execute executes the update product command
-func (c *UpdateProductCommand) Execute(ctx context.Context, id int64, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+// Execute executes the update product command. Quantity cannot be changed here;
+// use AdjustStockCommand so the correction ends up in the ledger. A non-zero
+// version must match the stored one or ErrConcurrentModification is returned.
+func (c *UpdateProductCommand) Execute(ctx context.Context, id, version int64, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+	if req.Quantity != nil {
+		return nil, product.ErrQuantityManagedByLedger
+	}
+
 	// Get existing product
 	prod, err := c.productRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if version != 0 && prod.Version != version {
+		return nil, product.ErrConcurrentModification
+	}
+
+	// Apply only the fields that were provided
+	if req.SKUName != nil {
+		if err := prod.Rename(*req.SKUName); err != nil {
+			return nil, err
+		}
+	}
 
-	// Update fields if provided
-	if req.SKUName != "" {
-		prod.SKUName = req.SKUName
+	data := prod.MasterData
+	if req.Description != nil {
+		data.Description = *req.Description
+	}
+	if req.Category != nil {
+		data.Category = *req.Category
+	}
+	if req.Brand != nil {
+		data.Brand = *req.Brand
+	}
+	if req.BaseUOM != nil {
+		data.BaseUOM = *req.BaseUOM
+	}
+	if req.Barcodes != nil {
+		data.Barcodes = dto.ToBarcodes(*req.Barcodes)
 	}
-	if req.Quantity >= 0 {
-		prod.Quantity = req.Quantity
+	if req.Packs != nil {
+		data.Packs = dto.ToPacks(*req.Packs)
+	}
+	if req.Dimensions != nil {
+		data.Dimensions = dto.ToDimensions(req.Dimensions)
+	}
+	if req.WeightGrams != nil {
+		data.WeightGrams = *req.WeightGrams
+	}
+	if req.ShelfLifeDays != nil {
+		data.ShelfLifeDays = *req.ShelfLifeDays
+	}
+	if req.Status != nil {
+		data.Status = product.Status(*req.Status)
+	}
+	if req.Serialized != nil {
+		data.Serialized = *req.Serialized
+	}
+	if err := prod.SetMasterData(data); err != nil {
+		return nil, err
+	}
+	if err := ensureBarcodesUnassigned(ctx, c.productRepo, prod); err != nil {
+		return nil, err
 	}
 
 	// Save to repository
@@ -40,9 +89,5 @@ func (c *UpdateProductCommand) Execute(ctx context.Context, id int64, req *dto.U
 		return nil, err
 	}
 
-	return &dto.ProductResponse{
-		ID:       prod.ID,
-		SKUName:  prod.SKUName,
-		Quantity: prod.Quantity,
-	}, nil
+	return dto.NewProductResponse(prod), nil
 }
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// CreateWebhookCommand handles subscribing an endpoint to events
+type CreateWebhookCommand struct {
+	webhookRepo webhook.Repository
+}
+
+// NewCreateWebhookCommand creates a new create webhook command
+func NewCreateWebhookCommand(webhookRepo webhook.Repository) *CreateWebhookCommand {
+	return &CreateWebhookCommand{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Execute creates the subscription and returns it with its signing secret
+func (c *CreateWebhookCommand) Execute(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	types := make([]webhook.EventType, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		types = append(types, webhook.EventType(t))
+	}
+
+	sub, err := webhook.NewSubscription(req.URL, types, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	result := dto.NewWebhookResponse(sub)
+	result.Secret = sub.Secret
+	return result, nil
+}
+
+// ReplayWebhookDeliveryCommand handles sending a finished delivery again
+type ReplayWebhookDeliveryCommand struct {
+	webhookRepo webhook.Repository
+}
+
+// NewReplayWebhookDeliveryCommand creates a new replay webhook delivery command
+func NewReplayWebhookDeliveryCommand(webhookRepo webhook.Repository) *ReplayWebhookDeliveryCommand {
+	return &ReplayWebhookDeliveryCommand{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Execute puts the delivery back in the queue with a fresh set of attempts;
+// the dispatcher sends it on its next run
+func (c *ReplayWebhookDeliveryCommand) Execute(ctx context.Context, id int64) (*dto.WebhookDeliveryResponse, error) {
+	delivery, err := c.webhookRepo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := delivery.Replay(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := c.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return dto.NewWebhookDeliveryResponse(delivery), nil
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// ProductBalanceResponse is the DTO for a product's total quantity across locations
+type ProductBalanceResponse struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// StockAsOfResponse is the DTO for point-in-time stock balances
+type StockAsOfResponse struct {
+	Timestamp time.Time                 `json:"timestamp"`
+	Products  []*ProductBalanceResponse `json:"products"`
+	Locations []*LocationStockResponse  `json:"locations"`
+}
+
+// SnapshotResponse is the DTO for a stored balance snapshot
+type SnapshotResponse struct {
+	ID           int64     `json:"id"`
+	TakenAt      time.Time `json:"taken_at"`
+	LastSequence int64     `json:"last_sequence"`
+	BalanceCount int       `json:"balance_count"`
+}
+
+// BalanceFilter is the DTO for filtering projected balance listings
+type BalanceFilter struct {
+	ProductID  int64  `form:"product_id" binding:"omitempty,min=1"`
+	LocationID int64  `form:"location_id" binding:"omitempty,min=1"`
+	Lot        string `form:"lot" binding:"max=50"`
+}
+
+// LotBalanceResponse is the DTO for the quantity of one lot held at a location
+type LotBalanceResponse struct {
+	ProductID  int64  `json:"product_id"`
+	LocationID int64  `json:"location_id"`
+	Lot        string `json:"lot"`
+	Quantity   int64  `json:"quantity"`
+}
+
+// RebuildReportResponse is the DTO for a projection rebuild
+type RebuildReportResponse struct {
+	Projections     []string  `json:"projections"`
+	Movements       int       `json:"movements"`
+	ThroughSequence int64     `json:"through_sequence"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+}
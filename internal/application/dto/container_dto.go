@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+)
+
+// ContainerContentDTO is the DTO for a product quantity held in a container
+type ContainerContentDTO struct {
+	ProductID int64  `json:"product_id" binding:"required,min=1"`
+	Lot       string `json:"lot,omitempty" binding:"max=50"`
+	Quantity  int64  `json:"quantity" binding:"required,min=1"`
+}
+
+// ContainerTotalDTO is the DTO for a product total across a container tree
+type ContainerTotalDTO struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// BuildContainerRequest is the DTO for building a container from loose stock and
+// from other containers at the same location
+type BuildContainerRequest struct {
+	LPN        string                `json:"lpn" binding:"required,max=50"`
+	SSCC       string                `json:"sscc,omitempty" binding:"omitempty,len=18,numeric"`
+	Type       string                `json:"type" binding:"required,oneof=PALLET CARTON TOTE"`
+	LocationID int64                 `json:"location_id" binding:"required,min=1"`
+	Contents   []ContainerContentDTO `json:"contents,omitempty" binding:"dive"`
+	ChildLPNs  []string              `json:"child_lpns,omitempty"`
+}
+
+// MoveContainerRequest is the DTO for moving a whole container to another location
+type MoveContainerRequest struct {
+	ToLocationID   int64  `json:"to_location_id" binding:"required,min=1"`
+	DocumentNumber string `json:"document_number,omitempty" binding:"max=100"`
+	Notes          string `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// ContainerResponse is the DTO for a container and everything nested inside it
+type ContainerResponse struct {
+	ID         int64                 `json:"id"`
+	LPN        string                `json:"lpn"`
+	SSCC       string                `json:"sscc,omitempty"`
+	Type       string                `json:"type"`
+	LocationID int64                 `json:"location_id"`
+	ParentID   *int64                `json:"parent_id,omitempty"`
+	Status     string                `json:"status"`
+	Contents   []ContainerContentDTO `json:"contents"`
+	Children   []*ContainerResponse  `json:"children,omitempty"`
+	Totals     []ContainerTotalDTO   `json:"totals"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// MoveContainerResponse is the DTO for a container move and the movements it wrote
+type MoveContainerResponse struct {
+	Container *ContainerResponse       `json:"container"`
+	Movements []*StockMovementResponse `json:"movements"`
+}
+
+// NewContainerResponse maps a container tree to its response DTO
+func NewContainerResponse(tree *container.Tree) *ContainerResponse {
+	contents := make([]ContainerContentDTO, 0, len(tree.Contents))
+	for _, c := range tree.Contents {
+		contents = append(contents, ContainerContentDTO{ProductID: c.ProductID, Lot: c.Lot, Quantity: c.Quantity})
+	}
+
+	var children []*ContainerResponse
+	for _, child := range tree.Children {
+		children = append(children, NewContainerResponse(child))
+	}
+
+	totals := make([]ContainerTotalDTO, 0)
+	for productID, qty := range tree.Totals() {
+		totals = append(totals, ContainerTotalDTO{ProductID: productID, Quantity: qty})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].ProductID < totals[j].ProductID })
+
+	return &ContainerResponse{
+		ID:         tree.ID,
+		LPN:        tree.LPN,
+		SSCC:       tree.SSCC,
+		Type:       string(tree.Type),
+		LocationID: tree.LocationID,
+		ParentID:   tree.ParentID,
+		Status:     string(tree.Status),
+		Contents:   contents,
+		Children:   children,
+		Totals:     totals,
+		CreatedAt:  tree.CreatedAt,
+	}
+}
@@ -0,0 +1,16 @@
+package dto
+
+// Column types of an export
+const (
+	ExportInt64  = "int64"
+	ExportString = "string"
+	ExportBool   = "bool"
+	ExportTime   = "time"
+)
+
+// ExportColumn is the DTO for one column of an export. Values in an int64
+// column are int64, in a time column time.Time, and so on.
+type ExportColumn struct {
+	Name string
+	Type string
+}
@@ -0,0 +1,22 @@
+package dto
+
+// ImportRowResult is the DTO for the outcome of one imported row
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReportResponse is the DTO for a bulk import. Applied is only true when
+// every row was valid, it was not a dry run and all rows were committed.
+type ImportReportResponse struct {
+	Kind    string             `json:"kind"`
+	DryRun  bool               `json:"dry_run"`
+	Applied bool               `json:"applied"`
+	Rows    int                `json:"rows"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Results []*ImportRowResult `json:"results"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+// PageRequest is the DTO for the page of a listing to return. After takes the
+// next_cursor of the previous page and continues right behind it, which stays
+// correct and fast while rows are added; Offset skips rows instead.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	After  string
+
+	// IncludeTotal asks for the total count, which costs a scan of every
+	// matching row. Pages continuing from a cursor never count.
+	IncludeTotal bool
+}
@@ -1,28 +1,212 @@
 package dto
 
-// CreateProductRequest is the DTO for creating a product
+import "github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+
+// BarcodeDTO is the DTO for a product barcode
+type BarcodeDTO struct {
+	Type  string `json:"type" binding:"required,oneof=EAN8 EAN13 UPC_A GTIN14"`
+	Value string `json:"value" binding:"required,numeric"`
+}
+
+// DimensionsDTO is the DTO for product dimensions in millimetres
+type DimensionsDTO struct {
+	LengthMM int64 `json:"length_mm" binding:"min=0"`
+	WidthMM  int64 `json:"width_mm" binding:"min=0"`
+	HeightMM int64 `json:"height_mm" binding:"min=0"`
+}
+
+// PackDTO is the DTO for one level of a product's pack hierarchy
+type PackDTO struct {
+	Level        string `json:"level" binding:"required,oneof=INNER CASE PALLET"`
+	UnitsPerPack int64  `json:"units_per_pack" binding:"required,min=2"`
+}
+
+// PackQuantityDTO is a base-unit quantity expressed as whole packs plus leftover base units
+type PackQuantityDTO struct {
+	UOM       string `json:"uom"`
+	Packs     int64  `json:"packs"`
+	Remainder int64  `json:"remainder"`
+}
+
+// CreateProductRequest is the DTO for creating a product. New products start
+// with no stock; stock is brought in by movements so the ledger accounts for it.
 type CreateProductRequest struct {
-	SKUName  string `json:"sku_name" binding:"required"`
-	Quantity int64  `json:"quantity" binding:"required,min=0"`
+	SKUName       string         `json:"sku_name" binding:"required"`
+	Description   string         `json:"description,omitempty" binding:"max=2000"`
+	Category      string         `json:"category,omitempty" binding:"max=100"`
+	Brand         string         `json:"brand,omitempty" binding:"max=100"`
+	BaseUOM       string         `json:"base_uom,omitempty" binding:"max=10"`
+	Barcodes      []BarcodeDTO   `json:"barcodes,omitempty" binding:"dive"`
+	Packs         []PackDTO      `json:"packs,omitempty" binding:"dive"`
+	Dimensions    *DimensionsDTO `json:"dimensions,omitempty"`
+	WeightGrams   int64          `json:"weight_grams,omitempty" binding:"min=0"`
+	ShelfLifeDays int            `json:"shelf_life_days,omitempty" binding:"min=0"`
+	Status        string         `json:"status,omitempty" binding:"omitempty,oneof=ACTIVE INACTIVE"`
+	Serialized    bool           `json:"serialized,omitempty"`
+
+	// Quantity is rejected when present; opening stock is an IN movement
+	Quantity *int64 `json:"quantity,omitempty"`
 }
 
-// UpdateProductRequest is the DTO for updating a product
+// UpdateProductRequest is the DTO for patching product master data.
+// Omitted fields keep their current values.
 type UpdateProductRequest struct {
-	SKUName  string `json:"sku_name"`
-	Quantity int64  `json:"quantity" binding:"min=0"`
+	SKUName       *string        `json:"sku_name"`
+	Description   *string        `json:"description" binding:"omitempty,max=2000"`
+	Category      *string        `json:"category" binding:"omitempty,max=100"`
+	Brand         *string        `json:"brand" binding:"omitempty,max=100"`
+	BaseUOM       *string        `json:"base_uom" binding:"omitempty,max=10"`
+	Barcodes      *[]BarcodeDTO  `json:"barcodes" binding:"omitempty,dive"`
+	Packs         *[]PackDTO     `json:"packs" binding:"omitempty,dive"`
+	Dimensions    *DimensionsDTO `json:"dimensions"`
+	WeightGrams   *int64         `json:"weight_grams" binding:"omitempty,min=0"`
+	ShelfLifeDays *int           `json:"shelf_life_days" binding:"omitempty,min=0"`
+	Status        *string        `json:"status" binding:"omitempty,oneof=ACTIVE INACTIVE"`
+	Serialized    *bool          `json:"serialized"`
+
+	// Quantity is rejected when present; stock changes go through adjustments
+	Quantity *int64 `json:"quantity,omitempty"`
+}
+
+// AdjustStockRequest is the DTO for correcting a product's quantity through the ledger
+type AdjustStockRequest struct {
+	LocationID     int64    `json:"location_id" binding:"required,min=1"`
+	Delta          int64    `json:"delta" binding:"required"`
+	Reason         string   `json:"reason" binding:"required,max=1000"`
+	DocumentNumber string   `json:"document_number,omitempty" binding:"max=100"`
+	Serials        []string `json:"serials,omitempty" binding:"dive,required,max=100"`
 }
 
 // ProductResponse is the DTO for product response
 type ProductResponse struct {
-	ID       int64  `json:"id"`
-	SKUName  string `json:"sku_name"`
-	Quantity int64  `json:"quantity"`
+	ID            int64         `json:"id"`
+	SKUName       string        `json:"sku_name"`
+	Quantity      int64         `json:"quantity"`
+	Description   string        `json:"description"`
+	Category      string        `json:"category"`
+	Brand         string        `json:"brand"`
+	BaseUOM       string        `json:"base_uom"`
+	Barcodes      []BarcodeDTO  `json:"barcodes"`
+	Packs         []PackDTO     `json:"packs"`
+	Dimensions    DimensionsDTO `json:"dimensions"`
+	WeightGrams   int64         `json:"weight_grams"`
+	ShelfLifeDays int           `json:"shelf_life_days"`
+	Status        string        `json:"status"`
+	Serialized    bool          `json:"serialized"`
+	Version       int64         `json:"version"`
+
+	// QuantityInUOM is only set when a pack level was requested
+	QuantityInUOM *PackQuantityDTO `json:"quantity_in_uom,omitempty"`
+}
+
+// NewProductResponse maps a product entity to its response DTO
+func NewProductResponse(p *product.Product) *ProductResponse {
+	barcodes := make([]BarcodeDTO, 0, len(p.Barcodes))
+	for _, b := range p.Barcodes {
+		barcodes = append(barcodes, BarcodeDTO{Type: string(b.Type), Value: b.Value})
+	}
+
+	packs := make([]PackDTO, 0, len(p.Packs))
+	for _, pack := range p.Packs {
+		packs = append(packs, PackDTO{Level: string(pack.Level), UnitsPerPack: pack.UnitsPerPack})
+	}
+
+	return &ProductResponse{
+		ID:          p.ID,
+		SKUName:     p.SKUName,
+		Quantity:    p.Quantity,
+		Description: p.Description,
+		Category:    p.Category,
+		Brand:       p.Brand,
+		BaseUOM:     p.BaseUOM,
+		Barcodes:    barcodes,
+		Packs:       packs,
+		Dimensions: DimensionsDTO{
+			LengthMM: p.Dimensions.LengthMM,
+			WidthMM:  p.Dimensions.WidthMM,
+			HeightMM: p.Dimensions.HeightMM,
+		},
+		WeightGrams:   p.WeightGrams,
+		ShelfLifeDays: p.ShelfLifeDays,
+		Status:        string(p.Status),
+		Serialized:    p.Serialized,
+		Version:       p.Version,
+	}
+}
+
+// SetQuantityIn expresses the product's quantity in the given pack level
+func (r *ProductResponse) SetQuantityIn(p *product.Product, level product.PackLevel) error {
+	packs, remainder, err := p.FromBaseUnits(level, p.Quantity)
+	if err != nil {
+		return err
+	}
+
+	r.QuantityInUOM = &PackQuantityDTO{UOM: string(level), Packs: packs, Remainder: remainder}
+	return nil
+}
+
+// ToPacks maps pack DTOs to domain packs
+func ToPacks(items []PackDTO) []product.Pack {
+	packs := make([]product.Pack, 0, len(items))
+	for _, p := range items {
+		packs = append(packs, product.Pack{Level: product.PackLevel(p.Level), UnitsPerPack: p.UnitsPerPack})
+	}
+	return packs
+}
+
+// ToBarcodes maps barcode DTOs to domain barcodes
+func ToBarcodes(items []BarcodeDTO) []product.Barcode {
+	barcodes := make([]product.Barcode, 0, len(items))
+	for _, b := range items {
+		barcodes = append(barcodes, product.Barcode{Type: product.BarcodeType(b.Type), Value: b.Value})
+	}
+	return barcodes
+}
+
+// ToDimensions maps a dimensions DTO to domain dimensions
+func ToDimensions(d *DimensionsDTO) product.Dimensions {
+	if d == nil {
+		return product.Dimensions{}
+	}
+	return product.Dimensions{LengthMM: d.LengthMM, WidthMM: d.WidthMM, HeightMM: d.HeightMM}
+}
+
+// ProductFilter is the DTO for filtering, searching and sorting product listings.
+// Sort lists fields to order by, each prefixed with - for descending order.
+type ProductFilter struct {
+	SKUPrefix   string `form:"sku_prefix"`
+	Category    string `form:"category"`
+	Brand       string `form:"brand"`
+	Status      string `form:"status" binding:"omitempty,oneof=ACTIVE INACTIVE"`
+	MinQuantity *int64 `form:"min_quantity"`
+	MaxQuantity *int64 `form:"max_quantity"`
+	Search      string `form:"q" binding:"max=100"`
+	Sort        string `form:"sort"`
+}
+
+// ProductSearchHit is the DTO for one product a search found
+type ProductSearchHit struct {
+	*ProductResponse
+	Score float64 `json:"score"`
+
+	// Match is barcode when a scanned code identified the product exactly
+	// and text when it was ranked by the search
+	Match string `json:"match"`
+}
+
+// ProductSearchResponse is the DTO for product search results, best first.
+// Scan is set when the query was a GS1 scan.
+type ProductSearchResponse struct {
+	Query string              `json:"query"`
+	Scan  *ScanResponse       `json:"scan,omitempty"`
+	Data  []*ProductSearchHit `json:"data"`
 }
 
 // ProductListResponse is the DTO for product list response
 type ProductListResponse struct {
-	Data   []*ProductResponse `json:"data"`
-	Total  int64              `json:"total"`
-	Limit  int                `json:"limit"`
-	Offset int                `json:"offset"`
+	Data       []*ProductResponse `json:"data"`
+	Total      *int64             `json:"total,omitempty"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,63 @@
+package dto
+
+import "github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+
+// PutawaySuggestRequest is the DTO for asking where to put goods away
+type PutawaySuggestRequest struct {
+	ProductID int64  `json:"product_id" binding:"required,min=1"`
+	Quantity  int64  `json:"quantity" binding:"required,min=1"`
+	UOM       string `json:"uom,omitempty" binding:"omitempty,oneof=EACH INNER CASE PALLET"`
+	Lot       string `json:"lot,omitempty" binding:"max=50"`
+	Limit     int    `json:"limit,omitempty" binding:"min=0,max=50"`
+}
+
+// PutawaySuggestionResponse is the DTO for one ranked putaway location
+type PutawaySuggestionResponse struct {
+	LocationID      int64    `json:"location_id"`
+	LocationCode    string   `json:"location_code"`
+	TemperatureZone string   `json:"temperature_zone"`
+	Score           int      `json:"score"`
+	OnHand          int64    `json:"on_hand"`
+	SameLot         bool     `json:"same_lot"`
+	FreeUnits       int64    `json:"free_units"`
+	Reasons         []string `json:"reasons"`
+}
+
+// NewPutawaySuggestionResponse maps a putaway suggestion to its response DTO
+func NewPutawaySuggestionResponse(s *putaway.Suggestion) *PutawaySuggestionResponse {
+	return &PutawaySuggestionResponse{
+		LocationID:      s.Location.ID,
+		LocationCode:    s.Location.Code,
+		TemperatureZone: string(s.Location.TemperatureZone),
+		Score:           s.Score,
+		OnHand:          s.OnHand,
+		SameLot:         s.SameLot,
+		FreeUnits:       s.FreeUnits,
+		Reasons:         s.Reasons,
+	}
+}
+
+// PutawayRuleRequest is the DTO for setting a category's putaway rule
+type PutawayRuleRequest struct {
+	TemperatureZone string `json:"temperature_zone,omitempty" binding:"omitempty,oneof=AMBIENT CHILLED FROZEN"`
+	LocationPrefix  string `json:"location_prefix,omitempty" binding:"max=100"`
+	AllowMixedSKUs  bool   `json:"allow_mixed_skus"`
+}
+
+// PutawayRuleResponse is the DTO for putaway rule response
+type PutawayRuleResponse struct {
+	Category        string `json:"category"`
+	TemperatureZone string `json:"temperature_zone"`
+	LocationPrefix  string `json:"location_prefix,omitempty"`
+	AllowMixedSKUs  bool   `json:"allow_mixed_skus"`
+}
+
+// NewPutawayRuleResponse maps a putaway rule to its response DTO
+func NewPutawayRuleResponse(r *putaway.Rule) *PutawayRuleResponse {
+	return &PutawayRuleResponse{
+		Category:        r.Category,
+		TemperatureZone: string(r.TemperatureZone),
+		LocationPrefix:  r.LocationPrefix,
+		AllowMixedSKUs:  r.AllowMixedSKUs,
+	}
+}
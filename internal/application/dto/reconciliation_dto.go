@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// ReconcileRequest is the DTO for triggering a reconciliation run
+type ReconcileRequest struct {
+	Repair bool `json:"repair"`
+}
+
+// DiscrepancyResponse is the DTO for a single reconciliation discrepancy
+type DiscrepancyResponse struct {
+	Scope      string `json:"scope"`
+	ProductID  int64  `json:"product_id"`
+	LocationID int64  `json:"location_id,omitempty"`
+	Stored     int64  `json:"stored"`
+	Ledger     int64  `json:"ledger"`
+	Difference int64  `json:"difference"`
+	Reason     string `json:"reason"`
+	Repaired   bool   `json:"repaired"`
+}
+
+// ReconciliationReportResponse is the DTO for a reconciliation report
+type ReconciliationReportResponse struct {
+	StartedAt        time.Time              `json:"started_at"`
+	FinishedAt       time.Time              `json:"finished_at"`
+	ProductsChecked  int                    `json:"products_checked"`
+	LocationsChecked int                    `json:"locations_checked"`
+	Discrepancies    []*DiscrepancyResponse `json:"discrepancies"`
+}
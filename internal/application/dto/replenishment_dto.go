@@ -0,0 +1,167 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// PickFaceRequest is the DTO for setting the min/max of a SKU at a pick location
+type PickFaceRequest struct {
+	ProductID  int64 `json:"product_id" binding:"required,min=1"`
+	LocationID int64 `json:"location_id" binding:"required,min=1"`
+	Min        int64 `json:"min" binding:"min=0"`
+	Max        int64 `json:"max" binding:"required,min=1"`
+}
+
+// PickFaceResponse is the DTO for pick face response
+type PickFaceResponse struct {
+	ProductID  int64 `json:"product_id"`
+	LocationID int64 `json:"location_id"`
+	Min        int64 `json:"min"`
+	Max        int64 `json:"max"`
+}
+
+// NewPickFaceResponse maps a pick face to its response DTO
+func NewPickFaceResponse(pf *replenishment.PickFace) *PickFaceResponse {
+	return &PickFaceResponse{
+		ProductID:  pf.ProductID,
+		LocationID: pf.LocationID,
+		Min:        pf.Min,
+		Max:        pf.Max,
+	}
+}
+
+// RunReplenishmentRequest is the DTO for running replenishment on demand, e.g. before a wave
+type RunReplenishmentRequest struct {
+	ProductIDs []int64 `json:"product_ids,omitempty" binding:"dive,min=1"`
+	Reference  string  `json:"reference,omitempty" binding:"max=100"`
+}
+
+// CompleteReplenishmentTaskRequest is the DTO for confirming a replenishment task was carried out
+type CompleteReplenishmentTaskRequest struct {
+	Serials        []string `json:"serials,omitempty" binding:"dive,required,max=100"`
+	DocumentNumber string   `json:"document_number,omitempty" binding:"max=100"`
+	Notes          string   `json:"notes,omitempty" binding:"max=1000"`
+}
+
+// ReplenishmentTaskResponse is the DTO for replenishment task response
+type ReplenishmentTaskResponse struct {
+	ID             int64      `json:"id"`
+	ProductID      int64      `json:"product_id"`
+	FromLocationID int64      `json:"from_location_id"`
+	ToLocationID   int64      `json:"to_location_id"`
+	Quantity       int64      `json:"quantity"`
+	Status         string     `json:"status"`
+	Reference      string     `json:"reference,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// NewReplenishmentTaskResponse maps a replenishment task to its response DTO
+func NewReplenishmentTaskResponse(t *replenishment.Task) *ReplenishmentTaskResponse {
+	return &ReplenishmentTaskResponse{
+		ID:             t.ID,
+		ProductID:      t.ProductID,
+		FromLocationID: t.FromLocationID,
+		ToLocationID:   t.ToLocationID,
+		Quantity:       t.Quantity,
+		Status:         string(t.Status),
+		Reference:      t.Reference,
+		CreatedAt:      t.CreatedAt,
+		CompletedAt:    t.CompletedAt,
+	}
+}
+
+// CompleteReplenishmentTaskResponse is the DTO for a completed task and the movements it wrote
+type CompleteReplenishmentTaskResponse struct {
+	Task      *ReplenishmentTaskResponse `json:"task"`
+	Movements []*StockMovementResponse   `json:"movements"`
+}
+
+// ReplenishmentTaskListResponse is the DTO for paginated replenishment task list
+type ReplenishmentTaskListResponse struct {
+	Data   []*ReplenishmentTaskResponse `json:"data"`
+	Limit  int                          `json:"limit"`
+	Offset int                          `json:"offset"`
+}
+
+// ReorderPolicyRequest is the DTO for setting when a SKU should be reordered.
+// An empty warehouse sets the policy for the SKU across all warehouses.
+type ReorderPolicyRequest struct {
+	ProductID    int64  `json:"product_id" binding:"required,min=1"`
+	Warehouse    string `json:"warehouse,omitempty" binding:"max=50"`
+	ReorderPoint int64  `json:"reorder_point" binding:"min=0"`
+	SafetyStock  int64  `json:"safety_stock" binding:"min=0"`
+	ReorderQty   int64  `json:"reorder_qty" binding:"required,min=1"`
+}
+
+// ReorderPolicyResponse is the DTO for reorder policy response
+type ReorderPolicyResponse struct {
+	ProductID    int64  `json:"product_id"`
+	Warehouse    string `json:"warehouse,omitempty"`
+	ReorderPoint int64  `json:"reorder_point"`
+	SafetyStock  int64  `json:"safety_stock"`
+	ReorderQty   int64  `json:"reorder_qty"`
+}
+
+// NewReorderPolicyResponse maps a reorder policy to its response DTO
+func NewReorderPolicyResponse(p *replenishment.ReorderPolicy) *ReorderPolicyResponse {
+	return &ReorderPolicyResponse{
+		ProductID:    p.ProductID,
+		Warehouse:    p.Warehouse,
+		ReorderPoint: p.ReorderPoint,
+		SafetyStock:  p.SafetyStock,
+		ReorderQty:   p.ReorderQty,
+	}
+}
+
+// StockAlertResponse is the DTO for a low-stock alert
+type StockAlertResponse struct {
+	ID           int64      `json:"id"`
+	ProductID    int64      `json:"product_id"`
+	Warehouse    string     `json:"warehouse,omitempty"`
+	Available    int64      `json:"available"`
+	ReorderPoint int64      `json:"reorder_point"`
+	SafetyStock  int64      `json:"safety_stock"`
+	Severity     string     `json:"severity"`
+	RaisedAt     time.Time  `json:"raised_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// NewStockAlertResponse maps a stock alert to its response DTO
+func NewStockAlertResponse(a *replenishment.Alert) *StockAlertResponse {
+	return &StockAlertResponse{
+		ID:           a.ID,
+		ProductID:    a.ProductID,
+		Warehouse:    a.Warehouse,
+		Available:    a.Available,
+		ReorderPoint: a.ReorderPoint,
+		SafetyStock:  a.SafetyStock,
+		Severity:     string(a.Severity),
+		RaisedAt:     a.RaisedAt,
+		UpdatedAt:    a.UpdatedAt,
+		ResolvedAt:   a.ResolvedAt,
+	}
+}
+
+// StockAlertListResponse is the DTO for paginated stock alert list
+type StockAlertListResponse struct {
+	Data   []*StockAlertResponse `json:"data"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// ReorderSuggestionResponse is the DTO for one line of the reorder report
+type ReorderSuggestionResponse struct {
+	ProductID    int64  `json:"product_id"`
+	SKUName      string `json:"sku_name"`
+	Warehouse    string `json:"warehouse,omitempty"`
+	Available    int64  `json:"available"`
+	ReorderPoint int64  `json:"reorder_point"`
+	SafetyStock  int64  `json:"safety_stock"`
+	ReorderQty   int64  `json:"reorder_qty"`
+	Severity     string `json:"severity"`
+	SuggestedQty int64  `json:"suggested_qty"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
+)
+
+// ParseScanRequest is the DTO for parsing a raw scanner string
+type ParseScanRequest struct {
+	Scan string `json:"scan" binding:"required,max=200"`
+}
+
+// ScanElementDTO is one application identifier of a scan and its data
+type ScanElementDTO struct {
+	AI    string `json:"ai"`
+	Value string `json:"value"`
+}
+
+// ScanResponse is the DTO for the fields of a parsed GS1 scan
+type ScanResponse struct {
+	SSCC        string           `json:"sscc,omitempty"`
+	GTIN        string           `json:"gtin,omitempty"`
+	ContentGTIN string           `json:"content_gtin,omitempty"`
+	Lot         string           `json:"lot,omitempty"`
+	Expiry      string           `json:"expiry,omitempty"`
+	Serial      string           `json:"serial,omitempty"`
+	Count       int64            `json:"count,omitempty"`
+	Elements    []ScanElementDTO `json:"elements,omitempty"`
+}
+
+// ParseScanResponse is the DTO for a parsed scan and what it resolved to
+type ParseScanResponse struct {
+	Fields    *ScanResponse      `json:"fields,omitempty"`
+	Product   *ProductResponse   `json:"product"`
+	Lot       string             `json:"lot,omitempty"`
+	Container *ContainerResponse `json:"container"`
+}
+
+// NewScanResponse maps a parsed scan to its response DTO
+func NewScanResponse(scan *gs1.Scan) *ScanResponse {
+	resp := &ScanResponse{
+		SSCC:        scan.SSCC,
+		GTIN:        scan.GTIN,
+		ContentGTIN: scan.ContentGTIN,
+		Lot:         scan.Lot,
+		Serial:      scan.Serial,
+		Count:       scan.Count,
+	}
+	if !scan.Expiry.IsZero() {
+		resp.Expiry = scan.Expiry.Format("2006-01-02")
+	}
+	for _, e := range scan.Elements {
+		resp.Elements = append(resp.Elements, ScanElementDTO{AI: e.AI, Value: e.Value})
+	}
+	return resp
+}
+
+// NewParseScanResponse maps a scan resolution to its response DTO
+func NewParseScanResponse(res *scanning.Resolution) *ParseScanResponse {
+	resp := &ParseScanResponse{Lot: res.Lot}
+	if res.Scan != nil {
+		resp.Fields = NewScanResponse(res.Scan)
+	}
+	if res.Product != nil {
+		resp.Product = NewProductResponse(res.Product)
+	}
+	if res.Container != nil {
+		resp.Container = NewContainerResponse(res.Container)
+	}
+	return resp
+}
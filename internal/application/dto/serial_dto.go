@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
+)
+
+// SerialFilter is the DTO for filtering a product's serials
+type SerialFilter struct {
+	LocationID int64  `form:"location_id" binding:"omitempty,min=1"`
+	Status     string `form:"status" binding:"omitempty,oneof=IN_STOCK SHIPPED"`
+}
+
+// SerialEventResponse is the DTO for one entry in a serial's history
+type SerialEventResponse struct {
+	MovementID   int64     `json:"movement_id"`
+	MovementType string    `json:"movement_type"`
+	LocationID   int64     `json:"location_id"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SerialResponse is the DTO for a serialized unit
+type SerialResponse struct {
+	ID         int64                  `json:"id"`
+	ProductID  int64                  `json:"product_id"`
+	Serial     string                 `json:"serial"`
+	LocationID int64                  `json:"location_id,omitempty"`
+	Status     string                 `json:"status"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	History    []*SerialEventResponse `json:"history,omitempty"`
+}
+
+// NewSerialResponse maps a serial entity to its response DTO
+func NewSerialResponse(s *serial.Serial) *SerialResponse {
+	return &SerialResponse{
+		ID:         s.ID,
+		ProductID:  s.ProductID,
+		Serial:     s.Number,
+		LocationID: s.LocationID,
+		Status:     string(s.Status),
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+// SetHistory attaches the serial's movement history
+func (r *SerialResponse) SetHistory(events []*serial.Event) {
+	r.History = make([]*SerialEventResponse, 0, len(events))
+	for _, e := range events {
+		r.History = append(r.History, &SerialEventResponse{
+			MovementID:   e.MovementID,
+			MovementType: e.MovementType,
+			LocationID:   e.LocationID,
+			Status:       string(e.Status),
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+}
+
+// SerialListResponse is the DTO for serial list response
+type SerialListResponse struct {
+	Data   []*SerialResponse `json:"data"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
@@ -1,31 +1,110 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
 
 // RecordStockMovementRequest is the DTO for recording stock movement
 type RecordStockMovementRequest struct {
-	ProductID  int64  `json:"product_id" binding:"required,min=1"`
-	LocationID int64  `json:"location_id" binding:"required,min=1"`
-	Type       string `json:"type" binding:"required,oneof=IN OUT"`
-	Quantity   int64  `json:"quantity" binding:"required,min=1"`
+	ProductID      int64    `json:"product_id" binding:"required_without=Scan,min=0"`
+	LocationID     int64    `json:"location_id" binding:"required_without_all=AutoAssign LocationScan Scan,min=0"`
+	Type           string   `json:"type" binding:"required,oneof=IN OUT"`
+	Quantity       int64    `json:"quantity" binding:"required_without=Scan,min=0"`
+	UOM            string   `json:"uom,omitempty" binding:"omitempty,oneof=EACH INNER CASE PALLET"`
+	ReferenceType  string   `json:"reference_type,omitempty" binding:"omitempty,oneof=PO ORDER RMA TRANSFER COUNT"`
+	ReferenceID    string   `json:"reference_id,omitempty" binding:"max=100"`
+	DocumentNumber string   `json:"document_number,omitempty" binding:"max=100"`
+	Notes          string   `json:"notes,omitempty" binding:"max=1000"`
+	Serials        []string `json:"serials,omitempty" binding:"dive,required,max=100"`
+
+	// AutoAssign lets an IN movement without location_id go to the best putaway location
+	AutoAssign bool   `json:"auto_assign,omitempty"`
+	Lot        string `json:"lot,omitempty" binding:"max=50"`
+
+	// Scan is a raw product or pallet label scan standing in for product_id. Its
+	// lot fills in lot, its count (37) fills in quantity, and a container's
+	// location fills in location_id when no location is given.
+	Scan string `json:"scan,omitempty" binding:"max=200"`
+
+	// LocationScan is a scanned location code standing in for location_id
+	LocationScan string `json:"location_scan,omitempty" binding:"max=100"`
 }
 
 // StockMovementResponse is the DTO for stock movement response
 type StockMovementResponse struct {
-	ID         int64     `json:"id"`
-	ProductID  int64     `json:"product_id"`
-	LocationID int64     `json:"location_id"`
-	Type       string    `json:"type"`
-	Quantity   int64     `json:"quantity"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	Sequence       int64     `json:"sequence"`
+	ProductID      int64     `json:"product_id"`
+	LocationID     int64     `json:"location_id"`
+	Type           string    `json:"type"`
+	Quantity       int64     `json:"quantity"`
+	ReferenceType  string    `json:"reference_type,omitempty"`
+	ReferenceID    string    `json:"reference_id,omitempty"`
+	DocumentNumber string    `json:"document_number,omitempty"`
+	Notes          string    `json:"notes,omitempty"`
+	EnteredUOM     string    `json:"entered_uom,omitempty"`
+	EnteredQty     int64     `json:"entered_quantity,omitempty"`
+	Serials        []string  `json:"serials,omitempty"`
+	Lot            string    `json:"lot,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewStockMovementResponse maps a stock movement entity to its response DTO
+func NewStockMovementResponse(m *stock.StockMovement) *StockMovementResponse {
+	return &StockMovementResponse{
+		ID:             m.ID,
+		Sequence:       m.Sequence,
+		ProductID:      m.ProductID,
+		LocationID:     m.LocationID,
+		Type:           string(m.Type),
+		Quantity:       m.Quantity,
+		ReferenceType:  string(m.ReferenceType),
+		ReferenceID:    m.ReferenceID,
+		DocumentNumber: m.DocumentNumber,
+		Notes:          m.Notes,
+		EnteredUOM:     string(m.EnteredUOM),
+		EnteredQty:     m.EnteredQuantity,
+		Serials:        m.Serials,
+		Lot:            m.Lot,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// ReverseStockMovementRequest is the DTO for reversing a recorded stock movement
+type ReverseStockMovementRequest struct {
+	Notes   string   `json:"notes,omitempty" binding:"max=1000"`
+	Serials []string `json:"serials,omitempty" binding:"dive,required,max=100"`
+}
+
+// StockMovementFilter is the DTO for filtering stock movement listings
+type StockMovementFilter struct {
+	ProductID      int64  `form:"product_id" binding:"omitempty,min=1"`
+	LocationID     int64  `form:"location_id" binding:"omitempty,min=1"`
+	Type           string `form:"type" binding:"omitempty,oneof=IN OUT"`
+	ReferenceType  string `form:"reference_type" binding:"omitempty,oneof=PO ORDER RMA TRANSFER COUNT ADJUSTMENT REVERSAL"`
+	ReferenceID    string `form:"reference_id"`
+	DocumentNumber string `form:"document_number"`
+	MinQuantity    *int64 `form:"min_quantity"`
+	MaxQuantity    *int64 `form:"max_quantity"`
+	Search         string `form:"q" binding:"max=100"`
+	Sort           string `form:"sort"`
+
+	// From and To bound the creation time, both inclusive. Handlers parse them
+	// from the from and to query parameters.
+	From time.Time `form:"-"`
+	To   time.Time `form:"-"`
 }
 
 // StockMovementListResponse is the DTO for stock movement list response
 type StockMovementListResponse struct {
-	Data   []*StockMovementResponse `json:"data"`
-	Total  int64                    `json:"total"`
-	Limit  int                      `json:"limit"`
-	Offset int                      `json:"offset"`
+	Data       []*StockMovementResponse `json:"data"`
+	Total      *int64                   `json:"total,omitempty"`
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
 }
 
 // LocationStockResponse is the DTO for location stock response
@@ -37,23 +116,71 @@ type LocationStockResponse struct {
 
 // LocationRequest is the DTO for creating/updating location
 type LocationRequest struct {
-	Code     string `json:"code" binding:"required"`
-	Name     string `json:"name" binding:"required"`
-	Capacity int64  `json:"capacity" binding:"required,min=1"`
+	Code            string `json:"code" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	Capacity        int64  `json:"capacity" binding:"required,min=1"`
+	MaxVolumeCM3    int64  `json:"max_volume_cm3,omitempty" binding:"min=0"`
+	MaxWeightGrams  int64  `json:"max_weight_grams,omitempty" binding:"min=0"`
+	PalletPositions int64  `json:"pallet_positions,omitempty" binding:"min=0"`
+	TemperatureZone string `json:"temperature_zone,omitempty" binding:"omitempty,oneof=AMBIENT CHILLED FROZEN"`
+	Warehouse       string `json:"warehouse,omitempty" binding:"max=50"`
 }
 
 // LocationResponse is the DTO for location response
 type LocationResponse struct {
-	ID       int64  `json:"id"`
-	Code     string `json:"code"`
-	Name     string `json:"name"`
-	Capacity int64  `json:"capacity"`
+	ID              int64  `json:"id"`
+	Code            string `json:"code"`
+	Name            string `json:"name"`
+	Capacity        int64  `json:"capacity"`
+	MaxVolumeCM3    int64  `json:"max_volume_cm3"`
+	MaxWeightGrams  int64  `json:"max_weight_grams"`
+	PalletPositions int64  `json:"pallet_positions"`
+	TemperatureZone string `json:"temperature_zone"`
+	Warehouse       string `json:"warehouse,omitempty"`
+	Version         int64  `json:"version"`
+}
+
+// NewLocationResponse maps a location entity to its response DTO
+func NewLocationResponse(l *location.Location) *LocationResponse {
+	return &LocationResponse{
+		ID:              l.ID,
+		Code:            l.Code,
+		Name:            l.Name,
+		Capacity:        l.Capacity,
+		MaxVolumeCM3:    l.MaxVolumeCM3,
+		MaxWeightGrams:  l.MaxWeightGrams,
+		PalletPositions: l.PalletPositions,
+		TemperatureZone: string(l.TemperatureZone),
+		Warehouse:       l.Warehouse,
+		Version:         l.Version,
+	}
+}
+
+// Limits maps the request's optional physical limits to the domain
+func (r *LocationRequest) Limits() location.Limits {
+	return location.Limits{
+		MaxVolumeCM3:    r.MaxVolumeCM3,
+		MaxWeightGrams:  r.MaxWeightGrams,
+		PalletPositions: r.PalletPositions,
+	}
+}
+
+// LocationFilter is the DTO for filtering, searching and sorting location listings
+type LocationFilter struct {
+	CodePrefix      string `form:"code_prefix"`
+	Warehouse       string `form:"warehouse"`
+	TemperatureZone string `form:"temperature_zone" binding:"omitempty,oneof=AMBIENT CHILLED FROZEN"`
+	MinCapacity     *int64 `form:"min_capacity"`
+	MaxCapacity     *int64 `form:"max_capacity"`
+	Search          string `form:"q" binding:"max=100"`
+	Sort            string `form:"sort"`
 }
 
 // LocationListResponse is the DTO for location list response
 type LocationListResponse struct {
-	Data   []*LocationResponse `json:"data"`
-	Total  int64               `json:"total"`
-	Limit  int                 `json:"limit"`
-	Offset int                 `json:"offset"`
+	Data       []*LocationResponse `json:"data"`
+	Total      *int64              `json:"total,omitempty"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
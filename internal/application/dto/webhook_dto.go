@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// CreateWebhookRequest is the DTO for subscribing an endpoint to events
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,max=2000"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Secret     string   `json:"secret,omitempty" binding:"omitempty,min=16,max=200"`
+}
+
+// WebhookResponse is the DTO for webhook subscription response. The secret is
+// only returned when the subscription is created.
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewWebhookResponse maps a subscription to its response DTO without the secret
+func NewWebhookResponse(s *webhook.Subscription) *WebhookResponse {
+	types := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		types = append(types, string(t))
+	}
+
+	return &WebhookResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: types,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// WebhookDeliveryResponse is the DTO for one event delivery to one subscription
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// NewWebhookDeliveryResponse maps a delivery to its response DTO
+func NewWebhookDeliveryResponse(d *webhook.Delivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+// WebhookDeliveryListResponse is the DTO for paginated webhook delivery list
+type WebhookDeliveryListResponse struct {
+	Data   []*WebhookDeliveryResponse `json:"data"`
+	Limit  int                        `json:"limit"`
+	Offset int                        `json:"offset"`
+}
@@ -34,6 +34,13 @@ type TransactionManager interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
+// Lock keeps a job to one runner at a time, across instances
+type Lock interface {
+	// TryRun runs fn while holding the lock and reports whether it did; it
+	// returns false without running fn when the lock is held elsewhere
+	TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
 // Clock provides current time
 type Clock interface {
 	Now() interface{}
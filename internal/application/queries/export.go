@@ -0,0 +1,275 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// exportPageSize is how many products or locations are read per repository call
+const exportPageSize = 500
+
+// ErrProjectionsUnavailable is returned for balance exports when no projection
+// repository is configured
+var ErrProjectionsUnavailable = errors.New("balance projections are not available")
+
+// Export columns. Products and locations use the column names import reads;
+// import skips id, quantity and version.
+var (
+	productExportColumns = []dto.ExportColumn{
+		{Name: "id", Type: dto.ExportInt64},
+		{Name: "sku_name", Type: dto.ExportString},
+		{Name: "description", Type: dto.ExportString},
+		{Name: "category", Type: dto.ExportString},
+		{Name: "brand", Type: dto.ExportString},
+		{Name: "base_uom", Type: dto.ExportString},
+		{Name: "barcodes", Type: dto.ExportString},
+		{Name: "packs", Type: dto.ExportString},
+		{Name: "length_mm", Type: dto.ExportInt64},
+		{Name: "width_mm", Type: dto.ExportInt64},
+		{Name: "height_mm", Type: dto.ExportInt64},
+		{Name: "weight_grams", Type: dto.ExportInt64},
+		{Name: "shelf_life_days", Type: dto.ExportInt64},
+		{Name: "status", Type: dto.ExportString},
+		{Name: "serialized", Type: dto.ExportBool},
+		{Name: "quantity", Type: dto.ExportInt64},
+		{Name: "version", Type: dto.ExportInt64},
+	}
+	locationExportColumns = []dto.ExportColumn{
+		{Name: "id", Type: dto.ExportInt64},
+		{Name: "code", Type: dto.ExportString},
+		{Name: "name", Type: dto.ExportString},
+		{Name: "capacity", Type: dto.ExportInt64},
+		{Name: "max_volume_cm3", Type: dto.ExportInt64},
+		{Name: "max_weight_grams", Type: dto.ExportInt64},
+		{Name: "pallet_positions", Type: dto.ExportInt64},
+		{Name: "temperature_zone", Type: dto.ExportString},
+		{Name: "warehouse", Type: dto.ExportString},
+		{Name: "version", Type: dto.ExportInt64},
+	}
+	movementExportColumns = []dto.ExportColumn{
+		{Name: "id", Type: dto.ExportInt64},
+		{Name: "sequence", Type: dto.ExportInt64},
+		{Name: "product_id", Type: dto.ExportInt64},
+		{Name: "location_id", Type: dto.ExportInt64},
+		{Name: "type", Type: dto.ExportString},
+		{Name: "quantity", Type: dto.ExportInt64},
+		{Name: "lot", Type: dto.ExportString},
+		{Name: "reference_type", Type: dto.ExportString},
+		{Name: "reference_id", Type: dto.ExportString},
+		{Name: "document_number", Type: dto.ExportString},
+		{Name: "notes", Type: dto.ExportString},
+		{Name: "entered_uom", Type: dto.ExportString},
+		{Name: "entered_quantity", Type: dto.ExportInt64},
+		{Name: "created_at", Type: dto.ExportTime},
+	}
+	balanceExportColumns = []dto.ExportColumn{
+		{Name: "product_id", Type: dto.ExportInt64},
+		{Name: "location_id", Type: dto.ExportInt64},
+		{Name: "quantity", Type: dto.ExportInt64},
+	}
+	lotExportColumns = []dto.ExportColumn{
+		{Name: "product_id", Type: dto.ExportInt64},
+		{Name: "location_id", Type: dto.ExportInt64},
+		{Name: "lot", Type: dto.ExportString},
+		{Name: "quantity", Type: dto.ExportInt64},
+	}
+)
+
+// ExportWriter receives the rows of an export. Begin is called once, before
+// the first row, with the columns every row follows.
+type ExportWriter interface {
+	Begin(columns []dto.ExportColumn) error
+	WriteRow(values []interface{}) error
+}
+
+// ExportQuery streams master data, stock movements and balances row by row,
+// so exports of any size run in constant memory
+type ExportQuery struct {
+	productRepo    product.Repository
+	locationRepo   location.Repository
+	stockRepo      stock.Repository
+	projectionRepo stock.ProjectionRepository
+}
+
+// NewExportQuery creates a new export query
+func NewExportQuery(productRepo product.Repository, locationRepo location.Repository, stockRepo stock.Repository) *ExportQuery {
+	return &ExportQuery{
+		productRepo:  productRepo,
+		locationRepo: locationRepo,
+		stockRepo:    stockRepo,
+	}
+}
+
+// WithProjections enables the balance and lot exports
+func (q *ExportQuery) WithProjections(projectionRepo stock.ProjectionRepository) *ExportQuery {
+	q.projectionRepo = projectionRepo
+	return q
+}
+
+// Products writes every product and returns how many were written
+func (q *ExportQuery) Products(ctx context.Context, w ExportWriter) (int64, error) {
+	if err := w.Begin(productExportColumns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	spec := product.ListSpec{Limit: exportPageSize}
+	for {
+		products, err := q.productRepo.List(ctx, spec)
+		if err != nil {
+			return count, err
+		}
+		for _, p := range products {
+			err := w.WriteRow([]interface{}{
+				p.ID, p.SKUName, p.Description, p.Category, p.Brand, p.BaseUOM,
+				formatBarcodes(p.Barcodes), formatPacks(p.Packs),
+				p.Dimensions.LengthMM, p.Dimensions.WidthMM, p.Dimensions.HeightMM,
+				p.WeightGrams, int64(p.ShelfLifeDays), string(p.Status), p.Serialized,
+				p.Quantity, p.Version,
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(products) < exportPageSize {
+			return count, nil
+		}
+		spec.After = listing.Keys(products[len(products)-1], product.DefaultOrder, product.SortValue)
+	}
+}
+
+// Locations writes every location and returns how many were written
+func (q *ExportQuery) Locations(ctx context.Context, w ExportWriter) (int64, error) {
+	if err := w.Begin(locationExportColumns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	spec := location.ListSpec{Limit: exportPageSize}
+	for {
+		locations, err := q.locationRepo.List(ctx, spec)
+		if err != nil {
+			return count, err
+		}
+		for _, l := range locations {
+			err := w.WriteRow([]interface{}{
+				l.ID, l.Code, l.Name, l.Capacity,
+				l.MaxVolumeCM3, l.MaxWeightGrams, l.PalletPositions,
+				string(l.TemperatureZone), l.Warehouse, l.Version,
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(locations) < exportPageSize {
+			return count, nil
+		}
+		spec.After = listing.Keys(locations[len(locations)-1], location.DefaultOrder, location.SortValue)
+	}
+}
+
+// Movements writes the movements matching the filter in ledger order and
+// returns how many were written
+func (q *ExportQuery) Movements(ctx context.Context, filter *dto.StockMovementFilter, w ExportWriter) (int64, error) {
+	criteria, err := movementCriteria(filter)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.Begin(movementExportColumns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = q.stockRepo.Each(ctx, criteria, func(m *stock.StockMovement) error {
+		err := w.WriteRow([]interface{}{
+			m.ID, m.Sequence, m.ProductID, m.LocationID, string(m.Type), m.Quantity, m.Lot,
+			string(m.ReferenceType), m.ReferenceID, m.DocumentNumber, m.Notes,
+			string(m.EnteredUOM), m.EnteredQuantity, m.CreatedAt,
+		})
+		if err == nil {
+			count++
+		}
+		return err
+	})
+	return count, err
+}
+
+// Balances writes the current quantity per product and location
+func (q *ExportQuery) Balances(ctx context.Context, filter *dto.BalanceFilter, w ExportWriter) (int64, error) {
+	if q.projectionRepo == nil {
+		return 0, ErrProjectionsUnavailable
+	}
+
+	balances, err := q.projectionRepo.ListBalances(ctx, stock.BalanceFilter{
+		ProductID:  filter.ProductID,
+		LocationID: filter.LocationID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.Begin(balanceExportColumns); err != nil {
+		return 0, err
+	}
+	for i, b := range balances {
+		if err := w.WriteRow([]interface{}{b.ProductID, b.LocationID, b.Quantity}); err != nil {
+			return int64(i), err
+		}
+	}
+	return int64(len(balances)), nil
+}
+
+// Lots writes the current quantity per product, location and lot
+func (q *ExportQuery) Lots(ctx context.Context, filter *dto.BalanceFilter, w ExportWriter) (int64, error) {
+	if q.projectionRepo == nil {
+		return 0, ErrProjectionsUnavailable
+	}
+
+	balances, err := q.projectionRepo.ListLotBalances(ctx, stock.BalanceFilter{
+		ProductID:  filter.ProductID,
+		LocationID: filter.LocationID,
+		Lot:        filter.Lot,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.Begin(lotExportColumns); err != nil {
+		return 0, err
+	}
+	for i, b := range balances {
+		if err := w.WriteRow([]interface{}{b.ProductID, b.LocationID, b.Lot, b.Quantity}); err != nil {
+			return int64(i), err
+		}
+	}
+	return int64(len(balances)), nil
+}
+
+// formatBarcodes writes barcodes as TYPE:VALUE pairs separated by semicolons,
+// the way import reads them
+func formatBarcodes(barcodes []product.Barcode) string {
+	pairs := make([]string, len(barcodes))
+	for i, b := range barcodes {
+		pairs[i] = string(b.Type) + ":" + b.Value
+	}
+	return strings.Join(pairs, ";")
+}
+
+// formatPacks writes packs as LEVEL:UNITS pairs separated by semicolons
+func formatPacks(packs []product.Pack) string {
+	pairs := make([]string, len(packs))
+	for i, p := range packs {
+		pairs[i] = string(p.Level) + ":" + strconv.FormatInt(p.UnitsPerPack, 10)
+	}
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+)
+
+// GetContainerQuery handles container lookups by LPN or SSCC
+type GetContainerQuery struct {
+	containerService *container.Service
+}
+
+// NewGetContainerQuery creates a new get container query
+func NewGetContainerQuery(containerRepo container.Repository) *GetContainerQuery {
+	return &GetContainerQuery{
+		containerService: container.NewService(containerRepo),
+	}
+}
+
+// Execute returns the container with its contents and nested containers
+func (q *GetContainerQuery) Execute(ctx context.Context, code string) (*dto.ContainerResponse, error) {
+	cont, err := q.containerService.Find(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := q.containerService.LoadTree(ctx, cont)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewContainerResponse(tree), nil
+}
@@ -0,0 +1,53 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// GetStockAsOfQuery handles point-in-time stock balance lookups
+type GetStockAsOfQuery struct {
+	balanceService *stock.BalanceService
+}
+
+// NewGetStockAsOfQuery creates a new stock as-of query
+func NewGetStockAsOfQuery(balanceService *stock.BalanceService) *GetStockAsOfQuery {
+	return &GetStockAsOfQuery{
+		balanceService: balanceService,
+	}
+}
+
+// Execute executes the stock as-of query
+func (q *GetStockAsOfQuery) Execute(ctx context.Context, at time.Time) (*dto.StockAsOfResponse, error) {
+	balances, err := q.balanceService.BalancesAsOf(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	// Balances are ordered by product, so per-product totals can be accumulated in one pass
+	result := &dto.StockAsOfResponse{
+		Timestamp: at,
+		Products:  []*dto.ProductBalanceResponse{},
+		Locations: []*dto.LocationStockResponse{},
+	}
+
+	var current *dto.ProductBalanceResponse
+	for _, b := range balances {
+		result.Locations = append(result.Locations, &dto.LocationStockResponse{
+			LocationID: b.LocationID,
+			ProductID:  b.ProductID,
+			Quantity:   b.Quantity,
+		})
+
+		if current == nil || current.ProductID != b.ProductID {
+			current = &dto.ProductBalanceResponse{ProductID: b.ProductID}
+			result.Products = append(result.Products, current)
+		}
+		current.Quantity += b.Quantity
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+)
+
+// ListLocationsQuery handles location listing
+type ListLocationsQuery struct {
+	locationRepo location.Repository
+}
+
+// NewListLocationsQuery creates a new list locations query
+func NewListLocationsQuery(locationRepo location.Repository) *ListLocationsQuery {
+	return &ListLocationsQuery{
+		locationRepo: locationRepo,
+	}
+}
+
+// Execute executes the list locations query. Invalid sorts, ranges and cursors
+// are rejected with listing.ErrInvalidQuery.
+func (q *ListLocationsQuery) Execute(ctx context.Context, filter *dto.LocationFilter, page dto.PageRequest) (*dto.LocationListResponse, error) {
+	if filter == nil {
+		filter = &dto.LocationFilter{}
+	}
+	criteria := location.Filter{
+		CodePrefix:      filter.CodePrefix,
+		Warehouse:       filter.Warehouse,
+		TemperatureZone: location.TemperatureZone(filter.TemperatureZone),
+		Capacity:        listing.Int64Range{Min: filter.MinCapacity, Max: filter.MaxCapacity},
+	}
+	if err := criteria.Capacity.Validate("capacity"); err != nil {
+		return nil, err
+	}
+	sorts, err := listing.ParseSort(filter.Sort, location.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	order := listing.Order(sorts, location.DefaultOrder)
+	after, err := afterKeys(page, order)
+	if err != nil {
+		return nil, err
+	}
+	spec := location.ListSpec{Filter: criteria, Search: filter.Search, Sort: sorts, After: after, Limit: fetchLimit(page), Offset: page.Offset}
+
+	// Get locations
+	locations, err := q.locationRepo.List(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	locations, next := listing.NextKeys(locations, page.Limit, order, location.SortValue)
+
+	// Get total count
+	var total *int64
+	if page.IncludeTotal {
+		count, err := q.locationRepo.Count(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	// Convert to DTOs
+	var responses []*dto.LocationResponse
+	for _, l := range locations {
+		responses = append(responses, dto.NewLocationResponse(l))
+	}
+
+	return &dto.LocationListResponse{
+		Data:       responses,
+		Total:      total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: listing.EncodeCursor(order, next),
+	}, nil
+}
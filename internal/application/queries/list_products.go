@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
 )
 
@@ -19,34 +20,66 @@ func NewListProductsQuery(productRepo product.Repository) *ListProductsQuery {
 	}
 }
 
-// Execute executes the list products query
-func (q *ListProductsQuery) Execute(ctx context.Context, limit, offset int) (*dto.ProductListResponse, error) {
-	// Get products
-	products, err := q.productRepo.List(ctx, limit, offset)
+// Execute executes the list products query. When uom is set, each product's quantity is
+// also expressed in that pack level; products without that level configured are left as is.
+// Invalid sorts, ranges and cursors are rejected with listing.ErrInvalidQuery.
+func (q *ListProductsQuery) Execute(ctx context.Context, filter *dto.ProductFilter, page dto.PageRequest, uom product.PackLevel) (*dto.ProductListResponse, error) {
+	if filter == nil {
+		filter = &dto.ProductFilter{}
+	}
+	criteria := product.Filter{
+		SKUPrefix: filter.SKUPrefix,
+		Category:  filter.Category,
+		Brand:     filter.Brand,
+		Status:    product.Status(filter.Status),
+		Quantity:  listing.Int64Range{Min: filter.MinQuantity, Max: filter.MaxQuantity},
+	}
+	if err := criteria.Quantity.Validate("quantity"); err != nil {
+		return nil, err
+	}
+	sorts, err := listing.ParseSort(filter.Sort, product.SortFields)
 	if err != nil {
 		return nil, err
 	}
+	order := listing.Order(sorts, product.DefaultOrder)
+	after, err := afterKeys(page, order)
+	if err != nil {
+		return nil, err
+	}
+	spec := product.ListSpec{Filter: criteria, Search: filter.Search, Sort: sorts, After: after, Limit: fetchLimit(page), Offset: page.Offset}
 
-	// Get total count
-	total, err := q.productRepo.Count(ctx)
+	// Get products
+	products, err := q.productRepo.List(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
+	products, next := listing.NextKeys(products, page.Limit, order, product.SortValue)
+
+	// Get total count
+	var total *int64
+	if page.IncludeTotal {
+		count, err := q.productRepo.Count(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
 
 	// Convert to DTOs
 	var responses []*dto.ProductResponse
 	for _, p := range products {
-		responses = append(responses, &dto.ProductResponse{
-			ID:       p.ID,
-			SKUName:  p.SKUName,
-			Quantity: p.Quantity,
-		})
+		resp := dto.NewProductResponse(p)
+		if uom != "" {
+			_ = resp.SetQuantityIn(p, uom)
+		}
+		responses = append(responses, resp)
 	}
 
 	return &dto.ProductListResponse{
-		Data:   responses,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Data:       responses,
+		Total:      total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: listing.EncodeCursor(order, next),
 	}, nil
 }
@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// ListReplenishmentTasksQuery handles listing replenishment tasks
+type ListReplenishmentTasksQuery struct {
+	replenishmentRepo replenishment.Repository
+}
+
+// NewListReplenishmentTasksQuery creates a new list replenishment tasks query
+func NewListReplenishmentTasksQuery(replenishmentRepo replenishment.Repository) *ListReplenishmentTasksQuery {
+	return &ListReplenishmentTasksQuery{
+		replenishmentRepo: replenishmentRepo,
+	}
+}
+
+// Execute lists tasks, newest first, optionally by status
+func (q *ListReplenishmentTasksQuery) Execute(ctx context.Context, status string, limit, offset int) (*dto.ReplenishmentTaskListResponse, error) {
+	tasks, err := q.replenishmentRepo.ListTasks(ctx, replenishment.TaskStatus(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ReplenishmentTaskResponse, 0, len(tasks))
+	for _, t := range tasks {
+		responses = append(responses, dto.NewReplenishmentTaskResponse(t))
+	}
+
+	return &dto.ReplenishmentTaskListResponse{
+		Data:   responses,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// ListStockBalancesQuery reads the current balances from the ledger projections
+type ListStockBalancesQuery struct {
+	projectionRepo stock.ProjectionRepository
+}
+
+// NewListStockBalancesQuery creates a new list stock balances query
+func NewListStockBalancesQuery(projectionRepo stock.ProjectionRepository) *ListStockBalancesQuery {
+	return &ListStockBalancesQuery{
+		projectionRepo: projectionRepo,
+	}
+}
+
+// Locations returns the current quantity per product and location
+func (q *ListStockBalancesQuery) Locations(ctx context.Context, filter *dto.BalanceFilter) ([]*dto.LocationStockResponse, error) {
+	balances, err := q.projectionRepo.ListBalances(ctx, stock.BalanceFilter{
+		ProductID:  filter.ProductID,
+		LocationID: filter.LocationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.LocationStockResponse, 0, len(balances))
+	for _, b := range balances {
+		result = append(result, &dto.LocationStockResponse{
+			LocationID: b.LocationID,
+			ProductID:  b.ProductID,
+			Quantity:   b.Quantity,
+		})
+	}
+
+	return result, nil
+}
+
+// Lots returns the current quantity per product, location and lot
+func (q *ListStockBalancesQuery) Lots(ctx context.Context, filter *dto.BalanceFilter) ([]*dto.LotBalanceResponse, error) {
+	balances, err := q.projectionRepo.ListLotBalances(ctx, stock.BalanceFilter{
+		ProductID:  filter.ProductID,
+		LocationID: filter.LocationID,
+		Lot:        filter.Lot,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dto.LotBalanceResponse, 0, len(balances))
+	for _, b := range balances {
+		result = append(result, &dto.LotBalanceResponse{
+			ProductID:  b.ProductID,
+			LocationID: b.LocationID,
+			Lot:        b.Lot,
+			Quantity:   b.Quantity,
+		})
+	}
+
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 )
 
@@ -19,37 +20,82 @@ func NewListStockMovementsQuery(stockRepo stock.Repository) *ListStockMovementsQ
 	}
 }
 
-// Execute executes the list stock movements query
-func (q *ListStockMovementsQuery) Execute(ctx context.Context, limit, offset int) (*dto.StockMovementListResponse, error) {
-	// Get movements
-	movements, err := q.stockRepo.List(ctx, limit, offset)
+// Execute executes the list stock movements query. Invalid sorts, ranges and
+// cursors are rejected with listing.ErrInvalidQuery.
+func (q *ListStockMovementsQuery) Execute(ctx context.Context, filter *dto.StockMovementFilter, page dto.PageRequest) (*dto.StockMovementListResponse, error) {
+	if filter == nil {
+		filter = &dto.StockMovementFilter{}
+	}
+	criteria, err := movementCriteria(filter)
+	if err != nil {
+		return nil, err
+	}
+	sorts, err := listing.ParseSort(filter.Sort, stock.SortFields)
 	if err != nil {
 		return nil, err
 	}
+	order := listing.Order(sorts, stock.DefaultOrder)
+	after, err := afterKeys(page, order)
+	if err != nil {
+		return nil, err
+	}
+	spec := stock.ListSpec{Filter: criteria, Search: filter.Search, Sort: sorts, After: after, Limit: fetchLimit(page), Offset: page.Offset}
 
-	// Get total count
-	total, err := q.stockRepo.Count(ctx)
+	// Get movements
+	movements, err := q.stockRepo.List(ctx, spec)
 	if err != nil {
 		return nil, err
 	}
+	movements, next := listing.NextKeys(movements, page.Limit, order, stock.SortValue)
+
+	// Get total count
+	var total *int64
+	if page.IncludeTotal {
+		count, err := q.stockRepo.Count(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		total = &count
+	}
 
 	// Convert to DTOs
 	var responses []*dto.StockMovementResponse
 	for _, m := range movements {
-		responses = append(responses, &dto.StockMovementResponse{
-			ID:         m.ID,
-			ProductID:  m.ProductID,
-			LocationID: m.LocationID,
-			Type:       string(m.Type),
-			Quantity:   m.Quantity,
-			CreatedAt:  m.CreatedAt,
-		})
+		responses = append(responses, dto.NewStockMovementResponse(m))
 	}
 
 	return &dto.StockMovementListResponse{
-		Data:   responses,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Data:       responses,
+		Total:      total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: listing.EncodeCursor(order, next),
 	}, nil
 }
+
+// movementCriteria converts a listing filter into repository criteria. Ranges
+// that end before they start are rejected with listing.ErrInvalidQuery.
+func movementCriteria(filter *dto.StockMovementFilter) (stock.Filter, error) {
+	if filter == nil {
+		return stock.Filter{}, nil
+	}
+
+	criteria := stock.Filter{
+		ProductID:      filter.ProductID,
+		LocationID:     filter.LocationID,
+		Type:           stock.MovementType(filter.Type),
+		ReferenceType:  stock.ReferenceType(filter.ReferenceType),
+		ReferenceID:    filter.ReferenceID,
+		DocumentNumber: filter.DocumentNumber,
+		Quantity:       listing.Int64Range{Min: filter.MinQuantity, Max: filter.MaxQuantity},
+		CreatedAt:      listing.TimeRange{From: filter.From, To: filter.To},
+	}
+	if err := criteria.Quantity.Validate("quantity"); err != nil {
+		return stock.Filter{}, err
+	}
+	if err := criteria.CreatedAt.Validate(); err != nil {
+		return stock.Filter{}, err
+	}
+
+	return criteria, nil
+}
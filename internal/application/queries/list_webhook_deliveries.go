@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// ListWebhookDeliveriesQuery handles listing webhook deliveries
+type ListWebhookDeliveriesQuery struct {
+	webhookRepo webhook.Repository
+}
+
+// NewListWebhookDeliveriesQuery creates a new list webhook deliveries query
+func NewListWebhookDeliveriesQuery(webhookRepo webhook.Repository) *ListWebhookDeliveriesQuery {
+	return &ListWebhookDeliveriesQuery{
+		webhookRepo: webhookRepo,
+	}
+}
+
+// Execute lists deliveries, newest first, optionally by status
+func (q *ListWebhookDeliveriesQuery) Execute(ctx context.Context, status string, limit, offset int) (*dto.WebhookDeliveryListResponse, error) {
+	deliveries, err := q.webhookRepo.ListDeliveries(ctx, webhook.DeliveryStatus(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		responses = append(responses, dto.NewWebhookDeliveryResponse(d))
+	}
+
+	return &dto.WebhookDeliveryListResponse{
+		Data:   responses,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+)
+
+// afterKeys decodes the cursor a page continues from, if any. A cursor already
+// says where the page starts, so it cannot be combined with an offset.
+func afterKeys(page dto.PageRequest, order []listing.Sort) ([]string, error) {
+	if page.After == "" {
+		return nil, nil
+	}
+	if page.Offset > 0 {
+		return nil, fmt.Errorf("%w: after and offset cannot be combined", listing.ErrInvalidQuery)
+	}
+	return listing.DecodeCursor(page.After, order)
+}
+
+// fetchLimit asks for one row more than the page holds, which tells whether
+// there is a next page without counting
+func fetchLimit(page dto.PageRequest) int {
+	if page.Limit <= 0 {
+		return 0
+	}
+	return page.Limit + 1
+}
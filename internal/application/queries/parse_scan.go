@@ -0,0 +1,30 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
+)
+
+// ParseScanQuery turns raw scanner strings into structured fields and the
+// product, lot and container they refer to
+type ParseScanQuery struct {
+	resolver *scanning.Resolver
+}
+
+// NewParseScanQuery creates a new parse scan query
+func NewParseScanQuery(resolver *scanning.Resolver) *ParseScanQuery {
+	return &ParseScanQuery{
+		resolver: resolver,
+	}
+}
+
+// Execute parses and resolves one scan
+func (q *ParseScanQuery) Execute(ctx context.Context, req *dto.ParseScanRequest) (*dto.ParseScanResponse, error) {
+	res, err := q.resolver.Resolve(ctx, req.Scan)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewParseScanResponse(res), nil
+}
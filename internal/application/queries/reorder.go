@@ -0,0 +1,90 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// GetReorderSuggestionsQuery handles the reorder report for buyers
+type GetReorderSuggestionsQuery struct {
+	monitor     *replenishment.Monitor
+	productRepo product.Repository
+}
+
+// NewGetReorderSuggestionsQuery creates a new get reorder suggestions query
+func NewGetReorderSuggestionsQuery(monitor *replenishment.Monitor, productRepo product.Repository) *GetReorderSuggestionsQuery {
+	return &GetReorderSuggestionsQuery{
+		monitor:     monitor,
+		productRepo: productRepo,
+	}
+}
+
+// Execute lists every SKU below its reorder point with the quantity to order, most urgent first
+func (q *GetReorderSuggestionsQuery) Execute(ctx context.Context) ([]*dto.ReorderSuggestionResponse, error) {
+	suggestions, err := q.monitor.Suggestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skuNames := make(map[int64]string)
+	responses := make([]*dto.ReorderSuggestionResponse, 0, len(suggestions))
+	for _, s := range suggestions {
+		name, ok := skuNames[s.Policy.ProductID]
+		if !ok {
+			prod, err := q.productRepo.GetByID(ctx, s.Policy.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			name = prod.SKUName
+			skuNames[s.Policy.ProductID] = name
+		}
+
+		responses = append(responses, &dto.ReorderSuggestionResponse{
+			ProductID:    s.Policy.ProductID,
+			SKUName:      name,
+			Warehouse:    s.Policy.Warehouse,
+			Available:    s.Available,
+			ReorderPoint: s.Policy.ReorderPoint,
+			SafetyStock:  s.Policy.SafetyStock,
+			ReorderQty:   s.Policy.ReorderQty,
+			Severity:     string(s.Severity),
+			SuggestedQty: s.SuggestedQty,
+		})
+	}
+
+	return responses, nil
+}
+
+// ListStockAlertsQuery handles listing low-stock alerts
+type ListStockAlertsQuery struct {
+	reorderRepo replenishment.ReorderRepository
+}
+
+// NewListStockAlertsQuery creates a new list stock alerts query
+func NewListStockAlertsQuery(reorderRepo replenishment.ReorderRepository) *ListStockAlertsQuery {
+	return &ListStockAlertsQuery{
+		reorderRepo: reorderRepo,
+	}
+}
+
+// Execute lists alerts, newest first, optionally only open ones
+func (q *ListStockAlertsQuery) Execute(ctx context.Context, openOnly bool, limit, offset int) (*dto.StockAlertListResponse, error) {
+	alerts, err := q.reorderRepo.ListAlerts(ctx, openOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.StockAlertResponse, 0, len(alerts))
+	for _, a := range alerts {
+		responses = append(responses, dto.NewStockAlertResponse(a))
+	}
+
+	return &dto.StockAlertListResponse{
+		Data:   responses,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+const (
+	matchBarcode = "barcode"
+	matchText    = "text"
+)
+
+// SearchProductsQuery finds products by partial SKU, free text or a scanned code
+type SearchProductsQuery struct {
+	productRepo product.Repository
+}
+
+// NewSearchProductsQuery creates a new search products query
+func NewSearchProductsQuery(productRepo product.Repository) *SearchProductsQuery {
+	return &SearchProductsQuery{
+		productRepo: productRepo,
+	}
+}
+
+// Execute searches for text. A GS1 scan resolves straight to the product of
+// its GTIN, or of the GTIN it contains for a logistic unit label, and a bare GTIN to the product carrying it in any of its lengths;
+// everything else, including GTINs no product carries, is ranked by the
+// repository's search.
+func (q *SearchProductsQuery) Execute(ctx context.Context, text string, limit int) (*dto.ProductSearchResponse, error) {
+	text = strings.TrimSpace(text)
+	result := &dto.ProductSearchResponse{Query: text, Data: []*dto.ProductSearchHit{}}
+
+	if gs1.LooksLikeElementString(text) {
+		if scan, err := gs1.Parse(text); err == nil {
+			result.Scan = dto.NewScanResponse(scan)
+			if scan.ItemGTIN() == "" {
+				return result, nil
+			}
+			prod, err := q.byGTIN(ctx, scan.ItemGTIN())
+			if err != nil || prod == nil {
+				return result, err
+			}
+			result.Data = append(result.Data, newSearchHit(prod, 1, matchBarcode))
+			return result, nil
+		}
+	}
+
+	if isGTINLength(len(text)) {
+		prod, err := q.byGTIN(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		if prod != nil {
+			result.Data = append(result.Data, newSearchHit(prod, 1, matchBarcode))
+			return result, nil
+		}
+	}
+
+	hits, err := q.productRepo.Search(ctx, text, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		result.Data = append(result.Data, newSearchHit(hit.Product, hit.Score, matchText))
+	}
+	return result, nil
+}
+
+// byGTIN looks a GTIN up under each length it may be registered with; nil
+// means no product carries it
+func (q *SearchProductsQuery) byGTIN(ctx context.Context, gtin string) (*product.Product, error) {
+	prod, err := product.FindByGTIN(ctx, q.productRepo, gtin)
+	if errors.Is(err, product.ErrProductNotFound) {
+		return nil, nil
+	}
+	return prod, err
+}
+
+func isGTINLength(n int) bool {
+	return n == 8 || n == 12 || n == 13 || n == 14
+}
+
+func newSearchHit(p *product.Product, score float64, match string) *dto.ProductSearchHit {
+	return &dto.ProductSearchHit{ProductResponse: dto.NewProductResponse(p), Score: score, Match: match}
+}
@@ -0,0 +1,78 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
+)
+
+// ListSerialsQuery handles listing a product's serials
+type ListSerialsQuery struct {
+	serialRepo  serial.Repository
+	productRepo product.Repository
+}
+
+// NewListSerialsQuery creates a new list serials query
+func NewListSerialsQuery(serialRepo serial.Repository, productRepo product.Repository) *ListSerialsQuery {
+	return &ListSerialsQuery{
+		serialRepo:  serialRepo,
+		productRepo: productRepo,
+	}
+}
+
+// Execute lists the product's serials, optionally by location or status
+func (q *ListSerialsQuery) Execute(ctx context.Context, productID int64, filter *dto.SerialFilter, limit, offset int) (*dto.SerialListResponse, error) {
+	if _, err := q.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	serials, err := q.serialRepo.List(ctx, productID, serial.Filter{
+		LocationID: filter.LocationID,
+		Status:     serial.Status(filter.Status),
+	}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.SerialResponse, 0, len(serials))
+	for _, s := range serials {
+		responses = append(responses, dto.NewSerialResponse(s))
+	}
+
+	return &dto.SerialListResponse{
+		Data:   responses,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// GetSerialQuery handles looking up one serial with its history
+type GetSerialQuery struct {
+	serialRepo serial.Repository
+}
+
+// NewGetSerialQuery creates a new get serial query
+func NewGetSerialQuery(serialRepo serial.Repository) *GetSerialQuery {
+	return &GetSerialQuery{
+		serialRepo: serialRepo,
+	}
+}
+
+// Execute returns the serial's current state and every movement it took part in
+func (q *GetSerialQuery) Execute(ctx context.Context, productID int64, number string) (*dto.SerialResponse, error) {
+	s, err := q.serialRepo.GetByNumber(ctx, productID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := q.serialRepo.History(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dto.NewSerialResponse(s)
+	result.SetHistory(events)
+	return result, nil
+}
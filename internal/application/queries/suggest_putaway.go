@@ -0,0 +1,41 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+)
+
+// SuggestPutawayQuery handles putaway location suggestions
+type SuggestPutawayQuery struct {
+	putawayService *putaway.Service
+}
+
+// NewSuggestPutawayQuery creates a new suggest putaway query
+func NewSuggestPutawayQuery(putawayService *putaway.Service) *SuggestPutawayQuery {
+	return &SuggestPutawayQuery{
+		putawayService: putawayService,
+	}
+}
+
+// Execute returns the best locations for the goods, best first
+func (q *SuggestPutawayQuery) Execute(ctx context.Context, req *dto.PutawaySuggestRequest) ([]*dto.PutawaySuggestionResponse, error) {
+	suggestions, err := q.putawayService.Suggest(ctx, putaway.Request{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UOM:       product.PackLevel(req.UOM),
+		Lot:       req.Lot,
+		Limit:     req.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.PutawaySuggestionResponse, 0, len(suggestions))
+	for _, s := range suggestions {
+		responses = append(responses, dto.NewPutawaySuggestionResponse(s))
+	}
+	return responses, nil
+}
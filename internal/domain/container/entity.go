@@ -0,0 +1,190 @@
+package container
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// Type is the physical kind of container
+type Type string
+
+const (
+	TypePallet Type = "PALLET"
+	TypeCarton Type = "CARTON"
+	TypeTote   Type = "TOTE"
+)
+
+// Status tells whether a container still exists on the floor
+type Status string
+
+const (
+	StatusActive Status = "ACTIVE"
+	StatusBroken Status = "BROKEN"
+)
+
+// Field limits for container identifiers
+const (
+	maxLPNLength = 50
+	maxLotLength = 50
+	ssccLength   = 18
+)
+
+// Content is a quantity of one product (and optionally lot) held directly in a container
+type Content struct {
+	ProductID int64
+	Lot       string
+	Quantity  int64
+}
+
+// Container is a license-plated unit (pallet, carton, tote) that holds stock and
+// may itself sit inside another container
+type Container struct {
+	ID         int64
+	LPN        string
+	SSCC       string
+	Type       Type
+	LocationID int64
+	ParentID   *int64
+	Status     Status
+	Contents   []Content
+	CreatedAt  time.Time
+}
+
+// NewContainer creates a new, empty container at a location
+func NewContainer(lpn, sscc string, containerType Type, locationID int64) (*Container, error) {
+	lpn = strings.TrimSpace(lpn)
+	if lpn == "" || len(lpn) > maxLPNLength {
+		return nil, ErrInvalidLPN
+	}
+	if sscc != "" && (len(sscc) != ssccLength || !product.ValidGTIN(sscc)) {
+		return nil, ErrInvalidSSCC
+	}
+	if containerType != TypePallet && containerType != TypeCarton && containerType != TypeTote {
+		return nil, ErrInvalidType
+	}
+	if locationID <= 0 {
+		return nil, ErrInvalidLocation
+	}
+
+	return &Container{
+		LPN:        lpn,
+		SSCC:       sscc,
+		Type:       containerType,
+		LocationID: locationID,
+		Status:     StatusActive,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// IsActive reports whether the container has not been broken down
+func (c *Container) IsActive() bool {
+	return c.Status == StatusActive
+}
+
+// AddContent puts a product quantity into the container, merging with an existing line
+// for the same product and lot
+func (c *Container) AddContent(productID int64, lot string, quantity int64) error {
+	if !c.IsActive() {
+		return ErrContainerBroken
+	}
+	if productID <= 0 || quantity <= 0 {
+		return ErrInvalidContent
+	}
+	if len(lot) > maxLotLength {
+		return ErrInvalidContent
+	}
+
+	for i := range c.Contents {
+		if c.Contents[i].ProductID == productID && c.Contents[i].Lot == lot {
+			c.Contents[i].Quantity += quantity
+			return nil
+		}
+	}
+	c.Contents = append(c.Contents, Content{ProductID: productID, Lot: lot, Quantity: quantity})
+	return nil
+}
+
+// Nest places child inside the container. Both must be active, at the same
+// location, and the child must not already sit in another container.
+func (c *Container) Nest(child *Container) error {
+	if !c.IsActive() || !child.IsActive() {
+		return ErrContainerBroken
+	}
+	if child.ID == c.ID || child.ParentID != nil {
+		return ErrAlreadyNested
+	}
+	if child.LocationID != c.LocationID {
+		return ErrLocationMismatch
+	}
+
+	parentID := c.ID
+	child.ParentID = &parentID
+	return nil
+}
+
+// Break marks the container as broken down. Its contents become loose stock at
+// its location; children are released by the caller.
+func (c *Container) Break() error {
+	if !c.IsActive() {
+		return ErrContainerBroken
+	}
+	c.Status = StatusBroken
+	c.ParentID = nil
+	return nil
+}
+
+// Tree is a container together with every container nested inside it
+type Tree struct {
+	*Container
+	Children []*Tree
+}
+
+// Totals sums the contents of the whole tree per product
+func (t *Tree) Totals() map[int64]int64 {
+	totals := make(map[int64]int64)
+	t.Walk(func(c *Container) {
+		for _, content := range c.Contents {
+			totals[content.ProductID] += content.Quantity
+		}
+	})
+	return totals
+}
+
+// LotTotals sums the contents of the whole tree per product and lot, ordered by
+// product and then lot
+func (t *Tree) LotTotals() []Content {
+	type key struct {
+		productID int64
+		lot       string
+	}
+
+	totals := make(map[key]int64)
+	t.Walk(func(c *Container) {
+		for _, content := range c.Contents {
+			totals[key{content.ProductID, content.Lot}] += content.Quantity
+		}
+	})
+
+	result := make([]Content, 0, len(totals))
+	for k, qty := range totals {
+		result = append(result, Content{ProductID: k.productID, Lot: k.lot, Quantity: qty})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ProductID != result[j].ProductID {
+			return result[i].ProductID < result[j].ProductID
+		}
+		return result[i].Lot < result[j].Lot
+	})
+	return result
+}
+
+// Walk calls fn for the container and every container nested inside it
+func (t *Tree) Walk(fn func(c *Container)) {
+	fn(t.Container)
+	for _, child := range t.Children {
+		child.Walk(fn)
+	}
+}
@@ -0,0 +1,19 @@
+package container
+
+import "errors"
+
+var (
+	ErrContainerNotFound = errors.New("container not found")
+	ErrInvalidLPN        = errors.New("invalid license plate number")
+	ErrInvalidSSCC       = errors.New("invalid SSCC")
+	ErrInvalidType       = errors.New("invalid container type")
+	ErrInvalidLocation   = errors.New("invalid location ID")
+	ErrInvalidContent    = errors.New("invalid container content")
+	ErrDuplicateLPN      = errors.New("license plate number already exists")
+	ErrContainerBroken   = errors.New("container has been broken down")
+	ErrAlreadyNested     = errors.New("container is already nested in another container")
+	ErrLocationMismatch  = errors.New("containers must be at the same location to be nested")
+	ErrInsufficientLoose = errors.New("not enough loose stock at the location to fill the container")
+	ErrSameLocation      = errors.New("container is already at that location")
+	ErrSerializedContent = errors.New("serialized products cannot be packed into containers")
+)
@@ -0,0 +1,30 @@
+package container
+
+import "context"
+
+// Repository defines the contract for container persistence
+type Repository interface {
+	// Create saves a new container with its contents
+	Create(ctx context.Context, c *Container) error
+
+	// GetByID retrieves a container by ID
+	GetByID(ctx context.Context, id int64) (*Container, error)
+
+	// GetByLPN retrieves a container by license plate number
+	GetByLPN(ctx context.Context, lpn string) (*Container, error)
+
+	// GetBySSCC retrieves a container by serial shipping container code
+	GetBySSCC(ctx context.Context, sscc string) (*Container, error)
+
+	// GetChildren retrieves the active containers nested directly inside a container
+	GetChildren(ctx context.Context, parentID int64) ([]*Container, error)
+
+	// Update saves the container's location, parent, status and contents
+	Update(ctx context.Context, c *Container) error
+
+	// QuantityAt returns how much of a product is held in active containers at a location
+	QuantityAt(ctx context.Context, locationID, productID int64) (int64, error)
+
+	// LocationsWithLot returns the locations where active containers hold a product's lot
+	LocationsWithLot(ctx context.Context, productID int64, lot string) ([]int64, error)
+}
@@ -0,0 +1,44 @@
+package container
+
+import "context"
+
+// Service contains lookups shared by container use cases
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new container service
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Find looks a container up by LPN, falling back to SSCC
+func (s *Service) Find(ctx context.Context, code string) (*Container, error) {
+	c, err := s.repo.GetByLPN(ctx, code)
+	if err != ErrContainerNotFound {
+		return c, err
+	}
+	if len(code) != ssccLength {
+		return nil, ErrContainerNotFound
+	}
+	return s.repo.GetBySSCC(ctx, code)
+}
+
+// LoadTree loads the container and everything nested inside it
+func (s *Service) LoadTree(ctx context.Context, c *Container) (*Tree, error) {
+	tree := &Tree{Container: c}
+
+	children, err := s.repo.GetChildren(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		subtree, err := s.LoadTree(ctx, child)
+		if err != nil {
+			return nil, err
+		}
+		tree.Children = append(tree.Children, subtree)
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,316 @@
+// Package gs1 parses GS1 element strings, the data carried by GS1-128 and
+// GS1 DataMatrix labels
+package gs1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidScan = errors.New("invalid GS1 scan")
+	ErrUnknownAI   = errors.New("unsupported GS1 application identifier")
+)
+
+// GroupSeparator is the FNC1 character scanners send to end a variable-length field
+const GroupSeparator = '\x1d'
+
+// symbologyIdentifiers are the prefixes scanners put in front of GS1 data:
+// GS1-128, GS1 DataMatrix, GS1 QR code, GS1 DataBar and DataMatrix with FNC1
+var symbologyIdentifiers = []string{"]C1", "]d2", "]Q3", "]e0", "]J1"}
+
+// definition describes the data of one application identifier
+type definition struct {
+	length  int  // exact length when fixed, maximum length otherwise
+	fixed   bool // fixed-length fields need no separator
+	numeric bool
+}
+
+// definitions lists the application identifiers this package understands
+var definitions = map[string]definition{
+	"00": {length: 18, fixed: true, numeric: true}, // SSCC
+	"01": {length: 14, fixed: true, numeric: true}, // GTIN
+	"02": {length: 14, fixed: true, numeric: true}, // GTIN of the contained trade items
+	"10": {length: 20},                             // batch or lot
+	"17": {length: 6, fixed: true, numeric: true},  // expiry date, YYMMDD
+	"21": {length: 20},                             // serial number
+	"37": {length: 8, numeric: true},               // count of contained trade items
+}
+
+// checkDigitAIs are the identifiers whose data ends with a GS1 check digit
+var checkDigitAIs = map[string]bool{"00": true, "01": true, "02": true}
+
+// Element is one application identifier and its data
+type Element struct {
+	AI    string
+	Value string
+}
+
+// Scan is a parsed element string
+type Scan struct {
+	Elements []Element
+
+	// SSCC identifies the logistic unit, such as a pallet, from AI (00)
+	SSCC string
+
+	// GTIN identifies the trade item, from AI (01)
+	GTIN string
+
+	// ContentGTIN identifies the trade items inside a logistic unit, from AI (02)
+	ContentGTIN string
+
+	// Lot is the batch or lot number, from AI (10)
+	Lot string
+
+	// Expiry is the last day the item may be used, from AI (17); zero if absent
+	Expiry time.Time
+
+	// Serial is the serial number of the item, from AI (21)
+	Serial string
+
+	// Count is the number of trade items in a logistic unit, from AI (37); zero if absent
+	Count int64
+}
+
+// ItemGTIN returns the GTIN of the trade item the scan is about: its own GTIN,
+// or for a logistic unit label the GTIN of what it contains
+func (s *Scan) ItemGTIN() string {
+	if s.GTIN != "" {
+		return s.GTIN
+	}
+	return s.ContentGTIN
+}
+
+// LooksLikeElementString reports whether raw is worth parsing as GS1 data
+// rather than as text: it carries a symbology identifier, parenthesised AIs
+// or group separators, or starts with AI (00), (01) or (02) and valid data
+func LooksLikeElementString(raw string) bool {
+	if hasSymbologyIdentifier(raw) || strings.HasPrefix(raw, "(") || strings.ContainsRune(raw, GroupSeparator) {
+		return true
+	}
+	switch {
+	case strings.HasPrefix(raw, "00"):
+		return len(raw) >= 20 && ValidCheckDigit(raw[2:20])
+	case strings.HasPrefix(raw, "01"), strings.HasPrefix(raw, "02"):
+		return len(raw) >= 16 && ValidCheckDigit(raw[2:16])
+	}
+	return false
+}
+
+// Parse reads a scanner string in either the raw form, e.g.
+// "]C101095060001343521725123110LOT42", or the human-readable form, e.g.
+// "(01)09506000134352(17)251231(10)LOT42"
+func Parse(raw string) (*Scan, error) {
+	return parseAt(raw, time.Now())
+}
+
+// parseAt parses raw, placing two-digit years in the century closest to now
+func parseAt(raw string, now time.Time) (*Scan, error) {
+	data := strings.TrimSpace(raw)
+	for _, id := range symbologyIdentifiers {
+		data = strings.TrimPrefix(data, id)
+	}
+	if data == "" {
+		return nil, fmt.Errorf("%w: no data", ErrInvalidScan)
+	}
+
+	var elements []Element
+	var err error
+	if strings.HasPrefix(data, "(") {
+		elements, err = splitBracketed(data)
+	} else {
+		elements, err = splitRaw(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &Scan{Elements: elements}
+	seen := make(map[string]string)
+	for _, e := range elements {
+		if previous, ok := seen[e.AI]; ok && previous != e.Value {
+			return nil, fmt.Errorf("%w: AI (%s) appears twice with different data", ErrInvalidScan, e.AI)
+		}
+		seen[e.AI] = e.Value
+
+		if err := validate(e); err != nil {
+			return nil, err
+		}
+		switch e.AI {
+		case "00":
+			scan.SSCC = e.Value
+		case "01":
+			scan.GTIN = e.Value
+		case "02":
+			scan.ContentGTIN = e.Value
+		case "10":
+			scan.Lot = e.Value
+		case "17":
+			scan.Expiry, err = parseDate(e.Value, now)
+			if err != nil {
+				return nil, err
+			}
+		case "21":
+			scan.Serial = e.Value
+		case "37":
+			scan.Count, _ = strconv.ParseInt(e.Value, 10, 64)
+		}
+	}
+	return scan, nil
+}
+
+// splitBracketed splits the human-readable form, where every AI is in parentheses
+func splitBracketed(data string) ([]Element, error) {
+	var elements []Element
+	for data != "" {
+		end := strings.IndexByte(data, ')')
+		if !strings.HasPrefix(data, "(") || end < 0 {
+			return nil, fmt.Errorf("%w: unbalanced parentheses", ErrInvalidScan)
+		}
+		ai := data[1:end]
+		data = data[end+1:]
+
+		next := strings.IndexByte(data, '(')
+		if next < 0 {
+			next = len(data)
+		}
+		elements = append(elements, Element{AI: ai, Value: data[:next]})
+		data = data[next:]
+	}
+	return elements, nil
+}
+
+// splitRaw splits the raw form, where fixed-length fields run into the next AI
+// and variable-length ones end at a group separator or the end of the data
+func splitRaw(data string) ([]Element, error) {
+	var elements []Element
+	for data != "" {
+		ai, def, err := lookupPrefix(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[len(ai):]
+
+		var value string
+		if def.fixed {
+			if len(data) < def.length {
+				return nil, fmt.Errorf("%w: AI (%s) needs %d characters", ErrInvalidScan, ai, def.length)
+			}
+			value, data = data[:def.length], data[def.length:]
+		} else {
+			end := strings.IndexRune(data, GroupSeparator)
+			if end < 0 {
+				end = len(data)
+			}
+			value, data = data[:end], data[end:]
+		}
+		data = strings.TrimPrefix(data, string(GroupSeparator))
+		elements = append(elements, Element{AI: ai, Value: value})
+	}
+	return elements, nil
+}
+
+// lookupPrefix finds the application identifier data starts with
+func lookupPrefix(data string) (string, definition, error) {
+	for length := 2; length <= 4 && length <= len(data); length++ {
+		if def, ok := definitions[data[:length]]; ok {
+			return data[:length], def, nil
+		}
+	}
+	return "", definition{}, fmt.Errorf("%w at %q", ErrUnknownAI, truncate(data, 4))
+}
+
+// validate checks the data of one element against its definition
+func validate(e Element) error {
+	def, ok := definitions[e.AI]
+	if !ok {
+		return fmt.Errorf("%w (%s)", ErrUnknownAI, e.AI)
+	}
+	if e.Value == "" || len(e.Value) > def.length || (def.fixed && len(e.Value) != def.length) {
+		return fmt.Errorf("%w: AI (%s) has the wrong length", ErrInvalidScan, e.AI)
+	}
+	if def.numeric && !isDigits(e.Value) {
+		return fmt.Errorf("%w: AI (%s) must be numeric", ErrInvalidScan, e.AI)
+	}
+	if checkDigitAIs[e.AI] && !ValidCheckDigit(e.Value) {
+		return fmt.Errorf("%w: AI (%s) %s has a wrong check digit", ErrInvalidScan, e.AI, e.Value)
+	}
+	return nil
+}
+
+// parseDate reads a YYMMDD date. Day 00 stands for the last day of the month,
+// and the year is placed no more than 50 years ahead of or 49 behind now.
+func parseDate(value string, now time.Time) (time.Time, error) {
+	yy := int(value[0]-'0')*10 + int(value[1]-'0')
+	month := time.Month(int(value[2]-'0')*10 + int(value[3]-'0'))
+	day := int(value[4]-'0')*10 + int(value[5]-'0')
+	if month < 1 || month > 12 || day > 31 {
+		return time.Time{}, fmt.Errorf("%w: %s is not a date", ErrInvalidScan, value)
+	}
+
+	century := now.Year() / 100 * 100
+	switch diff := yy - now.Year()%100; {
+	case diff >= 51:
+		century -= 100
+	case diff <= -50:
+		century += 100
+	}
+
+	if day == 0 {
+		return time.Date(century+yy, month+1, 0, 0, 0, 0, 0, time.UTC), nil
+	}
+	date := time.Date(century+yy, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("%w: %s is not a date", ErrInvalidScan, value)
+	}
+	return date, nil
+}
+
+// ValidCheckDigit reports whether s is all digits and ends with a correct GS1
+// mod-10 check digit. It works for every GTIN length (8, 12, 13 and 14) as well
+// as 18-digit SSCCs.
+func ValidCheckDigit(s string) bool {
+	if len(s) < 2 || !isDigits(s) {
+		return false
+	}
+
+	sum := 0
+	// Weights alternate 3,1,3,... starting from the digit left of the check digit
+	for i := len(s) - 2; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if (len(s)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return (10-sum%10)%10 == int(s[len(s)-1]-'0')
+}
+
+func hasSymbologyIdentifier(raw string) bool {
+	for _, id := range symbologyIdentifiers {
+		if strings.HasPrefix(raw, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,9 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrInvalidKey        = errors.New("idempotency key must be between 1 and 255 characters")
+	ErrKeyReused         = errors.New("idempotency key was already used for a different request")
+	ErrRequestInProgress = errors.New("a request with this idempotency key is still being processed")
+)
@@ -0,0 +1,42 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// maxKeyLength is the longest idempotency key accepted
+const maxKeyLength = 255
+
+// Record is a request made under an idempotency key and, once it finished, its response
+type Record struct {
+	// Scope separates keys of different clients, e.g. the authenticated user
+	Scope       string
+	Key         string
+	Fingerprint string
+	CreatedAt   time.Time
+
+	// Set when the request completed
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CompletedAt *time.Time
+}
+
+// IsCompleted reports whether the response of the request has been stored
+func (r *Record) IsCompleted() bool {
+	return r.CompletedAt != nil
+}
+
+// Fingerprint identifies a request by method, path and body, so a key reused
+// for a different request can be told apart from a retry
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
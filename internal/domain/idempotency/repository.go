@@ -0,0 +1,23 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the contract for idempotency record persistence
+type Repository interface {
+	// Claim stores the record unless its scope and key are taken. An unfinished
+	// record with the same fingerprint created before staleBefore is taken over.
+	// It returns nil when the claim succeeded and the record holding the key otherwise.
+	Claim(ctx context.Context, record *Record, staleBefore time.Time) (*Record, error)
+
+	// Complete stores the response of a claimed record
+	Complete(ctx context.Context, record *Record) error
+
+	// Release removes the record so the key can be used again
+	Release(ctx context.Context, scope, key string) error
+
+	// DeleteBefore removes records created before the given time and returns how many
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long keys and their responses are kept
+	DefaultTTL = 24 * time.Hour
+
+	// lockTimeout is how long an unfinished request holds its key, e.g. after a crash
+	lockTimeout = time.Minute
+)
+
+// Service makes retried requests return the response of the first attempt
+type Service struct {
+	repo Repository
+	ttl  time.Duration
+}
+
+// NewService creates a new idempotency service keeping keys for DefaultTTL
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+		ttl:  DefaultTTL,
+	}
+}
+
+// WithTTL changes how long keys and their responses are kept
+func (s *Service) WithTTL(ttl time.Duration) *Service {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+	return s
+}
+
+// Begin claims the key for a request. When the same request already completed
+// under the key, its record is returned for replay and the request must not run
+// again. Otherwise the caller runs the request and then calls Finish or Abandon.
+func (s *Service) Begin(ctx context.Context, scope, key, fingerprint string) (*Record, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	record := &Record{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+	}
+
+	existing, err := s.repo.Claim(ctx, record, now.Add(-lockTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	// An expired key that has not been purged yet is free again
+	if existing.CreatedAt.Before(now.Add(-s.ttl)) {
+		if err := s.repo.Release(ctx, scope, key); err != nil {
+			return nil, err
+		}
+		return s.Begin(ctx, scope, key, fingerprint)
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !existing.IsCompleted() {
+		return nil, ErrRequestInProgress
+	}
+
+	return existing, nil
+}
+
+// Finish stores the response of a request started with Begin
+func (s *Service) Finish(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	now := time.Now()
+	return s.repo.Complete(ctx, &Record{
+		Scope:       scope,
+		Key:         key,
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        body,
+		CompletedAt: &now,
+	})
+}
+
+// Abandon frees the key of a request that failed without a result worth
+// replaying, so the client can retry it
+func (s *Service) Abandon(ctx context.Context, scope, key string) error {
+	return s.repo.Release(ctx, scope, key)
+}
+
+// Purge removes keys older than the TTL and returns how many were removed
+func (s *Service) Purge(ctx context.Context) (int64, error) {
+	return s.repo.DeleteBefore(ctx, time.Now().Add(-s.ttl))
+}
@@ -0,0 +1,96 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order returns the order a listing is returned in: its sort, or fallback when
+// it has none, ending with id so that no two rows tie
+func Order(sorts, fallback []Sort) []Sort {
+	if len(sorts) == 0 {
+		sorts = fallback
+	}
+	for _, s := range sorts {
+		if s.Field == "id" {
+			return sorts
+		}
+	}
+	return append(append([]Sort(nil), sorts...), Sort{Field: "id", Desc: true})
+}
+
+// FormatSort is the inverse of ParseSort
+func FormatSort(sorts []Sort) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		parts[i] = s.Field
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Keys returns the values of the order fields of one row, as a Spec's After
+// expects them. value reads a field of the row.
+func Keys[T any](row T, order []Sort, value func(T, string) interface{}) []string {
+	keys := make([]string, len(order))
+	for i, s := range order {
+		switch v := value(row, s.Field).(type) {
+		case time.Time:
+			keys[i] = v.UTC().Format(time.RFC3339Nano)
+		case int64:
+			keys[i] = strconv.FormatInt(v, 10)
+		default:
+			keys[i] = fmt.Sprint(v)
+		}
+	}
+	return keys
+}
+
+// NextKeys trims rows, fetched with a limit of one more than limit, back to
+// limit. If the extra row was there, it returns the keys to list the next page
+// after the last row kept; otherwise the listing ends here and keys are nil.
+func NextKeys[T any](rows []T, limit int, order []Sort, value func(T, string) interface{}) ([]T, []string) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, nil
+	}
+	rows = rows[:limit]
+	return rows, Keys(rows[limit-1], order, value)
+}
+
+// cursor is the payload of a cursor token
+type cursor struct {
+	Order string   `json:"o"`
+	Keys  []string `json:"k"`
+}
+
+// EncodeCursor turns the keys a page ends on into an opaque token for clients
+func EncodeCursor(order []Sort, keys []string) string {
+	if keys == nil {
+		return ""
+	}
+	payload, _ := json.Marshal(cursor{Order: FormatSort(order), Keys: keys})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor reads a token made by EncodeCursor. It fails with
+// ErrInvalidQuery if the token is damaged or was made for another order.
+func DecodeCursor(token string, order []Sort) ([]string, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	var c cursor
+	if err := json.Unmarshal(payload, &c); err != nil || len(c.Keys) != len(order) {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Order != FormatSort(order) {
+		return nil, fmt.Errorf("%w: cursor was made for sort %q", ErrInvalidQuery, c.Order)
+	}
+	return c.Keys, nil
+}
@@ -0,0 +1,112 @@
+// Package listing holds the query spec shared by the list methods of repositories
+package listing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is wrapped by errors in a sort or filter given by a client
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Spec selects a page of a listing. F holds the filters of one aggregate; search,
+// sorting and paging work the same way for all of them.
+type Spec[F any] struct {
+	Filter F
+
+	// Search matches the aggregate's text fields case-insensitively; empty
+	// matches everything
+	Search string
+
+	// Sort orders the results field by field. Repositories fall back to their
+	// default order and always break ties by ID; see Order.
+	Sort []Sort
+
+	// After holds the keys of the row the page starts after, one per field of
+	// the listing's Order; nil starts at the top. Unlike Offset it stays put
+	// when rows are added or removed in front of the page.
+	After []string
+
+	Limit  int
+	Offset int
+}
+
+// Sort orders a listing by one field
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort reads a comma-separated list of fields, each optionally prefixed
+// with - for descending order, e.g. "-quantity,sku_name". Only the allowed
+// fields are accepted.
+func ParseSort(value string, allowed []string) ([]Sort, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var sorts []Sort
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		s := Sort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !contains(allowed, s.Field) {
+			return nil, fmt.Errorf("%w: cannot sort by %q, expected some of %s", ErrInvalidQuery, s.Field, strings.Join(allowed, ", "))
+		}
+		if seen[s.Field] {
+			return nil, fmt.Errorf("%w: %q is sorted by twice", ErrInvalidQuery, s.Field)
+		}
+		seen[s.Field] = true
+		sorts = append(sorts, s)
+	}
+	return sorts, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Int64Range bounds a number, both ends inclusive. A nil end is open.
+type Int64Range struct {
+	Min *int64
+	Max *int64
+}
+
+// Contains reports whether v lies inside the range
+func (r Int64Range) Contains(v int64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+// Validate rejects a range whose minimum is above its maximum
+func (r Int64Range) Validate(name string) error {
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("%w: min_%s is above max_%s", ErrInvalidQuery, name, name)
+	}
+	return nil
+}
+
+// TimeRange bounds a moment, both ends inclusive. A zero end is open.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Contains reports whether t lies inside the range
+func (r TimeRange) Contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || !t.After(r.To))
+}
+
+// Validate rejects a range that ends before it starts
+func (r TimeRange) Validate() error {
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		return fmt.Errorf("%w: to is before from", ErrInvalidQuery)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package location
+
+import "fmt"
+
+// Capacity limit names reported in CapacityExceededError
+const (
+	LimitUnits           = "units"
+	LimitVolume          = "volume"
+	LimitWeight          = "weight"
+	LimitPalletPositions = "pallet positions"
+)
+
+// Limits are the optional physical limits of a location. Zero means not configured.
+type Limits struct {
+	MaxVolumeCM3    int64
+	MaxWeightGrams  int64
+	PalletPositions int64
+}
+
+// Load is what a location holds, in every dimension capacity can be checked on
+type Load struct {
+	Units           int64
+	VolumeMM3       int64
+	WeightGrams     int64
+	PalletPositions int64
+}
+
+// CapacityExceededError tells which limit a load would exceed
+type CapacityExceededError struct {
+	Limit    string
+	Max      int64
+	Required int64
+	Unit     string
+}
+
+// Error implements error
+func (e *CapacityExceededError) Error() string {
+	return fmt.Sprintf("location %s capacity exceeded: requires %d %s, limit is %d %s",
+		e.Limit, e.Required, e.Unit, e.Max, e.Unit)
+}
+
+// Is lets errors.Is match ErrCapacityExceeded whatever limit was hit
+func (e *CapacityExceededError) Is(target error) bool {
+	return target == ErrCapacityExceeded
+}
+
+// SetLimits validates and replaces the location's physical limits
+func (l *Location) SetLimits(limits Limits) error {
+	if limits.MaxVolumeCM3 < 0 || limits.MaxWeightGrams < 0 || limits.PalletPositions < 0 {
+		return ErrInvalidCapacity
+	}
+	l.Limits = limits
+	return nil
+}
+
+// CheckLoad returns a CapacityExceededError for the first configured limit the load exceeds
+func (l *Location) CheckLoad(load Load) error {
+	if load.Units > l.Capacity {
+		return &CapacityExceededError{Limit: LimitUnits, Max: l.Capacity, Required: load.Units, Unit: "units"}
+	}
+
+	if l.MaxVolumeCM3 > 0 {
+		// Round up so a partly used cubic centimetre still counts
+		required := (load.VolumeMM3 + 999) / 1000
+		if required > l.MaxVolumeCM3 {
+			return &CapacityExceededError{Limit: LimitVolume, Max: l.MaxVolumeCM3, Required: required, Unit: "cm3"}
+		}
+	}
+
+	if l.MaxWeightGrams > 0 && load.WeightGrams > l.MaxWeightGrams {
+		return &CapacityExceededError{Limit: LimitWeight, Max: l.MaxWeightGrams, Required: load.WeightGrams, Unit: "g"}
+	}
+
+	if l.PalletPositions > 0 && load.PalletPositions > l.PalletPositions {
+		return &CapacityExceededError{Limit: LimitPalletPositions, Max: l.PalletPositions, Required: load.PalletPositions, Unit: "positions"}
+	}
+
+	return nil
+}
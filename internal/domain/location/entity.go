@@ -8,6 +8,15 @@ type Location struct {
 	Code     string
 	Name     string
 	Capacity int64
+	Limits
+
+	TemperatureZone TemperatureZone
+
+	// Warehouse groups locations into a site for per-warehouse stock levels; empty means none
+	Warehouse string
+
+	// Version counts changes to the location and guards concurrent updates
+	Version int64
 }
 
 // NewLocation creates a new location
@@ -23,9 +32,11 @@ func NewLocation(code, name string, capacity int64) (*Location, error) {
 	}
 
 	return &Location{
-		Code:     code,
-		Name:     name,
-		Capacity: capacity,
+		Code:            code,
+		Name:            name,
+		Capacity:        capacity,
+		TemperatureZone: ZoneAmbient,
+		Version:         1,
 	}, nil
 }
 
@@ -9,4 +9,8 @@ var (
 	ErrInvalidCapacity  = errors.New("invalid capacity")
 	ErrDuplicateCode    = errors.New("location code already exists")
 	ErrCapacityExceeded = errors.New("location capacity exceeded")
+	ErrInvalidZone      = errors.New("invalid temperature zone")
+
+	// ErrConcurrentModification is returned when the location changed since the caller read it
+	ErrConcurrentModification = errors.New("location was modified by another request")
 )
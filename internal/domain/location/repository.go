@@ -1,6 +1,44 @@
 package location
 
-import "context"
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+)
+
+// Filter narrows down location listings. Zero values are ignored.
+type Filter struct {
+	// CodePrefix matches the start of the location code, ignoring case
+	CodePrefix      string
+	Warehouse       string
+	TemperatureZone TemperatureZone
+	Capacity        listing.Int64Range
+}
+
+// SortFields are the fields location listings can be sorted by
+var SortFields = []string{"id", "code", "name", "capacity", "warehouse"}
+
+// DefaultOrder lists the newest locations first
+var DefaultOrder = []listing.Sort{{Field: "id", Desc: true}}
+
+// SortValue returns a location's value of one of the SortFields
+func SortValue(l *Location, field string) interface{} {
+	switch field {
+	case "code":
+		return l.Code
+	case "name":
+		return l.Name
+	case "capacity":
+		return l.Capacity
+	case "warehouse":
+		return l.Warehouse
+	default:
+		return l.ID
+	}
+}
+
+// ListSpec selects a page of locations. Search looks at the code, name and warehouse.
+type ListSpec = listing.Spec[Filter]
 
 // Repository defines the contract for location persistence
 type Repository interface {
@@ -13,15 +51,16 @@ type Repository interface {
 	// GetByCode retrieves a location by code
 	GetByCode(ctx context.Context, code string) (*Location, error)
 
-	// List retrieves all locations with pagination
-	List(ctx context.Context, limit, offset int) ([]*Location, error)
+	// List retrieves the locations the spec selects, newest first unless it sorts otherwise
+	List(ctx context.Context, spec ListSpec) ([]*Location, error)
 
-	// Update updates an existing location
+	// Update updates an existing location. It fails with ErrConcurrentModification
+	// unless the stored version equals location.Version, and bumps location.Version on success.
 	Update(ctx context.Context, location *Location) error
 
-	// Delete deletes a location
-	Delete(ctx context.Context, id int64) error
+	// Delete deletes a location if its stored version equals version
+	Delete(ctx context.Context, id, version int64) error
 
-	// Count returns total number of locations
-	Count(ctx context.Context) (int64, error)
+	// Count returns how many locations match the spec's filter and search
+	Count(ctx context.Context, spec ListSpec) (int64, error)
 }
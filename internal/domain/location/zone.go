@@ -0,0 +1,31 @@
+package location
+
+// TemperatureZone is the storage temperature a location provides
+type TemperatureZone string
+
+const (
+	ZoneAmbient TemperatureZone = "AMBIENT"
+	ZoneChilled TemperatureZone = "CHILLED"
+	ZoneFrozen  TemperatureZone = "FROZEN"
+)
+
+// IsValid reports whether z is a known temperature zone
+func (z TemperatureZone) IsValid() bool {
+	switch z {
+	case ZoneAmbient, ZoneChilled, ZoneFrozen:
+		return true
+	}
+	return false
+}
+
+// SetTemperatureZone changes the location's temperature zone; empty means ambient
+func (l *Location) SetTemperatureZone(zone TemperatureZone) error {
+	if zone == "" {
+		zone = ZoneAmbient
+	}
+	if !zone.IsValid() {
+		return ErrInvalidZone
+	}
+	l.TemperatureZone = zone
+	return nil
+}
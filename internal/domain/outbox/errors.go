@@ -0,0 +1,7 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrInvalidMessage = errors.New("outbox message needs an aggregate type and an event type")
+)
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Message is a domain event stored in the outbox until the relay has handed
+// it to every sink
+type Message struct {
+	// ID is the outbox sequence number; messages are relayed in ID order
+	ID int64
+
+	// EventID identifies the event across redeliveries so consumers can drop duplicates
+	EventID string
+
+	// AggregateType and AggregateID name the aggregate the event belongs to.
+	// Events of one aggregate are relayed in the order they were recorded.
+	AggregateType string
+	AggregateID   int64
+
+	EventType   string
+	Payload     []byte
+	OccurredAt  time.Time
+	Attempts    int
+	LastError   string
+	PublishedAt *time.Time
+
+	// DeadLetteredAt is set once the relay has given up on the message
+	DeadLetteredAt *time.Time
+}
+
+// NewMessage creates an unpublished message with data encoded as JSON
+func NewMessage(aggregateType string, aggregateID int64, eventType string, data interface{}) (*Message, error) {
+	if aggregateType == "" || eventType == "" {
+		return nil, ErrInvalidMessage
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return &Message{
+		EventID:       randomHex(16),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       payload,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// AggregateKey identifies the message's aggregate, e.g. "product:12". Sinks
+// with partitions use it as the partition key so per-aggregate order survives.
+func (m *Message) AggregateKey() string {
+	return fmt.Sprintf("%s:%d", m.AggregateType, m.AggregateID)
+}
+
+// envelope is the JSON form of a message handed to message brokers and logs
+type envelope struct {
+	ID            string          `json:"id"`
+	Sequence      int64           `json:"sequence"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Envelope returns the message as a self-describing JSON document
+func (m *Message) Envelope() ([]byte, error) {
+	return json.Marshal(envelope{
+		ID:            m.EventID,
+		Sequence:      m.ID,
+		Type:          m.EventType,
+		AggregateType: m.AggregateType,
+		AggregateID:   m.AggregateID,
+		OccurredAt:    m.OccurredAt,
+		Data:          json.RawMessage(m.Payload),
+	})
+}
+
+// randomHex returns n random bytes hex-encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the contract for outbox persistence
+type Repository interface {
+	// Append stores messages. It must run in the transaction of the change the
+	// messages describe, so they are committed or rolled back with it. IDs are
+	// assigned in order and, per aggregate, in commit order.
+	Append(ctx context.Context, messages []*Message) error
+
+	// Unpublished returns the oldest messages neither published nor
+	// dead-lettered, in ID order
+	Unpublished(ctx context.Context, limit int) ([]*Message, error)
+
+	// MarkPublished records that every sink accepted the message
+	MarkPublished(ctx context.Context, id int64, at time.Time) error
+
+	// MarkFailed records a failed relay attempt; the message stays unpublished
+	MarkFailed(ctx context.Context, id int64, reason string) error
+
+	// MarkDeadLettered records a final failed attempt; the relay no longer
+	// picks the message up
+	MarkDeadLettered(ctx context.Context, id int64, reason string, at time.Time) error
+}
+
+// Sink is a destination messages are relayed to, e.g. webhooks or a message broker.
+// Delivery is at least once: a message can reach a sink again when a later
+// step fails, so sinks and their consumers must tolerate duplicate event IDs.
+type Sink interface {
+	// Name identifies the sink in errors
+	Name() string
+
+	// Publish hands one message to the sink
+	Publish(ctx context.Context, message *Message) error
+}
@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// relayBatchSize is the number of messages read per relay run
+const relayBatchSize = 100
+
+// DefaultMaxAttempts is how many times the relay tries a message before
+// dead-lettering it
+const DefaultMaxAttempts = 10
+
+// Outbox records domain events alongside the changes that raise them
+type Outbox struct {
+	repo Repository
+}
+
+// NewOutbox creates a new outbox
+func NewOutbox(repo Repository) *Outbox {
+	return &Outbox{repo: repo}
+}
+
+// Record stores messages in the outbox. Call it with the context of the
+// transaction making the change, so an event exists exactly when its change does.
+func (o *Outbox) Record(ctx context.Context, messages ...*Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return o.repo.Append(ctx, messages)
+}
+
+// Lock keeps the relay to one runner at a time across instances
+type Lock interface {
+	// TryRun runs fn while holding the lock and reports whether it did; it
+	// returns false without running fn when the lock is held elsewhere
+	TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+// Relay publishes outbox messages to sinks
+type Relay struct {
+	repo        Repository
+	sinks       []Sink
+	lock        Lock
+	maxAttempts int
+}
+
+// NewRelay creates a relay publishing to every given sink
+func NewRelay(repo Repository, sinks ...Sink) *Relay {
+	return &Relay{
+		repo:        repo,
+		sinks:       sinks,
+		maxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// WithLock makes each run take lock first and skip when another instance holds it.
+// Without a lock, only one process may run the relay.
+func (r *Relay) WithLock(lock Lock) *Relay {
+	r.lock = lock
+	return r
+}
+
+// WithMaxAttempts sets how many attempts a message gets before it is dead-lettered
+func (r *Relay) WithMaxAttempts(n int) *Relay {
+	if n > 0 {
+		r.maxAttempts = n
+	}
+	return r
+}
+
+// PublishPending relays unpublished messages in order and returns how many
+// were published. A message is published once every sink has accepted it. When
+// a message fails, later messages of the same aggregate wait for the next run
+// so consumers never see an aggregate's events out of order; other aggregates
+// carry on. A message that has failed maxAttempts times is dead-lettered and
+// stops holding its aggregate back. When the lock is held by another instance
+// the run does nothing.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	if r.lock == nil {
+		return r.publishPending(ctx)
+	}
+
+	published := 0
+	_, err := r.lock.TryRun(ctx, func(ctx context.Context) error {
+		var err error
+		published, err = r.publishPending(ctx)
+		return err
+	})
+	return published, err
+}
+
+// publishPending relays one batch of unpublished messages
+func (r *Relay) publishPending(ctx context.Context) (int, error) {
+	messages, err := r.repo.Unpublished(ctx, relayBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[string]bool)
+	published := 0
+	for _, m := range messages {
+		key := m.AggregateKey()
+		if blocked[key] {
+			continue
+		}
+
+		if err := r.publish(ctx, m); err != nil {
+			if m.Attempts+1 >= r.maxAttempts {
+				if err := r.repo.MarkDeadLettered(ctx, m.ID, err.Error(), time.Now()); err != nil {
+					return published, err
+				}
+				continue
+			}
+			blocked[key] = true
+			if err := r.repo.MarkFailed(ctx, m.ID, err.Error()); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.repo.MarkPublished(ctx, m.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// publish hands a message to every sink, stopping at the first failure
+func (r *Relay) publish(ctx context.Context, m *Message) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, m); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+)
+
+// BarcodeType identifies the symbology family of a barcode
+type BarcodeType string
+
+const (
+	BarcodeTypeEAN8   BarcodeType = "EAN8"
+	BarcodeTypeEAN13  BarcodeType = "EAN13"
+	BarcodeTypeUPCA   BarcodeType = "UPC_A"
+	BarcodeTypeGTIN14 BarcodeType = "GTIN14"
+)
+
+// Barcode is an alternate code a product can be scanned by
+type Barcode struct {
+	Type  BarcodeType
+	Value string
+}
+
+// Validate checks the barcode length and GS1 check digit for its type
+func (b Barcode) Validate() error {
+	var length int
+	switch b.Type {
+	case BarcodeTypeEAN8:
+		length = 8
+	case BarcodeTypeEAN13:
+		length = 13
+	case BarcodeTypeUPCA:
+		length = 12
+	case BarcodeTypeGTIN14:
+		length = 14
+	default:
+		return ErrInvalidBarcode
+	}
+
+	if len(b.Value) != length || !ValidGTIN(b.Value) {
+		return ErrInvalidBarcode
+	}
+	return nil
+}
+
+// ValidGTIN reports whether s is a GTIN with a correct check digit
+func ValidGTIN(s string) bool {
+	return gs1.ValidCheckDigit(s)
+}
+
+// GTINForms returns the codes a GTIN may be stored under. An EAN-13 is the
+// GTIN-14 with its leading zero dropped, a UPC-A drops two and an EAN-8 six, so
+// a scan of one matches a product registered with another.
+func GTINForms(gtin string) []string {
+	if len(gtin) > 14 || !ValidGTIN(gtin) {
+		return nil
+	}
+
+	full := strings.Repeat("0", 14-len(gtin)) + gtin
+	forms := []string{gtin}
+	for _, length := range []int{14, 13, 12, 8} {
+		form := full[14-length:]
+		if length != len(gtin) && strings.Trim(full[:14-length], "0") == "" {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// FindByGTIN looks a GTIN up under each length it may be registered with. It
+// fails with ErrProductNotFound when no product carries it.
+func FindByGTIN(ctx context.Context, repo Repository, gtin string) (*Product, error) {
+	for _, code := range GTINForms(gtin) {
+		prod, err := repo.GetByBarcode(ctx, code)
+		if !errors.Is(err, ErrProductNotFound) {
+			return prod, err
+		}
+	}
+	return nil, ErrProductNotFound
+}
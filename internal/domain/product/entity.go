@@ -7,23 +7,59 @@ type Product struct {
 	ID       int64
 	SKUName  string
 	Quantity int64
+	MasterData
+
+	// Version counts master data changes and guards concurrent updates.
+	// Quantity changes do not bump it.
+	Version int64
 }
 
-// NewProduct creates a new product
-func NewProduct(skuName string, quantity int64) (*Product, error) {
+// NewProduct creates a new product with no stock. Stock is brought in by
+// movements, so the ledger accounts for every unit.
+func NewProduct(skuName string, data MasterData) (*Product, error) {
 	if skuName == "" {
 		return nil, errors.New("SKU name cannot be empty")
 	}
-	if quantity < 0 {
-		return nil, errors.New("quantity cannot be negative")
+
+	data, err := data.normalize()
+	if err != nil {
+		return nil, err
 	}
 
 	return &Product{
-		SKUName:  skuName,
-		Quantity: quantity,
+		SKUName:    skuName,
+		MasterData: data,
+		Version:    1,
 	}, nil
 }
 
+// Rename changes the product's SKU name
+func (p *Product) Rename(skuName string) error {
+	if skuName == "" {
+		return ErrInvalidSKU
+	}
+	p.SKUName = skuName
+	return nil
+}
+
+// SetMasterData validates and replaces the product's descriptive attributes
+func (p *Product) SetMasterData(data MasterData) error {
+	data, err := data.normalize()
+	if err != nil {
+		return err
+	}
+	if data.Serialized != p.Serialized && p.Quantity != 0 {
+		return ErrSerializedWithStock
+	}
+	p.MasterData = data
+	return nil
+}
+
+// IsActive reports whether the product can still be received
+func (p *Product) IsActive() bool {
+	return p.Status != StatusInactive
+}
+
 // IncreaseStock increases the product quantity
 func (p *Product) IncreaseStock(quantity int64) error {
 	if quantity <= 0 {
@@ -3,9 +3,36 @@ package product
 import "errors"
 
 var (
-	ErrProductNotFound   = errors.New("product not found")
-	ErrInsufficientStock = errors.New("insufficient stock")
-	ErrInvalidSKU        = errors.New("invalid SKU name")
-	ErrInvalidQuantity   = errors.New("invalid quantity")
-	ErrDuplicateSKU      = errors.New("SKU already exists")
+	ErrProductNotFound        = errors.New("product not found")
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrInvalidSKU             = errors.New("invalid SKU name")
+	ErrInvalidQuantity        = errors.New("invalid quantity")
+	ErrDuplicateSKU           = errors.New("SKU already exists")
+	ErrInvalidDescription     = errors.New("description is too long")
+	ErrInvalidCategory        = errors.New("category is too long")
+	ErrInvalidBrand           = errors.New("brand is too long")
+	ErrInvalidUOM             = errors.New("invalid unit of measure")
+	ErrInvalidStatus          = errors.New("invalid product status")
+	ErrInvalidBarcode         = errors.New("invalid barcode")
+	ErrDuplicateBarcode       = errors.New("barcode already assigned")
+	ErrInvalidDimensions      = errors.New("dimensions must all be positive or all be zero")
+	ErrInvalidWeight          = errors.New("weight cannot be negative")
+	ErrInvalidShelfLife       = errors.New("shelf life cannot be negative")
+	ErrProductInactive        = errors.New("product is inactive")
+	ErrInvalidPackLevel       = errors.New("invalid pack level")
+	ErrInvalidPackHierarchy   = errors.New("each pack level must hold a whole number of the level below it")
+	ErrDimensionsUnknown      = errors.New("product dimensions are required for volume-limited locations")
+	ErrWeightUnknown          = errors.New("product weight is required for weight-limited locations")
+	ErrPalletPackUnknown      = errors.New("product pallet pack is required for locations with pallet positions")
+	ErrPackLevelNotConfigured = errors.New("pack level is not configured for this product")
+	ErrSerializedWithStock    = errors.New("serial tracking can only be switched while the product has no stock")
+
+	// ErrConcurrentModification is returned when the product changed since the caller read it
+	ErrConcurrentModification = errors.New("product was modified by another request")
+
+	// ErrQuantityManagedByLedger is returned when a caller tries to set quantity directly
+	ErrQuantityManagedByLedger = errors.New("quantity can only be changed through stock movements or adjustments")
+
+	// ErrOpeningStock is returned when a new product is given a quantity
+	ErrOpeningStock = errors.New("quantity cannot be set on a new product; stock comes from movements")
 )
@@ -0,0 +1,121 @@
+package product
+
+import "strings"
+
+// Status tells whether a product can still be received
+type Status string
+
+const (
+	StatusActive   Status = "ACTIVE"
+	StatusInactive Status = "INACTIVE"
+)
+
+// DefaultBaseUOM is the unit of measure used when none is given
+const DefaultBaseUOM = "EA"
+
+// Field length limits for descriptive master data
+const (
+	maxDescriptionLength = 2000
+	maxCategoryLength    = 100
+	maxBrandLength       = 100
+	maxUOMLength         = 10
+)
+
+// Dimensions are the outer measurements of one base unit, in millimetres.
+// All zero means the dimensions are unknown.
+type Dimensions struct {
+	LengthMM int64
+	WidthMM  int64
+	HeightMM int64
+}
+
+// IsZero reports whether no dimensions were recorded
+func (d Dimensions) IsZero() bool {
+	return d.LengthMM == 0 && d.WidthMM == 0 && d.HeightMM == 0
+}
+
+// VolumeMM3 returns the volume of one base unit in cubic millimetres
+func (d Dimensions) VolumeMM3() int64 {
+	return d.LengthMM * d.WidthMM * d.HeightMM
+}
+
+// MasterData holds the descriptive attributes of a product
+type MasterData struct {
+	Description   string
+	Category      string
+	Brand         string
+	BaseUOM       string
+	Barcodes      []Barcode
+	Packs         []Pack
+	Dimensions    Dimensions
+	WeightGrams   int64
+	ShelfLifeDays int
+	Status        Status
+
+	// Serialized products are tracked unit by unit; every movement lists its serials
+	Serialized bool
+}
+
+// normalize applies defaults and validates the master data
+func (md MasterData) normalize() (MasterData, error) {
+	md.Description = strings.TrimSpace(md.Description)
+	md.Category = strings.TrimSpace(md.Category)
+	md.Brand = strings.TrimSpace(md.Brand)
+	md.BaseUOM = strings.ToUpper(strings.TrimSpace(md.BaseUOM))
+
+	if md.BaseUOM == "" {
+		md.BaseUOM = DefaultBaseUOM
+	}
+	if md.Status == "" {
+		md.Status = StatusActive
+	}
+
+	if len(md.Description) > maxDescriptionLength {
+		return md, ErrInvalidDescription
+	}
+	if len(md.Category) > maxCategoryLength {
+		return md, ErrInvalidCategory
+	}
+	if len(md.Brand) > maxBrandLength {
+		return md, ErrInvalidBrand
+	}
+	if len(md.BaseUOM) > maxUOMLength {
+		return md, ErrInvalidUOM
+	}
+	if md.Status != StatusActive && md.Status != StatusInactive {
+		return md, ErrInvalidStatus
+	}
+
+	seen := make(map[string]bool)
+	for _, b := range md.Barcodes {
+		if err := b.Validate(); err != nil {
+			return md, err
+		}
+		if seen[b.Value] {
+			return md, ErrDuplicateBarcode
+		}
+		seen[b.Value] = true
+	}
+
+	packs, err := validatePacks(md.Packs)
+	if err != nil {
+		return md, err
+	}
+	md.Packs = packs
+
+	d := md.Dimensions
+	if d.LengthMM < 0 || d.WidthMM < 0 || d.HeightMM < 0 {
+		return md, ErrInvalidDimensions
+	}
+	if !d.IsZero() && (d.LengthMM == 0 || d.WidthMM == 0 || d.HeightMM == 0) {
+		return md, ErrInvalidDimensions
+	}
+	if md.WeightGrams < 0 {
+		return md, ErrInvalidWeight
+	}
+	if md.ShelfLifeDays < 0 {
+		return md, ErrInvalidShelfLife
+	}
+
+	return md, nil
+}
@@ -1,10 +1,58 @@
 package product
 
-import "context"
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+)
+
+// Filter narrows down product listings. Zero values are ignored.
+type Filter struct {
+	// SKUPrefix matches the start of the SKU name, ignoring case
+	SKUPrefix string
+	Category  string
+	Brand     string
+	Status    Status
+	Quantity  listing.Int64Range
+}
+
+// SortFields are the fields product listings can be sorted by
+var SortFields = []string{"id", "sku_name", "category", "brand", "status", "quantity"}
+
+// DefaultOrder lists the newest products first
+var DefaultOrder = []listing.Sort{{Field: "id", Desc: true}}
+
+// SortValue returns a product's value of one of the SortFields
+func SortValue(p *Product, field string) interface{} {
+	switch field {
+	case "sku_name":
+		return p.SKUName
+	case "category":
+		return p.Category
+	case "brand":
+		return p.Brand
+	case "status":
+		return string(p.Status)
+	case "quantity":
+		return p.Quantity
+	default:
+		return p.ID
+	}
+}
+
+// ListSpec selects a page of products. Search looks at the SKU name,
+// description, category and brand.
+type ListSpec = listing.Spec[Filter]
+
+// SearchHit is a product a search found and how well it matched; higher is better
+type SearchHit struct {
+	Product *Product
+	Score   float64
+}
 
 // Repository defines the contract for product persistence
 type Repository interface {
-	// Create saves a new product
+	// Create saves a new product; its stock starts at zero
 	Create(ctx context.Context, product *Product) error
 
 	// GetByID retrieves a product by ID
@@ -13,15 +61,27 @@ type Repository interface {
 	// GetBySKU retrieves a product by SKU name
 	GetBySKU(ctx context.Context, skuName string) (*Product, error)
 
-	// List retrieves all products with pagination
-	List(ctx context.Context, limit, offset int) ([]*Product, error)
+	// GetByBarcode retrieves a product by one of its barcodes
+	GetByBarcode(ctx context.Context, barcode string) (*Product, error)
 
-	// Update updates an existing product
+	// Search ranks the products whose SKU, descriptive fields or barcodes match
+	// text and returns the best limit of them
+	Search(ctx context.Context, text string, limit int) ([]SearchHit, error)
+
+	// List retrieves the products the spec selects, newest first unless it sorts otherwise
+	List(ctx context.Context, spec ListSpec) ([]*Product, error)
+
+	// Update updates the master data of an existing product. Quantity is left untouched.
+	// It fails with ErrConcurrentModification unless the stored version equals
+	// product.Version, and bumps product.Version on success.
 	Update(ctx context.Context, product *Product) error
 
-	// Delete deletes a product
-	Delete(ctx context.Context, id int64) error
+	// UpdateQuantity persists the product's current quantity
+	UpdateQuantity(ctx context.Context, product *Product) error
+
+	// Delete deletes a product if its stored version equals version
+	Delete(ctx context.Context, id, version int64) error
 
-	// Count returns total number of products
-	Count(ctx context.Context) (int64, error)
+	// Count returns how many products match the spec's filter and search
+	Count(ctx context.Context, spec ListSpec) (int64, error)
 }
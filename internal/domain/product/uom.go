@@ -0,0 +1,119 @@
+package product
+
+import "math"
+
+// PackLevel is a level in a product's pack hierarchy
+type PackLevel string
+
+const (
+	// PackLevelEach is a single base unit and always converts 1:1
+	PackLevelEach   PackLevel = "EACH"
+	PackLevelInner  PackLevel = "INNER"
+	PackLevelCase   PackLevel = "CASE"
+	PackLevelPallet PackLevel = "PALLET"
+)
+
+// packLevelOrder lists pack levels from smallest to largest
+var packLevelOrder = []PackLevel{PackLevelEach, PackLevelInner, PackLevelCase, PackLevelPallet}
+
+// rank returns the position of the level in the hierarchy, or -1 if unknown
+func (l PackLevel) rank() int {
+	for i, level := range packLevelOrder {
+		if level == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsValid checks if the pack level is one of the supported values
+func (l PackLevel) IsValid() bool {
+	return l.rank() >= 0
+}
+
+// Pack configures how many base units one pack of a level contains
+type Pack struct {
+	Level        PackLevel
+	UnitsPerPack int64
+}
+
+// validatePacks checks that configured packs form a strictly growing hierarchy
+// and returns them ordered from smallest to largest
+func validatePacks(packs []Pack) ([]Pack, error) {
+	byRank := make([]*Pack, len(packLevelOrder))
+	for i := range packs {
+		p := packs[i]
+		r := p.Level.rank()
+		if r < 0 {
+			return nil, ErrInvalidPackLevel
+		}
+		// EACH is implicit and cannot be redefined
+		if p.Level == PackLevelEach || p.UnitsPerPack <= 1 || byRank[r] != nil {
+			return nil, ErrInvalidPackHierarchy
+		}
+		byRank[r] = &p
+	}
+
+	ordered := make([]Pack, 0, len(packs))
+	var previous int64 = 1
+	for _, p := range byRank {
+		if p == nil {
+			continue
+		}
+		// Each level must hold a whole number of the level below it
+		if p.UnitsPerPack <= previous || p.UnitsPerPack%previous != 0 {
+			return nil, ErrInvalidPackHierarchy
+		}
+		previous = p.UnitsPerPack
+		ordered = append(ordered, *p)
+	}
+
+	return ordered, nil
+}
+
+// UnitsPerPack returns how many base units one pack of the given level holds
+func (p *Product) UnitsPerPack(level PackLevel) (int64, error) {
+	if level == "" || level == PackLevelEach {
+		return 1, nil
+	}
+	if !level.IsValid() {
+		return 0, ErrInvalidPackLevel
+	}
+	for _, pack := range p.Packs {
+		if pack.Level == level {
+			return pack.UnitsPerPack, nil
+		}
+	}
+	return 0, ErrPackLevelNotConfigured
+}
+
+// ToBaseUnits converts a quantity expressed in the given pack level to base units
+func (p *Product) ToBaseUnits(level PackLevel, quantity int64) (int64, error) {
+	factor, err := p.UnitsPerPack(level)
+	if err != nil {
+		return 0, err
+	}
+	if quantity > math.MaxInt64/factor {
+		return 0, ErrInvalidQuantity
+	}
+	return quantity * factor, nil
+}
+
+// FromBaseUnits expresses a base-unit quantity as whole packs of the given level
+// plus the base units left over
+func (p *Product) FromBaseUnits(level PackLevel, quantity int64) (packs, remainder int64, err error) {
+	factor, err := p.UnitsPerPack(level)
+	if err != nil {
+		return 0, 0, err
+	}
+	return quantity / factor, quantity % factor, nil
+}
+
+// PalletsFor returns how many pallet positions a base-unit quantity occupies
+func (p *Product) PalletsFor(quantity int64) (int64, error) {
+	factor, err := p.UnitsPerPack(PackLevelPallet)
+	if err != nil {
+		return 0, err
+	}
+	return (quantity + factor - 1) / factor, nil
+}
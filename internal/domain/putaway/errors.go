@@ -0,0 +1,12 @@
+package putaway
+
+import "errors"
+
+var (
+	ErrRuleNotFound        = errors.New("putaway rule not found")
+	ErrInvalidRule         = errors.New("invalid putaway rule")
+	ErrNoSuitableLocation  = errors.New("no location can take this putaway")
+	ErrInboundOnly         = errors.New("auto-assign is only available for inbound movements")
+	ErrAutoAssignDisabled  = errors.New("auto-assign is not configured")
+	ErrInvalidSuggestLimit = errors.New("suggestion limit must be positive")
+)
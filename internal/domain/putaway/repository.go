@@ -0,0 +1,23 @@
+package putaway
+
+import "context"
+
+// RuleRepository defines the contract for putaway rule persistence
+type RuleRepository interface {
+	// Get retrieves the rule for a product category
+	Get(ctx context.Context, category string) (*Rule, error)
+
+	// List retrieves all rules ordered by category
+	List(ctx context.Context) ([]*Rule, error)
+
+	// Save creates or replaces the rule for its category
+	Save(ctx context.Context, rule *Rule) error
+
+	// Delete removes the rule for a category
+	Delete(ctx context.Context, category string) error
+}
+
+// LotLocator finds where a lot of a product is already stored
+type LotLocator interface {
+	LocationsWithLot(ctx context.Context, productID int64, lot string) ([]int64, error)
+}
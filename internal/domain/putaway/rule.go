@@ -0,0 +1,69 @@
+package putaway
+
+import (
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+)
+
+// maxCategoryLength matches the product category limit
+const maxCategoryLength = 100
+
+// Rule constrains where products of one category may be put away
+type Rule struct {
+	Category string
+
+	// TemperatureZone is the zone the category must be stored in
+	TemperatureZone location.TemperatureZone
+
+	// LocationPrefix, when set, limits putaway to locations whose code starts with it
+	LocationPrefix string
+
+	// AllowMixedSKUs lets the category share a location with other products
+	AllowMixedSKUs bool
+}
+
+// DefaultRule applies to products whose category has no rule of its own
+func DefaultRule() *Rule {
+	return &Rule{
+		TemperatureZone: location.ZoneAmbient,
+		AllowMixedSKUs:  true,
+	}
+}
+
+// NewRule creates a validated putaway rule for a category
+func NewRule(category string, zone location.TemperatureZone, prefix string, allowMixed bool) (*Rule, error) {
+	category = strings.TrimSpace(category)
+	if category == "" || len(category) > maxCategoryLength {
+		return nil, ErrInvalidRule
+	}
+
+	if zone == "" {
+		zone = location.ZoneAmbient
+	}
+	if !zone.IsValid() {
+		return nil, location.ErrInvalidZone
+	}
+
+	return &Rule{
+		Category:        category,
+		TemperatureZone: zone,
+		LocationPrefix:  strings.TrimSpace(prefix),
+		AllowMixedSKUs:  allowMixed,
+	}, nil
+}
+
+// Admits reports whether a location satisfies the rule's hard constraints.
+// holdsOtherSKUs tells whether the location has stock of any other product.
+func (r *Rule) Admits(loc *location.Location, holdsOtherSKUs bool) bool {
+	if loc.TemperatureZone != r.TemperatureZone {
+		return false
+	}
+	if r.LocationPrefix != "" && !strings.HasPrefix(loc.Code, r.LocationPrefix) {
+		return false
+	}
+	if !r.AllowMixedSKUs && holdsOtherSKUs {
+		return false
+	}
+	return true
+}
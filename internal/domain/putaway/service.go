@@ -0,0 +1,246 @@
+package putaway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// locationPageSize is the number of locations loaded per page while ranking
+const locationPageSize = 500
+
+// Ranking weights. Consolidation outweighs fill level so a SKU or lot is kept
+// together before empty space is packed tightly.
+const (
+	scoreSameLot       = 100
+	scoreSameSKU       = 50
+	scoreFillMax       = 20
+	defaultSuggestions = 5
+)
+
+// Request describes goods waiting to be put away
+type Request struct {
+	ProductID int64
+	Quantity  int64
+	UOM       product.PackLevel
+	Lot       string
+
+	// Limit is the maximum number of suggestions; zero means a default of 5
+	Limit int
+}
+
+// Suggestion is one candidate location with the reasons it was ranked where it is
+type Suggestion struct {
+	Location  *location.Location
+	Score     int
+	OnHand    int64
+	SameLot   bool
+	FreeUnits int64
+	Reasons   []string
+}
+
+// Service ranks locations for incoming goods
+type Service struct {
+	productRepo  product.Repository
+	locationRepo location.Repository
+	stockRepo    stock.Repository
+	stockService *stock.Service
+	ruleRepo     RuleRepository
+	lots         LotLocator
+}
+
+// NewService creates a new putaway service. Capacity is checked through the
+// stock service, so suggestions pass the same checks the movement will.
+func NewService(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	stockService *stock.Service,
+) *Service {
+	return &Service{
+		productRepo:  productRepo,
+		locationRepo: locationRepo,
+		stockRepo:    stockRepo,
+		stockService: stockService,
+	}
+}
+
+// WithRules enables per-category putaway rules; without them every product uses DefaultRule
+func (s *Service) WithRules(ruleRepo RuleRepository) *Service {
+	s.ruleRepo = ruleRepo
+	return s
+}
+
+// WithLots enables consolidation with locations already holding the same lot
+func (s *Service) WithLots(lots LotLocator) *Service {
+	s.lots = lots
+	return s
+}
+
+// Suggest ranks the locations that can take the goods, best first
+func (s *Service) Suggest(ctx context.Context, req Request) ([]*Suggestion, error) {
+	if req.Limit < 0 {
+		return nil, ErrInvalidSuggestLimit
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestions
+	}
+	if req.Quantity <= 0 {
+		return nil, stock.ErrInvalidQuantity
+	}
+
+	prod, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !prod.IsActive() {
+		return nil, product.ErrProductInactive
+	}
+
+	quantity, err := prod.ToBaseUnits(req.UOM, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.ruleFor(ctx, prod.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.stockRepo.SumBalances(ctx, stock.LedgerRange{})
+	if err != nil {
+		return nil, err
+	}
+	onHand := make(map[int64]int64)
+	holdsOther := make(map[int64]bool)
+	for _, b := range balances {
+		if b.Quantity <= 0 {
+			continue
+		}
+		if b.ProductID == prod.ID {
+			onHand[b.LocationID] = b.Quantity
+		} else {
+			holdsOther[b.LocationID] = true
+		}
+	}
+
+	lotAt := make(map[int64]bool)
+	if req.Lot != "" && s.lots != nil {
+		locationIDs, err := s.lots.LocationsWithLot(ctx, prod.ID, req.Lot)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range locationIDs {
+			lotAt[id] = true
+		}
+	}
+
+	var suggestions []*Suggestion
+	spec := location.ListSpec{Limit: locationPageSize}
+	for {
+		locations, err := s.locationRepo.List(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, loc := range locations {
+			if !rule.Admits(loc, holdsOther[loc.ID]) {
+				continue
+			}
+
+			suggestion, err := s.evaluate(ctx, loc, prod, quantity, onHand[loc.ID], lotAt[loc.ID])
+			if err != nil {
+				return nil, err
+			}
+			if suggestion != nil {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+
+		if len(locations) < locationPageSize {
+			break
+		}
+		spec.After = listing.Keys(locations[len(locations)-1], location.DefaultOrder, location.SortValue)
+	}
+
+	if len(suggestions) == 0 {
+		return nil, ErrNoSuitableLocation
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.FreeUnits != b.FreeUnits {
+			return a.FreeUnits < b.FreeUnits
+		}
+		return a.Location.Code < b.Location.Code
+	})
+
+	if len(suggestions) > req.Limit {
+		suggestions = suggestions[:req.Limit]
+	}
+	return suggestions, nil
+}
+
+// evaluate scores one admitted location, returning nil when the goods do not fit.
+// Locations whose limits need master data the products lack are skipped, the
+// movement itself would be rejected there.
+func (s *Service) evaluate(ctx context.Context, loc *location.Location, prod *product.Product, quantity, onHand int64, sameLot bool) (*Suggestion, error) {
+	load, err := s.stockService.LoadAfter(ctx, loc, prod, quantity)
+	if errors.Is(err, product.ErrDimensionsUnknown) || errors.Is(err, product.ErrWeightUnknown) ||
+		errors.Is(err, product.ErrPalletPackUnknown) || errors.Is(err, product.ErrProductNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if loc.CheckLoad(load) != nil {
+		return nil, nil
+	}
+
+	suggestion := &Suggestion{
+		Location:  loc,
+		OnHand:    onHand,
+		SameLot:   sameLot,
+		FreeUnits: loc.Capacity - load.Units,
+	}
+
+	if sameLot {
+		suggestion.Score += scoreSameLot
+		suggestion.Reasons = append(suggestion.Reasons, "holds the same lot")
+	}
+	if onHand > 0 {
+		suggestion.Score += scoreSameSKU
+		suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("consolidates with %d units already stored", onHand))
+	}
+
+	fill := int(load.Units * scoreFillMax / loc.Capacity)
+	suggestion.Score += fill
+	suggestion.Reasons = append(suggestion.Reasons, fmt.Sprintf("%d%% full after putaway", load.Units*100/loc.Capacity))
+
+	return suggestion, nil
+}
+
+// ruleFor returns the category's rule, falling back to DefaultRule
+func (s *Service) ruleFor(ctx context.Context, category string) (*Rule, error) {
+	if s.ruleRepo == nil || category == "" {
+		return DefaultRule(), nil
+	}
+
+	rule, err := s.ruleRepo.Get(ctx, category)
+	if err == ErrRuleNotFound {
+		return DefaultRule(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,144 @@
+package replenishment
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// Scope limits a replenishment run
+type Scope struct {
+	// ProductIDs restricts the run to these SKUs, e.g. the lines of an upcoming wave.
+	// Empty means every pick face.
+	ProductIDs []int64
+
+	// Reference is stored on the tasks created by the run
+	Reference string
+}
+
+// Engine creates transfer tasks from reserve locations to pick faces below their minimum
+type Engine struct {
+	repo      Repository
+	stockRepo stock.Repository
+}
+
+// NewEngine creates a new replenishment engine
+func NewEngine(repo Repository, stockRepo stock.Repository) *Engine {
+	return &Engine{
+		repo:      repo,
+		stockRepo: stockRepo,
+	}
+}
+
+// placement identifies a SKU at a location
+type placement struct {
+	productID, locationID int64
+}
+
+// Run tops up every pick face in scope whose stock, counting tasks already on
+// the way, is below its minimum. Each pick face is filled up to its maximum from
+// reserve locations, emptying the smallest reserve quantities first so partial
+// pallets are cleared before full ones are broken.
+func (e *Engine) Run(ctx context.Context, scope Scope) ([]*Task, error) {
+	faces, err := e.repo.ListPickFaces(ctx, scope.ProductIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(faces) == 0 {
+		return nil, nil
+	}
+
+	balances, err := e.stockRepo.SumBalances(ctx, stock.LedgerRange{})
+	if err != nil {
+		return nil, err
+	}
+	onHand := make(map[placement]int64)
+	byProduct := make(map[int64][]stock.Balance)
+	for _, b := range balances {
+		onHand[placement{b.ProductID, b.LocationID}] = b.Quantity
+		byProduct[b.ProductID] = append(byProduct[b.ProductID], b)
+	}
+
+	openTasks, err := e.repo.OpenTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	incoming := make(map[placement]int64)
+	outgoing := make(map[placement]int64)
+	for _, t := range openTasks {
+		incoming[placement{t.ProductID, t.ToLocationID}] += t.Quantity
+		outgoing[placement{t.ProductID, t.FromLocationID}] += t.Quantity
+	}
+
+	isPickFace := make(map[placement]bool)
+	for _, pf := range faces {
+		isPickFace[placement{pf.ProductID, pf.LocationID}] = true
+	}
+
+	sort.Slice(faces, func(i, j int) bool {
+		if faces[i].ProductID != faces[j].ProductID {
+			return faces[i].ProductID < faces[j].ProductID
+		}
+		return faces[i].LocationID < faces[j].LocationID
+	})
+
+	var tasks []*Task
+	for _, pf := range faces {
+		target := placement{pf.ProductID, pf.LocationID}
+		level := onHand[target] + incoming[target]
+		if level >= pf.Min {
+			continue
+		}
+		need := pf.Max - level
+
+		for _, source := range reserveSources(byProduct[pf.ProductID], isPickFace, outgoing) {
+			if need == 0 {
+				break
+			}
+
+			quantity := source.Quantity
+			if quantity > need {
+				quantity = need
+			}
+
+			task, err := NewTask(pf.ProductID, source.LocationID, pf.LocationID, quantity, scope.Reference)
+			if err != nil {
+				return nil, err
+			}
+			if err := e.repo.CreateTask(ctx, task); err != nil {
+				return nil, err
+			}
+
+			outgoing[placement{pf.ProductID, source.LocationID}] += quantity
+			incoming[target] += quantity
+			need -= quantity
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+// reserveSources returns the reserve locations of a SKU with the quantity not yet
+// promised to open tasks, smallest first
+func reserveSources(balances []stock.Balance, isPickFace map[placement]bool, outgoing map[placement]int64) []stock.Balance {
+	var sources []stock.Balance
+	for _, b := range balances {
+		p := placement{b.ProductID, b.LocationID}
+		if isPickFace[p] {
+			continue
+		}
+		if available := b.Quantity - outgoing[p]; available > 0 {
+			sources = append(sources, stock.Balance{ProductID: b.ProductID, LocationID: b.LocationID, Quantity: available})
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Quantity != sources[j].Quantity {
+			return sources[i].Quantity < sources[j].Quantity
+		}
+		return sources[i].LocationID < sources[j].LocationID
+	})
+	return sources
+}
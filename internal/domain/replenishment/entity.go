@@ -0,0 +1,97 @@
+package replenishment
+
+import "time"
+
+// TaskStatus is where a replenishment task is in its life cycle
+type TaskStatus string
+
+const (
+	TaskStatusOpen      TaskStatus = "OPEN"
+	TaskStatusCompleted TaskStatus = "COMPLETED"
+	TaskStatusCancelled TaskStatus = "CANCELLED"
+)
+
+// PickFace is a pick location for a SKU with the range its stock should stay in
+type PickFace struct {
+	ProductID  int64
+	LocationID int64
+	Min        int64
+	Max        int64
+}
+
+// NewPickFace creates a validated pick face setting
+func NewPickFace(productID, locationID, min, max int64) (*PickFace, error) {
+	if productID <= 0 || locationID <= 0 {
+		return nil, ErrInvalidPickFace
+	}
+	if min < 0 || max <= min {
+		return nil, ErrInvalidMinMax
+	}
+
+	return &PickFace{
+		ProductID:  productID,
+		LocationID: locationID,
+		Min:        min,
+		Max:        max,
+	}, nil
+}
+
+// Task moves stock of a SKU from a reserve location to its pick face
+type Task struct {
+	ID             int64
+	ProductID      int64
+	FromLocationID int64
+	ToLocationID   int64
+	Quantity       int64
+	Status         TaskStatus
+
+	// Reference ties the task to what triggered it, e.g. a wave
+	Reference string
+
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// NewTask creates an open replenishment task
+func NewTask(productID, fromLocationID, toLocationID, quantity int64, reference string) (*Task, error) {
+	if productID <= 0 || fromLocationID <= 0 || toLocationID <= 0 || fromLocationID == toLocationID || quantity <= 0 {
+		return nil, ErrInvalidTask
+	}
+
+	return &Task{
+		ProductID:      productID,
+		FromLocationID: fromLocationID,
+		ToLocationID:   toLocationID,
+		Quantity:       quantity,
+		Status:         TaskStatusOpen,
+		Reference:      reference,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// IsOpen reports whether the task still has to be carried out
+func (t *Task) IsOpen() bool {
+	return t.Status == TaskStatusOpen
+}
+
+// Complete marks the task as carried out
+func (t *Task) Complete() error {
+	if !t.IsOpen() {
+		return ErrTaskNotOpen
+	}
+	now := time.Now()
+	t.Status = TaskStatusCompleted
+	t.CompletedAt = &now
+	return nil
+}
+
+// Cancel drops the task without moving stock
+func (t *Task) Cancel() error {
+	if !t.IsOpen() {
+		return ErrTaskNotOpen
+	}
+	now := time.Now()
+	t.Status = TaskStatusCancelled
+	t.CompletedAt = &now
+	return nil
+}
@@ -0,0 +1,17 @@
+package replenishment
+
+import "errors"
+
+var (
+	ErrPickFaceNotFound = errors.New("pick face not found")
+	ErrInvalidPickFace  = errors.New("invalid pick face")
+	ErrInvalidMinMax    = errors.New("max must be greater than min and min cannot be negative")
+	ErrTaskNotFound     = errors.New("replenishment task not found")
+	ErrInvalidTask      = errors.New("invalid replenishment task")
+	ErrTaskNotOpen      = errors.New("replenishment task is not open")
+
+	ErrPolicyNotFound      = errors.New("reorder policy not found")
+	ErrInvalidPolicy       = errors.New("invalid reorder policy")
+	ErrInvalidReorderLevel = errors.New("reorder point cannot be below safety stock, safety stock cannot be negative and reorder quantity must be positive")
+	ErrAlertNotFound       = errors.New("stock alert not found")
+)
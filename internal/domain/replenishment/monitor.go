@@ -0,0 +1,213 @@
+package replenishment
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// Suggestion is a reorder recommendation for a policy whose stock is below its reorder point
+type Suggestion struct {
+	Policy       *ReorderPolicy
+	Available    int64
+	Severity     Severity
+	SuggestedQty int64
+}
+
+// Monitor compares available stock with reorder policies
+type Monitor struct {
+	reorderRepo  ReorderRepository
+	locationRepo location.Repository
+	stockRepo    stock.Repository
+}
+
+// NewMonitor creates a new stock level monitor
+func NewMonitor(reorderRepo ReorderRepository, locationRepo location.Repository, stockRepo stock.Repository) *Monitor {
+	return &Monitor{
+		reorderRepo:  reorderRepo,
+		locationRepo: locationRepo,
+		stockRepo:    stockRepo,
+	}
+}
+
+// level identifies the stock a policy watches: a SKU in one warehouse, or
+// everywhere when warehouse is empty
+type level struct {
+	productID int64
+	warehouse string
+}
+
+// Check compares a product's available stock with each of its policies. An
+// alert is raised when stock drops below the reorder point, kept up to date
+// while it stays there, and resolved once it recovers. The alerts raised or
+// escalated to a higher severity by this check are returned.
+func (m *Monitor) Check(ctx context.Context, productID int64) ([]*Alert, error) {
+	policies, err := m.reorderRepo.ListPolicies(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	movements, err := m.stockRepo.GetByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	byLocation := make(map[int64]int64)
+	for _, mv := range movements {
+		if mv.IsInbound() {
+			byLocation[mv.LocationID] += mv.Quantity
+		} else {
+			byLocation[mv.LocationID] -= mv.Quantity
+		}
+	}
+	balances := make([]stock.Balance, 0, len(byLocation))
+	for locationID, qty := range byLocation {
+		balances = append(balances, stock.Balance{ProductID: productID, LocationID: locationID, Quantity: qty})
+	}
+
+	levels, err := m.levels(ctx, balances)
+	if err != nil {
+		return nil, err
+	}
+
+	var raised []*Alert
+	now := time.Now()
+	for _, p := range policies {
+		available := levels[level{p.ProductID, p.Warehouse}]
+		severity := p.Severity(available)
+
+		alert, err := m.reorderRepo.OpenAlert(ctx, p.ProductID, p.Warehouse)
+		if err == ErrAlertNotFound {
+			alert = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		notify := false
+		switch {
+		case severity == "" && alert == nil:
+			continue
+		case severity == "":
+			alert.Available = available
+			alert.UpdatedAt = now
+			alert.ResolvedAt = &now
+		case alert == nil:
+			alert = &Alert{
+				ProductID: p.ProductID,
+				Warehouse: p.Warehouse,
+				RaisedAt:  now,
+			}
+			notify = true
+			fallthrough
+		default:
+			if alert.Severity == SeverityLow && severity == SeverityCritical {
+				notify = true
+			}
+			alert.Available = available
+			alert.ReorderPoint = p.ReorderPoint
+			alert.SafetyStock = p.SafetyStock
+			alert.Severity = severity
+			alert.UpdatedAt = now
+		}
+
+		if err := m.reorderRepo.SaveAlert(ctx, alert); err != nil {
+			return nil, err
+		}
+		if notify {
+			raised = append(raised, alert)
+		}
+	}
+
+	return raised, nil
+}
+
+// Suggestions lists every policy whose available stock is below its reorder
+// point with the quantity to order, most urgent first
+func (m *Monitor) Suggestions(ctx context.Context) ([]*Suggestion, error) {
+	policies, err := m.reorderRepo.ListPolicies(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	watched := make(map[int64]bool)
+	for _, p := range policies {
+		watched[p.ProductID] = true
+	}
+
+	all, err := m.stockRepo.SumBalances(ctx, stock.LedgerRange{})
+	if err != nil {
+		return nil, err
+	}
+	var balances []stock.Balance
+	for _, b := range all {
+		if watched[b.ProductID] {
+			balances = append(balances, b)
+		}
+	}
+
+	levels, err := m.levels(ctx, balances)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []*Suggestion
+	for _, p := range policies {
+		available := levels[level{p.ProductID, p.Warehouse}]
+		severity := p.Severity(available)
+		if severity == "" {
+			continue
+		}
+		suggestions = append(suggestions, &Suggestion{
+			Policy:       p,
+			Available:    available,
+			Severity:     severity,
+			SuggestedQty: p.SuggestedQuantity(available),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Severity != b.Severity {
+			return a.Severity == SeverityCritical
+		}
+		if a.Policy.ProductID != b.Policy.ProductID {
+			return a.Policy.ProductID < b.Policy.ProductID
+		}
+		return a.Policy.Warehouse < b.Policy.Warehouse
+	})
+
+	return suggestions, nil
+}
+
+// levels totals balances per product, both overall and per warehouse
+func (m *Monitor) levels(ctx context.Context, balances []stock.Balance) (map[level]int64, error) {
+	warehouses := make(map[int64]string)
+	levels := make(map[level]int64)
+
+	for _, b := range balances {
+		levels[level{b.ProductID, ""}] += b.Quantity
+
+		warehouse, ok := warehouses[b.LocationID]
+		if !ok {
+			loc, err := m.locationRepo.GetByID(ctx, b.LocationID)
+			if err != nil {
+				return nil, err
+			}
+			warehouse = loc.Warehouse
+			warehouses[b.LocationID] = warehouse
+		}
+		if warehouse != "" {
+			levels[level{b.ProductID, warehouse}] += b.Quantity
+		}
+	}
+
+	return levels, nil
+}
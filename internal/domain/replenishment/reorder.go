@@ -0,0 +1,87 @@
+package replenishment
+
+import "time"
+
+// Severity tells how urgent a low-stock alert is
+type Severity string
+
+const (
+	// SeverityLow means available stock is below the reorder point
+	SeverityLow Severity = "LOW"
+
+	// SeverityCritical means available stock is below safety stock
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// ReorderPolicy is the stock level a SKU should be reordered at, for one
+// warehouse or, with an empty Warehouse, across all of them
+type ReorderPolicy struct {
+	ProductID    int64
+	Warehouse    string
+	ReorderPoint int64
+	SafetyStock  int64
+	ReorderQty   int64
+}
+
+// NewReorderPolicy creates a validated reorder policy
+func NewReorderPolicy(productID int64, warehouse string, reorderPoint, safetyStock, reorderQty int64) (*ReorderPolicy, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidPolicy
+	}
+	if safetyStock < 0 || reorderPoint < safetyStock || reorderQty <= 0 {
+		return nil, ErrInvalidReorderLevel
+	}
+
+	return &ReorderPolicy{
+		ProductID:    productID,
+		Warehouse:    warehouse,
+		ReorderPoint: reorderPoint,
+		SafetyStock:  safetyStock,
+		ReorderQty:   reorderQty,
+	}, nil
+}
+
+// Severity returns how far below the policy the available stock is, or an
+// empty severity when it is at or above the reorder point
+func (p *ReorderPolicy) Severity(available int64) Severity {
+	switch {
+	case available < p.SafetyStock:
+		return SeverityCritical
+	case available < p.ReorderPoint:
+		return SeverityLow
+	default:
+		return ""
+	}
+}
+
+// SuggestedQuantity returns how much to order to get back to the reorder point,
+// in whole multiples of the reorder quantity
+func (p *ReorderPolicy) SuggestedQuantity(available int64) int64 {
+	if available >= p.ReorderPoint {
+		return 0
+	}
+
+	short := p.ReorderPoint - available
+	orders := (short + p.ReorderQty - 1) / p.ReorderQty
+	return orders * p.ReorderQty
+}
+
+// Alert records that a SKU's available stock dropped below its reorder point.
+// One alert stays open per policy until stock recovers.
+type Alert struct {
+	ID           int64
+	ProductID    int64
+	Warehouse    string
+	Available    int64
+	ReorderPoint int64
+	SafetyStock  int64
+	Severity     Severity
+	RaisedAt     time.Time
+	UpdatedAt    time.Time
+	ResolvedAt   *time.Time
+}
+
+// IsOpen reports whether stock is still below the reorder point
+func (a *Alert) IsOpen() bool {
+	return a.ResolvedAt == nil
+}
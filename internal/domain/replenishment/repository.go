@@ -0,0 +1,51 @@
+package replenishment
+
+import "context"
+
+// Repository defines the contract for pick face and replenishment task persistence
+type Repository interface {
+	// SavePickFace creates or replaces the min/max of a SKU at a pick location
+	SavePickFace(ctx context.Context, pf *PickFace) error
+
+	// ListPickFaces retrieves the pick faces of the given products, or all when productIDs is empty
+	ListPickFaces(ctx context.Context, productIDs []int64) ([]*PickFace, error)
+
+	// DeletePickFace removes a pick face setting
+	DeletePickFace(ctx context.Context, productID, locationID int64) error
+
+	// CreateTask saves a new task
+	CreateTask(ctx context.Context, t *Task) error
+
+	// GetTask retrieves a task by ID
+	GetTask(ctx context.Context, id int64) (*Task, error)
+
+	// UpdateTask saves a task's status
+	UpdateTask(ctx context.Context, t *Task) error
+
+	// ListTasks retrieves tasks, newest first, optionally by status
+	ListTasks(ctx context.Context, status TaskStatus, limit, offset int) ([]*Task, error)
+
+	// OpenTasks retrieves every open task
+	OpenTasks(ctx context.Context) ([]*Task, error)
+}
+
+// ReorderRepository defines the contract for reorder policy and stock alert persistence
+type ReorderRepository interface {
+	// SavePolicy creates or replaces the reorder policy of a SKU for a warehouse
+	SavePolicy(ctx context.Context, p *ReorderPolicy) error
+
+	// ListPolicies retrieves a product's policies, or all when productID is 0
+	ListPolicies(ctx context.Context, productID int64) ([]*ReorderPolicy, error)
+
+	// DeletePolicy removes a reorder policy
+	DeletePolicy(ctx context.Context, productID int64, warehouse string) error
+
+	// OpenAlert retrieves the open alert of a policy
+	OpenAlert(ctx context.Context, productID int64, warehouse string) (*Alert, error)
+
+	// SaveAlert inserts a new alert or updates an existing one
+	SaveAlert(ctx context.Context, a *Alert) error
+
+	// ListAlerts retrieves alerts, newest first, optionally only open ones
+	ListAlerts(ctx context.Context, openOnly bool, limit, offset int) ([]*Alert, error)
+}
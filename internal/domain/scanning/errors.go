@@ -0,0 +1,12 @@
+package scanning
+
+import "errors"
+
+var (
+	ErrUnrecognizedScan = errors.New("scan is neither GS1 data nor a known barcode")
+	ErrUnresolvedScan   = errors.New("scan does not identify a known product")
+	ErrProductMismatch  = errors.New("scan identifies a different product")
+	ErrLotMismatch      = errors.New("scanned lot differs from the lot given")
+	ErrLocationMismatch = errors.New("scanned location differs from the location given")
+	ErrScanningDisabled = errors.New("scanning is not configured")
+)
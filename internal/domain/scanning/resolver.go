@@ -0,0 +1,141 @@
+// Package scanning turns raw scanner input into the products, lots, containers
+// and locations it refers to
+package scanning
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// Resolution is what a scan refers to. Any part the scan does not identify,
+// or that is not on file, is left empty.
+type Resolution struct {
+	// Scan holds the parsed GS1 fields; a plain barcode yields only a GTIN
+	Scan *gs1.Scan
+
+	Product   *product.Product
+	Container *container.Tree
+
+	// Lot is the scanned lot, or the lot of a container's single content line
+	Lot string
+
+	// Count is the number of trade items a logistic unit label declares
+	Count int64
+}
+
+// Resolver looks scans up
+type Resolver struct {
+	productRepo      product.Repository
+	locationRepo     location.Repository
+	containerRepo    container.Repository
+	containerService *container.Service
+}
+
+// NewResolver creates a new scan resolver
+func NewResolver(productRepo product.Repository, locationRepo location.Repository) *Resolver {
+	return &Resolver{
+		productRepo:  productRepo,
+		locationRepo: locationRepo,
+	}
+}
+
+// WithContainers enables resolving SSCCs to license-plated containers
+func (r *Resolver) WithContainers(containerRepo container.Repository) *Resolver {
+	r.containerRepo = containerRepo
+	r.containerService = container.NewService(containerRepo)
+	return r
+}
+
+// Resolve parses raw and looks up what it refers to. GS1 data is parsed and
+// its GTIN and SSCC resolved; anything else must be a product barcode. It fails
+// with the gs1 errors for malformed GS1 data and with ErrUnrecognizedScan for
+// a code that is neither.
+func (r *Resolver) Resolve(ctx context.Context, raw string) (*Resolution, error) {
+	raw = strings.TrimSpace(raw)
+	if !gs1.LooksLikeElementString(raw) {
+		return r.resolveBarcode(ctx, raw)
+	}
+
+	scan, err := gs1.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	res := &Resolution{Scan: scan, Lot: scan.Lot, Count: scan.Count}
+
+	if gtin := scan.ItemGTIN(); gtin != "" {
+		if res.Product, err = r.findProduct(ctx, gtin); err != nil {
+			return nil, err
+		}
+	}
+
+	if scan.SSCC != "" && r.containerRepo != nil {
+		cont, err := r.containerRepo.GetBySSCC(ctx, scan.SSCC)
+		switch {
+		case err == nil:
+			if res.Container, err = r.containerService.LoadTree(ctx, cont); err != nil {
+				return nil, err
+			}
+		case err != container.ErrContainerNotFound:
+			return nil, err
+		}
+	}
+
+	// A label carrying only an SSCC still names the product when the
+	// container holds a single product and lot
+	if res.Product == nil && res.Container != nil && res.Container.IsActive() && len(res.Container.Contents) == 1 {
+		content := res.Container.Contents[0]
+		if res.Product, err = r.productRepo.GetByID(ctx, content.ProductID); err != nil {
+			return nil, err
+		}
+		if res.Lot == "" {
+			res.Lot = content.Lot
+		}
+	}
+
+	return res, nil
+}
+
+// resolveBarcode looks up a plain barcode. A valid GTIN is reported as such
+// even when no product carries it.
+func (r *Resolver) resolveBarcode(ctx context.Context, raw string) (*Resolution, error) {
+	if product.GTINForms(raw) != nil {
+		gtin := strings.Repeat("0", 14-len(raw)) + raw
+		prod, err := r.findProduct(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		return &Resolution{
+			Scan:    &gs1.Scan{Elements: []gs1.Element{{AI: "01", Value: gtin}}, GTIN: gtin},
+			Product: prod,
+		}, nil
+	}
+
+	prod, err := r.productRepo.GetByBarcode(ctx, raw)
+	if errors.Is(err, product.ErrProductNotFound) {
+		return nil, ErrUnrecognizedScan
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Resolution{Product: prod}, nil
+}
+
+// Location looks up the location whose code label was scanned
+func (r *Resolver) Location(ctx context.Context, raw string) (*location.Location, error) {
+	return r.locationRepo.GetByCode(ctx, strings.TrimSpace(raw))
+}
+
+// findProduct returns the product carrying gtin, or nil if none does
+func (r *Resolver) findProduct(ctx context.Context, gtin string) (*product.Product, error) {
+	prod, err := product.FindByGTIN(ctx, r.productRepo, gtin)
+	if errors.Is(err, product.ErrProductNotFound) {
+		return nil, nil
+	}
+	return prod, err
+}
@@ -0,0 +1,85 @@
+package serial
+
+import (
+	"strings"
+	"time"
+)
+
+// Status is where a serialized unit currently is in its life cycle
+type Status string
+
+const (
+	StatusInStock Status = "IN_STOCK"
+	StatusShipped Status = "SHIPPED"
+)
+
+// maxNumberLength is the maximum length of a serial number
+const maxNumberLength = 100
+
+// Serial is one individually tracked unit of a serialized product
+type Serial struct {
+	ID         int64
+	ProductID  int64
+	Number     string
+	LocationID int64
+	Status     Status
+	UpdatedAt  time.Time
+}
+
+// Event is one entry in a serial's history, written for every movement it takes part in
+type Event struct {
+	ID           int64
+	SerialID     int64
+	MovementID   int64
+	MovementType string
+	LocationID   int64
+	Status       Status
+	CreatedAt    time.Time
+}
+
+// NormalizeNumber trims a serial number and checks its length
+func NormalizeNumber(number string) (string, error) {
+	number = strings.TrimSpace(number)
+	if number == "" || len(number) > maxNumberLength {
+		return "", ErrInvalidSerial
+	}
+	return number, nil
+}
+
+// NewSerial creates a serial that has not been received yet
+func NewSerial(productID int64, number string) (*Serial, error) {
+	number, err := NormalizeNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	if productID <= 0 {
+		return nil, ErrInvalidSerial
+	}
+
+	return &Serial{
+		ProductID: productID,
+		Number:    number,
+	}, nil
+}
+
+// Receive puts the serial in stock at a location. A shipped serial may come back, e.g. on a return.
+func (s *Serial) Receive(locationID int64) error {
+	if s.Status == StatusInStock {
+		return ErrSerialInStock
+	}
+	s.Status = StatusInStock
+	s.LocationID = locationID
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Ship takes the serial out of stock at a location
+func (s *Serial) Ship(locationID int64) error {
+	if s.Status != StatusInStock || s.LocationID != locationID {
+		return ErrSerialNotAtLocation
+	}
+	s.Status = StatusShipped
+	s.LocationID = 0
+	s.UpdatedAt = time.Now()
+	return nil
+}
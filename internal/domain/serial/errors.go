@@ -0,0 +1,10 @@
+package serial
+
+import "errors"
+
+var (
+	ErrSerialNotFound      = errors.New("serial not found")
+	ErrInvalidSerial       = errors.New("invalid serial number")
+	ErrSerialInStock       = errors.New("serial is already in stock")
+	ErrSerialNotAtLocation = errors.New("serial is not in stock at this location")
+)
@@ -0,0 +1,27 @@
+package serial
+
+import "context"
+
+// Filter narrows a serial listing; zero fields are ignored
+type Filter struct {
+	LocationID int64
+	Status     Status
+}
+
+// Repository defines the contract for serial persistence
+type Repository interface {
+	// GetByNumber retrieves a product's serial by number
+	GetByNumber(ctx context.Context, productID int64, number string) (*Serial, error)
+
+	// Save inserts a new serial or updates an existing one
+	Save(ctx context.Context, s *Serial) error
+
+	// AddEvent appends an entry to a serial's history
+	AddEvent(ctx context.Context, e *Event) error
+
+	// List retrieves a product's serials
+	List(ctx context.Context, productID int64, filter Filter, limit, offset int) ([]*Serial, error)
+
+	// History retrieves a serial's events, oldest first
+	History(ctx context.Context, serialID int64) ([]*Event, error)
+}
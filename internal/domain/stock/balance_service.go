@@ -0,0 +1,120 @@
+package stock
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// BalanceService rebuilds stock balances from the movement ledger
+type BalanceService struct {
+	stockRepo    Repository
+	snapshotRepo SnapshotRepository
+}
+
+// NewBalanceService creates a new balance service. The snapshot repository is
+// optional; without it every rebuild replays the whole ledger.
+func NewBalanceService(stockRepo Repository, snapshotRepo SnapshotRepository) *BalanceService {
+	return &BalanceService{
+		stockRepo:    stockRepo,
+		snapshotRepo: snapshotRepo,
+	}
+}
+
+// BalancesAsOf returns the non-zero balances per product and location at the given moment
+func (s *BalanceService) BalancesAsOf(ctx context.Context, at time.Time) ([]Balance, error) {
+	var base []Balance
+	var afterSequence int64
+
+	if s.snapshotRepo != nil {
+		snapshot, err := s.snapshotRepo.LatestBefore(ctx, at)
+		if err != nil && err != ErrSnapshotNotFound {
+			return nil, err
+		}
+		if snapshot != nil {
+			base = snapshot.Balances
+			afterSequence = snapshot.LastSequence
+		}
+	}
+
+	delta, err := s.stockRepo.SumBalances(ctx, LedgerRange{AfterSequence: afterSequence, Until: at})
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeBalances(base, delta), nil
+}
+
+// TakeSnapshot captures the current balances so later rebuilds can start from here
+func (s *BalanceService) TakeSnapshot(ctx context.Context) (*Snapshot, error) {
+	if s.snapshotRepo == nil {
+		return nil, ErrSnapshotNotFound
+	}
+
+	// Every movement up to the ledger head has committed, since sequences are
+	// handed out under the head's lock and only become visible with their movement
+	lastSequence, err := s.stockRepo.LastSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var base []Balance
+	var afterSequence int64
+
+	previous, err := s.snapshotRepo.LatestBefore(ctx, time.Now())
+	if err != nil && err != ErrSnapshotNotFound {
+		return nil, err
+	}
+	if previous != nil {
+		base = previous.Balances
+		afterSequence = previous.LastSequence
+	}
+
+	// Bound the delta by sequence so movements recorded while we read are left to the next snapshot
+	delta, err := s.stockRepo.SumBalances(ctx, LedgerRange{AfterSequence: afterSequence, ThroughSequence: lastSequence})
+	if err != nil {
+		return nil, err
+	}
+	balances := mergeBalances(base, delta)
+
+	snapshot := &Snapshot{
+		TakenAt:      time.Now(),
+		LastSequence: lastSequence,
+		Balances:     balances,
+	}
+
+	if err := s.snapshotRepo.Save(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// mergeBalances adds two balance sets together, dropping zero results
+func mergeBalances(sets ...[]Balance) []Balance {
+	type key struct{ productID, locationID int64 }
+
+	totals := make(map[key]int64)
+	for _, set := range sets {
+		for _, b := range set {
+			totals[key{b.ProductID, b.LocationID}] += b.Quantity
+		}
+	}
+
+	result := make([]Balance, 0, len(totals))
+	for k, qty := range totals {
+		if qty == 0 {
+			continue
+		}
+		result = append(result, Balance{ProductID: k.productID, LocationID: k.locationID, Quantity: qty})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ProductID != result[j].ProductID {
+			return result[i].ProductID < result[j].ProductID
+		}
+		return result[i].LocationID < result[j].LocationID
+	})
+
+	return result
+}
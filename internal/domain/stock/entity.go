@@ -3,6 +3,9 @@ package stock
 import (
 	"errors"
 	"time"
+	"unicode/utf8"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
 )
 
 // MovementType represents the type of stock movement
@@ -13,14 +16,64 @@ const (
 	MovementTypeOUT MovementType = "OUT"
 )
 
-// StockMovement is the aggregate root for stock movement domain
+// ReferenceType represents the kind of business document a movement belongs to
+type ReferenceType string
+
+const (
+	ReferenceTypePO       ReferenceType = "PO"
+	ReferenceTypeOrder    ReferenceType = "ORDER"
+	ReferenceTypeRMA      ReferenceType = "RMA"
+	ReferenceTypeTransfer ReferenceType = "TRANSFER"
+	ReferenceTypeCount    ReferenceType = "COUNT"
+
+	// ReferenceTypeAdjustment marks corrections posted by the system, e.g. by reconciliation
+	ReferenceTypeAdjustment ReferenceType = "ADJUSTMENT"
+
+	// ReferenceTypeReversal marks a movement that cancels out an earlier one,
+	// whose ID is the reference ID
+	ReferenceTypeReversal ReferenceType = "REVERSAL"
+)
+
+// maxNotesLength is the maximum number of characters allowed in movement notes
+const maxNotesLength = 1000
+
+// IsValid checks if the reference type is one of the supported values
+func (rt ReferenceType) IsValid() bool {
+	switch rt {
+	case ReferenceTypePO, ReferenceTypeOrder, ReferenceTypeRMA, ReferenceTypeTransfer, ReferenceTypeCount,
+		ReferenceTypeAdjustment, ReferenceTypeReversal:
+		return true
+	}
+	return false
+}
+
+// StockMovement is the aggregate root for stock movement domain. Movements form an
+// append-only ledger; Sequence is their gapless position in commit order.
 type StockMovement struct {
 	ID         int64
+	Sequence   int64
 	ProductID  int64
 	LocationID int64
 	Type       MovementType
 	Quantity   int64
 	CreatedAt  time.Time
+
+	// Optional business document references
+	ReferenceType  ReferenceType
+	ReferenceID    string
+	DocumentNumber string
+	Notes          string
+
+	// Quantity as entered, when it was given in a pack level other than EACH.
+	// Quantity itself always holds base units once the movement is recorded.
+	EnteredUOM      product.PackLevel
+	EnteredQuantity int64
+
+	// Serials lists the individual units moved; required for serialized products
+	Serials []string
+
+	// Lot is the batch the units belong to, empty when not lot-tracked
+	Lot string
 }
 
 // NewStockMovement creates a new stock movement
@@ -47,6 +100,14 @@ func NewStockMovement(productID, locationID int64, movementType MovementType, qu
 	}, nil
 }
 
+// SignedQuantity returns the quantity as a balance change: positive for IN, negative for OUT
+func (sm *StockMovement) SignedQuantity() int64 {
+	if sm.IsOutbound() {
+		return -sm.Quantity
+	}
+	return sm.Quantity
+}
+
 // IsInbound checks if movement is inbound
 func (sm *StockMovement) IsInbound() bool {
 	return sm.Type == MovementTypeIN
@@ -56,3 +117,52 @@ func (sm *StockMovement) IsInbound() bool {
 func (sm *StockMovement) IsOutbound() bool {
 	return sm.Type == MovementTypeOUT
 }
+
+// SetReference attaches business document references and notes to the movement
+func (sm *StockMovement) SetReference(referenceType ReferenceType, referenceID, documentNumber, notes string) error {
+	if referenceType != "" && !referenceType.IsValid() {
+		return ErrInvalidReferenceType
+	}
+	if referenceID != "" && referenceType == "" {
+		return ErrMissingReferenceType
+	}
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return ErrNotesTooLong
+	}
+
+	sm.ReferenceType = referenceType
+	sm.ReferenceID = referenceID
+	sm.DocumentNumber = documentNumber
+	sm.Notes = notes
+	return nil
+}
+
+// SetUOM marks the movement quantity as expressed in the given pack level.
+// The quantity is converted to base units when the movement is recorded.
+func (sm *StockMovement) SetUOM(level product.PackLevel) error {
+	if level != "" && !level.IsValid() {
+		return product.ErrInvalidPackLevel
+	}
+	if level == product.PackLevelEach {
+		level = ""
+	}
+	sm.EnteredUOM = level
+	return nil
+}
+
+// NormalizeQuantity converts a quantity entered in a pack level to base units
+// using the product's pack hierarchy. Calling it again is a no-op.
+func (sm *StockMovement) NormalizeQuantity(p *product.Product) error {
+	if sm.EnteredUOM == "" || sm.EnteredQuantity != 0 {
+		return nil
+	}
+
+	base, err := p.ToBaseUnits(sm.EnteredUOM, sm.Quantity)
+	if err != nil {
+		return err
+	}
+
+	sm.EnteredQuantity = sm.Quantity
+	sm.Quantity = base
+	return nil
+}
@@ -3,11 +3,20 @@ package stock
 import "errors"
 
 var (
-	ErrMovementNotFound    = errors.New("stock movement not found")
-	ErrInvalidProductID    = errors.New("invalid product ID")
-	ErrInvalidLocationID   = errors.New("invalid location ID")
-	ErrInvalidMovementType = errors.New("invalid movement type")
-	ErrInvalidQuantity     = errors.New("invalid quantity")
-	ErrInsufficientStock   = errors.New("insufficient stock for outbound movement")
-	ErrCapacityExceeded    = errors.New("location capacity exceeded for inbound movement")
+	ErrMovementNotFound      = errors.New("stock movement not found")
+	ErrInvalidProductID      = errors.New("invalid product ID")
+	ErrInvalidLocationID     = errors.New("invalid location ID")
+	ErrInvalidMovementType   = errors.New("invalid movement type")
+	ErrInvalidQuantity       = errors.New("invalid quantity")
+	ErrInsufficientStock     = errors.New("insufficient stock for outbound movement")
+	ErrCapacityExceeded      = errors.New("location capacity exceeded for inbound movement")
+	ErrInvalidReferenceType  = errors.New("invalid reference type")
+	ErrMissingReferenceType  = errors.New("reference type is required when reference ID is set")
+	ErrNotesTooLong          = errors.New("notes exceed maximum length")
+	ErrSnapshotNotFound      = errors.New("stock snapshot not found")
+	ErrSerialCountMismatch   = errors.New("serialized products need exactly one serial per unit moved")
+	ErrDuplicateSerial       = errors.New("serial listed more than once")
+	ErrSerialsNotAllowed     = errors.New("serials can only be given for serialized products")
+	ErrAlreadyReversed       = errors.New("stock movement has already been reversed")
+	ErrReversalNotReversible = errors.New("a reversal cannot itself be reversed")
 )
@@ -0,0 +1,322 @@
+package stock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// replayBatchSize is the number of movements loaded per batch while rebuilding
+const replayBatchSize = 1000
+
+// Projection names
+const (
+	ProjectionProductTotals    = "product_totals"
+	ProjectionLocationBalances = "location_balances"
+	ProjectionLotBalances      = "lot_balances"
+)
+
+// LotBalance is the net quantity of one lot of a product held at a location
+type LotBalance struct {
+	ProductID  int64
+	LocationID int64
+	Lot        string
+	Quantity   int64
+}
+
+// Projection is a read model derived from the movement ledger. Apply receives
+// movements in sequence order; Reset empties the read model before a replay.
+type Projection interface {
+	Name() string
+	Reset(ctx context.Context) error
+	Apply(ctx context.Context, movements []*StockMovement) error
+}
+
+// ProductTotalsProjection keeps each product's stored quantity equal to its ledger total
+type ProductTotalsProjection struct {
+	productRepo product.Repository
+}
+
+// NewProductTotalsProjection creates a new product totals projection
+func NewProductTotalsProjection(productRepo product.Repository) *ProductTotalsProjection {
+	return &ProductTotalsProjection{productRepo: productRepo}
+}
+
+// Name returns the projection name
+func (p *ProductTotalsProjection) Name() string {
+	return ProjectionProductTotals
+}
+
+// Reset sets every product quantity to zero
+func (p *ProductTotalsProjection) Reset(ctx context.Context) error {
+	spec := product.ListSpec{Limit: reconcilePageSize}
+	for {
+		products, err := p.productRepo.List(ctx, spec)
+		if err != nil {
+			return err
+		}
+
+		for _, prod := range products {
+			if prod.Quantity == 0 {
+				continue
+			}
+			prod.Quantity = 0
+			if err := p.productRepo.UpdateQuantity(ctx, prod); err != nil {
+				return err
+			}
+		}
+
+		if len(products) < reconcilePageSize {
+			return nil
+		}
+		spec.After = listing.Keys(products[len(products)-1], product.DefaultOrder, product.SortValue)
+	}
+}
+
+// Apply adds the net quantity of the movements to each product's total
+func (p *ProductTotalsProjection) Apply(ctx context.Context, movements []*StockMovement) error {
+	deltas := make(map[int64]int64)
+	var productIDs []int64
+	for _, m := range movements {
+		if _, ok := deltas[m.ProductID]; !ok {
+			productIDs = append(productIDs, m.ProductID)
+		}
+		deltas[m.ProductID] += m.SignedQuantity()
+	}
+
+	for _, productID := range productIDs {
+		delta := deltas[productID]
+		if delta == 0 {
+			continue
+		}
+
+		prod, err := p.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		if delta > 0 {
+			err = prod.IncreaseStock(delta)
+		} else {
+			err = prod.DecreaseStock(-delta)
+		}
+		if err != nil {
+			return fmt.Errorf("product %d: %w", productID, err)
+		}
+
+		if err := p.productRepo.UpdateQuantity(ctx, prod); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LocationBalancesProjection keeps the net quantity per product and location
+type LocationBalancesProjection struct {
+	repo ProjectionRepository
+}
+
+// NewLocationBalancesProjection creates a new location balances projection
+func NewLocationBalancesProjection(repo ProjectionRepository) *LocationBalancesProjection {
+	return &LocationBalancesProjection{repo: repo}
+}
+
+// Name returns the projection name
+func (p *LocationBalancesProjection) Name() string {
+	return ProjectionLocationBalances
+}
+
+// Reset removes every location balance
+func (p *LocationBalancesProjection) Reset(ctx context.Context) error {
+	return p.repo.ResetBalances(ctx)
+}
+
+// Apply adds the net quantity of the movements to each product and location
+func (p *LocationBalancesProjection) Apply(ctx context.Context, movements []*StockMovement) error {
+	type key struct{ productID, locationID int64 }
+
+	totals := make(map[key]int64)
+	for _, m := range movements {
+		totals[key{m.ProductID, m.LocationID}] += m.SignedQuantity()
+	}
+
+	deltas := make([]Balance, 0, len(totals))
+	for k, qty := range totals {
+		if qty != 0 {
+			deltas = append(deltas, Balance{ProductID: k.productID, LocationID: k.locationID, Quantity: qty})
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	// A fixed order keeps concurrent upserts from deadlocking on each other
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].ProductID != deltas[j].ProductID {
+			return deltas[i].ProductID < deltas[j].ProductID
+		}
+		return deltas[i].LocationID < deltas[j].LocationID
+	})
+
+	return p.repo.AddBalances(ctx, deltas)
+}
+
+// LotBalancesProjection keeps the net quantity per product, location and lot
+type LotBalancesProjection struct {
+	repo ProjectionRepository
+}
+
+// NewLotBalancesProjection creates a new lot balances projection
+func NewLotBalancesProjection(repo ProjectionRepository) *LotBalancesProjection {
+	return &LotBalancesProjection{repo: repo}
+}
+
+// Name returns the projection name
+func (p *LotBalancesProjection) Name() string {
+	return ProjectionLotBalances
+}
+
+// Reset removes every lot balance
+func (p *LotBalancesProjection) Reset(ctx context.Context) error {
+	return p.repo.ResetLotBalances(ctx)
+}
+
+// Apply adds the net quantity of the movements to each product, location and lot.
+// Movements without a lot are left out.
+func (p *LotBalancesProjection) Apply(ctx context.Context, movements []*StockMovement) error {
+	type key struct {
+		productID, locationID int64
+		lot                   string
+	}
+
+	totals := make(map[key]int64)
+	for _, m := range movements {
+		if m.Lot == "" {
+			continue
+		}
+		totals[key{m.ProductID, m.LocationID, m.Lot}] += m.SignedQuantity()
+	}
+
+	deltas := make([]LotBalance, 0, len(totals))
+	for k, qty := range totals {
+		if qty != 0 {
+			deltas = append(deltas, LotBalance{ProductID: k.productID, LocationID: k.locationID, Lot: k.lot, Quantity: qty})
+		}
+	}
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := deltas[i], deltas[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if a.LocationID != b.LocationID {
+			return a.LocationID < b.LocationID
+		}
+		return a.Lot < b.Lot
+	})
+
+	return p.repo.AddLotBalances(ctx, deltas)
+}
+
+// RebuildReport is the outcome of regenerating the projections from the ledger
+type RebuildReport struct {
+	Projections     []string
+	Movements       int
+	ThroughSequence int64
+	StartedAt       time.Time
+	FinishedAt      time.Time
+}
+
+// Projector feeds ledger movements to a set of projections
+type Projector struct {
+	stockRepo   Repository
+	projections []Projection
+}
+
+// NewProjector creates a new projector
+func NewProjector(stockRepo Repository, projections ...Projection) *Projector {
+	return &Projector{
+		stockRepo:   stockRepo,
+		projections: projections,
+	}
+}
+
+// Add registers more projections
+func (p *Projector) Add(projections ...Projection) {
+	p.projections = append(p.projections, projections...)
+}
+
+// Names returns the names of the registered projections
+func (p *Projector) Names() []string {
+	names := make([]string, 0, len(p.projections))
+	for _, projection := range p.projections {
+		names = append(names, projection.Name())
+	}
+	return names
+}
+
+// Apply passes newly recorded movements to every projection
+func (p *Projector) Apply(ctx context.Context, movements ...*StockMovement) error {
+	for _, projection := range p.projections {
+		if err := projection.Apply(ctx, movements); err != nil {
+			return fmt.Errorf("projection %s: %w", projection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Rebuild empties every projection and replays the whole ledger into them. It
+// should run in a transaction so movements recorded meanwhile wait for it.
+func (p *Projector) Rebuild(ctx context.Context) (*RebuildReport, error) {
+	report := &RebuildReport{
+		Projections: p.Names(),
+		StartedAt:   time.Now(),
+	}
+
+	through, err := p.stockRepo.LastSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.ThroughSequence = through
+
+	for _, projection := range p.projections {
+		if err := projection.Reset(ctx); err != nil {
+			return nil, fmt.Errorf("projection %s: %w", projection.Name(), err)
+		}
+	}
+
+	for after := int64(0); after < through; {
+		batch, err := p.stockRepo.Replay(ctx, after, replayBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		// Movements past the starting position belong to the live path
+		for len(batch) > 0 && batch[len(batch)-1].Sequence > through {
+			batch = batch[:len(batch)-1]
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		if err := p.Apply(ctx, batch...); err != nil {
+			return nil, err
+		}
+		report.Movements += len(batch)
+		after = batch[len(batch)-1].Sequence
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
@@ -0,0 +1,341 @@
+package stock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+)
+
+// reconcilePageSize is the number of products loaded per page while reconciling
+const reconcilePageSize = 500
+
+// DiscrepancyScope tells whether a discrepancy concerns a product total or a single location
+type DiscrepancyScope string
+
+const (
+	DiscrepancyScopeProduct  DiscrepancyScope = "PRODUCT"
+	DiscrepancyScopeLocation DiscrepancyScope = "LOCATION"
+)
+
+// Discrepancy describes a stored balance that disagrees with the movement ledger
+type Discrepancy struct {
+	Scope      DiscrepancyScope
+	ProductID  int64
+	LocationID int64
+	Stored     int64
+	Ledger     int64
+	Reason     string
+	Repaired   bool
+}
+
+// Difference returns how far the stored value is from the ledger
+func (d Discrepancy) Difference() int64 {
+	return d.Stored - d.Ledger
+}
+
+// Repair is the audit record of a stored value a reconciliation run reset to the ledger
+type Repair struct {
+	ID         int64
+	Scope      DiscrepancyScope
+	ProductID  int64
+	LocationID int64
+	Stored     int64
+	Ledger     int64
+	Reason     string
+	RepairedAt time.Time
+}
+
+// ReconciliationReport is the outcome of a reconciliation run
+type ReconciliationReport struct {
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	ProductsChecked  int
+	LocationsChecked int
+	Discrepancies    []Discrepancy
+}
+
+// ReconcileOptions controls whether discrepancies are repaired
+type ReconcileOptions struct {
+	// Repair resets drifted product totals and location balances to what the
+	// ledger says. The ledger itself is never changed.
+	Repair bool
+}
+
+// Reconciler compares stored quantities and snapshots against the movement ledger.
+// The ledger is the source of truth; everything else is checked against it.
+type Reconciler struct {
+	productRepo  product.Repository
+	stockRepo    Repository
+	snapshotRepo SnapshotRepository
+	balanceRepo  ProjectionRepository
+	repairRepo   RepairRepository
+}
+
+// NewReconciler creates a new reconciler. The snapshot repository is optional;
+// without it location balances are only checked for negative stock.
+func NewReconciler(productRepo product.Repository, stockRepo Repository, snapshotRepo SnapshotRepository) *Reconciler {
+	return &Reconciler{
+		productRepo:  productRepo,
+		stockRepo:    stockRepo,
+		snapshotRepo: snapshotRepo,
+	}
+}
+
+// WithBalances also checks, and repairs, the per-location balance projection
+func (r *Reconciler) WithBalances(balanceRepo ProjectionRepository) *Reconciler {
+	r.balanceRepo = balanceRepo
+	return r
+}
+
+// WithRepairLog writes every repair to the audit trail, in the run's transaction
+func (r *Reconciler) WithRepairLog(repairRepo RepairRepository) *Reconciler {
+	r.repairRepo = repairRepo
+	return r
+}
+
+// Reconcile checks every product and location balance against the ledger
+func (r *Reconciler) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconciliationReport, error) {
+	report := &ReconciliationReport{StartedAt: time.Now()}
+
+	// Repairs write what the ledger says now, so hold back new movements until
+	// the run's transaction ends
+	if opts.Repair {
+		if _, err := r.stockRepo.LastSequence(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	balances, err := r.stockRepo.SumBalances(ctx, LedgerRange{})
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerTotals := make(map[int64]int64)
+	for _, b := range balances {
+		ledgerTotals[b.ProductID] += b.Quantity
+	}
+
+	// Product totals against the stored aggregate
+	spec := product.ListSpec{Limit: reconcilePageSize}
+	for {
+		products, err := r.productRepo.List(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range products {
+			report.ProductsChecked++
+			ledger := ledgerTotals[p.ID]
+			if p.Quantity == ledger {
+				continue
+			}
+
+			d := Discrepancy{
+				Scope:     DiscrepancyScopeProduct,
+				ProductID: p.ID,
+				Stored:    p.Quantity,
+				Ledger:    ledger,
+				Reason:    "stored quantity differs from ledger",
+			}
+			if opts.Repair {
+				p.Quantity = ledger
+				if err := r.productRepo.UpdateQuantity(ctx, p); err != nil {
+					d.Reason += "; not repaired: " + err.Error()
+				} else {
+					d.Repaired = true
+					if err := r.logRepair(ctx, d); err != nil {
+						return nil, err
+					}
+				}
+			}
+			report.Discrepancies = append(report.Discrepancies, d)
+		}
+
+		if len(products) < reconcilePageSize {
+			break
+		}
+		spec.After = listing.Keys(products[len(products)-1], product.DefaultOrder, product.SortValue)
+	}
+
+	// Location balances can never be negative
+	for _, b := range balances {
+		report.LocationsChecked++
+		if b.Quantity < 0 {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				Scope:      DiscrepancyScopeLocation,
+				ProductID:  b.ProductID,
+				LocationID: b.LocationID,
+				Stored:     0,
+				Ledger:     b.Quantity,
+				Reason:     "negative location balance",
+			})
+		}
+	}
+
+	balanceDiscrepancies, err := r.checkBalances(ctx, balances, opts.Repair)
+	if err != nil {
+		return nil, err
+	}
+	report.Discrepancies = append(report.Discrepancies, balanceDiscrepancies...)
+
+	snapshotDiscrepancies, err := r.checkLatestSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Discrepancies = append(report.Discrepancies, snapshotDiscrepancies...)
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// checkBalances compares the location balance projection with the ledger and,
+// when repairing, adds the difference so the projection matches the ledger
+func (r *Reconciler) checkBalances(ctx context.Context, ledgerBalances []Balance, repair bool) ([]Discrepancy, error) {
+	if r.balanceRepo == nil {
+		return nil, nil
+	}
+
+	stored, err := r.balanceRepo.ListBalances(ctx, BalanceFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ productID, locationID int64 }
+	ledger := make(map[key]int64)
+	for _, b := range ledgerBalances {
+		ledger[key{b.ProductID, b.LocationID}] = b.Quantity
+	}
+	projected := make(map[key]int64)
+	for _, b := range stored {
+		projected[key{b.ProductID, b.LocationID}] = b.Quantity
+	}
+
+	var discrepancies []Discrepancy
+	var fixes []Balance
+	check := func(k key) {
+		if projected[k] == ledger[k] {
+			return
+		}
+		discrepancies = append(discrepancies, Discrepancy{
+			Scope:      DiscrepancyScopeLocation,
+			ProductID:  k.productID,
+			LocationID: k.locationID,
+			Stored:     projected[k],
+			Ledger:     ledger[k],
+			Reason:     "stored location balance differs from ledger",
+		})
+		fixes = append(fixes, Balance{ProductID: k.productID, LocationID: k.locationID, Quantity: ledger[k] - projected[k]})
+	}
+	for _, b := range ledgerBalances {
+		check(key{b.ProductID, b.LocationID})
+	}
+	for _, b := range stored {
+		k := key{b.ProductID, b.LocationID}
+		if _, ok := ledger[k]; !ok {
+			check(k)
+		}
+	}
+
+	if repair && len(fixes) > 0 {
+		// A fixed order keeps concurrent upserts from deadlocking on each other
+		sort.Slice(fixes, func(i, j int) bool {
+			if fixes[i].ProductID != fixes[j].ProductID {
+				return fixes[i].ProductID < fixes[j].ProductID
+			}
+			return fixes[i].LocationID < fixes[j].LocationID
+		})
+		if err := r.balanceRepo.AddBalances(ctx, fixes); err != nil {
+			for i := range discrepancies {
+				discrepancies[i].Reason += "; not repaired: " + err.Error()
+			}
+		} else {
+			for i := range discrepancies {
+				discrepancies[i].Repaired = true
+				if err := r.logRepair(ctx, discrepancies[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// checkLatestSnapshot replays the ledger up to the latest snapshot and compares
+// the result per location, which reveals movements changed after the fact
+func (r *Reconciler) checkLatestSnapshot(ctx context.Context) ([]Discrepancy, error) {
+	if r.snapshotRepo == nil {
+		return nil, nil
+	}
+
+	snapshot, err := r.snapshotRepo.LatestBefore(ctx, time.Now())
+	if err == ErrSnapshotNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	replayed, err := r.stockRepo.SumBalances(ctx, LedgerRange{ThroughSequence: snapshot.LastSequence})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ productID, locationID int64 }
+	ledger := make(map[key]int64)
+	for _, b := range replayed {
+		ledger[key{b.ProductID, b.LocationID}] = b.Quantity
+	}
+
+	var discrepancies []Discrepancy
+	seen := make(map[key]bool)
+	for _, b := range snapshot.Balances {
+		k := key{b.ProductID, b.LocationID}
+		seen[k] = true
+		if ledger[k] != b.Quantity {
+			discrepancies = append(discrepancies, Discrepancy{
+				Scope:      DiscrepancyScopeLocation,
+				ProductID:  b.ProductID,
+				LocationID: b.LocationID,
+				Stored:     b.Quantity,
+				Ledger:     ledger[k],
+				Reason:     fmt.Sprintf("snapshot %d differs from ledger replay", snapshot.ID),
+			})
+		}
+	}
+	for k, qty := range ledger {
+		if !seen[k] && qty != 0 {
+			discrepancies = append(discrepancies, Discrepancy{
+				Scope:      DiscrepancyScopeLocation,
+				ProductID:  k.productID,
+				LocationID: k.locationID,
+				Stored:     0,
+				Ledger:     qty,
+				Reason:     fmt.Sprintf("snapshot %d differs from ledger replay", snapshot.ID),
+			})
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// logRepair writes a repair to the audit trail when one is configured. A failure
+// fails the run, so no repair is left unrecorded.
+func (r *Reconciler) logRepair(ctx context.Context, d Discrepancy) error {
+	if r.repairRepo == nil {
+		return nil
+	}
+	return r.repairRepo.Record(ctx, &Repair{
+		Scope:      d.Scope,
+		ProductID:  d.ProductID,
+		LocationID: d.LocationID,
+		Stored:     d.Stored,
+		Ledger:     d.Ledger,
+		Reason:     d.Reason,
+		RepairedAt: time.Now(),
+	})
+}
@@ -1,6 +1,58 @@
 package stock
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+)
+
+// Filter narrows down stock movement listings. Zero values are ignored.
+type Filter struct {
+	ProductID      int64
+	LocationID     int64
+	Type           MovementType
+	ReferenceType  ReferenceType
+	ReferenceID    string
+	DocumentNumber string
+	Quantity       listing.Int64Range
+	CreatedAt      listing.TimeRange
+}
+
+// SortFields are the fields movement listings can be sorted by
+var SortFields = []string{"id", "sequence", "created_at", "product_id", "location_id", "quantity"}
+
+// DefaultOrder lists the most recent movements first
+var DefaultOrder = []listing.Sort{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}
+
+// SortValue returns a movement's value of one of the SortFields
+func SortValue(sm *StockMovement, field string) interface{} {
+	switch field {
+	case "sequence":
+		return sm.Sequence
+	case "created_at":
+		return sm.CreatedAt
+	case "product_id":
+		return sm.ProductID
+	case "location_id":
+		return sm.LocationID
+	case "quantity":
+		return sm.Quantity
+	default:
+		return sm.ID
+	}
+}
+
+// ListSpec selects a page of stock movements. Search looks at the reference ID,
+// document number, notes and lot.
+type ListSpec = listing.Spec[Filter]
+
+// LedgerRange selects a slice of the movement ledger. Zero values are unbounded.
+type LedgerRange struct {
+	AfterSequence   int64
+	ThroughSequence int64
+	Until           time.Time
+}
 
 // Repository defines the contract for stock movement persistence
 type Repository interface {
@@ -16,9 +68,68 @@ type Repository interface {
 	// GetByLocation retrieves all movements for a location
 	GetByLocation(ctx context.Context, locationID int64) ([]*StockMovement, error)
 
-	// List retrieves all stock movements with pagination
-	List(ctx context.Context, limit, offset int) ([]*StockMovement, error)
+	// List retrieves the movements the spec selects, newest first unless it sorts otherwise
+	List(ctx context.Context, spec ListSpec) ([]*StockMovement, error)
+
+	// Count returns how many movements match the spec's filter and search
+	Count(ctx context.Context, spec ListSpec) (int64, error)
+
+	// Each calls fn for every movement matching the filter, in sequence order,
+	// reading them as fn consumes them. It stops at the first error fn returns.
+	Each(ctx context.Context, filter Filter, fn func(*StockMovement) error) error
+
+	// SumBalances returns net quantities per product and location for the
+	// movements inside the given ledger range
+	SumBalances(ctx context.Context, r LedgerRange) ([]Balance, error)
+
+	// Replay returns up to limit movements with a sequence above afterSequence,
+	// in sequence order
+	Replay(ctx context.Context, afterSequence int64, limit int) ([]*StockMovement, error)
+
+	// LastSequence returns the sequence of the newest movement, or 0 if the ledger
+	// is empty. Inside a transaction it also holds back new movements until commit.
+	LastSequence(ctx context.Context) (int64, error)
+}
+
+// BalanceFilter narrows down projected balance listings. Zero values are ignored.
+type BalanceFilter struct {
+	ProductID  int64
+	LocationID int64
+	Lot        string
+}
+
+// ProjectionRepository defines the contract for the balance read models built from the ledger
+type ProjectionRepository interface {
+	// AddBalances adds the given quantities to the per-location balances
+	AddBalances(ctx context.Context, deltas []Balance) error
+
+	// AddLotBalances adds the given quantities to the per-lot balances
+	AddLotBalances(ctx context.Context, deltas []LotBalance) error
+
+	// ResetBalances removes every per-location balance
+	ResetBalances(ctx context.Context) error
+
+	// ResetLotBalances removes every per-lot balance
+	ResetLotBalances(ctx context.Context) error
+
+	// ListBalances returns the non-zero per-location balances matching the filter
+	ListBalances(ctx context.Context, filter BalanceFilter) ([]Balance, error)
+
+	// ListLotBalances returns the non-zero per-lot balances matching the filter
+	ListLotBalances(ctx context.Context, filter BalanceFilter) ([]LotBalance, error)
+}
+
+// SnapshotRepository defines the contract for balance snapshot persistence
+type SnapshotRepository interface {
+	// Save stores a snapshot together with its balances
+	Save(ctx context.Context, snapshot *Snapshot) error
+
+	// LatestBefore retrieves the most recent snapshot taken at or before the given time
+	LatestBefore(ctx context.Context, at time.Time) (*Snapshot, error)
+}
 
-	// Count returns total number of stock movements
-	Count(ctx context.Context) (int64, error)
+// RepairRepository defines the contract for the reconciliation repair audit trail
+type RepairRepository interface {
+	// Record stores a repair
+	Record(ctx context.Context, repair *Repair) error
 }
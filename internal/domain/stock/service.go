@@ -3,9 +3,11 @@ package stock
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
 )
 
 // Service contains business rules for stock movements
@@ -13,9 +15,13 @@ type Service struct {
 	productRepo  product.Repository
 	locationRepo location.Repository
 	stockRepo    Repository
+	serialRepo   serial.Repository
+	balanceRepo  ProjectionRepository
+	projector    *Projector
 }
 
-// NewService creates a new stock service
+// NewService creates a new stock service. Product totals are kept as a projection
+// of the ledger; further projections are added with WithProjections.
 func NewService(
 	productRepo product.Repository,
 	locationRepo location.Repository,
@@ -25,9 +31,36 @@ func NewService(
 		productRepo:  productRepo,
 		locationRepo: locationRepo,
 		stockRepo:    stockRepo,
+		projector:    NewProjector(stockRepo, NewProductTotalsProjection(productRepo)),
 	}
 }
 
+// WithProjections keeps more read models up to date with every recorded movement
+func (s *Service) WithProjections(projections ...Projection) *Service {
+	s.projector.Add(projections...)
+	return s
+}
+
+// WithBalances keeps the per-location and per-lot balance projections and
+// checks outbound stock and inbound capacity against them instead of summing the ledger
+func (s *Service) WithBalances(balanceRepo ProjectionRepository) *Service {
+	s.balanceRepo = balanceRepo
+	s.projector.Add(NewLocationBalancesProjection(balanceRepo), NewLotBalancesProjection(balanceRepo))
+	return s
+}
+
+// Projector returns the projector fed by this service, e.g. to rebuild its projections
+func (s *Service) Projector() *Projector {
+	return s.projector
+}
+
+// WithSerials enables serial tracking: movements of serialized products then
+// update each serial's location and status and append to its history
+func (s *Service) WithSerials(serialRepo serial.Repository) *Service {
+	s.serialRepo = serialRepo
+	return s
+}
+
 // RecordMovement records a stock movement with business rule validation
 func (s *Service) RecordMovement(ctx context.Context, movement *StockMovement) error {
 	// Validate product exists
@@ -42,43 +75,254 @@ func (s *Service) RecordMovement(ctx context.Context, movement *StockMovement) e
 		return errors.New("location not found")
 	}
 
+	// Quantities entered in packs are stored in base units
+	if err := movement.NormalizeQuantity(prod); err != nil {
+		return err
+	}
+
+	// Hold back other movements until the caller's transaction ends, so two
+	// movements cannot both spend the same stock or fill the same space
+	if _, err := s.stockRepo.LastSequence(ctx); err != nil {
+		return err
+	}
+
 	// Apply business rules based on movement type
 	if movement.IsOutbound() {
-		// Stock OUT cannot exceed available stock
-		if prod.Quantity < movement.Quantity {
-			return errors.New("insufficient stock for outbound movement")
+		// Stock OUT cannot exceed what the location, or the lot there, holds
+		available, err := s.availableAt(ctx, movement)
+		if err != nil {
+			return err
+		}
+		if available < movement.Quantity {
+			return ErrInsufficientStock
 		}
 	} else if movement.IsInbound() {
-		// Stock IN cannot exceed location capacity
-		currentStock, err := s.getLocationStock(ctx, movement.LocationID)
+		// Inactive products are not received any more; adjustments may still correct them
+		if !prod.IsActive() && movement.ReferenceType != ReferenceTypeAdjustment {
+			return product.ErrProductInactive
+		}
+
+		// Stock IN cannot exceed any configured location limit
+		load, err := s.LoadAfter(ctx, loc, prod, movement.Quantity)
 		if err != nil {
 			return err
 		}
 
-		if !loc.CanAccommodate(currentStock, movement.Quantity) {
-			return errors.New("location capacity exceeded")
+		if err := loc.CheckLoad(load); err != nil {
+			return err
 		}
 	}
 
-	// Record the movement
-	return s.stockRepo.Create(ctx, movement)
+	serials, err := s.prepareSerials(ctx, prod, movement)
+	if err != nil {
+		return err
+	}
+
+	// Append the movement to the ledger and bring the projections up to date
+	if err := s.stockRepo.Create(ctx, movement); err != nil {
+		return err
+	}
+	if err := s.projector.Apply(ctx, movement); err != nil {
+		return err
+	}
+
+	return s.saveSerials(ctx, movement, serials)
 }
 
-// getLocationStock gets total stock at a location
-func (s *Service) getLocationStock(ctx context.Context, locationID int64) (int64, error) {
-	movements, err := s.stockRepo.GetByLocation(ctx, locationID)
+// availableAt returns how much of the movement's product, or of its lot, the
+// movement's location holds
+func (s *Service) availableAt(ctx context.Context, movement *StockMovement) (int64, error) {
+	filter := BalanceFilter{ProductID: movement.ProductID, LocationID: movement.LocationID, Lot: movement.Lot}
+	if s.balanceRepo != nil {
+		var available int64
+		if movement.Lot != "" {
+			lots, err := s.balanceRepo.ListLotBalances(ctx, filter)
+			if err != nil {
+				return 0, err
+			}
+			for _, b := range lots {
+				available += b.Quantity
+			}
+		} else {
+			balances, err := s.balanceRepo.ListBalances(ctx, filter)
+			if err != nil {
+				return 0, err
+			}
+			for _, b := range balances {
+				available += b.Quantity
+			}
+		}
+		return available, nil
+	}
+
+	// Without the projections, sum the location's ledger
+	movements, err := s.stockRepo.GetByLocation(ctx, movement.LocationID)
 	if err != nil {
 		return 0, err
 	}
-
-	var total int64
+	var available int64
 	for _, m := range movements {
-		if m.IsInbound() {
-			total += m.Quantity
+		if m.ProductID == movement.ProductID && (movement.Lot == "" || m.Lot == movement.Lot) {
+			available += m.SignedQuantity()
+		}
+	}
+	return available, nil
+}
+
+// prepareSerials validates the movement's serials and applies the movement to
+// each of them in memory. Nothing is returned when serial tracking is off.
+func (s *Service) prepareSerials(ctx context.Context, prod *product.Product, movement *StockMovement) ([]*serial.Serial, error) {
+	if !prod.Serialized {
+		if len(movement.Serials) > 0 {
+			return nil, ErrSerialsNotAllowed
+		}
+		return nil, nil
+	}
+
+	if int64(len(movement.Serials)) != movement.Quantity {
+		return nil, ErrSerialCountMismatch
+	}
+
+	seen := make(map[string]bool, len(movement.Serials))
+	for i, number := range movement.Serials {
+		number, err := serial.NormalizeNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		if seen[number] {
+			return nil, ErrDuplicateSerial
+		}
+		seen[number] = true
+		movement.Serials[i] = number
+	}
+
+	if s.serialRepo == nil {
+		return nil, nil
+	}
+
+	serials := make([]*serial.Serial, 0, len(movement.Serials))
+	for _, number := range movement.Serials {
+		sn, err := s.serialRepo.GetByNumber(ctx, prod.ID, number)
+		if err == serial.ErrSerialNotFound {
+			sn, err = serial.NewSerial(prod.ID, number)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if movement.IsInbound() {
+			err = sn.Receive(movement.LocationID)
 		} else {
-			total -= m.Quantity
+			err = sn.Ship(movement.LocationID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, number)
+		}
+
+		serials = append(serials, sn)
+	}
+
+	return serials, nil
+}
+
+// saveSerials stores the serials' new state and adds the movement to their history
+func (s *Service) saveSerials(ctx context.Context, movement *StockMovement, serials []*serial.Serial) error {
+	for _, sn := range serials {
+		if err := s.serialRepo.Save(ctx, sn); err != nil {
+			return err
+		}
+
+		event := &serial.Event{
+			SerialID:     sn.ID,
+			MovementID:   movement.ID,
+			MovementType: string(movement.Type),
+			LocationID:   movement.LocationID,
+			Status:       sn.Status,
+			CreatedAt:    movement.CreatedAt,
+		}
+		if err := s.serialRepo.AddEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAfter works out what a location would hold after receiving quantity
+// of prod. Volume, weight and pallet positions are only computed when the location
+// limits them, and then require the matching product master data.
+func (s *Service) LoadAfter(ctx context.Context, loc *location.Location, prod *product.Product, quantity int64) (location.Load, error) {
+	balances, err := s.balancesAt(ctx, loc.ID)
+	if err != nil {
+		return location.Load{}, err
+	}
+	balances[prod.ID] += quantity
+
+	var load location.Load
+	for productID, qty := range balances {
+		if qty <= 0 {
+			continue
+		}
+		load.Units += qty
+
+		if loc.MaxVolumeCM3 == 0 && loc.MaxWeightGrams == 0 && loc.PalletPositions == 0 {
+			continue
+		}
+
+		p := prod
+		if productID != prod.ID {
+			if p, err = s.productRepo.GetByID(ctx, productID); err != nil {
+				return location.Load{}, err
+			}
+		}
+
+		if loc.MaxVolumeCM3 > 0 {
+			if p.Dimensions.IsZero() {
+				return location.Load{}, fmt.Errorf("%w: %s", product.ErrDimensionsUnknown, p.SKUName)
+			}
+			load.VolumeMM3 += p.Dimensions.VolumeMM3() * qty
+		}
+
+		if loc.MaxWeightGrams > 0 {
+			if p.WeightGrams == 0 {
+				return location.Load{}, fmt.Errorf("%w: %s", product.ErrWeightUnknown, p.SKUName)
+			}
+			load.WeightGrams += p.WeightGrams * qty
+		}
+
+		if loc.PalletPositions > 0 {
+			pallets, err := p.PalletsFor(qty)
+			if err != nil {
+				return location.Load{}, fmt.Errorf("%w: %s", product.ErrPalletPackUnknown, p.SKUName)
+			}
+			load.PalletPositions += pallets
 		}
 	}
 
-	return total, nil
+	return load, nil
+}
+
+// balancesAt returns the quantity of each product a location holds, read from
+// the balance projection when it is kept and summed from the ledger otherwise
+func (s *Service) balancesAt(ctx context.Context, locationID int64) (map[int64]int64, error) {
+	balances := make(map[int64]int64)
+
+	if s.balanceRepo != nil {
+		projected, err := s.balanceRepo.ListBalances(ctx, BalanceFilter{LocationID: locationID})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range projected {
+			balances[b.ProductID] += b.Quantity
+		}
+		return balances, nil
+	}
+
+	movements, err := s.stockRepo.GetByLocation(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range movements {
+		balances[m.ProductID] += m.SignedQuantity()
+	}
+	return balances, nil
 }
@@ -0,0 +1,22 @@
+package stock
+
+import "time"
+
+// Balance is the net quantity of a product held at a location
+type Balance struct {
+	ProductID  int64
+	LocationID int64
+	Quantity   int64
+}
+
+// Snapshot captures every non-zero balance up to and including a ledger position
+type Snapshot struct {
+	ID      int64
+	TakenAt time.Time
+
+	// LastSequence is the sequence of the last movement the balances include.
+	// Sequences follow commit order, so no movement committed later can sort
+	// below it, which IDs do not guarantee.
+	LastSequence int64
+	Balances     []Balance
+}
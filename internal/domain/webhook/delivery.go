@@ -0,0 +1,109 @@
+package webhook
+
+import "time"
+
+// DeliveryStatus is where a delivery is in its life cycle
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliveryDelivered DeliveryStatus = "DELIVERED"
+
+	// DeliveryDead marks a delivery whose retries ran out; dead deliveries
+	// form the dead-letter store and can be replayed
+	DeliveryDead DeliveryStatus = "DEAD"
+)
+
+// RetryPolicy controls how failed deliveries are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy retries 8 times, starting after 30 seconds and doubling up to an hour
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+	}
+}
+
+// Backoff returns the wait before the next attempt after the given number of failed attempts
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Delivery is one event on its way to one subscription
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        string
+	EventType      EventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// NewDelivery creates a pending delivery due straight away
+func NewDelivery(subscriptionID int64, event *Event, payload []byte) *Delivery {
+	now := time.Now()
+	return &Delivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// Succeeded marks the delivery as accepted by the subscriber
+func (d *Delivery) Succeeded(statusCode int, now time.Time) {
+	d.Attempts++
+	d.Status = DeliveryDelivered
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// Failed records a failed attempt and schedules the next one, or moves the
+// delivery to the dead-letter store when the policy's attempts are used up
+func (d *Delivery) Failed(statusCode int, reason string, now time.Time, policy RetryPolicy) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = reason
+
+	if d.Attempts >= policy.MaxAttempts {
+		d.Status = DeliveryDead
+		return
+	}
+	d.NextAttemptAt = now.Add(policy.Backoff(d.Attempts))
+}
+
+// Replay puts a finished delivery back in the queue with a fresh set of attempts
+func (d *Delivery) Replay(now time.Time) error {
+	if d.Status == DeliveryPending {
+		return ErrDeliveryPending
+	}
+	d.Status = DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	d.LastError = ""
+	d.DeliveredAt = nil
+	return nil
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+)
+
+// EventType names a kind of domain event subscribers can receive
+type EventType string
+
+const (
+	EventMovementRecorded  EventType = "stock.movement_recorded"
+	EventProductCreated    EventType = "product.created"
+	EventLowStock          EventType = "stock.low"
+	EventShipmentConfirmed EventType = "shipment.confirmed"
+)
+
+// IsValid reports whether the event type is one the system emits
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventMovementRecorded, EventProductCreated, EventLowStock, EventShipmentConfirmed:
+		return true
+	}
+	return false
+}
+
+// Event is a domain event to be delivered to subscribers
+type Event struct {
+	ID         string
+	Type       EventType
+	OccurredAt time.Time
+	Data       interface{}
+}
+
+// NewEvent creates an event with a random ID
+func NewEvent(eventType EventType, data interface{}) *Event {
+	return &Event{
+		ID:         randomHex(16),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Subscription is an external endpoint that receives events of the chosen types
+type Subscription struct {
+	ID         int64
+	URL        string
+	EventTypes []EventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// NewSubscription creates a validated subscription. An empty secret is
+// replaced by a generated one.
+func NewSubscription(rawURL string, eventTypes []EventType, secret string) (*Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, ErrNoEventTypes
+	}
+	seen := make(map[EventType]bool, len(eventTypes))
+	types := make([]EventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !t.IsValid() {
+			return nil, ErrInvalidEventType
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	return &Subscription{
+		URL:        rawURL,
+		EventTypes: types,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Wants reports whether the subscription receives events of the given type
+func (s *Subscription) Wants(t EventType) bool {
+	for _, et := range s.EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// randomHex returns n random bytes hex-encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
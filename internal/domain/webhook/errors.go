@@ -0,0 +1,12 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidURL           = errors.New("webhook URL must be an absolute http or https URL")
+	ErrInvalidEventType     = errors.New("unknown webhook event type")
+	ErrNoEventTypes         = errors.New("webhook subscription needs at least one event type")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrDeliveryPending      = errors.New("webhook delivery is still pending")
+)
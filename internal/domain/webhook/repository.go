@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the contract for webhook subscription and delivery persistence
+type Repository interface {
+	// CreateSubscription saves a new subscription
+	CreateSubscription(ctx context.Context, s *Subscription) error
+
+	// GetSubscription retrieves a subscription by ID
+	GetSubscription(ctx context.Context, id int64) (*Subscription, error)
+
+	// ListSubscriptions retrieves every subscription
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+
+	// DeleteSubscription removes a subscription and its deliveries
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	// CreateDelivery saves a new delivery. An event already queued for the
+	// subscription is not queued again, so republishing an event is harmless.
+	CreateDelivery(ctx context.Context, d *Delivery) error
+
+	// GetDelivery retrieves a delivery by ID
+	GetDelivery(ctx context.Context, id int64) (*Delivery, error)
+
+	// UpdateDelivery saves a delivery's status and attempt details
+	UpdateDelivery(ctx context.Context, d *Delivery) error
+
+	// DueDeliveries retrieves pending deliveries whose next attempt is due, oldest first
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+
+	// ListDeliveries retrieves deliveries, newest first, optionally by status
+	ListDeliveries(ctx context.Context, status DeliveryStatus, limit, offset int) ([]*Delivery, error)
+}
+
+// Sender posts a signed payload to a subscriber
+type Sender interface {
+	// Send posts the payload and returns the response status code. A transport
+	// failure is returned as an error.
+	Send(ctx context.Context, url string, headers map[string]string, payload []byte) (int, error)
+}
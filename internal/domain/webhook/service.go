@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// dispatchBatchSize is the number of due deliveries sent per dispatch run
+const dispatchBatchSize = 100
+
+// envelope is the JSON body of every delivery
+type envelope struct {
+	ID         string      `json:"id"`
+	Type       EventType   `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Publisher queues events for every subscription that wants them
+type Publisher struct {
+	repo Repository
+}
+
+// NewPublisher creates a new webhook publisher
+func NewPublisher(repo Repository) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// Publish stores one pending delivery per interested subscription. Sending
+// happens later in the Dispatcher, so a slow subscriber never holds up the caller.
+func (p *Publisher) Publish(ctx context.Context, event *Event) error {
+	subscriptions, err := p.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, s := range subscriptions {
+		if !s.Wants(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(envelope{
+				ID:         event.ID,
+				Type:       event.Type,
+				OccurredAt: event.OccurredAt,
+				Data:       event.Data,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode webhook event: %w", err)
+			}
+		}
+
+		if err := p.repo.CreateDelivery(ctx, NewDelivery(s.ID, event, payload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lock keeps the dispatcher to one runner at a time across instances
+type Lock interface {
+	// TryRun runs fn while holding the lock and reports whether it did; it
+	// returns false without running fn when the lock is held elsewhere
+	TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+// Dispatcher sends due deliveries and schedules retries
+type Dispatcher struct {
+	repo   Repository
+	sender Sender
+	policy RetryPolicy
+	lock   Lock
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(repo Repository, sender Sender, policy RetryPolicy) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		sender: sender,
+		policy: policy,
+	}
+}
+
+// WithLock makes each run take lock first and skip when another instance holds it.
+// Without a lock, only one process may run the dispatcher, or a delivery due
+// in both runs is sent twice.
+func (d *Dispatcher) WithLock(lock Lock) *Dispatcher {
+	d.lock = lock
+	return d
+}
+
+// DeliverDue sends every delivery whose next attempt is due and returns how
+// many were attempted. A non-2xx response or a transport error counts as a
+// failed attempt and is retried with exponential backoff. A delivery whose
+// outcome cannot be saved does not stop the rest of the batch; the errors are
+// returned together after it. When the lock is held by another instance the
+// run does nothing.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	if d.lock == nil {
+		return d.deliverDue(ctx)
+	}
+
+	attempted := 0
+	_, err := d.lock.TryRun(ctx, func(ctx context.Context) error {
+		var err error
+		attempted, err = d.deliverDue(ctx)
+		return err
+	})
+	return attempted, err
+}
+
+// deliverDue sends one batch of due deliveries
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.DueDeliveries(ctx, time.Now(), dispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	attempted := 0
+	subscriptions := make(map[int64]*Subscription)
+	for _, delivery := range deliveries {
+		sub, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			sub, err = d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = sub
+		}
+
+		d.attempt(ctx, sub, delivery)
+		attempted++
+
+		// The attempt happened either way; saving its outcome failing for one
+		// delivery must not keep the others from being sent
+		if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+		}
+	}
+
+	return attempted, errors.Join(errs...)
+}
+
+// attempt sends one delivery and records the outcome on it
+func (d *Dispatcher) attempt(ctx context.Context, sub *Subscription, delivery *Delivery) {
+	now := time.Now()
+	timestamp := now.Unix()
+
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     string(delivery.EventType),
+		HeaderDelivery:  strconv.FormatInt(delivery.ID, 10),
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(sub.Secret, timestamp, delivery.Payload),
+	}
+
+	statusCode, err := d.sender.Send(ctx, sub.URL, headers, delivery.Payload)
+	switch {
+	case err != nil:
+		delivery.Failed(0, err.Error(), now, d.policy)
+	case statusCode < 200 || statusCode > 299:
+		delivery.Failed(statusCode, fmt.Sprintf("subscriber responded with status %d", statusCode), now, d.policy)
+	default:
+		delivery.Succeeded(statusCode, now)
+	}
+}
@@ -19,18 +19,26 @@ func NewJWTManager(secretKey string) *JWTManager {
 	}
 }
 
+// Roles a token can carry
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // Claims represents JWT claims
 type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken generates a new JWT token
-func (m *JWTManager) GenerateToken(userID int64, username string, expirationHours int) (string, error) {
+func (m *JWTManager) GenerateToken(userID int64, username, role string, expirationHours int) (string, error) {
 	claims := &Claims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expirationHours) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/logging"
+)
+
+// Scheduler runs background jobs at fixed intervals
+type Scheduler struct {
+	logger *logging.Logger
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(logger *logging.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+	}
+}
+
+// Every runs fn every interval until ctx is cancelled, logging only failed runs.
+// A non-positive interval disables the job.
+func (s *Scheduler) Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		s.logger.Infof("job %s disabled", name)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					s.logger.Errorf("job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until all running jobs have stopped
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,92 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+)
+
+// KafkaSink produces outbox messages to a Kafka topic through a Kafka REST
+// Proxy (v2 API). The aggregate key is the record key, so an aggregate's events
+// land on one partition and keep their order.
+type KafkaSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaSink creates a sink producing to topic through the REST proxy at baseURL
+func NewKafkaSink(baseURL, topic string, timeout time.Duration) *KafkaSink {
+	return &KafkaSink{
+		url:    strings.TrimRight(baseURL, "/") + "/topics/" + topic,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// kafkaRecord is one record of a REST proxy produce request
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// kafkaProduceResponse reports the outcome of each record
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Name identifies the sink
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+// Publish produces the message envelope and waits for the proxy's acknowledgement
+func (s *KafkaSink) Publish(ctx context.Context, m *outbox.Message) error {
+	value, err := m.Envelope()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: m.AggregateKey(), Value: value}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka proxy responded with status %d", resp.StatusCode)
+	}
+
+	var result kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to read kafka proxy response: %w", err)
+	}
+	for _, o := range result.Offsets {
+		if o.ErrorCode != nil || o.Error != "" {
+			return fmt.Errorf("kafka rejected record: %s", o.Error)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/logging"
+)
+
+// LogSink writes every outbox message to the application log
+type LogSink struct {
+	logger *logging.Logger
+}
+
+// NewLogSink creates a sink logging to logger
+func NewLogSink(logger *logging.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Name identifies the sink
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+// Publish logs the message envelope
+func (s *LogSink) Publish(ctx context.Context, m *outbox.Message) error {
+	body, err := m.Envelope()
+	if err != nil {
+		return err
+	}
+	s.logger.Infof("event %s", body)
+	return nil
+}
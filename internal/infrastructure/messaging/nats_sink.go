@@ -0,0 +1,147 @@
+package messaging
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+)
+
+// NATSSink publishes outbox messages to NATS subjects named
+// "<prefix>.<event type>", speaking the plain-text NATS client protocol. Each
+// publish is followed by a PING and only succeeds once the server's PONG
+// arrives, so a message the server never saw is retried by the relay.
+type NATSSink struct {
+	addr    string
+	prefix  string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSSink creates a sink for the server at rawURL, e.g. nats://localhost:4222
+func NewNATSSink(rawURL, subjectPrefix string, timeout time.Duration) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", rawURL)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	return &NATSSink{
+		addr:    addr,
+		prefix:  strings.TrimSuffix(subjectPrefix, "."),
+		timeout: timeout,
+	}, nil
+}
+
+// Name identifies the sink
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+// Publish sends the message envelope and waits for the server to confirm it
+func (s *NATSSink) Publish(ctx context.Context, m *outbox.Message) error {
+	body, err := m.Envelope()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.publish(m.EventType, body); err != nil {
+		s.close()
+		return err
+	}
+	return nil
+}
+
+// publish writes PUB and PING and reads until PONG, connecting first if needed
+func (s *NATSSink) publish(eventType string, body []byte) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+
+	subject := s.prefix + "." + eventType
+	if _, err := fmt.Fprintf(s.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body); err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// connect dials the server, reads its INFO and sends CONNECT
+func (s *NATSSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.addr, s.timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(info))
+	}
+
+	if _, err := conn.Write([]byte(`CONNECT {"verbose":false,"pedantic":false,"name":"wms-outbox"}` + "\r\n")); err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.conn = conn
+	s.reader = reader
+	return nil
+}
+
+// close drops the connection so the next publish reconnects
+func (s *NATSSink) close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.reader = nil
+	}
+}
@@ -0,0 +1,35 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+)
+
+// WebhookSink queues outbox messages as deliveries for webhook subscribers
+type WebhookSink struct {
+	publisher *webhook.Publisher
+}
+
+// NewWebhookSink creates a sink feeding the webhook publisher
+func NewWebhookSink(publisher *webhook.Publisher) *WebhookSink {
+	return &WebhookSink{publisher: publisher}
+}
+
+// Name identifies the sink
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish queues the message for every interested subscription. The event
+// keeps its outbox ID, so relaying it again does not queue it twice.
+func (s *WebhookSink) Publish(ctx context.Context, m *outbox.Message) error {
+	return s.publisher.Publish(ctx, &webhook.Event{
+		ID:         m.EventID,
+		Type:       webhook.EventType(m.EventType),
+		OccurredAt: m.OccurredAt,
+		Data:       json.RawMessage(m.Payload),
+	})
+}
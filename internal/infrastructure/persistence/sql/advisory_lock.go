@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// AdvisoryLock is a named Postgres session advisory lock. It keeps work that
+// every instance schedules, such as the outbox relay, running on one instance
+// at a time. The lock is released with the session, so a crashed holder never
+// keeps it.
+type AdvisoryLock struct {
+	db   *sql.DB
+	name string
+}
+
+// NewAdvisoryLock creates an advisory lock identified by name
+func NewAdvisoryLock(db *sql.DB, name string) *AdvisoryLock {
+	return &AdvisoryLock{db: db, name: name}
+}
+
+// TryRun runs fn while holding the lock and reports whether it did. When
+// another session holds the lock it returns false at once without running fn.
+func (l *AdvisoryLock) TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	// Session locks belong to a connection, so take and release it on one
+	c, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for lock %s: %w", l.name, err)
+	}
+	defer c.Close()
+
+	var acquired bool
+	if err := c.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, l.name).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to take lock %s: %w", l.name, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer c.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, l.name)
+
+	return true, fn(ctx)
+}
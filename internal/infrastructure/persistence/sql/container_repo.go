@@ -0,0 +1,257 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/lib/pq"
+)
+
+// containerColumns is the column list shared by every container SELECT
+const containerColumns = `id, lpn, COALESCE(sscc, ''), type, location_id, parent_id, status, created_at`
+
+// ContainerRepository implements container.Repository
+type ContainerRepository struct {
+	db *sql.DB
+}
+
+// NewContainerRepository creates a new container repository
+func NewContainerRepository(db *sql.DB) *ContainerRepository {
+	return &ContainerRepository{db: db}
+}
+
+// scanContainer reads one container row selected with containerColumns
+func scanContainer(row rowScanner) (*container.Container, error) {
+	c := &container.Container{}
+	var parentID sql.NullInt64
+	err := row.Scan(&c.ID, &c.LPN, &c.SSCC, &c.Type, &c.LocationID, &parentID, &c.Status, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+	return c, nil
+}
+
+// Create saves a new container with its contents
+func (r *ContainerRepository) Create(ctx context.Context, c *container.Container) error {
+	query := `
+		INSERT INTO containers (lpn, sscc, type, location_id, parent_id, status, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	return NewTransactionManager(r.db).WithTx(ctx, func(ctx context.Context) error {
+		err := conn(ctx, r.db).QueryRowContext(ctx, query,
+			c.LPN, c.SSCC, c.Type, c.LocationID, c.ParentID, c.Status, c.CreatedAt,
+		).Scan(&c.ID)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return container.ErrDuplicateLPN
+			}
+			return fmt.Errorf("failed to create container: %w", err)
+		}
+
+		return r.saveContents(ctx, c)
+	})
+}
+
+// GetByID retrieves a container by ID
+func (r *ContainerRepository) GetByID(ctx context.Context, id int64) (*container.Container, error) {
+	return r.getOne(ctx, `SELECT `+containerColumns+` FROM containers WHERE id = $1`, id)
+}
+
+// GetByLPN retrieves a container by license plate number
+func (r *ContainerRepository) GetByLPN(ctx context.Context, lpn string) (*container.Container, error) {
+	return r.getOne(ctx, `SELECT `+containerColumns+` FROM containers WHERE lpn = $1`, lpn)
+}
+
+// GetBySSCC retrieves a container by serial shipping container code
+func (r *ContainerRepository) GetBySSCC(ctx context.Context, sscc string) (*container.Container, error) {
+	return r.getOne(ctx, `SELECT `+containerColumns+` FROM containers WHERE sscc = $1`, sscc)
+}
+
+// getOne runs a single-container query and loads its contents
+func (r *ContainerRepository) getOne(ctx context.Context, query string, arg interface{}) (*container.Container, error) {
+	c, err := scanContainer(conn(ctx, r.db).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, container.ErrContainerNotFound
+		}
+		return nil, fmt.Errorf("failed to get container: %w", err)
+	}
+
+	if err := r.loadContents(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetChildren retrieves the active containers nested directly inside a container
+func (r *ContainerRepository) GetChildren(ctx context.Context, parentID int64) ([]*container.Container, error) {
+	query := `
+		SELECT ` + containerColumns + `
+		FROM containers
+		WHERE parent_id = $1 AND status = $2
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, parentID, container.StatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child containers: %w", err)
+	}
+	defer rows.Close()
+
+	var children []*container.Container
+	for rows.Next() {
+		c, err := scanContainer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan container: %w", err)
+		}
+		children = append(children, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating containers: %w", err)
+	}
+
+	for _, c := range children {
+		if err := r.loadContents(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	return children, nil
+}
+
+// Update saves the container's location, parent, status and contents
+func (r *ContainerRepository) Update(ctx context.Context, c *container.Container) error {
+	query := `
+		UPDATE containers
+		SET location_id = $1, parent_id = $2, status = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`
+
+	return NewTransactionManager(r.db).WithTx(ctx, func(ctx context.Context) error {
+		result, err := conn(ctx, r.db).ExecContext(ctx, query, c.LocationID, c.ParentID, c.Status, c.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update container: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return container.ErrContainerNotFound
+		}
+
+		return r.saveContents(ctx, c)
+	})
+}
+
+// QuantityAt returns how much of a product is held in active containers at a location
+func (r *ContainerRepository) QuantityAt(ctx context.Context, locationID, productID int64) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(cc.quantity), 0)
+		FROM container_contents cc
+		JOIN containers c ON c.id = cc.container_id
+		WHERE c.location_id = $1 AND cc.product_id = $2 AND c.status = $3
+	`
+
+	var quantity int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, locationID, productID, container.StatusActive).Scan(&quantity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum container contents: %w", err)
+	}
+
+	return quantity, nil
+}
+
+// LocationsWithLot returns the locations where active containers hold a product's lot
+func (r *ContainerRepository) LocationsWithLot(ctx context.Context, productID int64, lot string) ([]int64, error) {
+	query := `
+		SELECT DISTINCT c.location_id
+		FROM container_contents cc
+		JOIN containers c ON c.id = cc.container_id
+		WHERE cc.product_id = $1 AND cc.lot = $2 AND c.status = $3
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID, lot, container.StatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lot locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locationIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan lot location: %w", err)
+		}
+		locationIDs = append(locationIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lot locations: %w", err)
+	}
+
+	return locationIDs, nil
+}
+
+// loadContents fills in the contents of a container
+func (r *ContainerRepository) loadContents(ctx context.Context, c *container.Container) error {
+	query := `
+		SELECT product_id, lot, quantity
+		FROM container_contents
+		WHERE container_id = $1
+		ORDER BY product_id, lot
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load container contents: %w", err)
+	}
+	defer rows.Close()
+
+	c.Contents = nil
+	for rows.Next() {
+		var content container.Content
+		if err := rows.Scan(&content.ProductID, &content.Lot, &content.Quantity); err != nil {
+			return fmt.Errorf("failed to scan container content: %w", err)
+		}
+		c.Contents = append(c.Contents, content)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating container contents: %w", err)
+	}
+
+	return nil
+}
+
+// saveContents replaces the stored contents of a container
+func (r *ContainerRepository) saveContents(ctx context.Context, c *container.Container) error {
+	db := conn(ctx, r.db)
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM container_contents WHERE container_id = $1`, c.ID); err != nil {
+		return fmt.Errorf("failed to clear container contents: %w", err)
+	}
+
+	for _, content := range c.Contents {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO container_contents (container_id, product_id, lot, quantity) VALUES ($1, $2, $3, $4)`,
+			c.ID, content.ProductID, content.Lot, content.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save container content: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
+)
+
+// IdempotencyRepository implements idempotency.Repository
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim stores the record unless its scope and key are taken. An unfinished
+// record with the same fingerprint created before staleBefore is taken over.
+// It returns nil when the claim succeeded and the record holding the key otherwise.
+func (r *IdempotencyRepository) Claim(ctx context.Context, rec *idempotency.Record, staleBefore time.Time) (*idempotency.Record, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, idem_key, fingerprint, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, idem_key) DO UPDATE SET created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.completed_at IS NULL
+			AND idempotency_keys.fingerprint = EXCLUDED.fingerprint
+			AND idempotency_keys.created_at < $5
+		RETURNING scope
+	`
+
+	var scope string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, rec.Scope, rec.Key, rec.Fingerprint, rec.CreatedAt, staleBefore).Scan(&scope)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	existing, err := r.get(ctx, rec.Scope, rec.Key)
+	if err == sql.ErrNoRows {
+		// Released between the insert and the lookup; try again
+		return r.Claim(ctx, rec, staleBefore)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response of a claimed record
+func (r *IdempotencyRepository) Complete(ctx context.Context, rec *idempotency.Record) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, body = $5, completed_at = $6
+		WHERE scope = $1 AND idem_key = $2
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		rec.Scope, rec.Key, rec.StatusCode, rec.ContentType, rec.Body, rec.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes the record so the key can be used again
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND idem_key = $2`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBefore removes records created before the given time and returns how many
+func (r *IdempotencyRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE created_at < $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// get loads one record by scope and key
+func (r *IdempotencyRepository) get(ctx context.Context, scope, key string) (*idempotency.Record, error) {
+	query := `
+		SELECT scope, idem_key, fingerprint, created_at, status_code, content_type, body, completed_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idem_key = $2
+	`
+
+	rec := &idempotency.Record{}
+	var completedAt sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, scope, key).Scan(
+		&rec.Scope, &rec.Key, &rec.Fingerprint, &rec.CreatedAt,
+		&rec.StatusCode, &rec.ContentType, &rec.Body, &completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		rec.CompletedAt = &completedAt.Time
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,134 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
+)
+
+// likeEscaper escapes the LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// conditions collects the WHERE clause of a listing and its arguments
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// arg adds an argument and returns its placeholder
+func (c *conditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+// equal requires column to equal value
+func (c *conditions) equal(column string, value interface{}) {
+	c.clauses = append(c.clauses, column+" = "+c.arg(value))
+}
+
+// prefix requires column to start with value, ignoring case
+func (c *conditions) prefix(column, value string) {
+	c.clauses = append(c.clauses, column+" ILIKE "+c.arg(likeEscaper.Replace(value)+"%"))
+}
+
+// search requires one of the columns to contain term, ignoring case
+func (c *conditions) search(term string, columns ...string) {
+	if term == "" {
+		return
+	}
+	placeholder := c.arg("%" + likeEscaper.Replace(term) + "%")
+	matches := make([]string, len(columns))
+	for i, column := range columns {
+		matches[i] = column + " ILIKE " + placeholder
+	}
+	c.clauses = append(c.clauses, "("+strings.Join(matches, " OR ")+")")
+}
+
+// int64Range keeps column inside r
+func (c *conditions) int64Range(column string, r listing.Int64Range) {
+	if r.Min != nil {
+		c.clauses = append(c.clauses, column+" >= "+c.arg(*r.Min))
+	}
+	if r.Max != nil {
+		c.clauses = append(c.clauses, column+" <= "+c.arg(*r.Max))
+	}
+}
+
+// timeRange keeps column inside r
+func (c *conditions) timeRange(column string, r listing.TimeRange) {
+	if !r.From.IsZero() {
+		c.clauses = append(c.clauses, column+" >= "+c.arg(r.From))
+	}
+	if !r.To.IsZero() {
+		c.clauses = append(c.clauses, column+" <= "+c.arg(r.To))
+	}
+}
+
+// where returns the WHERE clause, or nothing when there are no conditions
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// page returns the LIMIT and OFFSET clause
+func (c *conditions) page(limit, offset int) string {
+	return "LIMIT " + c.arg(limit) + " OFFSET " + c.arg(offset)
+}
+
+// after keeps the rows that come after keys in order, the keyset equivalent of
+// OFFSET. Rows sorted all one way compare as a single row value, which an index
+// on the order's columns can serve; mixed directions compare field by field.
+func (c *conditions) after(order []listing.Sort, columns map[string]string, keys []string) {
+	if keys == nil {
+		return
+	}
+
+	cols := make([]string, len(order))
+	placeholders := make([]string, len(order))
+	sameDirection := true
+	for i, s := range order {
+		cols[i] = columns[s.Field]
+		placeholders[i] = c.arg(keys[i])
+		sameDirection = sameDirection && s.Desc == order[0].Desc
+	}
+
+	if sameDirection {
+		c.clauses = append(c.clauses, "("+strings.Join(cols, ", ")+") "+afterOperator(order[0])+" ("+strings.Join(placeholders, ", ")+")")
+		return
+	}
+
+	alternatives := make([]string, len(order))
+	for i, s := range order {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, cols[j]+" = "+placeholders[j])
+		}
+		terms = append(terms, cols[i]+" "+afterOperator(s)+" "+placeholders[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	c.clauses = append(c.clauses, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+// afterOperator compares a column with the key a page starts after
+func afterOperator(s listing.Sort) string {
+	if s.Desc {
+		return "<"
+	}
+	return ">"
+}
+
+// orderBy builds the ORDER BY clause of a listing from its listing.Order.
+// columns maps the sort fields the domain allows to SQL.
+func orderBy(order []listing.Sort, columns map[string]string) string {
+	terms := make([]string, len(order))
+	for i, s := range order {
+		terms[i] = columns[s.Field]
+		if s.Desc {
+			terms[i] += " DESC"
+		}
+	}
+	return "ORDER BY " + strings.Join(terms, ", ")
+}
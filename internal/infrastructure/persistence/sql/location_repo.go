@@ -5,9 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
 )
 
+// locationColumns is the column list shared by every location SELECT
+const locationColumns = `id, code, name, capacity, max_volume_cm3, max_weight_g, pallet_positions, temperature_zone, warehouse, version`
+
 // LocationRepository implements location.Repository
 type LocationRepository struct {
 	db *sql.DB
@@ -18,15 +22,27 @@ func NewLocationRepository(db *sql.DB) *LocationRepository {
 	return &LocationRepository{db: db}
 }
 
+// scanLocation reads one location row selected with locationColumns
+func scanLocation(row rowScanner) (*location.Location, error) {
+	l := &location.Location{}
+	err := row.Scan(&l.ID, &l.Code, &l.Name, &l.Capacity, &l.MaxVolumeCM3, &l.MaxWeightGrams, &l.PalletPositions, &l.TemperatureZone, &l.Warehouse, &l.Version)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
 // Create saves a new location
 func (r *LocationRepository) Create(ctx context.Context, l *location.Location) error {
 	query := `
-		INSERT INTO locations (code, name, capacity)
-		VALUES ($1, $2, $3)
-		RETURNING id
+		INSERT INTO locations (code, name, capacity, max_volume_cm3, max_weight_g, pallet_positions, temperature_zone, warehouse)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
 	`
 
-	err := r.db.QueryRowContext(ctx, query, l.Code, l.Name, l.Capacity).Scan(&l.ID)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		l.Code, l.Name, l.Capacity, l.MaxVolumeCM3, l.MaxWeightGrams, l.PalletPositions, l.TemperatureZone, l.Warehouse,
+	).Scan(&l.ID, &l.Version)
 	if err != nil {
 		return fmt.Errorf("failed to create location: %w", err)
 	}
@@ -37,13 +53,12 @@ func (r *LocationRepository) Create(ctx context.Context, l *location.Location) e
 // GetByID retrieves a location by ID
 func (r *LocationRepository) GetByID(ctx context.Context, id int64) (*location.Location, error) {
 	query := `
-		SELECT id, code, name, capacity
+		SELECT ` + locationColumns + `
 		FROM locations
 		WHERE id = $1
 	`
 
-	l := &location.Location{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&l.ID, &l.Code, &l.Name, &l.Capacity)
+	l, err := scanLocation(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, location.ErrLocationNotFound
@@ -57,13 +72,12 @@ func (r *LocationRepository) GetByID(ctx context.Context, id int64) (*location.L
 // GetByCode retrieves a location by code
 func (r *LocationRepository) GetByCode(ctx context.Context, code string) (*location.Location, error) {
 	query := `
-		SELECT id, code, name, capacity
+		SELECT ` + locationColumns + `
 		FROM locations
 		WHERE code = $1
 	`
 
-	l := &location.Location{}
-	err := r.db.QueryRowContext(ctx, query, code).Scan(&l.ID, &l.Code, &l.Name, &l.Capacity)
+	l, err := scanLocation(conn(ctx, r.db).QueryRowContext(ctx, query, code))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, location.ErrLocationNotFound
@@ -74,16 +88,46 @@ func (r *LocationRepository) GetByCode(ctx context.Context, code string) (*locat
 	return l, nil
 }
 
-// List retrieves all locations with pagination
-func (r *LocationRepository) List(ctx context.Context, limit, offset int) ([]*location.Location, error) {
+// locationSortColumns maps the sort fields of location listings to columns
+var locationSortColumns = map[string]string{
+	"id":        "id",
+	"code":      "code",
+	"name":      "name",
+	"capacity":  "capacity",
+	"warehouse": "warehouse",
+}
+
+// locationConditions turns a location spec's filter and search into WHERE conditions
+func locationConditions(spec location.ListSpec) *conditions {
+	c := &conditions{}
+	f := spec.Filter
+	if f.CodePrefix != "" {
+		c.prefix("code", f.CodePrefix)
+	}
+	if f.Warehouse != "" {
+		c.equal("warehouse", f.Warehouse)
+	}
+	if f.TemperatureZone != "" {
+		c.equal("temperature_zone", f.TemperatureZone)
+	}
+	c.int64Range("capacity", f.Capacity)
+	c.search(spec.Search, "code", "name", "warehouse")
+	return c
+}
+
+// List retrieves the locations the spec selects, newest first unless it sorts otherwise
+func (r *LocationRepository) List(ctx context.Context, spec location.ListSpec) ([]*location.Location, error) {
+	order := listing.Order(spec.Sort, location.DefaultOrder)
+	c := locationConditions(spec)
+	c.after(order, locationSortColumns, spec.After)
 	query := `
-		SELECT id, code, name, capacity
+		SELECT ` + locationColumns + `
 		FROM locations
-		ORDER BY id DESC
-		LIMIT $1 OFFSET $2
-	`
+		` + c.where() + `
+		` + orderBy(order, locationSortColumns) + `
+		` + c.page(spec.Limit, spec.Offset)
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, c.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list locations: %w", err)
 	}
@@ -91,8 +135,8 @@ func (r *LocationRepository) List(ctx context.Context, limit, offset int) ([]*lo
 
 	var locations []*location.Location
 	for rows.Next() {
-		l := &location.Location{}
-		if err := rows.Scan(&l.ID, &l.Code, &l.Name, &l.Capacity); err != nil {
+		l, err := scanLocation(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan location: %w", err)
 		}
 		locations = append(locations, l)
@@ -105,36 +149,37 @@ func (r *LocationRepository) List(ctx context.Context, limit, offset int) ([]*lo
 	return locations, nil
 }
 
-// Update updates an existing location
+// Update updates an existing location if it is still at l.Version
 func (r *LocationRepository) Update(ctx context.Context, l *location.Location) error {
 	query := `
 		UPDATE locations
-		SET code = $1, name = $2, capacity = $3, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
+		SET code = $1, name = $2, capacity = $3, max_volume_cm3 = $4, max_weight_g = $5,
+			pallet_positions = $6, temperature_zone = $7, warehouse = $8,
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9 AND version = $10
+		RETURNING version
 	`
 
-	result, err := r.db.ExecContext(ctx, query, l.Code, l.Name, l.Capacity, l.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update location: %w", err)
+	var version int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		l.Code, l.Name, l.Capacity, l.MaxVolumeCM3, l.MaxWeightGrams, l.PalletPositions, l.TemperatureZone, l.Warehouse, l.ID, l.Version,
+	).Scan(&version)
+	if err == sql.ErrNoRows {
+		return r.versionMismatch(ctx, l.ID)
 	}
-
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
-	if rowsAffected == 0 {
-		return location.ErrLocationNotFound
+		return fmt.Errorf("failed to update location: %w", err)
 	}
+	l.Version = version
 
 	return nil
 }
 
-// Delete deletes a location
-func (r *LocationRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM locations WHERE id = $1`
+// Delete deletes a location if it is still at the given version
+func (r *LocationRepository) Delete(ctx context.Context, id, version int64) error {
+	query := `DELETE FROM locations WHERE id = $1 AND version = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to delete location: %w", err)
 	}
@@ -145,18 +190,32 @@ func (r *LocationRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return location.ErrLocationNotFound
+		return r.versionMismatch(ctx, id)
 	}
 
 	return nil
 }
 
-// Count returns total number of locations
-func (r *LocationRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM locations`
+// versionMismatch tells a missing location apart from one at another version
+func (r *LocationRepository) versionMismatch(ctx context.Context, id int64) error {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM locations WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check location version: %w", err)
+	}
+	if !exists {
+		return location.ErrLocationNotFound
+	}
+	return location.ErrConcurrentModification
+}
+
+// Count returns how many locations match the spec's filter and search
+func (r *LocationRepository) Count(ctx context.Context, spec location.ListSpec) (int64, error) {
+	c := locationConditions(spec)
+	query := `SELECT COUNT(*) FROM locations ` + c.where()
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, c.args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count locations: %w", err)
 	}
@@ -0,0 +1,333 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationLockID is the advisory lock key held while migrations run, so that
+// several instances starting together apply each migration once
+const migrationLockID = 7_413_920_215
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one numbered schema change with its up and down SQL
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a known migration and when it was applied, nil if pending
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// MigrationStep is a migration to apply, or to revert when Revert is set
+type MigrationStep struct {
+	Migration
+	Revert bool
+}
+
+// Migrations returns the migrations embedded in the binary, ordered by version
+func Migrations() ([]Migration, error) {
+	return LoadMigrations(migrationFiles, "migrations")
+}
+
+// LoadMigrations reads <version>_<name>.up.sql and .down.sql pairs from dir.
+// Every migration needs both files and versions must be unique.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		up := strings.HasSuffix(base, ".up")
+		if !up && !strings.HasSuffix(base, ".down") {
+			return nil, fmt.Errorf("migration %s: name must end in .up.sql or .down.sql", entry.Name())
+		}
+		base = strings.TrimSuffix(strings.TrimSuffix(base, ".up"), ".down")
+
+		prefix, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version number", entry.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, name)
+		}
+		if up {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s: needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// PlanMigrations returns the steps that bring a database with the given applied
+// versions to target: pending migrations up to target are applied in order and
+// applied migrations above target are reverted newest first.
+func PlanMigrations(migrations []Migration, applied map[int64]bool, target int64) ([]MigrationStep, error) {
+	known := make(map[int64]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			return nil, fmt.Errorf("database has migration %d which this build does not know", version)
+		}
+	}
+	if target != 0 && !known[target] {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+
+	var steps []MigrationStep
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if m := migrations[i]; m.Version > target && applied[m.Version] {
+			steps = append(steps, MigrationStep{Migration: m, Revert: true})
+		}
+	}
+	for _, m := range migrations {
+		if m.Version <= target && !applied[m.Version] {
+			steps = append(steps, MigrationStep{Migration: m})
+		}
+	}
+
+	return steps, nil
+}
+
+// Migrator applies and reverts the embedded migrations, recording them in
+// schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator over the embedded migrations
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest known migration version
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) ([]MigrationStep, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) ([]MigrationStep, error) {
+	var steps []MigrationStep
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		var current int64
+		for version := range applied {
+			if version > current {
+				current = version
+			}
+		}
+		if current == 0 {
+			return nil
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version == current {
+				step := MigrationStep{Migration: migration, Revert: true}
+				if err := applyStep(ctx, conn, step); err != nil {
+					return err
+				}
+				steps = []MigrationStep{step}
+				return nil
+			}
+		}
+		return fmt.Errorf("database has migration %d which this build does not know", current)
+	})
+	return steps, err
+}
+
+// To migrates up or down to the given version; 0 reverts everything
+func (m *Migrator) To(ctx context.Context, target int64) ([]MigrationStep, error) {
+	var steps []MigrationStep
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		steps, err = m.run(ctx, conn, applied, target)
+		return err
+	})
+	return steps, err
+}
+
+// Status lists every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Migration: migration}
+			if at, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// run plans and executes the steps to target, each in its own transaction
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, applied map[int64]time.Time, target int64) ([]MigrationStep, error) {
+	versions := make(map[int64]bool, len(applied))
+	for version := range applied {
+		versions[version] = true
+	}
+
+	steps, err := PlanMigrations(m.migrations, versions, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, step := range steps {
+		if err := applyStep(ctx, conn, step); err != nil {
+			return steps[:i], err
+		}
+	}
+
+	return steps, nil
+}
+
+// locked runs fn on a dedicated connection holding the migration advisory lock
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations returns the applied versions and when each was applied
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = at
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// applyStep runs one migration and records it in a single transaction
+func applyStep(ctx context.Context, conn *sql.Conn, step MigrationStep) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", step.Version, err)
+	}
+	defer tx.Rollback()
+
+	body, record := step.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	args := []interface{}{step.Version, step.Name}
+	if step.Revert {
+		body, record = step.Down, `DELETE FROM schema_migrations WHERE version = $1`
+		args = args[:1]
+	}
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", step.Version, step.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", step.Version, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_stock_movements_document_number;
+DROP INDEX IF EXISTS idx_stock_movements_reference;
+
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS notes;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS document_number;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS reference_id;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS reference_type;
@@ -0,0 +1,8 @@
+-- Stock movement document references
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS reference_type VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS reference_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS document_number VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_document_number ON stock_movements(document_number);
@@ -0,0 +1,28 @@
+-- Stock balance snapshots
+CREATE TABLE IF NOT EXISTS stock_snapshots (
+	id SERIAL PRIMARY KEY,
+	taken_at TIMESTAMP NOT NULL,
+	last_sequence BIGINT NOT NULL
+);
+
+-- Snapshots are bounded by ledger sequence rather than movement ID, since IDs
+-- are not handed out in commit order. Snapshots taken by ID may have missed a
+-- movement that committed late, so they are dropped and retaken.
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'stock_snapshots' AND column_name = 'last_movement_id') THEN
+		DELETE FROM stock_snapshots;
+		ALTER TABLE stock_snapshots RENAME COLUMN last_movement_id TO last_sequence;
+	END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS stock_snapshot_balances (
+	snapshot_id INTEGER NOT NULL REFERENCES stock_snapshots(id) ON DELETE CASCADE,
+	product_id INTEGER NOT NULL,
+	location_id INTEGER NOT NULL,
+	quantity BIGINT NOT NULL,
+	PRIMARY KEY (snapshot_id, product_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_snapshots_taken_at ON stock_snapshots(taken_at);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS product_barcodes;
+
+DROP INDEX IF EXISTS idx_products_category;
+
+ALTER TABLE products DROP COLUMN IF EXISTS status;
+ALTER TABLE products DROP COLUMN IF EXISTS shelf_life_days;
+ALTER TABLE products DROP COLUMN IF EXISTS weight_g;
+ALTER TABLE products DROP COLUMN IF EXISTS height_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS width_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS length_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS base_uom;
+ALTER TABLE products DROP COLUMN IF EXISTS brand;
+ALTER TABLE products DROP COLUMN IF EXISTS category;
+ALTER TABLE products DROP COLUMN IF EXISTS description;
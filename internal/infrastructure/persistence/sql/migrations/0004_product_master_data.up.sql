@@ -0,0 +1,21 @@
+-- Product master data
+ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS brand VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS base_uom VARCHAR(10) NOT NULL DEFAULT 'EA';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS length_mm BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS width_mm BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS height_mm BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_g BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS shelf_life_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS product_barcodes (
+	product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	type VARCHAR(10) NOT NULL,
+	value VARCHAR(20) NOT NULL UNIQUE,
+	position INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (product_id, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products(category);
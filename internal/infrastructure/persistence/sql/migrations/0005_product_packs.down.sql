@@ -0,0 +1,4 @@
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS entered_quantity;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS entered_uom;
+
+DROP TABLE IF EXISTS product_packs;
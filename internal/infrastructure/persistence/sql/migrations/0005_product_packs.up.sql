@@ -0,0 +1,11 @@
+-- Pack hierarchy per product; EACH is implicit
+CREATE TABLE IF NOT EXISTS product_packs (
+	product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	level VARCHAR(10) NOT NULL,
+	units_per_pack BIGINT NOT NULL CHECK (units_per_pack > 1),
+	PRIMARY KEY (product_id, level)
+);
+
+-- Quantity as entered when a pack level other than EACH was used
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS entered_uom VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS entered_quantity BIGINT NOT NULL DEFAULT 0;
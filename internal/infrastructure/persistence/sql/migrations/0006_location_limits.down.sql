@@ -0,0 +1,3 @@
+ALTER TABLE locations DROP COLUMN IF EXISTS pallet_positions;
+ALTER TABLE locations DROP COLUMN IF EXISTS max_weight_g;
+ALTER TABLE locations DROP COLUMN IF EXISTS max_volume_cm3;
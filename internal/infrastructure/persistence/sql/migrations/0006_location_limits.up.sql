@@ -0,0 +1,4 @@
+-- Physical location limits; zero means not configured
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS max_volume_cm3 BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS max_weight_g BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS pallet_positions BIGINT NOT NULL DEFAULT 0;
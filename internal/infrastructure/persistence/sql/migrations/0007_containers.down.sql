@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS container_contents;
+DROP TABLE IF EXISTS containers;
@@ -0,0 +1,23 @@
+-- License-plated containers (pallets, cartons, totes) and their contents
+CREATE TABLE IF NOT EXISTS containers (
+	id SERIAL PRIMARY KEY,
+	lpn VARCHAR(50) UNIQUE NOT NULL,
+	sscc VARCHAR(18) UNIQUE,
+	type VARCHAR(10) NOT NULL,
+	location_id INTEGER NOT NULL REFERENCES locations(id),
+	parent_id INTEGER REFERENCES containers(id),
+	status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS container_contents (
+	container_id INTEGER NOT NULL REFERENCES containers(id) ON DELETE CASCADE,
+	product_id INTEGER NOT NULL REFERENCES products(id),
+	lot VARCHAR(50) NOT NULL DEFAULT '',
+	quantity BIGINT NOT NULL CHECK (quantity > 0),
+	PRIMARY KEY (container_id, product_id, lot)
+);
+
+CREATE INDEX IF NOT EXISTS idx_containers_parent_id ON containers(parent_id);
+CREATE INDEX IF NOT EXISTS idx_containers_location_id ON containers(location_id);
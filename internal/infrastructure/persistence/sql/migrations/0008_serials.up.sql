@@ -0,0 +1,25 @@
+-- Serialized units and the movements each one took part in
+ALTER TABLE products ADD COLUMN IF NOT EXISTS serialized BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS serials (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL REFERENCES products(id),
+	serial_number VARCHAR(100) NOT NULL,
+	location_id INTEGER REFERENCES locations(id),
+	status VARCHAR(10) NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (product_id, serial_number)
+);
+
+CREATE TABLE IF NOT EXISTS serial_events (
+	id SERIAL PRIMARY KEY,
+	serial_id INTEGER NOT NULL REFERENCES serials(id) ON DELETE CASCADE,
+	movement_id INTEGER NOT NULL REFERENCES stock_movements(id),
+	movement_type VARCHAR(10) NOT NULL,
+	location_id INTEGER NOT NULL REFERENCES locations(id),
+	status VARCHAR(10) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_serials_location_id ON serials(location_id);
+CREATE INDEX IF NOT EXISTS idx_serial_events_serial_id ON serial_events(serial_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS putaway_rules;
+
+ALTER TABLE locations DROP COLUMN IF EXISTS temperature_zone;
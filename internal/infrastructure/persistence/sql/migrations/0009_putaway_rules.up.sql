@@ -0,0 +1,11 @@
+-- Temperature zones and putaway constraints per product category
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS temperature_zone VARCHAR(10) NOT NULL DEFAULT 'AMBIENT';
+
+CREATE TABLE IF NOT EXISTS putaway_rules (
+	category VARCHAR(100) PRIMARY KEY,
+	temperature_zone VARCHAR(10) NOT NULL DEFAULT 'AMBIENT',
+	location_prefix VARCHAR(100) NOT NULL DEFAULT '',
+	allow_mixed_skus BOOLEAN NOT NULL DEFAULT true,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS replenishment_tasks;
+DROP TABLE IF EXISTS pick_faces;
@@ -0,0 +1,22 @@
+-- Pick face min/max settings and the replenishment tasks that keep them stocked
+CREATE TABLE IF NOT EXISTS pick_faces (
+	product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+	min_qty BIGINT NOT NULL,
+	max_qty BIGINT NOT NULL,
+	PRIMARY KEY (product_id, location_id)
+);
+
+CREATE TABLE IF NOT EXISTS replenishment_tasks (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL REFERENCES products(id),
+	from_location_id INTEGER NOT NULL REFERENCES locations(id),
+	to_location_id INTEGER NOT NULL REFERENCES locations(id),
+	quantity BIGINT NOT NULL,
+	status VARCHAR(10) NOT NULL,
+	reference VARCHAR(100) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_replenishment_tasks_status ON replenishment_tasks(status);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_alerts;
+DROP TABLE IF EXISTS reorder_policies;
+
+ALTER TABLE locations DROP COLUMN IF EXISTS warehouse;
@@ -0,0 +1,30 @@
+-- Warehouses group locations into sites for per-warehouse stock levels
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS warehouse VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Reorder points per SKU, overall (empty warehouse) or per warehouse, and the
+-- low-stock alerts raised against them
+CREATE TABLE IF NOT EXISTS reorder_policies (
+	product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	warehouse VARCHAR(50) NOT NULL DEFAULT '',
+	reorder_point BIGINT NOT NULL,
+	safety_stock BIGINT NOT NULL DEFAULT 0,
+	reorder_qty BIGINT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (product_id, warehouse)
+);
+
+CREATE TABLE IF NOT EXISTS stock_alerts (
+	id SERIAL PRIMARY KEY,
+	product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+	warehouse VARCHAR(50) NOT NULL DEFAULT '',
+	available BIGINT NOT NULL,
+	reorder_point BIGINT NOT NULL,
+	safety_stock BIGINT NOT NULL,
+	severity VARCHAR(10) NOT NULL,
+	raised_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_alerts_open ON stock_alerts(product_id, warehouse) WHERE resolved_at IS NULL;
@@ -0,0 +1,19 @@
+-- Transactional outbox: domain events written with the change that raised
+-- them and relayed to the configured sinks in id order
+CREATE TABLE IF NOT EXISTS outbox (
+	id BIGSERIAL PRIMARY KEY,
+	event_id VARCHAR(64) NOT NULL UNIQUE,
+	aggregate_type VARCHAR(50) NOT NULL,
+	aggregate_id BIGINT NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	occurred_at TIMESTAMP NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	published_at TIMESTAMP,
+	-- Set when relay attempts ran out, so the message stops holding up its aggregate
+	dead_lettered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox(id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_dead_lettered ON outbox(id) WHERE dead_lettered_at IS NOT NULL;
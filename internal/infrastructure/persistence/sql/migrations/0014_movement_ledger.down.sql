@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS stock_reconciliation_repairs;
+DROP TABLE IF EXISTS stock_lot_balances;
+DROP TABLE IF EXISTS stock_balances;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+DROP FUNCTION IF EXISTS stock_movements_append_only();
+
+DROP TABLE IF EXISTS stock_ledger_head;
+
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS sequence;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS lot;
@@ -0,0 +1,74 @@
+-- Lot of the units moved, empty when not lot-tracked
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS lot VARCHAR(50) NOT NULL DEFAULT '';
+
+-- The ledger is append-only and ordered by a gapless sequence handed out by
+-- the single-row ledger head. Movements recorded before sequences existed
+-- keep their id order.
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS sequence BIGINT;
+UPDATE stock_movements SET sequence = id WHERE sequence IS NULL;
+ALTER TABLE stock_movements ALTER COLUMN sequence SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_movements_sequence ON stock_movements(sequence);
+
+CREATE TABLE IF NOT EXISTS stock_ledger_head (
+	id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+	sequence BIGINT NOT NULL
+);
+INSERT INTO stock_ledger_head (id, sequence)
+SELECT true, COALESCE(MAX(sequence), 0) FROM stock_movements
+ON CONFLICT (id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+CREATE TRIGGER stock_movements_append_only
+	BEFORE UPDATE OR DELETE ON stock_movements
+	FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Balance projections rebuilt from the ledger
+CREATE TABLE IF NOT EXISTS stock_balances (
+	product_id INTEGER NOT NULL,
+	location_id INTEGER NOT NULL,
+	quantity BIGINT NOT NULL,
+	PRIMARY KEY (product_id, location_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_lot_balances (
+	product_id INTEGER NOT NULL,
+	location_id INTEGER NOT NULL,
+	lot VARCHAR(50) NOT NULL,
+	quantity BIGINT NOT NULL,
+	PRIMARY KEY (product_id, location_id, lot)
+);
+
+-- Seed the projections from the existing ledger; rows already projected stay as they are
+INSERT INTO stock_balances (product_id, location_id, quantity)
+SELECT product_id, location_id, SUM(CASE WHEN type = 'IN' THEN quantity ELSE -quantity END)
+FROM stock_movements
+GROUP BY product_id, location_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO stock_lot_balances (product_id, location_id, lot, quantity)
+SELECT product_id, location_id, lot, SUM(CASE WHEN type = 'IN' THEN quantity ELSE -quantity END)
+FROM stock_movements
+WHERE lot <> ''
+GROUP BY product_id, location_id, lot
+ON CONFLICT DO NOTHING;
+
+-- Audit trail of the stored values reconciliation reset to the ledger
+CREATE TABLE IF NOT EXISTS stock_reconciliation_repairs (
+	id BIGSERIAL PRIMARY KEY,
+	scope VARCHAR(20) NOT NULL,
+	product_id INTEGER NOT NULL,
+	location_id INTEGER NOT NULL DEFAULT 0,
+	stored BIGINT NOT NULL,
+	ledger BIGINT NOT NULL,
+	reason TEXT NOT NULL,
+	repaired_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reconciliation_repairs_product_id ON stock_reconciliation_repairs(product_id);
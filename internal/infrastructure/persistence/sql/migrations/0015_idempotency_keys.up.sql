@@ -0,0 +1,14 @@
+-- Idempotency keys of create requests and the response to replay for retries
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	scope VARCHAR(100) NOT NULL,
+	idem_key VARCHAR(255) NOT NULL,
+	fingerprint VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	status_code INTEGER NOT NULL DEFAULT 0,
+	content_type VARCHAR(100) NOT NULL DEFAULT '',
+	body BYTEA,
+	completed_at TIMESTAMP,
+	PRIMARY KEY (scope, idem_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE locations DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- Optimistic concurrency versions, bumped on every master data update
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE locations ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at ON stock_movements(created_at);
+DROP INDEX IF EXISTS idx_stock_movements_created_at_id;
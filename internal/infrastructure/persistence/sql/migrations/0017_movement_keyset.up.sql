@@ -0,0 +1,3 @@
+-- Cursor pages of the movement list seek on (created_at, id) instead of counting off rows
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at_id ON stock_movements(created_at, id);
+DROP INDEX IF EXISTS idx_stock_movements_created_at;
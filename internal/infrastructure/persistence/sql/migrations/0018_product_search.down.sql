@@ -0,0 +1,5 @@
+-- pg_trgm stays installed; other schemas in the database may use it
+DROP INDEX IF EXISTS idx_product_barcodes_value_trgm;
+DROP INDEX IF EXISTS idx_products_sku_name_trgm;
+DROP INDEX IF EXISTS idx_products_search_document;
+ALTER TABLE products DROP COLUMN IF EXISTS search_document;
@@ -0,0 +1,13 @@
+-- Ranked product search: full text over the SKU and descriptive fields,
+-- trigrams for partial SKUs and barcodes
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_document TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', sku_name), 'A') ||
+	setweight(to_tsvector('simple', description), 'B') ||
+	setweight(to_tsvector('simple', category || ' ' || brand), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_document ON products USING GIN (search_document);
+CREATE INDEX IF NOT EXISTS idx_products_sku_name_trgm ON products USING GIN (sku_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_product_barcodes_value_trgm ON product_barcodes USING GIN (value gin_trgm_ops);
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
+)
+
+// OutboxRepository implements outbox.Repository
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Append stores messages in the caller's transaction. Each aggregate is first
+// locked until the transaction ends, so a concurrent transaction for the same
+// aggregate gets later IDs and, per aggregate, ID order matches commit order.
+// Aggregates are locked in a fixed order to avoid deadlocks.
+func (r *OutboxRepository) Append(ctx context.Context, messages []*outbox.Message) error {
+	keys := make([]string, 0, len(messages))
+	seen := make(map[string]bool, len(messages))
+	for _, m := range messages {
+		if key := m.AggregateKey(); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	db := conn(ctx, r.db)
+	for _, key := range keys {
+		if _, err := db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+			return fmt.Errorf("failed to lock outbox aggregate: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO outbox (event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	for _, m := range messages {
+		err := db.QueryRowContext(ctx, query,
+			m.EventID, m.AggregateType, m.AggregateID, m.EventType, string(m.Payload), m.OccurredAt,
+		).Scan(&m.ID)
+		if err != nil {
+			return fmt.Errorf("failed to append outbox message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Unpublished retrieves the oldest unpublished, live messages in ID order
+func (r *OutboxRepository) Unpublished(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	query := `
+		SELECT id, event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts, last_error
+		FROM outbox
+		WHERE published_at IS NULL AND dead_lettered_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*outbox.Message
+	for rows.Next() {
+		m := &outbox.Message{}
+		var payload string
+		if err := rows.Scan(&m.ID, &m.EventID, &m.AggregateType, &m.AggregateID, &m.EventType,
+			&payload, &m.OccurredAt, &m.Attempts, &m.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		m.Payload = []byte(payload)
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkPublished records that the message has been relayed
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = '', published_at = $1 WHERE id = $2`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, at, id); err != nil {
+		return fmt.Errorf("failed to mark outbox message published: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed relay attempt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, reason, id); err != nil {
+		return fmt.Errorf("failed to record outbox failure: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDeadLettered records the final failed attempt and retires the message
+func (r *OutboxRepository) MarkDeadLettered(ctx context.Context, id int64, reason string, at time.Time) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1, dead_lettered_at = $2 WHERE id = $3`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, reason, at, id); err != nil {
+		return fmt.Errorf("failed to dead-letter outbox message: %w", err)
+	}
+
+	return nil
+}
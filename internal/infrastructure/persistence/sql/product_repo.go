@@ -5,9 +5,15 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/lib/pq"
 )
 
+// productColumns is the column list shared by every product SELECT
+const productColumns = `id, sku_name, quantity, description, category, brand, base_uom,
+	length_mm, width_mm, height_mm, weight_g, shelf_life_days, status, serialized, version`
+
 // ProductRepository implements product.Repository
 type ProductRepository struct {
 	db *sql.DB
@@ -18,32 +24,71 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-// Create saves a new product
+// scanProduct reads one product row selected with productColumns
+func scanProduct(row rowScanner) (*product.Product, error) {
+	p := &product.Product{}
+	err := row.Scan(
+		&p.ID, &p.SKUName, &p.Quantity, &p.Description, &p.Category, &p.Brand, &p.BaseUOM,
+		&p.Dimensions.LengthMM, &p.Dimensions.WidthMM, &p.Dimensions.HeightMM,
+		&p.WeightGrams, &p.ShelfLifeDays, &p.Status, &p.Serialized, &p.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Create saves a new product together with its barcodes
 func (r *ProductRepository) Create(ctx context.Context, p *product.Product) error {
 	query := `
-		INSERT INTO products (sku_name, quantity)
-		VALUES ($1, $2)
-		RETURNING id
+		INSERT INTO products (sku_name, description, category, brand, base_uom,
+			length_mm, width_mm, height_mm, weight_g, shelf_life_days, status, serialized)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, quantity, version
 	`
 
-	err := r.db.QueryRowContext(ctx, query, p.SKUName, p.Quantity).Scan(&p.ID)
-	if err != nil {
-		return fmt.Errorf("failed to create product: %w", err)
-	}
+	return NewTransactionManager(r.db).WithTx(ctx, func(ctx context.Context) error {
+		err := conn(ctx, r.db).QueryRowContext(ctx, query,
+			p.SKUName, p.Description, p.Category, p.Brand, p.BaseUOM,
+			p.Dimensions.LengthMM, p.Dimensions.WidthMM, p.Dimensions.HeightMM,
+			p.WeightGrams, p.ShelfLifeDays, p.Status, p.Serialized,
+		).Scan(&p.ID, &p.Quantity, &p.Version)
+		if err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
 
-	return nil
+		if err := r.saveBarcodes(ctx, p); err != nil {
+			return err
+		}
+		return r.savePacks(ctx, p)
+	})
 }
 
 // GetByID retrieves a product by ID
 func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*product.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+// GetBySKU retrieves a product by SKU name
+func (r *ProductRepository) GetBySKU(ctx context.Context, skuName string) (*product.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE sku_name = $1`
+	return r.getOne(ctx, query, skuName)
+}
+
+// GetByBarcode retrieves a product by one of its barcodes
+func (r *ProductRepository) GetByBarcode(ctx context.Context, barcode string) (*product.Product, error) {
 	query := `
-		SELECT id, sku_name, quantity
+		SELECT ` + productColumns + `
 		FROM products
-		WHERE id = $1
+		WHERE id = (SELECT product_id FROM product_barcodes WHERE value = $1)
 	`
+	return r.getOne(ctx, query, barcode)
+}
 
-	p := &product.Product{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.SKUName, &p.Quantity)
+// getOne runs a single-product query and loads the product's barcodes
+func (r *ProductRepository) getOne(ctx context.Context, query string, arg interface{}) (*product.Product, error) {
+	p, err := scanProduct(conn(ctx, r.db).QueryRowContext(ctx, query, arg))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, product.ErrProductNotFound
@@ -51,50 +96,126 @@ func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*product.Pro
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
+	if err := r.loadDetails(ctx, []*product.Product{p}); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
-// GetBySKU retrieves a product by SKU name
-func (r *ProductRepository) GetBySKU(ctx context.Context, skuName string) (*product.Product, error) {
+// productSortColumns maps the sort fields of product listings to columns
+var productSortColumns = map[string]string{
+	"id":       "id",
+	"sku_name": "sku_name",
+	"category": "category",
+	"brand":    "brand",
+	"status":   "status",
+	"quantity": "quantity",
+}
+
+// productConditions turns a product spec's filter and search into WHERE conditions
+func productConditions(spec product.ListSpec) *conditions {
+	c := &conditions{}
+	f := spec.Filter
+	if f.SKUPrefix != "" {
+		c.prefix("sku_name", f.SKUPrefix)
+	}
+	if f.Category != "" {
+		c.equal("category", f.Category)
+	}
+	if f.Brand != "" {
+		c.equal("brand", f.Brand)
+	}
+	if f.Status != "" {
+		c.equal("status", f.Status)
+	}
+	c.int64Range("quantity", f.Quantity)
+	c.search(spec.Search, "sku_name", "description", "category", "brand")
+	return c
+}
+
+// List retrieves the products the spec selects, newest first unless it sorts otherwise
+func (r *ProductRepository) List(ctx context.Context, spec product.ListSpec) ([]*product.Product, error) {
+	order := listing.Order(spec.Sort, product.DefaultOrder)
+	c := productConditions(spec)
+	c.after(order, productSortColumns, spec.After)
 	query := `
-		SELECT id, sku_name, quantity
+		SELECT ` + productColumns + `
 		FROM products
-		WHERE sku_name = $1
-	`
+		` + c.where() + `
+		` + orderBy(order, productSortColumns) + `
+		` + c.page(spec.Limit, spec.Offset)
 
-	p := &product.Product{}
-	err := r.db.QueryRowContext(ctx, query, skuName).Scan(&p.ID, &p.SKUName, &p.Quantity)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, c.args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, product.ErrProductNotFound
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	var products []*product.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get product: %w", err)
+		products = append(products, p)
 	}
 
-	return p, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating products: %w", err)
+	}
+
+	if err := r.loadDetails(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
 }
 
-// List retrieves all products with pagination
-func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+// Search ranks the products matching text, best first. An exact SKU outranks
+// a SKU prefix, which outranks partial and fuzzy SKU matches; full-text
+// matches on the descriptive fields and barcodes containing text add to that.
+func (r *ProductRepository) Search(ctx context.Context, text string, limit int) ([]product.SearchHit, error) {
+	escaped := likeEscaper.Replace(text)
 	query := `
-		SELECT id, sku_name, quantity
+		WITH barcode_matches AS (
+			SELECT product_id, 1 + MAX(similarity(value, $1)) AS score
+			FROM product_barcodes
+			WHERE value LIKE $3
+			GROUP BY product_id
+		)
+		SELECT ` + productColumns + `,
+			CASE WHEN lower(sku_name) = lower($1) THEN 4 ELSE 0 END
+			+ CASE WHEN sku_name ILIKE $2 THEN 2 ELSE 0 END
+			+ word_similarity($1, sku_name)
+			+ ts_rank(search_document, plainto_tsquery('simple', $1))
+			+ COALESCE(barcode_matches.score, 0) AS score
 		FROM products
-		ORDER BY id DESC
-		LIMIT $1 OFFSET $2
+		LEFT JOIN barcode_matches ON barcode_matches.product_id = products.id
+		WHERE search_document @@ plainto_tsquery('simple', $1)
+			OR sku_name ILIKE $3
+			OR $1 <% sku_name
+			OR barcode_matches.product_id IS NOT NULL
+		ORDER BY score DESC, id DESC
+		LIMIT $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, text, escaped+"%", "%"+escaped+"%", limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list products: %w", err)
+		return nil, fmt.Errorf("failed to search products: %w", err)
 	}
 	defer rows.Close()
 
+	var hits []product.SearchHit
 	var products []*product.Product
 	for rows.Next() {
-		p := &product.Product{}
-		if err := rows.Scan(&p.ID, &p.SKUName, &p.Quantity); err != nil {
+		var hit product.SearchHit
+		p, err := scanProduct(scoredRow{row: rows, score: &hit.Score})
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan product: %w", err)
 		}
+		hit.Product = p
+		hits = append(hits, hit)
 		products = append(products, p)
 	}
 
@@ -102,20 +223,199 @@ func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]*pro
 		return nil, fmt.Errorf("error iterating products: %w", err)
 	}
 
-	return products, nil
+	if err := r.loadDetails(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// scoredRow reads a product row followed by a score column
+type scoredRow struct {
+	row   rowScanner
+	score *float64
+}
+
+func (s scoredRow) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.score)...)
+}
+
+// loadDetails fills in the barcodes and pack hierarchy of the given products
+func (r *ProductRepository) loadDetails(ctx context.Context, products []*product.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*product.Product, len(products))
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+
+	if err := r.loadBarcodes(ctx, byID, ids); err != nil {
+		return err
+	}
+	return r.loadPacks(ctx, byID, ids)
+}
+
+// loadBarcodes fills in the barcodes of the given products with a single query
+func (r *ProductRepository) loadBarcodes(ctx context.Context, byID map[int64]*product.Product, ids []int64) error {
+	query := `
+		SELECT product_id, type, value
+		FROM product_barcodes
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, position
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load product barcodes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int64
+		var b product.Barcode
+		if err := rows.Scan(&productID, &b.Type, &b.Value); err != nil {
+			return fmt.Errorf("failed to scan product barcode: %w", err)
+		}
+		if p, ok := byID[productID]; ok {
+			p.Barcodes = append(p.Barcodes, b)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating product barcodes: %w", err)
+	}
+
+	return nil
+}
+
+// loadPacks fills in the pack hierarchy of the given products with a single query
+func (r *ProductRepository) loadPacks(ctx context.Context, byID map[int64]*product.Product, ids []int64) error {
+	query := `
+		SELECT product_id, level, units_per_pack
+		FROM product_packs
+		WHERE product_id = ANY($1)
+		ORDER BY product_id, units_per_pack
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load product packs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int64
+		var pack product.Pack
+		if err := rows.Scan(&productID, &pack.Level, &pack.UnitsPerPack); err != nil {
+			return fmt.Errorf("failed to scan product pack: %w", err)
+		}
+		if p, ok := byID[productID]; ok {
+			p.Packs = append(p.Packs, pack)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating product packs: %w", err)
+	}
+
+	return nil
+}
+
+// savePacks replaces the stored pack hierarchy of a product
+func (r *ProductRepository) savePacks(ctx context.Context, p *product.Product) error {
+	db := conn(ctx, r.db)
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM product_packs WHERE product_id = $1`, p.ID); err != nil {
+		return fmt.Errorf("failed to clear product packs: %w", err)
+	}
+
+	for _, pack := range p.Packs {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO product_packs (product_id, level, units_per_pack) VALUES ($1, $2, $3)`,
+			p.ID, pack.Level, pack.UnitsPerPack,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save product pack: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// saveBarcodes replaces the stored barcodes of a product
+func (r *ProductRepository) saveBarcodes(ctx context.Context, p *product.Product) error {
+	db := conn(ctx, r.db)
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM product_barcodes WHERE product_id = $1`, p.ID); err != nil {
+		return fmt.Errorf("failed to clear product barcodes: %w", err)
+	}
+
+	for i, b := range p.Barcodes {
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO product_barcodes (product_id, type, value, position) VALUES ($1, $2, $3, $4)`,
+			p.ID, b.Type, b.Value, i,
+		)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return product.ErrDuplicateBarcode
+			}
+			return fmt.Errorf("failed to save product barcode: %w", err)
+		}
+	}
+
+	return nil
 }
 
-// Update updates an existing product
+// Update updates the master data of an existing product if it is still at
+// p.Version. Quantity is left untouched.
 func (r *ProductRepository) Update(ctx context.Context, p *product.Product) error {
 	query := `
 		UPDATE products
-		SET sku_name = $1, quantity = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
+		SET sku_name = $1, description = $2, category = $3, brand = $4, base_uom = $5,
+			length_mm = $6, width_mm = $7, height_mm = $8, weight_g = $9,
+			shelf_life_days = $10, status = $11, serialized = $12,
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $13 AND version = $14
+		RETURNING version
 	`
 
-	result, err := r.db.ExecContext(ctx, query, p.SKUName, p.Quantity, p.ID)
+	return NewTransactionManager(r.db).WithTx(ctx, func(ctx context.Context) error {
+		var version int64
+		err := conn(ctx, r.db).QueryRowContext(ctx, query,
+			p.SKUName, p.Description, p.Category, p.Brand, p.BaseUOM,
+			p.Dimensions.LengthMM, p.Dimensions.WidthMM, p.Dimensions.HeightMM, p.WeightGrams,
+			p.ShelfLifeDays, p.Status, p.Serialized, p.ID, p.Version,
+		).Scan(&version)
+		if err == sql.ErrNoRows {
+			return r.versionMismatch(ctx, p.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update product: %w", err)
+		}
+		p.Version = version
+
+		if err := r.saveBarcodes(ctx, p); err != nil {
+			return err
+		}
+		return r.savePacks(ctx, p)
+	})
+}
+
+// UpdateQuantity persists the product's current quantity
+func (r *ProductRepository) UpdateQuantity(ctx context.Context, p *product.Product) error {
+	query := `
+		UPDATE products
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, p.Quantity, p.ID)
 	if err != nil {
-		return fmt.Errorf("failed to update product: %w", err)
+		return fmt.Errorf("failed to update product quantity: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -130,11 +430,11 @@ func (r *ProductRepository) Update(ctx context.Context, p *product.Product) erro
 	return nil
 }
 
-// Delete deletes a product
-func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM products WHERE id = $1`
+// Delete deletes a product if it is still at the given version
+func (r *ProductRepository) Delete(ctx context.Context, id, version int64) error {
+	query := `DELETE FROM products WHERE id = $1 AND version = $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
@@ -145,18 +445,32 @@ func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return product.ErrProductNotFound
+		return r.versionMismatch(ctx, id)
 	}
 
 	return nil
 }
 
-// Count returns total number of products
-func (r *ProductRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM products`
+// versionMismatch tells a missing product apart from one at another version
+func (r *ProductRepository) versionMismatch(ctx context.Context, id int64) error {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check product version: %w", err)
+	}
+	if !exists {
+		return product.ErrProductNotFound
+	}
+	return product.ErrConcurrentModification
+}
+
+// Count returns how many products match the spec's filter and search
+func (r *ProductRepository) Count(ctx context.Context, spec product.ListSpec) (int64, error) {
+	c := productConditions(spec)
+	query := `SELECT COUNT(*) FROM products ` + c.where()
 
 	var count int64
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, c.args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count products: %w", err)
 	}
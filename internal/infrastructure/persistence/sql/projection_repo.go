@@ -0,0 +1,138 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// ProjectionRepository implements stock.ProjectionRepository
+type ProjectionRepository struct {
+	db *sql.DB
+}
+
+// NewProjectionRepository creates a new projection repository
+func NewProjectionRepository(db *sql.DB) *ProjectionRepository {
+	return &ProjectionRepository{db: db}
+}
+
+// AddBalances adds the given quantities to the per-location balances
+func (r *ProjectionRepository) AddBalances(ctx context.Context, deltas []stock.Balance) error {
+	query := `
+		INSERT INTO stock_balances (product_id, location_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, location_id)
+		DO UPDATE SET quantity = stock_balances.quantity + EXCLUDED.quantity
+	`
+
+	for _, d := range deltas {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, d.ProductID, d.LocationID, d.Quantity); err != nil {
+			return fmt.Errorf("failed to update stock balance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// AddLotBalances adds the given quantities to the per-lot balances
+func (r *ProjectionRepository) AddLotBalances(ctx context.Context, deltas []stock.LotBalance) error {
+	query := `
+		INSERT INTO stock_lot_balances (product_id, location_id, lot, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, location_id, lot)
+		DO UPDATE SET quantity = stock_lot_balances.quantity + EXCLUDED.quantity
+	`
+
+	for _, d := range deltas {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, d.ProductID, d.LocationID, d.Lot, d.Quantity); err != nil {
+			return fmt.Errorf("failed to update lot balance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ResetBalances removes every per-location balance
+func (r *ProjectionRepository) ResetBalances(ctx context.Context) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM stock_balances`); err != nil {
+		return fmt.Errorf("failed to reset stock balances: %w", err)
+	}
+	return nil
+}
+
+// ResetLotBalances removes every per-lot balance
+func (r *ProjectionRepository) ResetLotBalances(ctx context.Context) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM stock_lot_balances`); err != nil {
+		return fmt.Errorf("failed to reset lot balances: %w", err)
+	}
+	return nil
+}
+
+// ListBalances returns the non-zero per-location balances matching the filter
+func (r *ProjectionRepository) ListBalances(ctx context.Context, filter stock.BalanceFilter) ([]stock.Balance, error) {
+	query := `
+		SELECT product_id, location_id, quantity
+		FROM stock_balances
+		WHERE quantity <> 0
+			AND ($1 = 0 OR product_id = $1)
+			AND ($2 = 0 OR location_id = $2)
+		ORDER BY product_id, location_id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, filter.ProductID, filter.LocationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []stock.Balance
+	for rows.Next() {
+		var b stock.Balance
+		if err := rows.Scan(&b.ProductID, &b.LocationID, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan stock balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock balances: %w", err)
+	}
+
+	return balances, nil
+}
+
+// ListLotBalances returns the non-zero per-lot balances matching the filter
+func (r *ProjectionRepository) ListLotBalances(ctx context.Context, filter stock.BalanceFilter) ([]stock.LotBalance, error) {
+	query := `
+		SELECT product_id, location_id, lot, quantity
+		FROM stock_lot_balances
+		WHERE quantity <> 0
+			AND ($1 = 0 OR product_id = $1)
+			AND ($2 = 0 OR location_id = $2)
+			AND ($3 = '' OR lot = $3)
+		ORDER BY product_id, location_id, lot
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, filter.ProductID, filter.LocationID, filter.Lot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lot balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []stock.LotBalance
+	for rows.Next() {
+		var b stock.LotBalance
+		if err := rows.Scan(&b.ProductID, &b.LocationID, &b.Lot, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan lot balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lot balances: %w", err)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+)
+
+// putawayRuleColumns is the column list shared by every putaway rule SELECT
+const putawayRuleColumns = `category, temperature_zone, location_prefix, allow_mixed_skus`
+
+// PutawayRuleRepository implements putaway.RuleRepository
+type PutawayRuleRepository struct {
+	db *sql.DB
+}
+
+// NewPutawayRuleRepository creates a new putaway rule repository
+func NewPutawayRuleRepository(db *sql.DB) *PutawayRuleRepository {
+	return &PutawayRuleRepository{db: db}
+}
+
+// scanPutawayRule reads one rule row selected with putawayRuleColumns
+func scanPutawayRule(row rowScanner) (*putaway.Rule, error) {
+	r := &putaway.Rule{}
+	if err := row.Scan(&r.Category, &r.TemperatureZone, &r.LocationPrefix, &r.AllowMixedSKUs); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get retrieves the rule for a product category
+func (r *PutawayRuleRepository) Get(ctx context.Context, category string) (*putaway.Rule, error) {
+	query := `SELECT ` + putawayRuleColumns + ` FROM putaway_rules WHERE category = $1`
+
+	rule, err := scanPutawayRule(conn(ctx, r.db).QueryRowContext(ctx, query, category))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, putaway.ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get putaway rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// List retrieves all rules ordered by category
+func (r *PutawayRuleRepository) List(ctx context.Context) ([]*putaway.Rule, error) {
+	query := `SELECT ` + putawayRuleColumns + ` FROM putaway_rules ORDER BY category`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list putaway rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*putaway.Rule
+	for rows.Next() {
+		rule, err := scanPutawayRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan putaway rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating putaway rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Save creates or replaces the rule for its category
+func (r *PutawayRuleRepository) Save(ctx context.Context, rule *putaway.Rule) error {
+	query := `
+		INSERT INTO putaway_rules (category, temperature_zone, location_prefix, allow_mixed_skus)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (category) DO UPDATE
+		SET temperature_zone = EXCLUDED.temperature_zone,
+			location_prefix = EXCLUDED.location_prefix,
+			allow_mixed_skus = EXCLUDED.allow_mixed_skus,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		rule.Category, rule.TemperatureZone, rule.LocationPrefix, rule.AllowMixedSKUs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save putaway rule: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the rule for a category
+func (r *PutawayRuleRepository) Delete(ctx context.Context, category string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM putaway_rules WHERE category = $1`, category)
+	if err != nil {
+		return fmt.Errorf("failed to delete putaway rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return putaway.ErrRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+)
+
+// stockAlertColumns is the column list shared by every stock alert SELECT
+const stockAlertColumns = `id, product_id, warehouse, available, reorder_point, safety_stock, severity, raised_at, updated_at, resolved_at`
+
+// ReorderRepository implements replenishment.ReorderRepository
+type ReorderRepository struct {
+	db *sql.DB
+}
+
+// NewReorderRepository creates a new reorder repository
+func NewReorderRepository(db *sql.DB) *ReorderRepository {
+	return &ReorderRepository{db: db}
+}
+
+// scanStockAlert reads one alert row selected with stockAlertColumns
+func scanStockAlert(row rowScanner) (*replenishment.Alert, error) {
+	a := &replenishment.Alert{}
+	var resolvedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.ProductID, &a.Warehouse, &a.Available, &a.ReorderPoint, &a.SafetyStock,
+		&a.Severity, &a.RaisedAt, &a.UpdatedAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		a.ResolvedAt = &resolvedAt.Time
+	}
+	return a, nil
+}
+
+// SavePolicy creates or replaces the reorder policy of a SKU for a warehouse
+func (r *ReorderRepository) SavePolicy(ctx context.Context, p *replenishment.ReorderPolicy) error {
+	query := `
+		INSERT INTO reorder_policies (product_id, warehouse, reorder_point, safety_stock, reorder_qty)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, warehouse) DO UPDATE
+		SET reorder_point = EXCLUDED.reorder_point,
+			safety_stock = EXCLUDED.safety_stock,
+			reorder_qty = EXCLUDED.reorder_qty,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, p.ProductID, p.Warehouse, p.ReorderPoint, p.SafetyStock, p.ReorderQty)
+	if err != nil {
+		return fmt.Errorf("failed to save reorder policy: %w", err)
+	}
+
+	return nil
+}
+
+// ListPolicies retrieves a product's policies, or all when productID is 0
+func (r *ReorderRepository) ListPolicies(ctx context.Context, productID int64) ([]*replenishment.ReorderPolicy, error) {
+	query := `
+		SELECT product_id, warehouse, reorder_point, safety_stock, reorder_qty
+		FROM reorder_policies
+		WHERE $1 = 0 OR product_id = $1
+		ORDER BY product_id, warehouse
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reorder policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*replenishment.ReorderPolicy
+	for rows.Next() {
+		p := &replenishment.ReorderPolicy{}
+		if err := rows.Scan(&p.ProductID, &p.Warehouse, &p.ReorderPoint, &p.SafetyStock, &p.ReorderQty); err != nil {
+			return nil, fmt.Errorf("failed to scan reorder policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reorder policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// DeletePolicy removes a reorder policy
+func (r *ReorderRepository) DeletePolicy(ctx context.Context, productID int64, warehouse string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM reorder_policies WHERE product_id = $1 AND warehouse = $2`, productID, warehouse)
+	if err != nil {
+		return fmt.Errorf("failed to delete reorder policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return replenishment.ErrPolicyNotFound
+	}
+
+	return nil
+}
+
+// OpenAlert retrieves the open alert of a policy
+func (r *ReorderRepository) OpenAlert(ctx context.Context, productID int64, warehouse string) (*replenishment.Alert, error) {
+	query := `
+		SELECT ` + stockAlertColumns + `
+		FROM stock_alerts
+		WHERE product_id = $1 AND warehouse = $2 AND resolved_at IS NULL
+	`
+
+	a, err := scanStockAlert(conn(ctx, r.db).QueryRowContext(ctx, query, productID, warehouse))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, replenishment.ErrAlertNotFound
+		}
+		return nil, fmt.Errorf("failed to get stock alert: %w", err)
+	}
+
+	return a, nil
+}
+
+// SaveAlert inserts a new alert or updates an existing one
+func (r *ReorderRepository) SaveAlert(ctx context.Context, a *replenishment.Alert) error {
+	if a.ID == 0 {
+		query := `
+			INSERT INTO stock_alerts (product_id, warehouse, available, reorder_point, safety_stock, severity, raised_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`
+
+		err := conn(ctx, r.db).QueryRowContext(ctx, query,
+			a.ProductID, a.Warehouse, a.Available, a.ReorderPoint, a.SafetyStock, a.Severity, a.RaisedAt, a.UpdatedAt,
+		).Scan(&a.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create stock alert: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE stock_alerts
+		SET available = $1, reorder_point = $2, safety_stock = $3, severity = $4, updated_at = $5, resolved_at = $6
+		WHERE id = $7
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		a.Available, a.ReorderPoint, a.SafetyStock, a.Severity, a.UpdatedAt, a.ResolvedAt, a.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update stock alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return replenishment.ErrAlertNotFound
+	}
+
+	return nil
+}
+
+// ListAlerts retrieves alerts, newest first, optionally only open ones
+func (r *ReorderRepository) ListAlerts(ctx context.Context, openOnly bool, limit, offset int) ([]*replenishment.Alert, error) {
+	query := `
+		SELECT ` + stockAlertColumns + `
+		FROM stock_alerts
+		WHERE NOT $1 OR resolved_at IS NULL
+		ORDER BY raised_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, openOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*replenishment.Alert
+	for rows.Next() {
+		a, err := scanStockAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock alerts: %w", err)
+	}
+
+	return alerts, nil
+}
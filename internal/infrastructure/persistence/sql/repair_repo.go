@@ -0,0 +1,37 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// RepairRepository implements stock.RepairRepository
+type RepairRepository struct {
+	db *sql.DB
+}
+
+// NewRepairRepository creates a new reconciliation repair repository
+func NewRepairRepository(db *sql.DB) *RepairRepository {
+	return &RepairRepository{db: db}
+}
+
+// Record stores a repair, inside the caller's transaction when there is one
+func (r *RepairRepository) Record(ctx context.Context, repair *stock.Repair) error {
+	query := `
+		INSERT INTO stock_reconciliation_repairs (scope, product_id, location_id, stored, ledger, reason, repaired_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		repair.Scope, repair.ProductID, repair.LocationID, repair.Stored, repair.Ledger, repair.Reason, repair.RepairedAt,
+	).Scan(&repair.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record reconciliation repair: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/lib/pq"
+)
+
+// replenishmentTaskColumns is the column list shared by every replenishment task SELECT
+const replenishmentTaskColumns = `id, product_id, from_location_id, to_location_id, quantity, status, reference, created_at, completed_at`
+
+// ReplenishmentRepository implements replenishment.Repository
+type ReplenishmentRepository struct {
+	db *sql.DB
+}
+
+// NewReplenishmentRepository creates a new replenishment repository
+func NewReplenishmentRepository(db *sql.DB) *ReplenishmentRepository {
+	return &ReplenishmentRepository{db: db}
+}
+
+// scanReplenishmentTask reads one task row selected with replenishmentTaskColumns
+func scanReplenishmentTask(row rowScanner) (*replenishment.Task, error) {
+	t := &replenishment.Task{}
+	var completedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.ProductID, &t.FromLocationID, &t.ToLocationID, &t.Quantity,
+		&t.Status, &t.Reference, &t.CreatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		t.CompletedAt = &completedAt.Time
+	}
+	return t, nil
+}
+
+// SavePickFace creates or replaces the min/max of a SKU at a pick location
+func (r *ReplenishmentRepository) SavePickFace(ctx context.Context, pf *replenishment.PickFace) error {
+	query := `
+		INSERT INTO pick_faces (product_id, location_id, min_qty, max_qty)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, location_id) DO UPDATE
+		SET min_qty = EXCLUDED.min_qty,
+			max_qty = EXCLUDED.max_qty
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, pf.ProductID, pf.LocationID, pf.Min, pf.Max)
+	if err != nil {
+		return fmt.Errorf("failed to save pick face: %w", err)
+	}
+
+	return nil
+}
+
+// ListPickFaces retrieves the pick faces of the given products, or all when
+// productIDs is empty. Inside a transaction the rows are locked so concurrent
+// replenishment runs cannot create tasks for the same pick face twice.
+func (r *ReplenishmentRepository) ListPickFaces(ctx context.Context, productIDs []int64) ([]*replenishment.PickFace, error) {
+	query := `
+		SELECT product_id, location_id, min_qty, max_qty
+		FROM pick_faces
+		WHERE cardinality($1::int8[]) = 0 OR product_id = ANY($1)
+		ORDER BY product_id, location_id
+	`
+	if GetTx(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pick faces: %w", err)
+	}
+	defer rows.Close()
+
+	var faces []*replenishment.PickFace
+	for rows.Next() {
+		pf := &replenishment.PickFace{}
+		if err := rows.Scan(&pf.ProductID, &pf.LocationID, &pf.Min, &pf.Max); err != nil {
+			return nil, fmt.Errorf("failed to scan pick face: %w", err)
+		}
+		faces = append(faces, pf)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pick faces: %w", err)
+	}
+
+	return faces, nil
+}
+
+// DeletePickFace removes a pick face setting
+func (r *ReplenishmentRepository) DeletePickFace(ctx context.Context, productID, locationID int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM pick_faces WHERE product_id = $1 AND location_id = $2`, productID, locationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete pick face: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return replenishment.ErrPickFaceNotFound
+	}
+
+	return nil
+}
+
+// CreateTask saves a new task
+func (r *ReplenishmentRepository) CreateTask(ctx context.Context, t *replenishment.Task) error {
+	query := `
+		INSERT INTO replenishment_tasks (product_id, from_location_id, to_location_id, quantity, status, reference, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		t.ProductID, t.FromLocationID, t.ToLocationID, t.Quantity, t.Status, t.Reference, t.CreatedAt,
+	).Scan(&t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create replenishment task: %w", err)
+	}
+
+	return nil
+}
+
+// GetTask retrieves a task by ID. Inside a transaction the row is locked so a
+// task cannot be completed twice.
+func (r *ReplenishmentRepository) GetTask(ctx context.Context, id int64) (*replenishment.Task, error) {
+	query := `SELECT ` + replenishmentTaskColumns + ` FROM replenishment_tasks WHERE id = $1`
+	if GetTx(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+
+	t, err := scanReplenishmentTask(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, replenishment.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get replenishment task: %w", err)
+	}
+
+	return t, nil
+}
+
+// UpdateTask saves a task's status
+func (r *ReplenishmentRepository) UpdateTask(ctx context.Context, t *replenishment.Task) error {
+	query := `
+		UPDATE replenishment_tasks
+		SET status = $1, completed_at = $2
+		WHERE id = $3
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, t.Status, t.CompletedAt, t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update replenishment task: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return replenishment.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// ListTasks retrieves tasks, newest first, optionally by status
+func (r *ReplenishmentRepository) ListTasks(ctx context.Context, status replenishment.TaskStatus, limit, offset int) ([]*replenishment.Task, error) {
+	query := `
+		SELECT ` + replenishmentTaskColumns + `
+		FROM replenishment_tasks
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryTasks(ctx, query, status, limit, offset)
+}
+
+// OpenTasks retrieves every open task
+func (r *ReplenishmentRepository) OpenTasks(ctx context.Context) ([]*replenishment.Task, error) {
+	query := `SELECT ` + replenishmentTaskColumns + ` FROM replenishment_tasks WHERE status = $1 ORDER BY id`
+
+	return r.queryTasks(ctx, query, replenishment.TaskStatusOpen)
+}
+
+// queryTasks runs a task SELECT and scans every row
+func (r *ReplenishmentRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*replenishment.Task, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replenishment tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*replenishment.Task
+	for rows.Next() {
+		t, err := scanReplenishmentTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan replenishment task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating replenishment tasks: %w", err)
+	}
+
+	return tasks, nil
+}
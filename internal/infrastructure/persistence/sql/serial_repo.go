@@ -0,0 +1,180 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
+	"github.com/lib/pq"
+)
+
+// serialColumns is the column list shared by every serial SELECT
+const serialColumns = `id, product_id, serial_number, COALESCE(location_id, 0), status, updated_at`
+
+// SerialRepository implements serial.Repository
+type SerialRepository struct {
+	db *sql.DB
+}
+
+// NewSerialRepository creates a new serial repository
+func NewSerialRepository(db *sql.DB) *SerialRepository {
+	return &SerialRepository{db: db}
+}
+
+// scanSerial reads one serial row selected with serialColumns
+func scanSerial(row rowScanner) (*serial.Serial, error) {
+	s := &serial.Serial{}
+	err := row.Scan(&s.ID, &s.ProductID, &s.Number, &s.LocationID, &s.Status, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetByNumber retrieves a product's serial by number. Inside a transaction the
+// row is locked so concurrent movements of the same unit are serialized.
+func (r *SerialRepository) GetByNumber(ctx context.Context, productID int64, number string) (*serial.Serial, error) {
+	query := `SELECT ` + serialColumns + ` FROM serials WHERE product_id = $1 AND serial_number = $2`
+	if GetTx(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+
+	s, err := scanSerial(conn(ctx, r.db).QueryRowContext(ctx, query, productID, number))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, serial.ErrSerialNotFound
+		}
+		return nil, fmt.Errorf("failed to get serial: %w", err)
+	}
+
+	return s, nil
+}
+
+// Save inserts a new serial or updates an existing one
+func (r *SerialRepository) Save(ctx context.Context, s *serial.Serial) error {
+	if s.ID == 0 {
+		query := `
+			INSERT INTO serials (product_id, serial_number, location_id, status, updated_at)
+			VALUES ($1, $2, NULLIF($3, 0), $4, $5)
+			RETURNING id
+		`
+
+		err := conn(ctx, r.db).QueryRowContext(ctx, query,
+			s.ProductID, s.Number, s.LocationID, s.Status, s.UpdatedAt,
+		).Scan(&s.ID)
+		if err != nil {
+			// Another movement received the same serial first
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return serial.ErrSerialInStock
+			}
+			return fmt.Errorf("failed to create serial: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE serials
+		SET location_id = NULLIF($1, 0), status = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, s.LocationID, s.Status, s.UpdatedAt, s.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update serial: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return serial.ErrSerialNotFound
+	}
+
+	return nil
+}
+
+// AddEvent appends an entry to a serial's history
+func (r *SerialRepository) AddEvent(ctx context.Context, e *serial.Event) error {
+	query := `
+		INSERT INTO serial_events (serial_id, movement_id, movement_type, location_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		e.SerialID, e.MovementID, e.MovementType, e.LocationID, e.Status, e.CreatedAt,
+	).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create serial event: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves a product's serials
+func (r *SerialRepository) List(ctx context.Context, productID int64, filter serial.Filter, limit, offset int) ([]*serial.Serial, error) {
+	query := `
+		SELECT ` + serialColumns + `
+		FROM serials
+		WHERE product_id = $1
+			AND ($2 = 0 OR location_id = $2)
+			AND ($3 = '' OR status = $3)
+		ORDER BY serial_number
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID, filter.LocationID, filter.Status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serials: %w", err)
+	}
+	defer rows.Close()
+
+	var serials []*serial.Serial
+	for rows.Next() {
+		s, err := scanSerial(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan serial: %w", err)
+		}
+		serials = append(serials, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating serials: %w", err)
+	}
+
+	return serials, nil
+}
+
+// History retrieves a serial's events, oldest first
+func (r *SerialRepository) History(ctx context.Context, serialID int64) ([]*serial.Event, error) {
+	query := `
+		SELECT id, serial_id, movement_id, movement_type, location_id, status, created_at
+		FROM serial_events
+		WHERE serial_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, serialID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get serial history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*serial.Event
+	for rows.Next() {
+		e := &serial.Event{}
+		if err := rows.Scan(&e.ID, &e.SerialID, &e.MovementID, &e.MovementType, &e.LocationID, &e.Status, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan serial event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating serial events: %w", err)
+	}
+
+	return events, nil
+}
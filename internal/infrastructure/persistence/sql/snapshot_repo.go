@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+)
+
+// SnapshotRepository implements stock.SnapshotRepository
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewSnapshotRepository creates a new snapshot repository
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// Save stores a snapshot together with its balances
+func (r *SnapshotRepository) Save(ctx context.Context, s *stock.Snapshot) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO stock_snapshots (taken_at, last_sequence)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, query, s.TakenAt, s.LastSequence).Scan(&s.ID); err != nil {
+		return fmt.Errorf("failed to create stock snapshot: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO stock_snapshot_balances (snapshot_id, product_id, location_id, quantity)
+		VALUES ($1, $2, $3, $4)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare snapshot balance insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, b := range s.Balances {
+		if _, err := stmt.ExecContext(ctx, s.ID, b.ProductID, b.LocationID, b.Quantity); err != nil {
+			return fmt.Errorf("failed to save snapshot balance: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LatestBefore retrieves the most recent snapshot taken at or before the given time
+func (r *SnapshotRepository) LatestBefore(ctx context.Context, at time.Time) (*stock.Snapshot, error) {
+	query := `
+		SELECT id, taken_at, last_sequence
+		FROM stock_snapshots
+		WHERE taken_at <= $1
+		ORDER BY taken_at DESC, id DESC
+		LIMIT 1
+	`
+
+	s := &stock.Snapshot{}
+	err := r.db.QueryRowContext(ctx, query, at).Scan(&s.ID, &s.TakenAt, &s.LastSequence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, stock.ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("failed to get stock snapshot: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT product_id, location_id, quantity
+		FROM stock_snapshot_balances
+		WHERE snapshot_id = $1
+	`, s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot balances: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b stock.Balance
+		if err := rows.Scan(&b.ProductID, &b.LocationID, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot balance: %w", err)
+		}
+		s.Balances = append(s.Balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating snapshot balances: %w", err)
+	}
+
+	return s, nil
+}
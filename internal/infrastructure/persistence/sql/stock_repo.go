@@ -4,10 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 )
 
+// movementColumns is the column list shared by all stock movement selects
+const movementColumns = `id, sequence, product_id, location_id, type, quantity, created_at,
+		reference_type, reference_id, document_number, notes, entered_uom, entered_quantity, lot`
+
 // StockRepository implements stock.Repository
 type StockRepository struct {
 	db *sql.DB
@@ -18,15 +24,25 @@ func NewStockRepository(db *sql.DB) *StockRepository {
 	return &StockRepository{db: db}
 }
 
-// Create saves a new stock movement
+// Create appends a new stock movement to the ledger. Taking the next sequence
+// from the ledger head locks it until commit, so sequences are gapless and
+// follow commit order.
 func (r *StockRepository) Create(ctx context.Context, m *stock.StockMovement) error {
 	query := `
-		INSERT INTO stock_movements (product_id, location_id, type, quantity)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id
+		WITH head AS (
+			UPDATE stock_ledger_head SET sequence = sequence + 1
+			RETURNING sequence
+		)
+		INSERT INTO stock_movements (sequence, product_id, location_id, type, quantity,
+			reference_type, reference_id, document_number, notes, entered_uom, entered_quantity, lot)
+		VALUES ((SELECT sequence FROM head), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, sequence
 	`
 
-	err := r.db.QueryRowContext(ctx, query, m.ProductID, m.LocationID, m.Type, m.Quantity).Scan(&m.ID)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		m.ProductID, m.LocationID, m.Type, m.Quantity,
+		m.ReferenceType, m.ReferenceID, m.DocumentNumber, m.Notes, m.EnteredUOM, m.EnteredQuantity, m.Lot,
+	).Scan(&m.ID, &m.Sequence)
 	if err != nil {
 		return fmt.Errorf("failed to create stock movement: %w", err)
 	}
@@ -37,13 +53,12 @@ func (r *StockRepository) Create(ctx context.Context, m *stock.StockMovement) er
 // GetByID retrieves a stock movement by ID
 func (r *StockRepository) GetByID(ctx context.Context, id int64) (*stock.StockMovement, error) {
 	query := `
-		SELECT id, product_id, location_id, type, quantity, created_at
+		SELECT ` + movementColumns + `
 		FROM stock_movements
 		WHERE id = $1
 	`
 
-	m := &stock.StockMovement{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Type, &m.Quantity, &m.CreatedAt)
+	m, err := scanMovement(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, stock.ErrMovementNotFound
@@ -57,84 +72,263 @@ func (r *StockRepository) GetByID(ctx context.Context, id int64) (*stock.StockMo
 // GetByProduct retrieves all movements for a product
 func (r *StockRepository) GetByProduct(ctx context.Context, productID int64) ([]*stock.StockMovement, error) {
 	query := `
-		SELECT id, product_id, location_id, type, quantity, created_at
+		SELECT ` + movementColumns + `
 		FROM stock_movements
 		WHERE product_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, productID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, productID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stock movements: %w", err)
 	}
 	defer rows.Close()
 
-	var movements []*stock.StockMovement
+	return scanMovements(rows)
+}
+
+// GetByLocation retrieves all movements for a location
+func (r *StockRepository) GetByLocation(ctx context.Context, locationID int64) ([]*stock.StockMovement, error) {
+	query := `
+		SELECT ` + movementColumns + `
+		FROM stock_movements
+		WHERE location_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMovements(rows)
+}
+
+// movementSortColumns maps the sort fields of movement listings to columns
+var movementSortColumns = map[string]string{
+	"id":          "id",
+	"sequence":    "sequence",
+	"created_at":  "created_at",
+	"product_id":  "product_id",
+	"location_id": "location_id",
+	"quantity":    "quantity",
+}
+
+// List retrieves the movements the spec selects, newest first unless it sorts otherwise
+func (r *StockRepository) List(ctx context.Context, spec stock.ListSpec) ([]*stock.StockMovement, error) {
+	order := listing.Order(spec.Sort, stock.DefaultOrder)
+	c := movementConditions(spec.Filter)
+	c.search(spec.Search, movementSearchColumns...)
+	c.after(order, movementSortColumns, spec.After)
+
+	query := `
+		SELECT ` + movementColumns + `
+		FROM stock_movements
+		` + c.where() + `
+		` + orderBy(order, movementSortColumns) + `
+		` + c.page(spec.Limit, spec.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, c.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMovements(rows)
+}
+
+// Count returns how many movements match the spec's filter and search
+func (r *StockRepository) Count(ctx context.Context, spec stock.ListSpec) (int64, error) {
+	c := movementConditions(spec.Filter)
+	c.search(spec.Search, movementSearchColumns...)
+	query := `SELECT COUNT(*) FROM stock_movements ` + c.where()
+
+	var count int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, c.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stock movements: %w", err)
+	}
+
+	return count, nil
+}
+
+// Each streams the movements matching the filter in sequence order without
+// loading them all into memory
+func (r *StockRepository) Each(ctx context.Context, filter stock.Filter, fn func(*stock.StockMovement) error) error {
+	c := movementConditions(filter)
+	query := `SELECT ` + movementColumns + ` FROM stock_movements ` + c.where() + ` ORDER BY sequence`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, c.args...)
+	if err != nil {
+		return fmt.Errorf("failed to read stock movements: %w", err)
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		m := &stock.StockMovement{}
-		if err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Type, &m.Quantity, &m.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		m, err := scanMovement(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan stock movement: %w", err)
+		}
+		if err := fn(m); err != nil {
+			return err
 		}
-		movements = append(movements, m)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating stock movements: %w", err)
+		return fmt.Errorf("error iterating stock movements: %w", err)
 	}
 
-	return movements, nil
+	return nil
 }
 
-// GetByLocation retrieves all movements for a location
-func (r *StockRepository) GetByLocation(ctx context.Context, locationID int64) ([]*stock.StockMovement, error) {
+// SumBalances returns net quantities per product and location for the
+// movements inside the given ledger range
+func (r *StockRepository) SumBalances(ctx context.Context, lr stock.LedgerRange) ([]stock.Balance, error) {
+	var conditions []string
+	var args []interface{}
+
+	if lr.AfterSequence > 0 {
+		args = append(args, lr.AfterSequence)
+		conditions = append(conditions, fmt.Sprintf("sequence > $%d", len(args)))
+	}
+	if lr.ThroughSequence > 0 {
+		args = append(args, lr.ThroughSequence)
+		conditions = append(conditions, fmt.Sprintf("sequence <= $%d", len(args)))
+	}
+	if !lr.Until.IsZero() {
+		args = append(args, lr.Until)
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	query := `
-		SELECT id, product_id, location_id, type, quantity, created_at
+		SELECT product_id, location_id,
+			SUM(CASE WHEN type = 'IN' THEN quantity ELSE -quantity END) AS balance
 		FROM stock_movements
-		WHERE location_id = $1
-		ORDER BY created_at DESC
+		` + where + `
+		GROUP BY product_id, location_id
+		ORDER BY product_id, location_id
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, locationID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stock movements: %w", err)
+		return nil, fmt.Errorf("failed to sum stock balances: %w", err)
 	}
 	defer rows.Close()
 
-	var movements []*stock.StockMovement
+	var balances []stock.Balance
 	for rows.Next() {
-		m := &stock.StockMovement{}
-		if err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Type, &m.Quantity, &m.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
+		var b stock.Balance
+		if err := rows.Scan(&b.ProductID, &b.LocationID, &b.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan stock balance: %w", err)
 		}
-		movements = append(movements, m)
+		balances = append(balances, b)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating stock movements: %w", err)
+		return nil, fmt.Errorf("error iterating stock balances: %w", err)
 	}
 
-	return movements, nil
+	return balances, nil
 }
 
-// List retrieves all stock movements with pagination
-func (r *StockRepository) List(ctx context.Context, limit, offset int) ([]*stock.StockMovement, error) {
+// Replay returns up to limit movements with a sequence above afterSequence,
+// in sequence order
+func (r *StockRepository) Replay(ctx context.Context, afterSequence int64, limit int) ([]*stock.StockMovement, error) {
 	query := `
-		SELECT id, product_id, location_id, type, quantity, created_at
+		SELECT ` + movementColumns + `
 		FROM stock_movements
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE sequence > $1
+		ORDER BY sequence
+		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, afterSequence, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list stock movements: %w", err)
+		return nil, fmt.Errorf("failed to replay stock movements: %w", err)
 	}
 	defer rows.Close()
 
+	return scanMovements(rows)
+}
+
+// LastSequence returns the sequence of the newest movement, or 0 if the ledger
+// is empty. Inside a transaction the ledger head stays locked until commit.
+func (r *StockRepository) LastSequence(ctx context.Context) (int64, error) {
+	query := `SELECT sequence FROM stock_ledger_head`
+	if GetTx(ctx) != nil {
+		query += ` FOR UPDATE`
+	}
+
+	var sequence int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query).Scan(&sequence); err != nil {
+		return 0, fmt.Errorf("failed to get last stock movement sequence: %w", err)
+	}
+
+	return sequence, nil
+}
+
+// movementSearchColumns are the text columns a movement search looks at
+var movementSearchColumns = []string{"reference_id", "document_number", "notes", "lot"}
+
+// movementConditions turns a movement filter into WHERE conditions
+func movementConditions(filter stock.Filter) *conditions {
+	c := &conditions{}
+	if filter.ProductID > 0 {
+		c.equal("product_id", filter.ProductID)
+	}
+	if filter.LocationID > 0 {
+		c.equal("location_id", filter.LocationID)
+	}
+	if filter.Type != "" {
+		c.equal("type", filter.Type)
+	}
+	if filter.ReferenceType != "" {
+		c.equal("reference_type", filter.ReferenceType)
+	}
+	if filter.ReferenceID != "" {
+		c.equal("reference_id", filter.ReferenceID)
+	}
+	if filter.DocumentNumber != "" {
+		c.equal("document_number", filter.DocumentNumber)
+	}
+	c.int64Range("quantity", filter.Quantity)
+	c.timeRange("created_at", filter.CreatedAt)
+	return c
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMovement scans a single stock movement row
+func scanMovement(row rowScanner) (*stock.StockMovement, error) {
+	m := &stock.StockMovement{}
+	var referenceType string
+	err := row.Scan(
+		&m.ID, &m.Sequence, &m.ProductID, &m.LocationID, &m.Type, &m.Quantity, &m.CreatedAt,
+		&referenceType, &m.ReferenceID, &m.DocumentNumber, &m.Notes, &m.EnteredUOM, &m.EnteredQuantity, &m.Lot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.ReferenceType = stock.ReferenceType(referenceType)
+
+	return m, nil
+}
+
+// scanMovements scans all stock movement rows
+func scanMovements(rows *sql.Rows) ([]*stock.StockMovement, error) {
 	var movements []*stock.StockMovement
 	for rows.Next() {
-		m := &stock.StockMovement{}
-		if err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.Type, &m.Quantity, &m.CreatedAt); err != nil {
+		m, err := scanMovement(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan stock movement: %w", err)
 		}
 		movements = append(movements, m)
@@ -146,16 +340,3 @@ func (r *StockRepository) List(ctx context.Context, limit, offset int) ([]*stock
 
 	return movements, nil
 }
-
-// Count returns total number of stock movements
-func (r *StockRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM stock_movements`
-
-	var count int64
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count stock movements: %w", err)
-	}
-
-	return count, nil
-}
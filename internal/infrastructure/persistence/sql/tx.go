@@ -6,6 +6,9 @@ import (
 	"fmt"
 )
 
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
 // TransactionManager manages database transactions
 type TransactionManager struct {
 	db *sql.DB
@@ -25,13 +28,13 @@ func (tm *TransactionManager) BeginTx(ctx context.Context) (context.Context, err
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	return context.WithValue(ctx, "tx", tx), nil
+	return context.WithValue(ctx, txKey{}, tx), nil
 }
 
 // CommitTx commits the transaction
 func (tm *TransactionManager) CommitTx(ctx context.Context) error {
-	tx, ok := ctx.Value("tx").(*sql.Tx)
-	if !ok {
+	tx := GetTx(ctx)
+	if tx == nil {
 		return fmt.Errorf("no transaction in context")
 	}
 
@@ -40,22 +43,27 @@ func (tm *TransactionManager) CommitTx(ctx context.Context) error {
 
 // RollbackTx rolls back the transaction
 func (tm *TransactionManager) RollbackTx(ctx context.Context) error {
-	tx, ok := ctx.Value("tx").(*sql.Tx)
-	if !ok {
+	tx := GetTx(ctx)
+	if tx == nil {
 		return fmt.Errorf("no transaction in context")
 	}
 
 	return tx.Rollback()
 }
 
-// WithTx executes a function within a transaction
+// WithTx executes a function within a transaction. If ctx already carries a
+// transaction, fn joins it instead of starting a new one.
 func (tm *TransactionManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if GetTx(ctx) != nil {
+		return fn(ctx)
+	}
+
 	tx, err := tm.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	txCtx := context.WithValue(ctx, "tx", tx)
+	txCtx := context.WithValue(ctx, txKey{}, tx)
 
 	if err := fn(txCtx); err != nil {
 		_ = tx.Rollback()
@@ -67,9 +75,24 @@ func (tm *TransactionManager) WithTx(ctx context.Context, fn func(ctx context.Co
 
 // GetTx retrieves transaction from context
 func GetTx(ctx context.Context) *sql.Tx {
-	tx, ok := ctx.Value("tx").(*sql.Tx)
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
 	if ok {
 		return tx
 	}
 	return nil
 }
+
+// executor is satisfied by both *sql.DB and *sql.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, falling back to the database handle
+func conn(ctx context.Context, db *sql.DB) executor {
+	if tx := GetTx(ctx); tx != nil {
+		return tx
+	}
+	return db
+}
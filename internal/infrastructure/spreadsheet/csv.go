@@ -0,0 +1,75 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// utf8BOM is written at the start of CSV files saved by Excel
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ReadCSV reads comma-separated rows. Rows may have different lengths.
+// Blank lines are kept as nil rows so row numbers match the file's lines.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, record)
+	}
+}
+
+// csvWriter writes a header row followed by one line per row
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	for i, c := range columns {
+		cw.record[i] = c.Name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow writes one record
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	if err := checkRow(cw.columns, values); err != nil {
+		return err
+	}
+	for i, v := range values {
+		cw.record[i] = formatText(v)
+	}
+	return cw.w.Write(cw.record)
+}
+
+// Close flushes buffered records
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,59 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonWriter writes each row as a JSON object on its own line, with the
+// keys in column order
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []Column
+	keys    [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column) *ndjsonWriter {
+	nw := &ndjsonWriter{w: bufio.NewWriter(w), columns: columns, keys: make([][]byte, len(columns))}
+	for i, c := range columns {
+		nw.keys[i], _ = json.Marshal(c.Name)
+	}
+	return nw
+}
+
+// WriteRow writes one line
+func (nw *ndjsonWriter) WriteRow(values []interface{}) error {
+	if err := checkRow(nw.columns, values); err != nil {
+		return err
+	}
+
+	nw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		nw.w.Write(nw.keys[i])
+		nw.w.WriteByte(':')
+		if t, ok := v.(time.Time); ok {
+			if t.IsZero() {
+				nw.w.WriteString("null")
+				continue
+			}
+			v = t.UTC()
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		nw.w.Write(encoded)
+	}
+	nw.w.WriteByte('}')
+	return nw.w.WriteByte('\n')
+}
+
+// Close flushes buffered lines
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
@@ -0,0 +1,260 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Parquet files are written with the parts of the format every reader
+// understands: required columns, one uncompressed PLAIN page per column chunk,
+// and no statistics or dictionaries.
+
+// parquetRowGroupRows is how many rows are held in memory before they are
+// written out as a row group
+const parquetRowGroupRows = 50000
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types, converted types and enum values used here
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMicros = 10
+
+	parquetRequired = 0
+	parquetPlain    = 0
+	parquetRLE      = 3
+	parquetDataPage = 0
+)
+
+// parquetColumn buffers the PLAIN encoded values of one column of the
+// current row group
+type parquetColumn struct {
+	Column
+	physical int32
+	data     bytes.Buffer
+
+	// Booleans are packed eight to a byte, lowest bit first
+	bits  byte
+	nbits int
+}
+
+// parquetChunk locates one column chunk in the file
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// parquetRowGroup is the footer entry of a written row group
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	rows   int64
+	size   int64
+}
+
+// countingWriter tracks the file offset chunks are written at
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// parquetWriter writes a Parquet file one row group at a time
+type parquetWriter struct {
+	w       *countingWriter
+	schema  []Column
+	columns []*parquetColumn
+	rows    int64
+	total   int64
+	groups  []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, columns []Column) (*parquetWriter, error) {
+	pw := &parquetWriter{w: &countingWriter{w: w}, schema: columns}
+	for _, c := range columns {
+		col := &parquetColumn{Column: c}
+		switch c.Type {
+		case ColumnInt64, ColumnTime:
+			col.physical = parquetInt64
+		case ColumnBool:
+			col.physical = parquetBoolean
+		default:
+			col.physical = parquetByteArray
+		}
+		pw.columns = append(pw.columns, col)
+	}
+
+	if _, err := pw.w.Write(parquetMagic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// WriteRow adds a row to the current row group, writing the group out once full
+func (pw *parquetWriter) WriteRow(values []interface{}) error {
+	if err := checkRow(pw.schema, values); err != nil {
+		return err
+	}
+
+	for i, c := range pw.columns {
+		switch v := values[i].(type) {
+		case int64:
+			c.data.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+		case time.Time:
+			var micros int64
+			if !v.IsZero() {
+				micros = v.UnixMicro()
+			}
+			c.data.Write(binary.LittleEndian.AppendUint64(nil, uint64(micros)))
+		case string:
+			c.data.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(v))))
+			c.data.WriteString(v)
+		case bool:
+			if v {
+				c.bits |= 1 << c.nbits
+			}
+			if c.nbits++; c.nbits == 8 {
+				c.data.WriteByte(c.bits)
+				c.bits, c.nbits = 0, 0
+			}
+		}
+	}
+
+	pw.rows++
+	if pw.rows == parquetRowGroupRows {
+		return pw.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group with one page per column
+func (pw *parquetWriter) flush() error {
+	if pw.rows == 0 {
+		return nil
+	}
+
+	group := parquetRowGroup{rows: pw.rows}
+	for _, c := range pw.columns {
+		if c.nbits > 0 {
+			c.data.WriteByte(c.bits)
+			c.bits, c.nbits = 0, 0
+		}
+
+		var header thriftEncoder
+		header.structValue(func() {
+			header.i32(1, parquetDataPage)
+			header.i32(2, int32(c.data.Len()))
+			header.i32(3, int32(c.data.Len()))
+			header.structField(5, func() {
+				header.i32(1, int32(pw.rows))
+				header.i32(2, parquetPlain)
+				header.i32(3, parquetRLE)
+				header.i32(4, parquetRLE)
+			})
+		})
+
+		chunk := parquetChunk{offset: pw.w.n, size: int64(header.buf.Len() + c.data.Len())}
+		if _, err := pw.w.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := pw.w.Write(c.data.Bytes()); err != nil {
+			return err
+		}
+		c.data.Reset()
+
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+
+	pw.groups = append(pw.groups, group)
+	pw.total += pw.rows
+	pw.rows = 0
+	return nil
+}
+
+// Close writes the last row group and the footer
+func (pw *parquetWriter) Close() error {
+	if err := pw.flush(); err != nil {
+		return err
+	}
+
+	footer := pw.footer()
+	if _, err := pw.w.Write(footer); err != nil {
+		return err
+	}
+	if _, err := pw.w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(parquetMagic)
+	return err
+}
+
+// footer encodes the file metadata: the schema and where each chunk is
+func (pw *parquetWriter) footer() []byte {
+	var e thriftEncoder
+	e.structValue(func() {
+		e.i32(1, 1)
+
+		e.list(2, thriftStruct, len(pw.columns)+1)
+		e.structValue(func() {
+			e.string(4, "schema")
+			e.i32(5, int32(len(pw.columns)))
+		})
+		for _, c := range pw.columns {
+			e.structValue(func() {
+				e.i32(1, c.physical)
+				e.i32(3, parquetRequired)
+				e.string(4, c.Name)
+				switch c.Type {
+				case ColumnString:
+					e.i32(6, parquetUTF8)
+				case ColumnTime:
+					e.i32(6, parquetTimestampMicros)
+				}
+			})
+		}
+
+		e.i64(3, pw.total)
+
+		e.list(4, thriftStruct, len(pw.groups))
+		for _, g := range pw.groups {
+			e.structValue(func() {
+				e.list(1, thriftStruct, len(g.chunks))
+				for i, chunk := range g.chunks {
+					c := pw.columns[i]
+					e.structValue(func() {
+						e.i64(2, chunk.offset)
+						e.structField(3, func() {
+							e.i32(1, c.physical)
+							e.list(2, thriftI32, 2)
+							e.varint(zigzag(parquetPlain))
+							e.varint(zigzag(parquetRLE))
+							e.list(3, thriftBinary, 1)
+							e.stringValue(c.Name)
+							e.i32(4, 0)
+							e.i64(5, g.rows)
+							e.i64(6, chunk.size)
+							e.i64(7, chunk.size)
+							e.i64(9, chunk.offset)
+						})
+					})
+				}
+				e.i64(2, g.size)
+				e.i64(3, g.rows)
+			})
+		}
+
+		e.string(6, "warehouse-management-system")
+	})
+	return e.buf.Bytes()
+}
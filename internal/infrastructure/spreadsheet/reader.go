@@ -0,0 +1,26 @@
+// Package spreadsheet reads CSV and XLSX files into rows of text cells, and
+// writes typed rows as CSV, NDJSON or Parquet
+package spreadsheet
+
+import (
+	"bytes"
+	"io"
+)
+
+// xlsxMagic is the zip local file header every XLSX file starts with
+var xlsxMagic = []byte("PK\x03\x04")
+
+// Read reads a CSV or XLSX file, telling them apart by content. Row i of the
+// result is line i+1 of the file; empty rows are kept so row numbers stay
+// meaningful in error reports.
+func Read(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, xlsxMagic) {
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	}
+	return ReadCSV(bytes.NewReader(data))
+}
@@ -0,0 +1,97 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes, as used in field and list headers
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftEncoder writes the subset of the Thrift compact protocol that Parquet
+// metadata needs: structs of i32, i64, string, list and struct fields.
+type thriftEncoder struct {
+	buf bytes.Buffer
+	// lastField holds the previous field ID of each open struct, as field
+	// headers are written as a delta from it
+	lastField []int16
+}
+
+// begin opens a struct
+func (e *thriftEncoder) begin() {
+	e.lastField = append(e.lastField, 0)
+}
+
+// end closes the innermost struct
+func (e *thriftEncoder) end() {
+	e.buf.WriteByte(0)
+	e.lastField = e.lastField[:len(e.lastField)-1]
+}
+
+func (e *thriftEncoder) fieldHeader(id int16, typ byte) {
+	last := &e.lastField[len(e.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		e.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (e *thriftEncoder) varint(v uint64) {
+	e.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (e *thriftEncoder) i32(id int16, v int32) {
+	e.fieldHeader(id, thriftI32)
+	e.varint(zigzag(int64(v)))
+}
+
+func (e *thriftEncoder) i64(id int16, v int64) {
+	e.fieldHeader(id, thriftI64)
+	e.varint(zigzag(v))
+}
+
+func (e *thriftEncoder) string(id int16, s string) {
+	e.fieldHeader(id, thriftBinary)
+	e.stringValue(s)
+}
+
+func (e *thriftEncoder) stringValue(s string) {
+	e.varint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+// structField writes a nested struct whose fields fn writes
+func (e *thriftEncoder) structField(id int16, fn func()) {
+	e.fieldHeader(id, thriftStruct)
+	e.structValue(fn)
+}
+
+func (e *thriftEncoder) structValue(fn func()) {
+	e.begin()
+	fn()
+	e.end()
+}
+
+// list writes a list header; the caller then writes n values of elemType
+func (e *thriftEncoder) list(id int16, elemType byte, n int) {
+	e.fieldHeader(id, thriftList)
+	if n < 15 {
+		e.buf.WriteByte(byte(n)<<4 | elemType)
+		return
+	}
+	e.buf.WriteByte(0xf0 | elemType)
+	e.varint(uint64(n))
+}
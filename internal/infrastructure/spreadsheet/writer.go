@@ -0,0 +1,118 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a file format rows can be written in
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat reads a format name, case-insensitively
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected csv, ndjson or parquet", name)
+}
+
+// ContentType returns the media type of files in this format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv"
+	}
+}
+
+// ColumnType is the type of the values in a column
+type ColumnType string
+
+const (
+	ColumnInt64  ColumnType = "int64"
+	ColumnString ColumnType = "string"
+	ColumnBool   ColumnType = "bool"
+	ColumnTime   ColumnType = "time"
+)
+
+// Column names and types one column of the rows being written
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Writer writes rows whose values match the columns it was created with:
+// int64, string, bool and time.Time. Close must be called to finish the file.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewWriter starts a file of the given format on w. CSV and NDJSON rows reach w
+// a few kilobytes at a time; Parquet rows are held back one row group at a time.
+func NewWriter(w io.Writer, format Format, columns []Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// checkRow reports a row that does not line up with the columns
+func checkRow(columns []Column, values []interface{}) error {
+	if len(values) != len(columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(columns))
+	}
+	for i, c := range columns {
+		var ok bool
+		switch c.Type {
+		case ColumnInt64:
+			_, ok = values[i].(int64)
+		case ColumnString:
+			_, ok = values[i].(string)
+		case ColumnBool:
+			_, ok = values[i].(bool)
+		case ColumnTime:
+			_, ok = values[i].(time.Time)
+		}
+		if !ok {
+			return fmt.Errorf("column %s expects %s, got %T", c.Name, c.Type, values[i])
+		}
+	}
+	return nil
+}
+
+// formatText renders a value as CSV text. Zero times are left empty.
+func formatText(value interface{}) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler handles administrative maintenance endpoints
+type AdminHandler struct {
+	reconcileCmd *commands.ReconcileStockCommand
+	rebuildCmd   *commands.RebuildProjectionsCommand
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(
+	reconcileCmd *commands.ReconcileStockCommand,
+	rebuildCmd *commands.RebuildProjectionsCommand,
+) *AdminHandler {
+	return &AdminHandler{
+		reconcileCmd: reconcileCmd,
+		rebuildCmd:   rebuildCmd,
+	}
+}
+
+// Reconcile compares stored quantities against the ledger and optionally repairs them
+func (h *AdminHandler) Reconcile(c *gin.Context) {
+	var req dto.ReconcileRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+			return
+		}
+	}
+
+	result, err := h.reconcileCmd.Execute(c.Request.Context(), &req)
+	if errors.Is(err, commands.ErrReconciliationRunning) {
+		c.JSON(http.StatusConflict, response.ErrorResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to reconcile stock"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("reconciliation completed", result))
+}
+
+// RebuildProjections regenerates every ledger projection by replaying the ledger
+func (h *AdminHandler) RebuildProjections(c *gin.Context) {
+	result, err := h.rebuildCmd.Execute(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to rebuild projections"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("projections rebuilt successfully", result))
+}
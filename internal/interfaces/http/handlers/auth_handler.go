@@ -6,20 +6,32 @@ import (
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/auth"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	jwtManager *auth.JWTManager
+	admins     map[string]string
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(jwtManager *auth.JWTManager) *AuthHandler {
 	return &AuthHandler{
 		jwtManager: jwtManager,
+		admins:     make(map[string]string),
 	}
 }
 
+// WithAdmins registers admin users by the bcrypt hashes of their passwords.
+// Only a login that matches the hash gets the admin role.
+func (h *AuthHandler) WithAdmins(admins map[string]string) *AuthHandler {
+	for username, hash := range admins {
+		h.admins[username] = hash
+	}
+	return h
+}
+
 // LoginRequest is the DTO for login request
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -46,8 +58,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Admin users must match their configured password hash
+	role := auth.RoleUser
+	if hash, ok := h.admins[req.Username]; ok {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)) != nil {
+			c.JSON(http.StatusUnauthorized, response.ErrorResponse("invalid credentials"))
+			return
+		}
+		role = auth.RoleAdmin
+	}
+
 	// Generate token (user ID is hardcoded for demo)
-	token, err := h.jwtManager.GenerateToken(1, req.Username, 24)
+	token, err := h.jwtManager.GenerateToken(1, req.Username, role, 24)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to generate token"))
 		return
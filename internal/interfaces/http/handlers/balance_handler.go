@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// BalanceHandler handles stock balance reporting endpoints
+type BalanceHandler struct {
+	asOfQuery     *queries.GetStockAsOfQuery
+	snapshotCmd   *commands.TakeStockSnapshotCommand
+	balancesQuery *queries.ListStockBalancesQuery
+}
+
+// NewBalanceHandler creates a new balance handler. balancesQuery may be nil when
+// the balance projections are not configured.
+func NewBalanceHandler(
+	asOfQuery *queries.GetStockAsOfQuery,
+	snapshotCmd *commands.TakeStockSnapshotCommand,
+	balancesQuery *queries.ListStockBalancesQuery,
+) *BalanceHandler {
+	return &BalanceHandler{
+		asOfQuery:     asOfQuery,
+		snapshotCmd:   snapshotCmd,
+		balancesQuery: balancesQuery,
+	}
+}
+
+// GetStockAsOf returns product and location balances at a past moment.
+// The timestamp accepts RFC3339 or a plain date, which means the end of that day (UTC).
+func (h *BalanceHandler) GetStockAsOf(c *gin.Context) {
+	at, err := parseAsOfTimestamp(c.Query("timestamp"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid timestamp"))
+		return
+	}
+
+	result, err := h.asOfQuery.Execute(c.Request.Context(), at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to rebuild stock balances"))
+		return
+	}
+
+	if wantsCSV(c) {
+		writeStockAsOfCSV(c, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("stock balances retrieved successfully", result))
+}
+
+// TakeSnapshot captures the current balances on demand
+func (h *BalanceHandler) TakeSnapshot(c *gin.Context) {
+	result, err := h.snapshotCmd.Execute(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to take stock snapshot"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse("stock snapshot taken successfully", result))
+}
+
+// ListBalances returns the current quantity per product and location
+func (h *BalanceHandler) ListBalances(c *gin.Context) {
+	var filter dto.BalanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	result, err := h.balancesQuery.Locations(c.Request.Context(), &filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list stock balances"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("stock balances retrieved successfully", result))
+}
+
+// ListLots returns the current quantity per product, location and lot
+func (h *BalanceHandler) ListLots(c *gin.Context) {
+	var filter dto.BalanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	result, err := h.balancesQuery.Lots(c.Request.Context(), &filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list lot balances"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("lot balances retrieved successfully", result))
+}
+
+// parseAsOfTimestamp parses an RFC3339 timestamp or a YYYY-MM-DD date
+func parseAsOfTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("timestamp is required")
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// wantsCSV reports whether the client asked for CSV via ?format=csv or the Accept header
+func wantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+// writeStockAsOfCSV writes the balances as CSV. ?level=product returns per-product
+// totals; the default is one row per product and location.
+func writeStockAsOfCSV(c *gin.Context, result *dto.StockAsOfResponse) {
+	filename := fmt.Sprintf("stock-as-of-%s.csv", result.Timestamp.UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if c.Query("level") == "product" {
+		_ = w.Write([]string{"product_id", "quantity"})
+		for _, p := range result.Products {
+			_ = w.Write([]string{
+				strconv.FormatInt(p.ProductID, 10),
+				strconv.FormatInt(p.Quantity, 10),
+			})
+		}
+	} else {
+		_ = w.Write([]string{"product_id", "location_id", "quantity"})
+		for _, l := range result.Locations {
+			_ = w.Write([]string{
+				strconv.FormatInt(l.ProductID, 10),
+				strconv.FormatInt(l.LocationID, 10),
+				strconv.FormatInt(l.Quantity, 10),
+			})
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ContainerHandler handles license-plated container endpoints
+type ContainerHandler struct {
+	buildCmd *commands.BuildContainerCommand
+	breakCmd *commands.BreakContainerCommand
+	moveCmd  *commands.MoveContainerCommand
+	getQuery *queries.GetContainerQuery
+}
+
+// NewContainerHandler creates a new container handler
+func NewContainerHandler(
+	buildCmd *commands.BuildContainerCommand,
+	breakCmd *commands.BreakContainerCommand,
+	moveCmd *commands.MoveContainerCommand,
+	getQuery *queries.GetContainerQuery,
+) *ContainerHandler {
+	return &ContainerHandler{
+		buildCmd: buildCmd,
+		breakCmd: breakCmd,
+		moveCmd:  moveCmd,
+		getQuery: getQuery,
+	}
+}
+
+// BuildContainer packs loose stock and existing containers into a new container
+func (h *ContainerHandler) BuildContainer(c *gin.Context) {
+	var req dto.BuildContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.buildCmd.Execute(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(containerErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse("container built successfully", result))
+}
+
+// GetContainer looks a container up by LPN or SSCC
+func (h *ContainerHandler) GetContainer(c *gin.Context) {
+	result, err := h.getQuery.Execute(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(containerErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("container retrieved successfully", result))
+}
+
+// BreakContainer breaks a container down into loose stock
+func (h *ContainerHandler) BreakContainer(c *gin.Context) {
+	result, err := h.breakCmd.Execute(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.JSON(containerErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("container broken down successfully", result))
+}
+
+// MoveContainer moves a container with everything inside it to another location
+func (h *ContainerHandler) MoveContainer(c *gin.Context) {
+	var req dto.MoveContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.moveCmd.Execute(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(containerErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("container moved successfully", result))
+}
+
+// containerErrorStatus maps container errors to HTTP status codes
+func containerErrorStatus(err error) int {
+	if err == container.ErrContainerNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
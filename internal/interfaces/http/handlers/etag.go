@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes a resource version as a strong entity tag, e.g. "3"
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatchVersion reads the version a write expects from the If-Match header.
+// "*" matches any version and yields 0. It answers 428 when the header is
+// missing and 412 when it cannot match any version, returning false in both cases.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, response.ErrorResponse("If-Match header is required"))
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	// If-Match uses strong comparison, so weak tags never match
+	if len(header) > 2 && strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) {
+		if version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64); err == nil && version > 0 {
+			return version, true
+		}
+	}
+
+	c.JSON(http.StatusPreconditionFailed, response.ErrorResponse("If-Match does not match the current version"))
+	return 0, false
+}
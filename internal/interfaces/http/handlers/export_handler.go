@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// exportErrorTrailer carries the error of an export that failed after its
+// first rows were already sent
+const exportErrorTrailer = "X-Export-Error"
+
+// ExportHandler handles the streaming export endpoints
+type ExportHandler struct {
+	exportQuery *queries.ExportQuery
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportQuery *queries.ExportQuery) *ExportHandler {
+	return &ExportHandler{
+		exportQuery: exportQuery,
+	}
+}
+
+// ExportProducts streams every product
+func (h *ExportHandler) ExportProducts(c *gin.Context) {
+	h.stream(c, "products", func(w queries.ExportWriter) (int64, error) {
+		return h.exportQuery.Products(c.Request.Context(), w)
+	})
+}
+
+// ExportLocations streams every location
+func (h *ExportHandler) ExportLocations(c *gin.Context) {
+	h.stream(c, "locations", func(w queries.ExportWriter) (int64, error) {
+		return h.exportQuery.Locations(c.Request.Context(), w)
+	})
+}
+
+// ExportMovements streams the stock movements matching the same filters as
+// the movement listing, in ledger order
+func (h *ExportHandler) ExportMovements(c *gin.Context) {
+	filter, err := bindMovementFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	h.stream(c, "movements", func(w queries.ExportWriter) (int64, error) {
+		return h.exportQuery.Movements(c.Request.Context(), filter, w)
+	})
+}
+
+// ExportBalances streams the current quantity per product and location
+func (h *ExportHandler) ExportBalances(c *gin.Context) {
+	var filter dto.BalanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	h.stream(c, "balances", func(w queries.ExportWriter) (int64, error) {
+		return h.exportQuery.Balances(c.Request.Context(), &filter, w)
+	})
+}
+
+// ExportLots streams the current quantity per product, location and lot
+func (h *ExportHandler) ExportLots(c *gin.Context) {
+	var filter dto.BalanceFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	h.stream(c, "lots", func(w queries.ExportWriter) (int64, error) {
+		return h.exportQuery.Lots(c.Request.Context(), &filter, w)
+	})
+}
+
+// stream runs an export straight into the response. Errors before the first
+// byte answer 500 as usual; later ones end the body early and are reported in
+// the X-Export-Error trailer, since the status line has already been sent.
+func (h *ExportHandler) stream(c *gin.Context, name string, run func(queries.ExportWriter) (int64, error)) {
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	w := &responseExportWriter{c: c, format: format, name: name}
+	_, err = run(w)
+	if err == nil && w.out != nil {
+		err = w.out.Close()
+	}
+	if err != nil {
+		if w.out == nil {
+			writeListError(c, err, "failed to export "+name)
+			return
+		}
+		c.Writer.Header().Set(exportErrorTrailer, "failed to export "+name)
+		_ = c.Error(err)
+	}
+}
+
+// exportFormat picks the format from ?format= or the Accept header, defaulting to CSV
+func exportFormat(c *gin.Context) (spreadsheet.Format, error) {
+	if format := c.Query("format"); format != "" {
+		return spreadsheet.ParseFormat(format)
+	}
+
+	accept := c.GetHeader("Accept")
+	for _, f := range []spreadsheet.Format{spreadsheet.FormatNDJSON, spreadsheet.FormatParquet} {
+		if strings.Contains(accept, f.ContentType()) {
+			return f, nil
+		}
+	}
+	return spreadsheet.FormatCSV, nil
+}
+
+// responseExportWriter writes export rows to the response. The headers go out
+// with the first call to Begin, once the export query has started.
+type responseExportWriter struct {
+	c      *gin.Context
+	format spreadsheet.Format
+	name   string
+	out    spreadsheet.Writer
+}
+
+// Begin sends the headers and starts the file
+func (w *responseExportWriter) Begin(columns []dto.ExportColumn) error {
+	filename := fmt.Sprintf("%s-%s.%s", w.name, time.Now().UTC().Format("20060102T150405Z"), w.format)
+	w.c.Header("Content-Type", w.format.ContentType())
+	w.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.c.Header("Trailer", exportErrorTrailer)
+	w.c.Status(http.StatusOK)
+
+	out, err := spreadsheet.NewWriter(w.c.Writer, w.format, exportColumns(columns))
+	if err != nil {
+		return err
+	}
+	w.out = out
+	return nil
+}
+
+// WriteRow writes one row
+func (w *responseExportWriter) WriteRow(values []interface{}) error {
+	return w.out.WriteRow(values)
+}
+
+// exportColumns maps export columns onto spreadsheet columns
+func exportColumns(columns []dto.ExportColumn) []spreadsheet.Column {
+	result := make([]spreadsheet.Column, len(columns))
+	for i, c := range columns {
+		result[i] = spreadsheet.Column{Name: c.Name, Type: spreadsheet.ColumnType(c.Type)}
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes caps the size of an uploaded import file
+const maxImportBytes = 32 << 20
+
+// ImportHandler handles bulk import endpoints
+type ImportHandler struct {
+	productsCmd  *commands.ImportProductsCommand
+	locationsCmd *commands.ImportLocationsCommand
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(
+	productsCmd *commands.ImportProductsCommand,
+	locationsCmd *commands.ImportLocationsCommand,
+) *ImportHandler {
+	return &ImportHandler{
+		productsCmd:  productsCmd,
+		locationsCmd: locationsCmd,
+	}
+}
+
+// ImportProducts creates or updates products from a CSV or XLSX file
+func (h *ImportHandler) ImportProducts(c *gin.Context) {
+	h.runImport(c, h.productsCmd.Execute)
+}
+
+// ImportLocations creates or updates locations from a CSV or XLSX file
+func (h *ImportHandler) ImportLocations(c *gin.Context) {
+	h.runImport(c, h.locationsCmd.Execute)
+}
+
+// runImport reads the uploaded file, either the multipart "file" field or the
+// raw request body, and answers 422 with the per-row errors if any row failed
+func (h *ImportHandler) runImport(c *gin.Context, execute func(ctx context.Context, table [][]string, dryRun bool) (*dto.ImportReportResponse, error)) {
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid dry_run"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			if isTooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse("import file is too large"))
+				return
+			}
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("multipart upload needs a file field"))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("failed to read upload"))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	table, err := spreadsheet.Read(body)
+	if err != nil {
+		if isTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse("import file is too large"))
+			return
+		}
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	report, err := execute(c.Request.Context(), table, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	switch {
+	case report.Failed > 0:
+		c.JSON(http.StatusUnprocessableEntity, &response.APIResponse{
+			Success: false,
+			Message: "import has invalid rows, nothing was saved",
+			Data:    report,
+		})
+	case dryRun:
+		c.JSON(http.StatusOK, response.SuccessResponse("import validated, nothing was saved", report))
+	default:
+		c.JSON(http.StatusOK, response.SuccessResponse("import applied successfully", report))
+	}
+}
+
+// isTooLarge reports whether reading the request hit maxImportBytes
+func isTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
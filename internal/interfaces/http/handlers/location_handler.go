@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
 	"github.com/gin-gonic/gin"
@@ -12,12 +14,23 @@ import (
 
 // LocationHandler handles location endpoints
 type LocationHandler struct {
+	createCmd    *commands.CreateLocationCommand
+	updateCmd    *commands.UpdateLocationCommand
+	listQuery    *queries.ListLocationsQuery
 	locationRepo location.Repository
 }
 
 // NewLocationHandler creates a new location handler
-func NewLocationHandler(locationRepo location.Repository) *LocationHandler {
+func NewLocationHandler(
+	createCmd *commands.CreateLocationCommand,
+	updateCmd *commands.UpdateLocationCommand,
+	listQuery *queries.ListLocationsQuery,
+	locationRepo location.Repository,
+) *LocationHandler {
 	return &LocationHandler{
+		createCmd:    createCmd,
+		updateCmd:    updateCmd,
+		listQuery:    listQuery,
 		locationRepo: locationRepo,
 	}
 }
@@ -30,23 +43,14 @@ func (h *LocationHandler) CreateLocation(c *gin.Context) {
 		return
 	}
 
-	loc, err := location.NewLocation(req.Code, req.Name, req.Capacity)
+	result, err := h.createCmd.Execute(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
 		return
 	}
 
-	if err := h.locationRepo.Create(c.Request.Context(), loc); err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse("failed to create location"))
-		return
-	}
-
-	c.JSON(http.StatusCreated, response.SuccessResponse("location created successfully", &dto.LocationResponse{
-		ID:       loc.ID,
-		Code:     loc.Code,
-		Name:     loc.Name,
-		Capacity: loc.Capacity,
-	}))
+	setETag(c, result.Version)
+	c.JSON(http.StatusCreated, response.SuccessResponse("location created successfully", result))
 }
 
 // GetLocation retrieves a location by ID
@@ -63,62 +67,30 @@ func (h *LocationHandler) GetLocation(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse("location retrieved successfully", &dto.LocationResponse{
-		ID:       loc.ID,
-		Code:     loc.Code,
-		Name:     loc.Name,
-		Capacity: loc.Capacity,
-	}))
+	setETag(c, loc.Version)
+	c.JSON(http.StatusOK, response.SuccessResponse("location retrieved successfully", dto.NewLocationResponse(loc)))
 }
 
-// ListLocations lists all locations
+// ListLocations lists locations, optionally filtered, searched and sorted
 func (h *LocationHandler) ListLocations(c *gin.Context) {
-	limit := 10
-	offset := 0
-
-	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
+	page := bindPage(c)
 
-	if o := c.Query("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
-	}
-
-	locations, err := h.locationRepo.List(c.Request.Context(), limit, offset)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list locations"))
+	var filter dto.LocationFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
 		return
 	}
 
-	total, err := h.locationRepo.Count(c.Request.Context())
+	result, err := h.listQuery.Execute(c.Request.Context(), &filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to count locations"))
+		writeListError(c, err, "failed to list locations")
 		return
 	}
 
-	var responses []*dto.LocationResponse
-	for _, loc := range locations {
-		responses = append(responses, &dto.LocationResponse{
-			ID:       loc.ID,
-			Code:     loc.Code,
-			Name:     loc.Name,
-			Capacity: loc.Capacity,
-		})
-	}
-
-	c.JSON(http.StatusOK, response.SuccessResponse("locations retrieved successfully", &dto.LocationListResponse{
-		Data:   responses,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
-	}))
+	c.JSON(http.StatusOK, response.SuccessResponse("locations retrieved successfully", result))
 }
 
-// UpdateLocation updates a location
+// UpdateLocation updates a location. If-Match must carry the ETag the client last read.
 func (h *LocationHandler) UpdateLocation(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -126,36 +98,35 @@ func (h *LocationHandler) UpdateLocation(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req dto.LocationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
 		return
 	}
 
-	loc, err := h.locationRepo.GetByID(c.Request.Context(), id)
+	result, err := h.updateCmd.Execute(c.Request.Context(), id, version, &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, response.ErrorResponse("location not found"))
-		return
-	}
-
-	loc.Code = req.Code
-	loc.Name = req.Name
-	loc.Capacity = req.Capacity
-
-	if err := h.locationRepo.Update(c.Request.Context(), loc); err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse("failed to update location"))
+		switch err {
+		case location.ErrConcurrentModification:
+			c.JSON(http.StatusPreconditionFailed, response.ErrorResponse(err.Error()))
+		case location.ErrLocationNotFound:
+			c.JSON(http.StatusNotFound, response.ErrorResponse("location not found"))
+		default:
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse("location updated successfully", &dto.LocationResponse{
-		ID:       loc.ID,
-		Code:     loc.Code,
-		Name:     loc.Name,
-		Capacity: loc.Capacity,
-	}))
+	setETag(c, result.Version)
+	c.JSON(http.StatusOK, response.SuccessResponse("location updated successfully", result))
 }
 
-// DeleteLocation deletes a location
+// DeleteLocation deletes a location if it still matches If-Match
 func (h *LocationHandler) DeleteLocation(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -163,8 +134,28 @@ func (h *LocationHandler) DeleteLocation(c *gin.Context) {
 		return
 	}
 
-	if err := h.locationRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, response.ErrorResponse("location not found"))
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if version == 0 {
+		loc, err := h.locationRepo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, response.ErrorResponse("location not found"))
+			return
+		}
+		version = loc.Version
+	}
+
+	if err := h.locationRepo.Delete(c.Request.Context(), id, version); err != nil {
+		switch err {
+		case location.ErrConcurrentModification:
+			c.JSON(http.StatusPreconditionFailed, response.ErrorResponse(err.Error()))
+		case location.ErrLocationNotFound:
+			c.JSON(http.StatusNotFound, response.ErrorResponse("location not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete location"))
+		}
 		return
 	}
 
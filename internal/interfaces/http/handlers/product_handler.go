@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
@@ -16,21 +18,34 @@ import (
 type ProductHandler struct {
 	createCmd   *commands.CreateProductCommand
 	updateCmd   *commands.UpdateProductCommand
+	adjustCmd   *commands.AdjustStockCommand
 	listQuery   *queries.ListProductsQuery
+	searchQuery *queries.SearchProductsQuery
 	productRepo product.Repository
 }
 
+const (
+	// maxSearchLength leaves room for the longest GS1 DataMatrix scans
+	maxSearchLength = 200
+
+	maxSearchResults = 50
+)
+
 // NewProductHandler creates a new product handler
 func NewProductHandler(
 	createCmd *commands.CreateProductCommand,
 	updateCmd *commands.UpdateProductCommand,
+	adjustCmd *commands.AdjustStockCommand,
 	listQuery *queries.ListProductsQuery,
+	searchQuery *queries.SearchProductsQuery,
 	productRepo product.Repository,
 ) *ProductHandler {
 	return &ProductHandler{
 		createCmd:   createCmd,
 		updateCmd:   updateCmd,
+		adjustCmd:   adjustCmd,
 		listQuery:   listQuery,
+		searchQuery: searchQuery,
 		productRepo: productRepo,
 	}
 }
@@ -49,6 +64,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	setETag(c, result.Version)
 	c.JSON(http.StatusCreated, response.SuccessResponse("product created successfully", result))
 }
 
@@ -66,40 +82,73 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse("product retrieved successfully", &dto.ProductResponse{
-		ID:       prod.ID,
-		SKUName:  prod.SKUName,
-		Quantity: prod.Quantity,
-	}))
+	result := dto.NewProductResponse(prod)
+	if uom := c.Query("uom"); uom != "" {
+		if err := result.SetQuantityIn(prod, product.PackLevel(uom)); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+	}
+
+	setETag(c, prod.Version)
+	c.JSON(http.StatusOK, response.SuccessResponse("product retrieved successfully", result))
 }
 
 // ListProducts lists all products
 func (h *ProductHandler) ListProducts(c *gin.Context) {
-	limit := 10
-	offset := 0
+	page := bindPage(c)
+
+	uom := product.PackLevel(c.Query("uom"))
+	if uom != "" && !uom.IsValid() {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(product.ErrInvalidPackLevel.Error()))
+		return
+	}
+
+	var filter dto.ProductFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	result, err := h.listQuery.Execute(c.Request.Context(), &filter, page, uom)
+	if err != nil {
+		writeListError(c, err, "failed to list products")
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("products retrieved successfully", result))
+}
+
+// SearchProducts ranks products by partial SKU, text or barcode. A scanned
+// barcode or GS1 label resolves to its product directly.
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" || len(q) > maxSearchLength {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(fmt.Sprintf("q must be 1 to %d characters", maxSearchLength)))
+		return
+	}
 
+	limit := 10
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
 			limit = parsed
 		}
 	}
-
-	if o := c.Query("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	if limit > maxSearchResults {
+		limit = maxSearchResults
 	}
 
-	result, err := h.listQuery.Execute(c.Request.Context(), limit, offset)
+	result, err := h.searchQuery.Execute(c.Request.Context(), q, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list products"))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to search products"))
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse("products retrieved successfully", result))
+	c.JSON(http.StatusOK, response.SuccessResponse("products searched successfully", result))
 }
 
-// UpdateProduct updates a product
+// UpdateProduct patches product master data; omitted fields are left unchanged.
+// If-Match must carry the ETag the client last read.
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -107,22 +156,51 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req dto.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
 		return
 	}
 
-	result, err := h.updateCmd.Execute(c.Request.Context(), id, &req)
+	result, err := h.updateCmd.Execute(c.Request.Context(), id, version, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		c.JSON(productErrorStatus(err), response.ErrorResponse(err.Error()))
 		return
 	}
 
+	setETag(c, result.Version)
 	c.JSON(http.StatusOK, response.SuccessResponse("product updated successfully", result))
 }
 
-// DeleteProduct deletes a product
+// AdjustStock corrects a product's quantity by posting an adjustment movement
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+		return
+	}
+
+	var req dto.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.adjustCmd.Execute(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse("stock adjusted successfully", result))
+}
+
+// DeleteProduct deletes a product if it still matches If-Match
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -130,10 +208,41 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := h.productRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusNotFound, response.ErrorResponse("product not found"))
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if version == 0 {
+		prod, err := h.productRepo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, response.ErrorResponse("product not found"))
+			return
+		}
+		version = prod.Version
+	}
+
+	if err := h.productRepo.Delete(c.Request.Context(), id, version); err != nil {
+		switch err {
+		case product.ErrConcurrentModification:
+			c.JSON(http.StatusPreconditionFailed, response.ErrorResponse(err.Error()))
+		case product.ErrProductNotFound:
+			c.JSON(http.StatusNotFound, response.ErrorResponse("product not found"))
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete product"))
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse("product deleted successfully", nil))
 }
+
+// productErrorStatus maps product update errors to HTTP status codes
+func productErrorStatus(err error) int {
+	switch err {
+	case product.ErrConcurrentModification:
+		return http.StatusPreconditionFailed
+	case product.ErrProductNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// PutawayHandler handles putaway suggestion and rule endpoints
+type PutawayHandler struct {
+	suggestQuery *queries.SuggestPutawayQuery
+	ruleRepo     putaway.RuleRepository
+}
+
+// NewPutawayHandler creates a new putaway handler. ruleRepo may be nil when
+// the rule endpoints are not registered.
+func NewPutawayHandler(suggestQuery *queries.SuggestPutawayQuery, ruleRepo putaway.RuleRepository) *PutawayHandler {
+	return &PutawayHandler{
+		suggestQuery: suggestQuery,
+		ruleRepo:     ruleRepo,
+	}
+}
+
+// Suggest ranks the locations that can take incoming goods
+func (h *PutawayHandler) Suggest(c *gin.Context) {
+	var req dto.PutawaySuggestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.suggestQuery.Execute(c.Request.Context(), &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == product.ErrProductNotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("putaway suggestions retrieved successfully", result))
+}
+
+// ListRules lists the putaway rules of every category that has one
+func (h *PutawayHandler) ListRules(c *gin.Context) {
+	rules, err := h.ruleRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list putaway rules"))
+		return
+	}
+
+	responses := make([]*dto.PutawayRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		responses = append(responses, dto.NewPutawayRuleResponse(r))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("putaway rules retrieved successfully", responses))
+}
+
+// SaveRule creates or replaces the putaway rule of a category
+func (h *PutawayHandler) SaveRule(c *gin.Context) {
+	var req dto.PutawayRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	rule, err := putaway.NewRule(c.Param("category"), location.TemperatureZone(req.TemperatureZone), req.LocationPrefix, req.AllowMixedSKUs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.ruleRepo.Save(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to save putaway rule"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("putaway rule saved successfully", dto.NewPutawayRuleResponse(rule)))
+}
+
+// DeleteRule removes a category's putaway rule, returning it to the default
+func (h *PutawayHandler) DeleteRule(c *gin.Context) {
+	if err := h.ruleRepo.Delete(c.Request.Context(), c.Param("category")); err != nil {
+		if err == putaway.ErrRuleNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete putaway rule"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("putaway rule deleted successfully", nil))
+}
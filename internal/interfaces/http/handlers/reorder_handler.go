@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReorderHandler handles reorder policy, low-stock alert and reorder report endpoints
+type ReorderHandler struct {
+	saveCmd          *commands.SaveReorderPolicyCommand
+	suggestionsQuery *queries.GetReorderSuggestionsQuery
+	alertsQuery      *queries.ListStockAlertsQuery
+	reorderRepo      replenishment.ReorderRepository
+}
+
+// NewReorderHandler creates a new reorder handler
+func NewReorderHandler(
+	saveCmd *commands.SaveReorderPolicyCommand,
+	suggestionsQuery *queries.GetReorderSuggestionsQuery,
+	alertsQuery *queries.ListStockAlertsQuery,
+	reorderRepo replenishment.ReorderRepository,
+) *ReorderHandler {
+	return &ReorderHandler{
+		saveCmd:          saveCmd,
+		suggestionsQuery: suggestionsQuery,
+		alertsQuery:      alertsQuery,
+		reorderRepo:      reorderRepo,
+	}
+}
+
+// SavePolicy creates or replaces the reorder policy of a SKU for a warehouse
+func (h *ReorderHandler) SavePolicy(c *gin.Context) {
+	var req dto.ReorderPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.saveCmd.Execute(c.Request.Context(), &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == product.ErrProductNotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("reorder policy saved successfully", result))
+}
+
+// ListPolicies lists reorder policies, optionally for one product
+func (h *ReorderHandler) ListPolicies(c *gin.Context) {
+	var productID int64
+	if p := c.Query("product_id"); p != "" {
+		parsed, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+			return
+		}
+		productID = parsed
+	}
+
+	policies, err := h.reorderRepo.ListPolicies(c.Request.Context(), productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list reorder policies"))
+		return
+	}
+
+	responses := make([]*dto.ReorderPolicyResponse, 0, len(policies))
+	for _, p := range policies {
+		responses = append(responses, dto.NewReorderPolicyResponse(p))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("reorder policies retrieved successfully", responses))
+}
+
+// DeletePolicy removes a reorder policy; ?warehouse= selects a warehouse policy
+func (h *ReorderHandler) DeletePolicy(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+		return
+	}
+
+	if err := h.reorderRepo.DeletePolicy(c.Request.Context(), productID, c.Query("warehouse")); err != nil {
+		if err == replenishment.ErrPolicyNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete reorder policy"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("reorder policy deleted successfully", nil))
+}
+
+// ListAlerts lists low-stock alerts; ?status=all includes resolved ones
+func (h *ReorderHandler) ListAlerts(c *gin.Context) {
+	limit := 10
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var openOnly bool
+	switch c.DefaultQuery("status", "open") {
+	case "open":
+		openOnly = true
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid status"))
+		return
+	}
+
+	result, err := h.alertsQuery.Execute(c.Request.Context(), openOnly, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list stock alerts"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("stock alerts retrieved successfully", result))
+}
+
+// Suggestions returns the reorder report, as JSON or as a CSV download
+func (h *ReorderHandler) Suggestions(c *gin.Context) {
+	result, err := h.suggestionsQuery.Execute(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to build reorder suggestions"))
+		return
+	}
+
+	if wantsCSV(c) {
+		writeReorderSuggestionsCSV(c, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("reorder suggestions retrieved successfully", result))
+}
+
+// writeReorderSuggestionsCSV writes the reorder report as CSV
+func writeReorderSuggestionsCSV(c *gin.Context, result []*dto.ReorderSuggestionResponse) {
+	filename := fmt.Sprintf("reorder-suggestions-%s.csv", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"product_id", "sku_name", "warehouse", "available", "reorder_point", "safety_stock", "reorder_qty", "severity", "suggested_qty"})
+	for _, s := range result {
+		_ = w.Write([]string{
+			strconv.FormatInt(s.ProductID, 10),
+			s.SKUName,
+			s.Warehouse,
+			strconv.FormatInt(s.Available, 10),
+			strconv.FormatInt(s.ReorderPoint, 10),
+			strconv.FormatInt(s.SafetyStock, 10),
+			strconv.FormatInt(s.ReorderQty, 10),
+			s.Severity,
+			strconv.FormatInt(s.SuggestedQty, 10),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReplenishmentHandler handles pick face and replenishment task endpoints
+type ReplenishmentHandler struct {
+	savePickFaceCmd   *commands.SavePickFaceCommand
+	runCmd            *commands.RunReplenishmentCommand
+	completeCmd       *commands.CompleteReplenishmentTaskCommand
+	cancelCmd         *commands.CancelReplenishmentTaskCommand
+	listTasksQuery    *queries.ListReplenishmentTasksQuery
+	replenishmentRepo replenishment.Repository
+}
+
+// NewReplenishmentHandler creates a new replenishment handler
+func NewReplenishmentHandler(
+	savePickFaceCmd *commands.SavePickFaceCommand,
+	runCmd *commands.RunReplenishmentCommand,
+	completeCmd *commands.CompleteReplenishmentTaskCommand,
+	cancelCmd *commands.CancelReplenishmentTaskCommand,
+	listTasksQuery *queries.ListReplenishmentTasksQuery,
+	replenishmentRepo replenishment.Repository,
+) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		savePickFaceCmd:   savePickFaceCmd,
+		runCmd:            runCmd,
+		completeCmd:       completeCmd,
+		cancelCmd:         cancelCmd,
+		listTasksQuery:    listTasksQuery,
+		replenishmentRepo: replenishmentRepo,
+	}
+}
+
+// SavePickFace creates or replaces the min/max of a SKU at a pick location
+func (h *ReplenishmentHandler) SavePickFace(c *gin.Context) {
+	var req dto.PickFaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.savePickFaceCmd.Execute(c.Request.Context(), &req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == product.ErrProductNotFound || err == location.ErrLocationNotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("pick face saved successfully", result))
+}
+
+// ListPickFaces lists pick faces, optionally for one product
+func (h *ReplenishmentHandler) ListPickFaces(c *gin.Context) {
+	var productIDs []int64
+	if p := c.Query("product_id"); p != "" {
+		productID, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+			return
+		}
+		productIDs = append(productIDs, productID)
+	}
+
+	faces, err := h.replenishmentRepo.ListPickFaces(c.Request.Context(), productIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list pick faces"))
+		return
+	}
+
+	responses := make([]*dto.PickFaceResponse, 0, len(faces))
+	for _, pf := range faces {
+		responses = append(responses, dto.NewPickFaceResponse(pf))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("pick faces retrieved successfully", responses))
+}
+
+// DeletePickFace removes a pick face setting
+func (h *ReplenishmentHandler) DeletePickFace(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("product_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+		return
+	}
+
+	locationID, err := strconv.ParseInt(c.Param("location_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid location ID"))
+		return
+	}
+
+	if err := h.replenishmentRepo.DeletePickFace(c.Request.Context(), productID, locationID); err != nil {
+		if err == replenishment.ErrPickFaceNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete pick face"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("pick face deleted successfully", nil))
+}
+
+// Run creates replenishment tasks for pick faces below their minimum
+func (h *ReplenishmentHandler) Run(c *gin.Context) {
+	var req dto.RunReplenishmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.runCmd.Execute(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to run replenishment"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("replenishment run successfully", result))
+}
+
+// ListTasks lists replenishment tasks, optionally by status
+func (h *ReplenishmentHandler) ListTasks(c *gin.Context) {
+	limit := 10
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	status := c.Query("status")
+	switch replenishment.TaskStatus(status) {
+	case "", replenishment.TaskStatusOpen, replenishment.TaskStatusCompleted, replenishment.TaskStatusCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid status"))
+		return
+	}
+
+	result, err := h.listTasksQuery.Execute(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list replenishment tasks"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("replenishment tasks retrieved successfully", result))
+}
+
+// CompleteTask confirms a task was carried out and moves its stock
+func (h *ReplenishmentHandler) CompleteTask(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid task ID"))
+		return
+	}
+
+	var req dto.CompleteReplenishmentTaskRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+			return
+		}
+	}
+
+	result, err := h.completeCmd.Execute(c.Request.Context(), id, &req)
+	if err != nil {
+		c.JSON(replenishmentErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("replenishment task completed successfully", result))
+}
+
+// CancelTask drops a task without moving stock
+func (h *ReplenishmentHandler) CancelTask(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid task ID"))
+		return
+	}
+
+	result, err := h.cancelCmd.Execute(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(replenishmentErrorStatus(err), response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("replenishment task cancelled successfully", result))
+}
+
+// replenishmentErrorStatus maps replenishment errors to HTTP status codes
+func replenishmentErrorStatus(err error) int {
+	if err == replenishment.ErrTaskNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ScanHandler handles barcode scan endpoints
+type ScanHandler struct {
+	parseQuery *queries.ParseScanQuery
+}
+
+// NewScanHandler creates a new scan handler
+func NewScanHandler(parseQuery *queries.ParseScanQuery) *ScanHandler {
+	return &ScanHandler{
+		parseQuery: parseQuery,
+	}
+}
+
+// ParseScan parses a raw scanner string and resolves it to product, lot and container
+func (h *ScanHandler) ParseScan(c *gin.Context) {
+	var req dto.ParseScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.parseQuery.Execute(c.Request.Context(), &req)
+	if err != nil {
+		if isScanError(err) {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to parse scan"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("scan parsed successfully", result))
+}
+
+// isScanError reports whether err is the scanned data's fault
+func isScanError(err error) bool {
+	return errors.Is(err, gs1.ErrInvalidScan) || errors.Is(err, gs1.ErrUnknownAI) || errors.Is(err, scanning.ErrUnrecognizedScan)
+}
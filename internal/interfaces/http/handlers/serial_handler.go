@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// SerialHandler handles serial number lookups
+type SerialHandler struct {
+	listQuery *queries.ListSerialsQuery
+	getQuery  *queries.GetSerialQuery
+}
+
+// NewSerialHandler creates a new serial handler
+func NewSerialHandler(listQuery *queries.ListSerialsQuery, getQuery *queries.GetSerialQuery) *SerialHandler {
+	return &SerialHandler{
+		listQuery: listQuery,
+		getQuery:  getQuery,
+	}
+}
+
+// ListSerials lists a product's serials, optionally by location or status
+func (h *SerialHandler) ListSerials(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+		return
+	}
+
+	limit := 10
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var filter dto.SerialFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
+	}
+
+	result, err := h.listQuery.Execute(c.Request.Context(), productID, &filter, limit, offset)
+	if err != nil {
+		if err == product.ErrProductNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list serials"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("serials retrieved successfully", result))
+}
+
+// GetSerial returns a serial's current location and status with its full history
+func (h *SerialHandler) GetSerial(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid product ID"))
+		return
+	}
+
+	result, err := h.getQuery.Execute(c.Request.Context(), productID, c.Param("serial"))
+	if err != nil {
+		if err == serial.ErrSerialNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to get serial"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("serial retrieved successfully", result))
+}
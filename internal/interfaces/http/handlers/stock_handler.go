@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
 	"github.com/gin-gonic/gin"
@@ -14,21 +17,24 @@ import (
 
 // StockHandler handles stock movement endpoints
 type StockHandler struct {
-	recordCmd *commands.RecordStockMovementCommand
-	listQuery *queries.ListStockMovementsQuery
-	stockRepo stock.Repository
+	recordCmd  *commands.RecordStockMovementCommand
+	reverseCmd *commands.ReverseStockMovementCommand
+	listQuery  *queries.ListStockMovementsQuery
+	stockRepo  stock.Repository
 }
 
 // NewStockHandler creates a new stock handler
 func NewStockHandler(
 	recordCmd *commands.RecordStockMovementCommand,
+	reverseCmd *commands.ReverseStockMovementCommand,
 	listQuery *queries.ListStockMovementsQuery,
 	stockRepo stock.Repository,
 ) *StockHandler {
 	return &StockHandler{
-		recordCmd: recordCmd,
-		listQuery: listQuery,
-		stockRepo: stockRepo,
+		recordCmd:  recordCmd,
+		reverseCmd: reverseCmd,
+		listQuery:  listQuery,
+		stockRepo:  stockRepo,
 	}
 }
 
@@ -49,6 +55,38 @@ func (h *StockHandler) RecordMovement(c *gin.Context) {
 	c.JSON(http.StatusCreated, response.SuccessResponse("stock movement recorded successfully", result))
 }
 
+// ReverseMovement posts a movement that cancels out the given one
+func (h *StockHandler) ReverseMovement(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid movement ID"))
+		return
+	}
+
+	var req dto.ReverseStockMovementRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+			return
+		}
+	}
+
+	result, err := h.reverseCmd.Execute(c.Request.Context(), id, &req)
+	if err != nil {
+		switch err {
+		case stock.ErrMovementNotFound:
+			c.JSON(http.StatusNotFound, response.ErrorResponse("movement not found"))
+		case stock.ErrAlreadyReversed, stock.ErrReversalNotReversible:
+			c.JSON(http.StatusConflict, response.ErrorResponse(err.Error()))
+		default:
+			c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse("stock movement reversed successfully", result))
+}
+
 // GetMovement retrieves a stock movement by ID
 func (h *StockHandler) GetMovement(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -63,36 +101,23 @@ func (h *StockHandler) GetMovement(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.SuccessResponse("movement retrieved successfully", &dto.StockMovementResponse{
-		ID:         movement.ID,
-		ProductID:  movement.ProductID,
-		LocationID: movement.LocationID,
-		Type:       string(movement.Type),
-		Quantity:   movement.Quantity,
-		CreatedAt:  movement.CreatedAt,
-	}))
+	c.JSON(http.StatusOK, response.SuccessResponse("movement retrieved successfully", dto.NewStockMovementResponse(movement)))
 }
 
-// ListMovements lists all stock movements
+// ListMovements lists stock movements, optionally filtered by product, location,
+// type, document reference, quantity or creation date, searched and sorted
 func (h *StockHandler) ListMovements(c *gin.Context) {
-	limit := 10
-	offset := 0
-
-	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
+	page := bindPage(c)
 
-	if o := c.Query("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	filter, err := bindMovementFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid filter"))
+		return
 	}
 
-	result, err := h.listQuery.Execute(c.Request.Context(), limit, offset)
+	result, err := h.listQuery.Execute(c.Request.Context(), filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list movements"))
+		writeListError(c, err, "failed to list movements")
 		return
 	}
 
@@ -115,14 +140,7 @@ func (h *StockHandler) GetProductMovements(c *gin.Context) {
 
 	var responses []*dto.StockMovementResponse
 	for _, m := range movements {
-		responses = append(responses, &dto.StockMovementResponse{
-			ID:         m.ID,
-			ProductID:  m.ProductID,
-			LocationID: m.LocationID,
-			Type:       string(m.Type),
-			Quantity:   m.Quantity,
-			CreatedAt:  m.CreatedAt,
-		})
+		responses = append(responses, dto.NewStockMovementResponse(m))
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse("movements retrieved successfully", responses))
@@ -144,15 +162,73 @@ func (h *StockHandler) GetLocationMovements(c *gin.Context) {
 
 	var responses []*dto.StockMovementResponse
 	for _, m := range movements {
-		responses = append(responses, &dto.StockMovementResponse{
-			ID:         m.ID,
-			ProductID:  m.ProductID,
-			LocationID: m.LocationID,
-			Type:       string(m.Type),
-			Quantity:   m.Quantity,
-			CreatedAt:  m.CreatedAt,
-		})
+		responses = append(responses, dto.NewStockMovementResponse(m))
 	}
 
 	c.JSON(http.StatusOK, response.SuccessResponse("movements retrieved successfully", responses))
 }
+
+// bindMovementFilter reads a movement filter from the query string. from and to
+// accept RFC3339 or a plain date; a date in to includes the whole day (UTC).
+func bindMovementFilter(c *gin.Context) (*dto.StockMovementFilter, error) {
+	var filter dto.StockMovementFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		return nil, err
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", from); err != nil {
+				return nil, err
+			}
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseAsOfTimestamp(to)
+		if err != nil {
+			return nil, err
+		}
+		filter.To = t
+	}
+	return &filter, nil
+}
+
+// bindPage reads the paging parameters of a list endpoint: limit, offset, the
+// after cursor and total. Invalid numbers fall back to the defaults.
+func bindPage(c *gin.Context) dto.PageRequest {
+	page := dto.PageRequest{Limit: 10, After: c.Query("after")}
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			page.Limit = parsed
+		}
+	}
+
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			page.Offset = parsed
+		}
+	}
+
+	// Counting scans every matching row, so it is only done on request and
+	// only for the first page; later pages reuse the client's first count
+	if t := c.Query("total"); t != "" && page.After == "" {
+		if include, err := strconv.ParseBool(t); err == nil {
+			page.IncludeTotal = include
+		}
+	}
+
+	return page
+}
+
+// writeListError answers 400 for a sort, range or cursor the client got wrong and
+// 500 with the given message otherwise
+func writeListError(c *gin.Context, err error, message string) {
+	if errors.Is(err, listing.ErrInvalidQuery) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, response.ErrorResponse(message))
+}
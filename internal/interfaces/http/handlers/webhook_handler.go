@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles webhook subscription and delivery endpoints
+type WebhookHandler struct {
+	createCmd       *commands.CreateWebhookCommand
+	replayCmd       *commands.ReplayWebhookDeliveryCommand
+	deliveriesQuery *queries.ListWebhookDeliveriesQuery
+	webhookRepo     webhook.Repository
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(
+	createCmd *commands.CreateWebhookCommand,
+	replayCmd *commands.ReplayWebhookDeliveryCommand,
+	deliveriesQuery *queries.ListWebhookDeliveriesQuery,
+	webhookRepo webhook.Repository,
+) *WebhookHandler {
+	return &WebhookHandler{
+		createCmd:       createCmd,
+		replayCmd:       replayCmd,
+		deliveriesQuery: deliveriesQuery,
+		webhookRepo:     webhookRepo,
+	}
+}
+
+// CreateWebhook subscribes an endpoint to events
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request"))
+		return
+	}
+
+	result, err := h.createCmd.Execute(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.SuccessResponse("webhook created successfully", result))
+}
+
+// ListWebhooks lists every subscription
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhookRepo.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list webhooks"))
+		return
+	}
+
+	responses := make([]*dto.WebhookResponse, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		responses = append(responses, dto.NewWebhookResponse(s))
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("webhooks retrieved successfully", responses))
+}
+
+// DeleteWebhook removes a subscription and its deliveries
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid webhook ID"))
+		return
+	}
+
+	if err := h.webhookRepo.DeleteSubscription(c.Request.Context(), id); err != nil {
+		if err == webhook.ErrSubscriptionNotFound {
+			c.JSON(http.StatusNotFound, response.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to delete webhook"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("webhook deleted successfully", nil))
+}
+
+// ListDeliveries lists deliveries; ?status=DEAD shows the dead-letter store
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit := 10
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	status := c.Query("status")
+	switch webhook.DeliveryStatus(status) {
+	case "", webhook.DeliveryPending, webhook.DeliveryDelivered, webhook.DeliveryDead:
+	default:
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid status"))
+		return
+	}
+
+	result, err := h.deliveriesQuery.Execute(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to list webhook deliveries"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("webhook deliveries retrieved successfully", result))
+}
+
+// ReplayDelivery queues a dead or delivered delivery to be sent again
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid delivery ID"))
+		return
+	}
+
+	result, err := h.replayCmd.Execute(c.Request.Context(), id)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == webhook.ErrDeliveryNotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, response.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SuccessResponse("webhook delivery queued for replay", result))
+}
@@ -40,6 +40,21 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		// Store claims in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects requests whose token does not carry the admin role.
+// It must run after AuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != auth.RoleAdmin {
+			c.JSON(http.StatusForbidden, response.ErrorResponse("admin role required"))
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a create request
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader marks a response replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotentBodyBytes caps the request body read for fingerprinting; it
+// matches the largest body any POST endpoint accepts, an import upload
+const maxIdempotentBodyBytes = 32 << 20
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key header
+// safe to retry: the first response is stored and returned again for retries
+// with the same body, while reusing the key for a different request is a 409.
+// Keys are scoped to the authenticated user, so it must run after AuthMiddleware.
+//
+// With a transaction manager the handler runs inside the transaction that
+// claims the key, and a successful response is stored and committed together
+// with the changes it made, so a crash can neither lose the response of a
+// committed change nor keep the key of a change that was rolled back.
+func IdempotencyMiddleware(service *idempotency.Service, txManager application.TransactionManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse("request body is too large"))
+			} else {
+				c.JSON(http.StatusBadRequest, response.ErrorResponse("invalid request body"))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := "user:" + c.GetString("username")
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		// The outcome must be stored even if the client hangs up meanwhile
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		tx := &requestTx{manager: txManager}
+		if ctx, err = tx.begin(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to check idempotency key"))
+			c.Abort()
+			return
+		}
+
+		stored, err := service.Begin(ctx, scope, key, fingerprint)
+		if err != nil {
+			tx.rollback(ctx)
+			switch {
+			case errors.Is(err, idempotency.ErrInvalidKey):
+				c.JSON(http.StatusBadRequest, response.ErrorResponse(err.Error()))
+			case errors.Is(err, idempotency.ErrKeyReused), errors.Is(err, idempotency.ErrRequestInProgress):
+				c.JSON(http.StatusConflict, response.ErrorResponse(err.Error()))
+			default:
+				c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to check idempotency key"))
+			}
+			c.Abort()
+			return
+		}
+
+		if stored != nil {
+			tx.rollback(ctx)
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// The response is held back until it is stored, so the client never
+		// sees an outcome that was not committed
+		writer := c.Writer
+		recorder := &bodyRecorder{ResponseWriter: writer}
+		c.Writer = recorder
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		c.Writer = writer
+
+		status := recorder.Status()
+		contentType := recorder.Header().Get("Content-Type")
+		switch {
+		case status >= http.StatusInternalServerError:
+			// Server errors are not replayed; the client may retry with the same key
+			if tx.active() {
+				tx.rollback(ctx)
+			} else if err := service.Abandon(ctx, scope, key); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+
+		case status >= http.StatusBadRequest && tx.active():
+			// A rejected request must not keep any change it made before
+			// failing, but its answer is still replayed
+			tx.rollback(ctx)
+			if err := storeResponse(context.WithoutCancel(c.Request.Context()), service, scope, key, fingerprint, status, contentType, recorder.body.Bytes()); err != nil {
+				log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+			}
+
+		default:
+			if err := service.Finish(ctx, scope, key, status, contentType, recorder.body.Bytes()); err != nil {
+				log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+				if tx.active() {
+					tx.rollback(ctx)
+					c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to store idempotent response"))
+					return
+				}
+			}
+			if err := tx.commit(ctx); err != nil {
+				log.Printf("Failed to commit request with idempotency key %q: %v", key, err)
+				c.JSON(http.StatusInternalServerError, response.ErrorResponse("failed to save changes"))
+				return
+			}
+		}
+
+		writer.WriteHeaderNow()
+		if recorder.body.Len() > 0 {
+			if _, err := writer.Write(recorder.body.Bytes()); err != nil {
+				log.Printf("Failed to write response for idempotency key %q: %v", key, err)
+			}
+		}
+	}
+}
+
+// storeResponse claims the key again outside the rolled back transaction and
+// stores the response, unless a concurrent retry claimed the key meanwhile
+func storeResponse(ctx context.Context, service *idempotency.Service, scope, key, fingerprint string, status int, contentType string, body []byte) error {
+	stored, err := service.Begin(ctx, scope, key, fingerprint)
+	if err != nil || stored != nil {
+		return err
+	}
+	return service.Finish(ctx, scope, key, status, contentType, body)
+}
+
+// requestTx is the transaction an idempotent request runs in. Without a
+// transaction manager every step runs on its own.
+type requestTx struct {
+	manager application.TransactionManager
+	ctx     context.Context
+}
+
+// begin starts the transaction and returns the context carrying it
+func (t *requestTx) begin(ctx context.Context) (context.Context, error) {
+	if t.manager == nil {
+		return ctx, nil
+	}
+	txCtx, err := t.manager.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.ctx = txCtx
+	return txCtx, nil
+}
+
+// active reports whether the transaction is still open
+func (t *requestTx) active() bool {
+	return t.ctx != nil
+}
+
+// commit commits the transaction if one is open
+func (t *requestTx) commit(ctx context.Context) error {
+	if !t.active() {
+		return nil
+	}
+	t.ctx = nil
+	return t.manager.CommitTx(ctx)
+}
+
+// rollback rolls the transaction back if one is open
+func (t *requestTx) rollback(ctx context.Context) {
+	if !t.active() {
+		return
+	}
+	t.ctx = nil
+	if err := t.manager.RollbackTx(ctx); err != nil {
+		log.Printf("Failed to roll back idempotent request: %v", err)
+	}
+}
+
+// bodyRecorder buffers the response body instead of writing it through, so
+// the response can be sent once its outcome is stored
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write buffers the response body
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString buffers the response body
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// WriteHeaderNow keeps the status unsent until the response is released
+func (w *bodyRecorder) WriteHeaderNow() {}
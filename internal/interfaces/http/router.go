@@ -1,11 +1,20 @@
 package http
 
 import (
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/queries"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/scanning"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/auth"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
@@ -14,6 +23,112 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RouterOption configures optional dependencies of the router
+type RouterOption func(*routerOptions)
+
+// routerOptions holds optional router dependencies
+type routerOptions struct {
+	snapshotRepo  stock.SnapshotRepository
+	containerRepo container.Repository
+	serialRepo    serial.Repository
+	ruleRepo      putaway.RuleRepository
+	replRepo      replenishment.Repository
+	reorderRepo   replenishment.ReorderRepository
+	webhookRepo   webhook.Repository
+	outboxRepo    outbox.Repository
+	projRepo      stock.ProjectionRepository
+	repairRepo    stock.RepairRepository
+	reconcileLock application.Lock
+	idemRepo      idempotency.Repository
+}
+
+// WithSnapshotRepository lets point-in-time stock queries start from stored snapshots
+func WithSnapshotRepository(repo stock.SnapshotRepository) RouterOption {
+	return func(o *routerOptions) {
+		o.snapshotRepo = repo
+	}
+}
+
+// WithContainerRepository enables the license-plated container endpoints
+func WithContainerRepository(repo container.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.containerRepo = repo
+	}
+}
+
+// WithSerialRepository enables serial tracking for serialized products and the serial lookup endpoints
+func WithSerialRepository(repo serial.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.serialRepo = repo
+	}
+}
+
+// WithPutawayRuleRepository enables per-category putaway rules and their endpoints
+func WithPutawayRuleRepository(repo putaway.RuleRepository) RouterOption {
+	return func(o *routerOptions) {
+		o.ruleRepo = repo
+	}
+}
+
+// WithReplenishmentRepository enables pick face min/max settings and the replenishment endpoints
+func WithReplenishmentRepository(repo replenishment.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.replRepo = repo
+	}
+}
+
+// WithReorderRepository enables reorder policies, low-stock alerts after each
+// movement and the reorder report
+func WithReorderRepository(repo replenishment.ReorderRepository) RouterOption {
+	return func(o *routerOptions) {
+		o.reorderRepo = repo
+	}
+}
+
+// WithWebhookRepository enables the webhook subscription and delivery endpoints
+func WithWebhookRepository(repo webhook.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.webhookRepo = repo
+	}
+}
+
+// WithOutboxRepository records domain events in the outbox, in the same
+// transaction as the change that raised them, for the relay to publish
+func WithOutboxRepository(repo outbox.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.outboxRepo = repo
+	}
+}
+
+// WithProjectionRepository keeps per-location and per-lot balances as ledger
+// projections and enables the endpoints that read them
+func WithProjectionRepository(repo stock.ProjectionRepository) RouterOption {
+	return func(o *routerOptions) {
+		o.projRepo = repo
+	}
+}
+
+// WithRepairRepository records every reconciliation repair in an audit trail
+func WithRepairRepository(repo stock.RepairRepository) RouterOption {
+	return func(o *routerOptions) {
+		o.repairRepo = repo
+	}
+}
+
+// WithReconciliationLock keeps reconciliation runs from overlapping across instances
+func WithReconciliationLock(lock application.Lock) RouterOption {
+	return func(o *routerOptions) {
+		o.reconcileLock = lock
+	}
+}
+
+// WithIdempotencyRepository honours the Idempotency-Key header on create requests
+func WithIdempotencyRepository(repo idempotency.Repository) RouterOption {
+	return func(o *routerOptions) {
+		o.idemRepo = repo
+	}
+}
+
 // SetupRouter sets up the HTTP router
 func SetupRouter(
 	cfg *config.Config,
@@ -21,9 +136,15 @@ func SetupRouter(
 	locationRepo location.Repository,
 	stockRepo stock.Repository,
 	txManager *sql.TransactionManager,
+	opts ...RouterOption,
 ) *gin.Engine {
 	router := gin.Default()
 
+	options := &routerOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(cfg.JWTSecret)
 
@@ -32,7 +153,7 @@ func SetupRouter(
 	router.Use(middleware.RecoveryMiddleware())
 
 	// Public routes
-	authHandler := handlers.NewAuthHandler(jwtManager)
+	authHandler := handlers.NewAuthHandler(jwtManager).WithAdmins(cfg.AdminUsers)
 	public := router.Group("/api/v1")
 	{
 		public.POST("/auth/login", authHandler.Login)
@@ -41,30 +162,136 @@ func SetupRouter(
 	// Protected routes
 	protected := router.Group("/api/v1")
 	protected.Use(middleware.AuthMiddleware(jwtManager))
+	if options.idemRepo != nil {
+		idempotencyService := idempotency.NewService(options.idemRepo).WithTTL(cfg.IdempotencyTTL)
+		protected.Use(middleware.IdempotencyMiddleware(idempotencyService, transactionManager(txManager)))
+	}
 	{
 		// Product routes
-		productHandler := setupProductHandler(productRepo)
+		productHandler := setupProductHandler(productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
 		protected.POST("/products", productHandler.CreateProduct)
 		protected.GET("/products", productHandler.ListProducts)
+		protected.GET("/products/search", productHandler.SearchProducts)
 		protected.GET("/products/:id", productHandler.GetProduct)
+		protected.PATCH("/products/:id", productHandler.UpdateProduct)
 		protected.PUT("/products/:id", productHandler.UpdateProduct)
 		protected.DELETE("/products/:id", productHandler.DeleteProduct)
+		protected.POST("/products/:id/adjustments", productHandler.AdjustStock)
+
+		// Serial routes
+		if options.serialRepo != nil {
+			serialHandler := setupSerialHandler(options.serialRepo, productRepo)
+			protected.GET("/products/:id/serials", serialHandler.ListSerials)
+			protected.GET("/products/:id/serials/:serial", serialHandler.GetSerial)
+		}
 
 		// Location routes
-		locationHandler := handlers.NewLocationHandler(locationRepo)
+		locationHandler := setupLocationHandler(locationRepo)
 		protected.POST("/locations", locationHandler.CreateLocation)
 		protected.GET("/locations", locationHandler.ListLocations)
 		protected.GET("/locations/:id", locationHandler.GetLocation)
 		protected.PUT("/locations/:id", locationHandler.UpdateLocation)
 		protected.DELETE("/locations/:id", locationHandler.DeleteLocation)
 
+		// Import routes
+		importHandler := setupImportHandler(productRepo, locationRepo, options, transactionManager(txManager))
+		protected.POST("/imports/products", importHandler.ImportProducts)
+		protected.POST("/imports/locations", importHandler.ImportLocations)
+
+		// Export routes
+		exportHandler := setupExportHandler(productRepo, locationRepo, stockRepo, options)
+		protected.GET("/exports/products", exportHandler.ExportProducts)
+		protected.GET("/exports/locations", exportHandler.ExportLocations)
+		protected.GET("/exports/movements", exportHandler.ExportMovements)
+		if options.projRepo != nil {
+			protected.GET("/exports/balances", exportHandler.ExportBalances)
+			protected.GET("/exports/lots", exportHandler.ExportLots)
+		}
+
 		// Stock movement routes
-		stockHandler := setupStockHandler(productRepo, locationRepo, stockRepo)
+		stockHandler := setupStockHandler(productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
 		protected.POST("/stock-movements", stockHandler.RecordMovement)
 		protected.GET("/stock-movements", stockHandler.ListMovements)
 		protected.GET("/stock-movements/:id", stockHandler.GetMovement)
+		protected.POST("/stock-movements/:id/reversal", stockHandler.ReverseMovement)
 		protected.GET("/stock-movements/product/:product_id", stockHandler.GetProductMovements)
 		protected.GET("/stock-movements/location/:location_id", stockHandler.GetLocationMovements)
+
+		// Scan routes
+		scanHandler := setupScanHandler(productRepo, locationRepo, options)
+		protected.POST("/scan/parse", scanHandler.ParseScan)
+
+		// Putaway routes
+		putawayHandler := setupPutawayHandler(productRepo, locationRepo, stockRepo, options)
+		protected.POST("/putaway/suggest", putawayHandler.Suggest)
+		if options.ruleRepo != nil {
+			protected.GET("/putaway/rules", putawayHandler.ListRules)
+			protected.PUT("/putaway/rules/:category", putawayHandler.SaveRule)
+			protected.DELETE("/putaway/rules/:category", putawayHandler.DeleteRule)
+		}
+
+		// Replenishment routes
+		if options.replRepo != nil {
+			replenishmentHandler := setupReplenishmentHandler(options.replRepo, productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
+			protected.PUT("/replenishment/pick-faces", replenishmentHandler.SavePickFace)
+			protected.GET("/replenishment/pick-faces", replenishmentHandler.ListPickFaces)
+			protected.DELETE("/replenishment/pick-faces/:product_id/:location_id", replenishmentHandler.DeletePickFace)
+			protected.POST("/replenishment/run", replenishmentHandler.Run)
+			protected.GET("/replenishment/tasks", replenishmentHandler.ListTasks)
+			protected.POST("/replenishment/tasks/:id/complete", replenishmentHandler.CompleteTask)
+			protected.POST("/replenishment/tasks/:id/cancel", replenishmentHandler.CancelTask)
+		}
+
+		// Reorder routes
+		if options.reorderRepo != nil {
+			reorderHandler := setupReorderHandler(options.reorderRepo, productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
+			protected.PUT("/replenishment/reorder-policies", reorderHandler.SavePolicy)
+			protected.GET("/replenishment/reorder-policies", reorderHandler.ListPolicies)
+			protected.DELETE("/replenishment/reorder-policies/:product_id", reorderHandler.DeletePolicy)
+			protected.GET("/replenishment/alerts", reorderHandler.ListAlerts)
+			protected.GET("/replenishment/suggestions", reorderHandler.Suggestions)
+		}
+
+		// Stock balance routes
+		balanceHandler := setupBalanceHandler(stockRepo, options)
+		protected.GET("/stock/as-of", balanceHandler.GetStockAsOf)
+		protected.POST("/stock/snapshots", balanceHandler.TakeSnapshot)
+		if options.projRepo != nil {
+			protected.GET("/stock/balances", balanceHandler.ListBalances)
+			protected.GET("/stock/lots", balanceHandler.ListLots)
+		}
+
+		// Container routes
+		if options.containerRepo != nil {
+			containerHandler := setupContainerHandler(options.containerRepo, productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
+			protected.POST("/containers", containerHandler.BuildContainer)
+			protected.GET("/containers/:code", containerHandler.GetContainer)
+			protected.POST("/containers/:code/break", containerHandler.BreakContainer)
+			protected.POST("/containers/:code/move", containerHandler.MoveContainer)
+		}
+
+		// Webhook routes
+		if options.webhookRepo != nil {
+			webhookHandler := setupWebhookHandler(options.webhookRepo)
+			protected.POST("/webhooks", webhookHandler.CreateWebhook)
+			protected.GET("/webhooks", webhookHandler.ListWebhooks)
+			protected.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		}
+	}
+
+	// Admin routes additionally need an admin token
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireAdmin())
+	{
+		adminHandler := setupAdminHandler(productRepo, locationRepo, stockRepo, options, transactionManager(txManager))
+		admin.POST("/reconciliation", adminHandler.Reconcile)
+		admin.POST("/projections/rebuild", adminHandler.RebuildProjections)
+
+		if options.webhookRepo != nil {
+			webhookHandler := setupWebhookHandler(options.webhookRepo)
+			admin.GET("/webhooks/deliveries", webhookHandler.ListDeliveries)
+			admin.POST("/webhooks/deliveries/:id/replay", webhookHandler.ReplayDelivery)
+		}
 	}
 
 	// Health check
@@ -76,12 +303,64 @@ func SetupRouter(
 }
 
 // setupProductHandler sets up product handler with all dependencies
-func setupProductHandler(productRepo product.Repository) *handlers.ProductHandler {
-	createCmd := commands.NewCreateProductCommand(productRepo)
+func setupProductHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.ProductHandler {
+	createCmd := commands.NewCreateProductCommand(productRepo, txManager).
+		WithEvents(newOutbox(options))
 	updateCmd := commands.NewUpdateProductCommand(productRepo)
+	stockService := newStockService(productRepo, locationRepo, stockRepo, options)
+	recordCmd := commands.NewRecordStockMovementCommand(stockService, txManager).
+		WithPutaway(newPutawayService(productRepo, locationRepo, stockRepo, stockService, options)).
+		WithAlerts(newMonitor(locationRepo, stockRepo, options)).
+		WithEvents(newOutbox(options))
+	adjustCmd := commands.NewAdjustStockCommand(recordCmd)
 	listQuery := queries.NewListProductsQuery(productRepo)
+	searchQuery := queries.NewSearchProductsQuery(productRepo)
+
+	return handlers.NewProductHandler(createCmd, updateCmd, adjustCmd, listQuery, searchQuery, productRepo)
+}
+
+// setupLocationHandler sets up location handler with all dependencies
+func setupLocationHandler(locationRepo location.Repository) *handlers.LocationHandler {
+	createCmd := commands.NewCreateLocationCommand(locationRepo)
+	updateCmd := commands.NewUpdateLocationCommand(locationRepo)
+	listQuery := queries.NewListLocationsQuery(locationRepo)
+
+	return handlers.NewLocationHandler(createCmd, updateCmd, listQuery, locationRepo)
+}
 
-	return handlers.NewProductHandler(createCmd, updateCmd, listQuery, productRepo)
+// setupImportHandler sets up import handler with all dependencies
+func setupImportHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.ImportHandler {
+	productsCmd := commands.NewImportProductsCommand(productRepo, txManager).
+		WithEvents(newOutbox(options))
+	locationsCmd := commands.NewImportLocationsCommand(locationRepo, txManager)
+
+	return handlers.NewImportHandler(productsCmd, locationsCmd)
+}
+
+// setupExportHandler sets up export handler with all dependencies
+func setupExportHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+) *handlers.ExportHandler {
+	exportQuery := queries.NewExportQuery(productRepo, locationRepo, stockRepo)
+	if options.projRepo != nil {
+		exportQuery.WithProjections(options.projRepo)
+	}
+
+	return handlers.NewExportHandler(exportQuery)
 }
 
 // setupStockHandler sets up stock handler with all dependencies
@@ -89,10 +368,239 @@ func setupStockHandler(
 	productRepo product.Repository,
 	locationRepo location.Repository,
 	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
 ) *handlers.StockHandler {
-	stockService := stock.NewService(productRepo, locationRepo, stockRepo)
-	recordCmd := commands.NewRecordStockMovementCommand(stockService, productRepo, nil)
+	stockService := newStockService(productRepo, locationRepo, stockRepo, options)
+	recordCmd := commands.NewRecordStockMovementCommand(stockService, txManager).
+		WithPutaway(newPutawayService(productRepo, locationRepo, stockRepo, stockService, options)).
+		WithAlerts(newMonitor(locationRepo, stockRepo, options)).
+		WithEvents(newOutbox(options)).
+		WithScans(newScanResolver(productRepo, locationRepo, options))
+	reverseCmd := commands.NewReverseStockMovementCommand(recordCmd, stockRepo, txManager)
 	listQuery := queries.NewListStockMovementsQuery(stockRepo)
 
-	return handlers.NewStockHandler(recordCmd, listQuery, stockRepo)
+	return handlers.NewStockHandler(recordCmd, reverseCmd, listQuery, stockRepo)
+}
+
+// setupBalanceHandler sets up balance handler with all dependencies
+func setupBalanceHandler(
+	stockRepo stock.Repository,
+	options *routerOptions,
+) *handlers.BalanceHandler {
+	balanceService := stock.NewBalanceService(stockRepo, options.snapshotRepo)
+	asOfQuery := queries.NewGetStockAsOfQuery(balanceService)
+	snapshotCmd := commands.NewTakeStockSnapshotCommand(balanceService)
+
+	var balancesQuery *queries.ListStockBalancesQuery
+	if options.projRepo != nil {
+		balancesQuery = queries.NewListStockBalancesQuery(options.projRepo)
+	}
+
+	return handlers.NewBalanceHandler(asOfQuery, snapshotCmd, balancesQuery)
+}
+
+// setupContainerHandler sets up container handler with all dependencies
+func setupContainerHandler(
+	containerRepo container.Repository,
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.ContainerHandler {
+	stockService := newStockService(productRepo, locationRepo, stockRepo, options)
+	buildCmd := commands.NewBuildContainerCommand(containerRepo, productRepo, locationRepo, stockRepo, txManager)
+	breakCmd := commands.NewBreakContainerCommand(containerRepo, txManager)
+	moveCmd := commands.NewMoveContainerCommand(containerRepo, stockService, txManager).
+		WithAlerts(newMonitor(locationRepo, stockRepo, options)).
+		WithEvents(newOutbox(options))
+	getQuery := queries.NewGetContainerQuery(containerRepo)
+
+	return handlers.NewContainerHandler(buildCmd, breakCmd, moveCmd, getQuery)
+}
+
+// setupScanHandler sets up scan handler with all dependencies
+func setupScanHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	options *routerOptions,
+) *handlers.ScanHandler {
+	parseQuery := queries.NewParseScanQuery(newScanResolver(productRepo, locationRepo, options))
+
+	return handlers.NewScanHandler(parseQuery)
+}
+
+// setupPutawayHandler sets up putaway handler with all dependencies
+func setupPutawayHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+) *handlers.PutawayHandler {
+	stockService := newStockService(productRepo, locationRepo, stockRepo, options)
+	putawayService := newPutawayService(productRepo, locationRepo, stockRepo, stockService, options)
+	suggestQuery := queries.NewSuggestPutawayQuery(putawayService)
+
+	return handlers.NewPutawayHandler(suggestQuery, options.ruleRepo)
+}
+
+// setupReplenishmentHandler sets up replenishment handler with all dependencies
+func setupReplenishmentHandler(
+	replRepo replenishment.Repository,
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.ReplenishmentHandler {
+	stockService := newStockService(productRepo, locationRepo, stockRepo, options)
+	saveCmd := commands.NewSavePickFaceCommand(replRepo, productRepo, locationRepo)
+	runCmd := commands.NewRunReplenishmentCommand(replenishment.NewEngine(replRepo, stockRepo), txManager)
+	completeCmd := commands.NewCompleteReplenishmentTaskCommand(replRepo, stockService, txManager).
+		WithAlerts(newMonitor(locationRepo, stockRepo, options)).
+		WithEvents(newOutbox(options))
+	cancelCmd := commands.NewCancelReplenishmentTaskCommand(replRepo, txManager)
+	listQuery := queries.NewListReplenishmentTasksQuery(replRepo)
+
+	return handlers.NewReplenishmentHandler(saveCmd, runCmd, completeCmd, cancelCmd, listQuery, replRepo)
+}
+
+// setupReorderHandler sets up reorder handler with all dependencies
+func setupReorderHandler(
+	reorderRepo replenishment.ReorderRepository,
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.ReorderHandler {
+	monitor := replenishment.NewMonitor(reorderRepo, locationRepo, stockRepo)
+	saveCmd := commands.NewSaveReorderPolicyCommand(reorderRepo, productRepo, monitor, txManager).
+		WithEvents(newOutbox(options))
+	suggestionsQuery := queries.NewGetReorderSuggestionsQuery(monitor, productRepo)
+	alertsQuery := queries.NewListStockAlertsQuery(reorderRepo)
+
+	return handlers.NewReorderHandler(saveCmd, suggestionsQuery, alertsQuery, reorderRepo)
+}
+
+// setupWebhookHandler sets up webhook handler with all dependencies
+func setupWebhookHandler(webhookRepo webhook.Repository) *handlers.WebhookHandler {
+	createCmd := commands.NewCreateWebhookCommand(webhookRepo)
+	replayCmd := commands.NewReplayWebhookDeliveryCommand(webhookRepo)
+	deliveriesQuery := queries.NewListWebhookDeliveriesQuery(webhookRepo)
+
+	return handlers.NewWebhookHandler(createCmd, replayCmd, deliveriesQuery, webhookRepo)
+}
+
+// setupSerialHandler sets up serial handler with all dependencies
+func setupSerialHandler(serialRepo serial.Repository, productRepo product.Repository) *handlers.SerialHandler {
+	listQuery := queries.NewListSerialsQuery(serialRepo, productRepo)
+	getQuery := queries.NewGetSerialQuery(serialRepo)
+
+	return handlers.NewSerialHandler(listQuery, getQuery)
+}
+
+// setupAdminHandler sets up admin handler with all dependencies
+func setupAdminHandler(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+	txManager application.TransactionManager,
+) *handlers.AdminHandler {
+	reconciler := stock.NewReconciler(productRepo, stockRepo, options.snapshotRepo)
+	if options.projRepo != nil {
+		reconciler.WithBalances(options.projRepo)
+	}
+	if options.repairRepo != nil {
+		reconciler.WithRepairLog(options.repairRepo)
+	}
+	reconcileCmd := commands.NewReconcileStockCommand(reconciler, txManager)
+	if options.reconcileLock != nil {
+		reconcileCmd.WithLock(options.reconcileLock)
+	}
+	projector := newStockService(productRepo, locationRepo, stockRepo, options).Projector()
+	rebuildCmd := commands.NewRebuildProjectionsCommand(projector, txManager)
+
+	return handlers.NewAdminHandler(reconcileCmd, rebuildCmd)
+}
+
+// newStockService creates the stock service, tracking serials when a serial repository
+// is configured and keeping the balance projections when their repository is
+func newStockService(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+) *stock.Service {
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+	if options.projRepo != nil {
+		service.WithBalances(options.projRepo)
+	}
+	if options.serialRepo != nil {
+		service.WithSerials(options.serialRepo)
+	}
+	return service
+}
+
+// newPutawayService creates the putaway service with whichever rules and lot
+// lookups are configured
+func newPutawayService(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	stockService *stock.Service,
+	options *routerOptions,
+) *putaway.Service {
+	service := putaway.NewService(productRepo, locationRepo, stockRepo, stockService)
+	if options.ruleRepo != nil {
+		service.WithRules(options.ruleRepo)
+	}
+	if options.containerRepo != nil {
+		service.WithLots(options.containerRepo)
+	}
+	return service
+}
+
+// newScanResolver creates the scan resolver, resolving SSCCs when containers are configured
+func newScanResolver(
+	productRepo product.Repository,
+	locationRepo location.Repository,
+	options *routerOptions,
+) *scanning.Resolver {
+	resolver := scanning.NewResolver(productRepo, locationRepo)
+	if options.containerRepo != nil {
+		resolver.WithContainers(options.containerRepo)
+	}
+	return resolver
+}
+
+// newMonitor creates the low-stock monitor, or nil when reorder policies are not configured
+func newMonitor(
+	locationRepo location.Repository,
+	stockRepo stock.Repository,
+	options *routerOptions,
+) *replenishment.Monitor {
+	if options.reorderRepo == nil {
+		return nil
+	}
+	return replenishment.NewMonitor(options.reorderRepo, locationRepo, stockRepo)
+}
+
+// newOutbox creates the domain event outbox, or nil when it is not configured
+func newOutbox(options *routerOptions) *outbox.Outbox {
+	if options.outboxRepo == nil {
+		return nil
+	}
+	return outbox.NewOutbox(options.outboxRepo)
+}
+
+// transactionManager adapts the optional SQL transaction manager to the
+// application port, keeping a nil pointer from becoming a non-nil interface
+func transactionManager(txManager *sql.TransactionManager) application.TransactionManager {
+	if txManager == nil {
+		return nil
+	}
+	return txManager
 }
@@ -11,7 +11,7 @@ if [ -f .env ]; then
     export $(cat .env | grep -v '#' | xargs)
 fi
 
-# Run migrations
-go run ./cmd/api
+# Run migrations; pass status, down or "to N" to do something else
+go run ./cmd/api migrate "${@:-up}"
 
 echo "Migrations completed successfully!"
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
 )
@@ -33,36 +37,15 @@ func main() {
 	// Initialize repositories
 	productRepo := sql.NewProductRepository(db)
 	locationRepo := sql.NewLocationRepository(db)
+	stockRepo := sql.NewStockRepository(db)
 
-	ctx := context.Background()
-
-	// Seed products
-	products := []struct {
-		sku      string
-		quantity int64
-	}{
-		{"SKU-001", 100},
-		{"SKU-002", 50},
-		{"SKU-003", 200},
-		{"SKU-004", 75},
-		{"SKU-005", 150},
-	}
+	// Opening stock is recorded as movements so the ledger accounts for it
+	stockService := stock.NewService(productRepo, locationRepo, stockRepo).
+		WithBalances(sql.NewProjectionRepository(db))
+	recordCmd := commands.NewRecordStockMovementCommand(stockService, sql.NewTransactionManager(db)).
+		WithEvents(outbox.NewOutbox(sql.NewOutboxRepository(db)))
 
-	fmt.Println("Seeding products...")
-	for _, p := range products {
-		prod, err := product.NewProduct(p.sku, p.quantity)
-		if err != nil {
-			log.Printf("Failed to create product %s: %v", p.sku, err)
-			continue
-		}
-
-		if err := productRepo.Create(ctx, prod); err != nil {
-			log.Printf("Failed to save product %s: %v", p.sku, err)
-			continue
-		}
-
-		fmt.Printf("Created product: %s (qty: %d)\n", p.sku, p.quantity)
-	}
+	ctx := context.Background()
 
 	// Seed locations
 	locations := []struct {
@@ -77,7 +60,8 @@ func main() {
 		{"LOC-C1", "Cold Storage - Zone 1", 300},
 	}
 
-	fmt.Println("\nSeeding locations...")
+	fmt.Println("Seeding locations...")
+	locationIDs := make(map[string]int64, len(locations))
 	for _, l := range locations {
 		loc, err := location.NewLocation(l.code, l.name, l.capacity)
 		if err != nil {
@@ -89,9 +73,58 @@ func main() {
 			log.Printf("Failed to save location %s: %v", l.code, err)
 			continue
 		}
+		locationIDs[l.code] = loc.ID
 
 		fmt.Printf("Created location: %s - %s (capacity: %d)\n", l.code, l.name, l.capacity)
 	}
 
+	// Seed products with their opening stock
+	products := []struct {
+		sku      string
+		quantity int64
+		category string
+		location string
+	}{
+		{"SKU-001", 100, "Electronics", "LOC-A1"},
+		{"SKU-002", 50, "Electronics", "LOC-A2"},
+		{"SKU-003", 200, "Groceries", "LOC-B1"},
+		{"SKU-004", 75, "Apparel", "LOC-B2"},
+		{"SKU-005", 150, "Groceries", "LOC-C1"},
+	}
+
+	fmt.Println("\nSeeding products...")
+	for _, p := range products {
+		prod, err := product.NewProduct(p.sku, product.MasterData{Category: p.category})
+		if err != nil {
+			log.Printf("Failed to create product %s: %v", p.sku, err)
+			continue
+		}
+
+		if err := productRepo.Create(ctx, prod); err != nil {
+			log.Printf("Failed to save product %s: %v", p.sku, err)
+			continue
+		}
+
+		locationID, ok := locationIDs[p.location]
+		if !ok {
+			log.Printf("Skipping opening stock of %s: location %s was not created", p.sku, p.location)
+			continue
+		}
+
+		_, err = recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{
+			ProductID:  prod.ID,
+			LocationID: locationID,
+			Type:       string(stock.MovementTypeIN),
+			Quantity:   p.quantity,
+			Notes:      "opening stock",
+		})
+		if err != nil {
+			log.Printf("Failed to record opening stock of %s: %v", p.sku, err)
+			continue
+		}
+
+		fmt.Printf("Created product: %s (qty: %d at %s)\n", p.sku, p.quantity, p.location)
+	}
+
 	fmt.Println("\nDatabase seeding completed successfully!")
 }
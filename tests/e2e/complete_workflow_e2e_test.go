@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
@@ -14,6 +17,7 @@ import (
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
 	httpinterface "github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/pkg"
 	"github.com/gin-gonic/gin"
 )
 
@@ -53,26 +57,103 @@ func (m *MockProductRepository) GetBySKU(ctx context.Context, skuName string) (*
 	return nil, product.ErrProductNotFound
 }
 
-func (m *MockProductRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+func (m *MockProductRepository) GetByBarcode(ctx context.Context, barcode string) (*product.Product, error) {
+	for _, p := range m.products {
+		for _, b := range p.Barcodes {
+			if b.Value == barcode {
+				return p, nil
+			}
+		}
+	}
+	return nil, product.ErrProductNotFound
+}
+
+func (m *MockProductRepository) List(ctx context.Context, spec product.ListSpec) ([]*product.Product, error) {
 	var result []*product.Product
 	for _, p := range m.products {
-		result = append(result, p)
+		if matchesProduct(p, spec) && afterCursor(p.ID, spec.After) {
+			result = append(result, p)
+		}
 	}
-	return result, nil
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return page(result, spec.Limit, spec.Offset), nil
+}
+
+// Search scores SKU matches like the SQL repository, without trigrams or stemming
+func (m *MockProductRepository) Search(ctx context.Context, text string, limit int) ([]product.SearchHit, error) {
+	text = strings.ToLower(text)
+	var hits []product.SearchHit
+	for _, p := range m.products {
+		sku := strings.ToLower(p.SKUName)
+		var score float64
+		switch {
+		case sku == text:
+			score = 6
+		case strings.HasPrefix(sku, text):
+			score = 2
+		case strings.Contains(sku, text):
+			score = 1
+		}
+		if matchesSearch(text, p.Description, p.Category, p.Brand) {
+			score += 0.5
+		}
+		for _, b := range p.Barcodes {
+			if strings.Contains(b.Value, text) {
+				score++
+				break
+			}
+		}
+		if score > 0 {
+			hits = append(hits, product.SearchHit{Product: p, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.ID > hits[j].Product.ID
+	})
+	return page(hits, limit, 0), nil
 }
 
 func (m *MockProductRepository) Update(ctx context.Context, p *product.Product) error {
+	stored, ok := m.products[p.ID]
+	if !ok {
+		return product.ErrProductNotFound
+	}
+	if stored.Version != p.Version {
+		return product.ErrConcurrentModification
+	}
+	p.Version++
+	m.products[p.ID] = p
+	return nil
+}
+
+func (m *MockProductRepository) UpdateQuantity(ctx context.Context, p *product.Product) error {
 	m.products[p.ID] = p
 	return nil
 }
 
-func (m *MockProductRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockProductRepository) Delete(ctx context.Context, id, version int64) error {
+	stored, ok := m.products[id]
+	if !ok {
+		return product.ErrProductNotFound
+	}
+	if stored.Version != version {
+		return product.ErrConcurrentModification
+	}
 	delete(m.products, id)
 	return nil
 }
 
-func (m *MockProductRepository) Count(ctx context.Context) (int64, error) {
-	return int64(len(m.products)), nil
+func (m *MockProductRepository) Count(ctx context.Context, spec product.ListSpec) (int64, error) {
+	var count int64
+	for _, p := range m.products {
+		if matchesProduct(p, spec) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // MockLocationRepository is a mock implementation of location.Repository
@@ -108,26 +189,50 @@ func (m *MockLocationRepository) GetByCode(ctx context.Context, code string) (*l
 	return nil, location.ErrLocationNotFound
 }
 
-func (m *MockLocationRepository) List(ctx context.Context, limit, offset int) ([]*location.Location, error) {
+func (m *MockLocationRepository) List(ctx context.Context, spec location.ListSpec) ([]*location.Location, error) {
 	var result []*location.Location
 	for _, l := range m.locations {
-		result = append(result, l)
+		if matchesLocation(l, spec) && afterCursor(l.ID, spec.After) {
+			result = append(result, l)
+		}
 	}
-	return result, nil
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return page(result, spec.Limit, spec.Offset), nil
 }
 
 func (m *MockLocationRepository) Update(ctx context.Context, l *location.Location) error {
+	stored, ok := m.locations[l.ID]
+	if !ok {
+		return location.ErrLocationNotFound
+	}
+	if stored.Version != l.Version {
+		return location.ErrConcurrentModification
+	}
+	l.Version++
 	m.locations[l.ID] = l
 	return nil
 }
 
-func (m *MockLocationRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockLocationRepository) Delete(ctx context.Context, id, version int64) error {
+	stored, ok := m.locations[id]
+	if !ok {
+		return location.ErrLocationNotFound
+	}
+	if stored.Version != version {
+		return location.ErrConcurrentModification
+	}
 	delete(m.locations, id)
 	return nil
 }
 
-func (m *MockLocationRepository) Count(ctx context.Context) (int64, error) {
-	return int64(len(m.locations)), nil
+func (m *MockLocationRepository) Count(ctx context.Context, spec location.ListSpec) (int64, error) {
+	var count int64
+	for _, l := range m.locations {
+		if matchesLocation(l, spec) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // MockStockRepository is a mock implementation of stock.Repository
@@ -143,6 +248,7 @@ func NewMockStockRepository() *MockStockRepository {
 
 func (m *MockStockRepository) Create(ctx context.Context, sm *stock.StockMovement) error {
 	sm.ID = int64(len(m.movements) + 1)
+	sm.Sequence = sm.ID
 	m.movements[sm.ID] = sm
 	return nil
 }
@@ -174,18 +280,148 @@ func (m *MockStockRepository) GetByLocation(ctx context.Context, locationID int6
 	return result, nil
 }
 
-func (m *MockStockRepository) List(ctx context.Context, limit, offset int) ([]*stock.StockMovement, error) {
+func (m *MockStockRepository) List(ctx context.Context, spec stock.ListSpec) ([]*stock.StockMovement, error) {
 	var result []*stock.StockMovement
+	for seq := int64(len(m.movements)); seq >= 1; seq-- {
+		if sm := m.movements[seq]; matchesMovement(sm, spec) && afterCursor(sm.ID, spec.After) {
+			result = append(result, sm)
+		}
+	}
+	return page(result, spec.Limit, spec.Offset), nil
+}
+
+func (m *MockStockRepository) Count(ctx context.Context, spec stock.ListSpec) (int64, error) {
+	var count int64
+	for _, sm := range m.movements {
+		if matchesMovement(sm, spec) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockStockRepository) Each(ctx context.Context, filter stock.Filter, fn func(*stock.StockMovement) error) error {
+	for seq := int64(1); seq <= int64(len(m.movements)); seq++ {
+		if sm := m.movements[seq]; matchesFilter(sm, filter) {
+			if err := fn(sm); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockStockRepository) SumBalances(ctx context.Context, r stock.LedgerRange) ([]stock.Balance, error) {
+	type key struct{ productID, locationID int64 }
+	totals := make(map[key]int64)
 	for _, sm := range m.movements {
-		result = append(result, sm)
+		if sm.Sequence <= r.AfterSequence || (r.ThroughSequence > 0 && sm.Sequence > r.ThroughSequence) {
+			continue
+		}
+		if !r.Until.IsZero() && sm.CreatedAt.After(r.Until) {
+			continue
+		}
+		k := key{sm.ProductID, sm.LocationID}
+		if sm.IsInbound() {
+			totals[k] += sm.Quantity
+		} else {
+			totals[k] -= sm.Quantity
+		}
+	}
+
+	var result []stock.Balance
+	for k, qty := range totals {
+		result = append(result, stock.Balance{ProductID: k.productID, LocationID: k.locationID, Quantity: qty})
 	}
 	return result, nil
 }
 
-func (m *MockStockRepository) Count(ctx context.Context) (int64, error) {
+func (m *MockStockRepository) Replay(ctx context.Context, afterSequence int64, limit int) ([]*stock.StockMovement, error) {
+	var result []*stock.StockMovement
+	for seq := afterSequence + 1; seq <= int64(len(m.movements)) && len(result) < limit; seq++ {
+		result = append(result, m.movements[seq])
+	}
+	return result, nil
+}
+
+func (m *MockStockRepository) LastSequence(ctx context.Context) (int64, error) {
 	return int64(len(m.movements)), nil
 }
 
+// matchesFilter reports whether a movement satisfies every non-zero filter field
+func matchesFilter(sm *stock.StockMovement, f stock.Filter) bool {
+	return (f.ProductID == 0 || sm.ProductID == f.ProductID) &&
+		(f.LocationID == 0 || sm.LocationID == f.LocationID) &&
+		(f.Type == "" || sm.Type == f.Type) &&
+		(f.ReferenceType == "" || sm.ReferenceType == f.ReferenceType) &&
+		(f.ReferenceID == "" || sm.ReferenceID == f.ReferenceID) &&
+		(f.DocumentNumber == "" || sm.DocumentNumber == f.DocumentNumber) &&
+		f.Quantity.Contains(sm.Quantity) &&
+		f.CreatedAt.Contains(sm.CreatedAt)
+}
+
+// matchesMovement applies the filter and search of a movement listing
+func matchesMovement(sm *stock.StockMovement, spec stock.ListSpec) bool {
+	return matchesFilter(sm, spec.Filter) &&
+		matchesSearch(spec.Search, sm.ReferenceID, sm.DocumentNumber, sm.Notes, sm.Lot)
+}
+
+// matchesProduct applies the filter and search of a product listing
+func matchesProduct(p *product.Product, spec product.ListSpec) bool {
+	f := spec.Filter
+	return (f.SKUPrefix == "" || strings.HasPrefix(strings.ToLower(p.SKUName), strings.ToLower(f.SKUPrefix))) &&
+		(f.Category == "" || p.Category == f.Category) &&
+		(f.Brand == "" || p.Brand == f.Brand) &&
+		(f.Status == "" || p.Status == f.Status) &&
+		f.Quantity.Contains(p.Quantity) &&
+		matchesSearch(spec.Search, p.SKUName, p.Description, p.Category, p.Brand)
+}
+
+// matchesLocation applies the filter and search of a location listing
+func matchesLocation(l *location.Location, spec location.ListSpec) bool {
+	f := spec.Filter
+	return (f.CodePrefix == "" || strings.HasPrefix(strings.ToLower(l.Code), strings.ToLower(f.CodePrefix))) &&
+		(f.Warehouse == "" || l.Warehouse == f.Warehouse) &&
+		(f.TemperatureZone == "" || l.TemperatureZone == f.TemperatureZone) &&
+		f.Capacity.Contains(l.Capacity) &&
+		matchesSearch(spec.Search, l.Code, l.Name, l.Warehouse)
+}
+
+// matchesSearch reports whether any field contains the search text, ignoring case
+func matchesSearch(search string, fields ...string) bool {
+	if search == "" {
+		return true
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), strings.ToLower(search)) {
+			return true
+		}
+	}
+	return false
+}
+
+// afterCursor reports whether a row comes after the cursor keys in the
+// default newest-first order, whose last key is always the ID
+func afterCursor(id int64, after []string) bool {
+	if len(after) == 0 {
+		return true
+	}
+	last, _ := strconv.ParseInt(after[len(after)-1], 10, 64)
+	return id < last
+}
+
+// page cuts one page out of a listing; a zero limit keeps the rest
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
 // MockTransactionManager is a mock implementation of transaction manager
 type MockTransactionManager struct{}
 
@@ -254,7 +490,8 @@ func TestE2EAuthLoginWithToken(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
@@ -322,8 +559,7 @@ func TestE2ECompleteProductWorkflow(t *testing.T) {
 
 	// 1. Create Product
 	createReq := dto.CreateProductRequest{
-		SKUName:  "SKU-PROD-001",
-		Quantity: 100,
+		SKUName: "SKU-PROD-001",
 	}
 
 	body, _ := json.Marshal(createReq)
@@ -351,6 +587,7 @@ func TestE2ECompleteProductWorkflow(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Step 2: Get - Expected status 200, got %d", w.Code)
 	}
+	etag := w.Header().Get("ETag")
 
 	// 3. List Products
 	req = httptest.NewRequest("GET", "/api/v1/products?limit=10&offset=0", nil)
@@ -365,14 +602,14 @@ func TestE2ECompleteProductWorkflow(t *testing.T) {
 
 	// 4. Update Product
 	updateReq := dto.UpdateProductRequest{
-		SKUName:  "SKU-PROD-001-UPDATED",
-		Quantity: 150,
+		SKUName: pkg.Ptr("SKU-PROD-001-UPDATED"),
 	}
 
 	body, _ = json.Marshal(updateReq)
-	req = httptest.NewRequest("PUT", "/api/v1/products/1", bytes.NewReader(body))
+	req = httptest.NewRequest("PATCH", "/api/v1/products/1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", etag)
 
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -380,10 +617,23 @@ func TestE2ECompleteProductWorkflow(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Step 4: Update - Expected status 200, got %d", w.Code)
 	}
+	staleETag, etag := etag, w.Header().Get("ETag")
+
+	// 5. Delete Product, first with the tag read before the update
+	req = httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", staleETag)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Step 5: Stale delete - Expected status 412, got %d", w.Code)
+	}
 
-	// 5. Delete Product
 	req = httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", etag)
 
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -417,7 +667,8 @@ func TestE2EProductPagination(t *testing.T) {
 
 	// Create multiple products
 	for i := 1; i <= 5; i++ {
-		prod, _ := product.NewProduct("SKU-"+string(rune(i)), int64(100*i))
+		prod, _ := product.NewProduct("SKU-"+string(rune(i)), product.MasterData{})
+		prod.Quantity = int64(100 * i)
 		productRepo.Create(ctx, prod)
 	}
 
@@ -489,6 +740,7 @@ func TestE2ECompleteLocationWorkflow(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Step 2: Get - Expected status 200, got %d", w.Code)
 	}
+	etag := w.Header().Get("ETag")
 
 	// 3. List Locations
 	req = httptest.NewRequest("GET", "/api/v1/locations?limit=10&offset=0", nil)
@@ -512,6 +764,7 @@ func TestE2ECompleteLocationWorkflow(t *testing.T) {
 	req = httptest.NewRequest("PUT", "/api/v1/locations/1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", etag)
 
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -523,6 +776,7 @@ func TestE2ECompleteLocationWorkflow(t *testing.T) {
 	// 5. Delete Location
 	req = httptest.NewRequest("DELETE", "/api/v1/locations/1", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", w.Header().Get("ETag"))
 
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -546,7 +800,8 @@ func TestE2ECompleteStockMovementWorkflow(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -652,7 +907,8 @@ func TestE2EStockMovementInsufficientStock(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create product with limited quantity
-	prod, _ := product.NewProduct("SKU-001", 30)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 30
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -694,7 +950,8 @@ func TestE2EStockMovementCapacityExceeded(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create location with limited capacity
-	prod, _ := product.NewProduct("SKU-001", 200)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 200
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 100) // Limited capacity
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -740,8 +997,7 @@ func TestE2EEndToEndInventoryManagement(t *testing.T) {
 
 	// 1. Create Product
 	productReq := dto.CreateProductRequest{
-		SKUName:  "LAPTOP-001",
-		Quantity: 50,
+		SKUName: "LAPTOP-001",
 	}
 	body, _ := json.Marshal(productReq)
 	req := httptest.NewRequest("POST", "/api/v1/products", bytes.NewReader(body))
@@ -1,20 +1,48 @@
 package unit
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/config"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/messaging"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/webhooks"
 	httpinterface "github.com/ardianhermawan17/warehouse-management-system-ddd/internal/interfaces/http"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/pkg"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // TestAuthLoginSuccess tests successful login
@@ -167,8 +195,7 @@ func TestCreateProductSuccess(t *testing.T) {
 
 	// Create product
 	createReq := dto.CreateProductRequest{
-		SKUName:  "SKU-001",
-		Quantity: 100,
+		SKUName: "SKU-001",
 	}
 
 	body, _ := json.Marshal(createReq)
@@ -189,6 +216,10 @@ func TestCreateProductSuccess(t *testing.T) {
 	if !response["success"].(bool) {
 		t.Error("Expected success to be true")
 	}
+	// Stock only enters through the ledger, so a new product starts empty
+	if data, ok := response["data"].(map[string]interface{}); !ok || data["quantity"].(float64) != 0 {
+		t.Errorf("Expected a new product to start with quantity 0, got %v", response["data"])
+	}
 }
 
 // TestCreateProductInvalidRequest tests product creation with invalid request
@@ -225,6 +256,35 @@ func TestCreateProductInvalidRequest(t *testing.T) {
 	}
 }
 
+// TestCreateProductRejectsQuantity tests that opening stock cannot bypass the ledger
+func TestCreateProductRejectsQuantity(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	body := []byte(`{"sku_name": "SKU-001", "quantity": 100}`)
+	req := httptest.NewRequest("POST", "/api/v1/products", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "stock comes from movements") {
+		t.Errorf("Expected the error to point at stock movements, got %s", w.Body.String())
+	}
+	if count, _ := productRepo.Count(context.Background(), product.ListSpec{}); count != 0 {
+		t.Errorf("Expected no product to be created, got %d", count)
+	}
+}
+
 // TestGetProductSuccess tests successful product retrieval
 func TestGetProductSuccess(t *testing.T) {
 	ctx := context.Background()
@@ -238,7 +298,8 @@ func TestGetProductSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test product
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
@@ -306,8 +367,10 @@ func TestListProductsSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test products
-	prod1, _ := product.NewProduct("SKU-001", 100)
-	prod2, _ := product.NewProduct("SKU-002", 200)
+	prod1, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod1.Quantity = 100
+	prod2, _ := product.NewProduct("SKU-002", product.MasterData{})
+	prod2.Quantity = 200
 	productRepo.Create(ctx, prod1)
 	productRepo.Create(ctx, prod2)
 
@@ -348,7 +411,8 @@ func TestUpdateProductSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test product
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
@@ -358,14 +422,14 @@ func TestUpdateProductSuccess(t *testing.T) {
 
 	// Update product
 	updateReq := dto.UpdateProductRequest{
-		SKUName:  "SKU-001-UPDATED",
-		Quantity: 150,
+		SKUName: pkg.Ptr("SKU-001-UPDATED"),
 	}
 
 	body, _ := json.Marshal(updateReq)
-	req := httptest.NewRequest("PUT", "/api/v1/products/1", bytes.NewReader(body))
+	req := httptest.NewRequest("PATCH", "/api/v1/products/1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -373,6 +437,101 @@ func TestUpdateProductSuccess(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
+
+	// Renaming must not touch the stock level
+	if prod.SKUName != "SKU-001-UPDATED" || prod.Quantity != 100 {
+		t.Errorf("Expected renamed product with quantity 100, got %s/%d", prod.SKUName, prod.Quantity)
+	}
+}
+
+// TestUpdateProductRejectsQuantity tests that quantity cannot be overwritten by a product update
+func TestUpdateProductRejectsQuantity(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Create test product
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	productRepo.Create(ctx, prod)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+
+	// Get auth token
+	token := getAuthToken(t, router)
+
+	body := []byte(`{"sku_name": "SKU-001", "quantity": 0}`)
+	req := httptest.NewRequest("PATCH", "/api/v1/products/1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if prod.Quantity != 100 {
+		t.Errorf("Expected quantity to stay 100, got %d", prod.Quantity)
+	}
+}
+
+// TestAdjustStockPostsLedgerMovement tests that quantity corrections are recorded as movements
+func TestAdjustStockPostsLedgerMovement(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Create test data
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+	recordAt(ctx, stockRepo, prod.ID, loc.ID, stock.MovementTypeIN, 100, time.Now())
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+
+	// Get auth token
+	token := getAuthToken(t, router)
+
+	adjustReq := dto.AdjustStockRequest{
+		LocationID: loc.ID,
+		Delta:      -8,
+		Reason:     "cycle count shortage",
+	}
+
+	body, _ := json.Marshal(adjustReq)
+	req := httptest.NewRequest("POST", "/api/v1/products/1/adjustments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	if prod.Quantity != 92 {
+		t.Errorf("Expected quantity 92 after adjustment, got %d", prod.Quantity)
+	}
+
+	movements, _ := stockRepo.List(ctx, stock.ListSpec{Filter: stock.Filter{ReferenceType: stock.ReferenceTypeAdjustment}, Limit: 10})
+	if len(movements) != 1 || movements[0].Type != stock.MovementTypeOUT || movements[0].Quantity != 8 {
+		t.Errorf("Expected one OUT adjustment of 8, got %+v", movements)
+	}
 }
 
 // TestDeleteProductSuccess tests successful product deletion
@@ -388,7 +547,8 @@ func TestDeleteProductSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test product
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
@@ -399,6 +559,7 @@ func TestDeleteProductSuccess(t *testing.T) {
 	// Delete product
 	req := httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -589,6 +750,7 @@ func TestUpdateLocationSuccess(t *testing.T) {
 	req := httptest.NewRequest("PUT", "/api/v1/locations/1", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -622,6 +784,7 @@ func TestDeleteLocationSuccess(t *testing.T) {
 	// Delete location
 	req := httptest.NewRequest("DELETE", "/api/v1/locations/1", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -646,7 +809,8 @@ func TestRecordStockMovementInboundSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -690,10 +854,12 @@ func TestRecordStockMovementOutboundSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
+	recordAt(ctx, stockRepo, prod.ID, loc.ID, stock.MovementTypeIN, 100, time.Now())
 
 	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
 
@@ -734,7 +900,8 @@ func TestRecordStockMovementInsufficientStock(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 50)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 50
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -778,7 +945,8 @@ func TestGetStockMovementSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -816,7 +984,8 @@ func TestListStockMovementsSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -856,7 +1025,8 @@ func TestGetProductMovementsSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -894,7 +1064,8 @@ func TestGetLocationMovementsSuccess(t *testing.T) {
 	// txManager removed (not needed for testing)
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
 	productRepo.Create(ctx, prod)
 	locationRepo.Create(ctx, loc)
@@ -919,27 +1090,3251 @@ func TestGetLocationMovementsSuccess(t *testing.T) {
 	}
 }
 
-// ===================== HELPER FUNCTIONS =====================
+// TestRecordStockMovementWithReference tests that document references are stored and returned
+func TestRecordStockMovementWithReference(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
 
-// getAuthToken retrieves an authentication token from the login endpoint
-func getAuthToken(t *testing.T, router *gin.Engine) string {
-	loginReq := map[string]string{
-		"username": "testuser",
-		"password": "testpass",
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Create test data
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+
+	// Get auth token
+	token := getAuthToken(t, router)
+
+	// Record inbound movement against a purchase order
+	movementReq := dto.RecordStockMovementRequest{
+		ProductID:      prod.ID,
+		LocationID:     loc.ID,
+		Type:           "IN",
+		Quantity:       50,
+		ReferenceType:  "PO",
+		ReferenceID:    "PO-1001",
+		DocumentNumber: "DN-4412",
+		Notes:          "pallet slightly damaged",
 	}
 
-	body, _ := json.Marshal(loginReq)
-	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+	body, _ := json.Marshal(movementReq)
+	req := httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
 
 	data := response["data"].(map[string]interface{})
-	return data["token"].(string)
+	if data["document_number"] != "DN-4412" {
+		t.Errorf("Expected document_number DN-4412, got %v", data["document_number"])
+	}
+	if data["reference_type"] != "PO" || data["reference_id"] != "PO-1001" {
+		t.Errorf("Expected reference PO/PO-1001, got %v/%v", data["reference_type"], data["reference_id"])
+	}
+}
+
+// TestRecordStockMovementInvalidReferenceType tests that unknown reference types are rejected
+func TestRecordStockMovementInvalidReferenceType(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Create test data
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+
+	// Get auth token
+	token := getAuthToken(t, router)
+
+	movementReq := dto.RecordStockMovementRequest{
+		ProductID:     prod.ID,
+		LocationID:    loc.ID,
+		Type:          "IN",
+		Quantity:      50,
+		ReferenceType: "INVOICE",
+	}
+
+	body, _ := json.Marshal(movementReq)
+	req := httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// TestListStockMovementsFilterByDocumentNumber tests filtering movements by document number
+func TestListStockMovementsFilterByDocumentNumber(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Create test data
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+
+	move1, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 50)
+	move1.SetReference(stock.ReferenceTypePO, "PO-1001", "DN-4412", "")
+	move2, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 30)
+	move2.SetReference(stock.ReferenceTypePO, "PO-1002", "DN-5000", "")
+	stockRepo.Create(ctx, move1)
+	stockRepo.Create(ctx, move2)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+
+	// Get auth token
+	token := getAuthToken(t, router)
+
+	req := httptest.NewRequest("GET", "/api/v1/stock-movements?document_number=DN-4412&total=true", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	if data["total"].(float64) != 1 {
+		t.Errorf("Expected 1 movement for DN-4412, got %v", data["total"])
+	}
+}
+
+// TestAdminLoginRequiresPassword tests that an admin user name alone does not grant the admin role
+func TestAdminLoginRequiresPassword(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), NewMockStockRepository(), nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "admin", "password": "wrong"})
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+// ===================== STOCK BALANCE TESTS =====================
+
+func TestGetStockAsOfEndpoint(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 100, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeOUT, 60, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC))
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	// JSON response at month end
+	req := httptest.NewRequest("GET", "/api/v1/stock/as-of?timestamp=2024-01-31", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	products := response["data"].(map[string]interface{})["products"].([]interface{})
+	if len(products) != 1 || products[0].(map[string]interface{})["quantity"].(float64) != 100 {
+		t.Errorf("Expected product total 100 at month end, got %v", products)
+	}
+
+	// CSV export
+	req = httptest.NewRequest("GET", "/api/v1/stock/as-of?timestamp=2024-01-31&format=csv", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if got := w.Body.String(); !strings.Contains(got, "product_id,location_id,quantity\n1,1,100\n") {
+		t.Errorf("Unexpected CSV body: %q", got)
+	}
+}
+
+func TestGetStockAsOfInvalidTimestamp(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	req := httptest.NewRequest("GET", "/api/v1/stock/as-of?timestamp=yesterday", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// ===================== RECONCILIATION TESTS =====================
+
+func TestReconciliationEndpoint(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	productRepo.Create(ctx, prod)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+	token := getAdminToken(t, router)
+
+	body, _ := json.Marshal(map[string]interface{}{"repair": true})
+	req := httptest.NewRequest("POST", "/api/v1/admin/reconciliation", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	discrepancies := response["data"].(map[string]interface{})["discrepancies"].([]interface{})
+	if len(discrepancies) != 1 || discrepancies[0].(map[string]interface{})["repaired"] != true {
+		t.Errorf("Expected one repaired discrepancy, got %v", discrepancies)
+	}
+	if prod.Quantity != 0 {
+		t.Errorf("Expected stored quantity reset to the empty ledger, got %d", prod.Quantity)
+	}
+}
+
+func TestReconciliationEndpointRequiresAdmin(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/reconciliation", bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
+func TestReconciliationEndpointRejectsOverlappingRuns(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), NewMockStockRepository(), nil,
+		httpinterface.WithReconciliationLock(heldLock{}),
+	)
+	token := getAdminToken(t, router)
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/reconciliation", bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+// ===================== PRODUCT MASTER DATA TESTS =====================
+
+func TestCreateProductWithMasterData(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	createReq := dto.CreateProductRequest{
+		SKUName:     "SKU-001",
+		Description: "Sparkling water 330ml",
+		Category:    "Beverages",
+		Brand:       "Acme",
+		BaseUOM:     "ea",
+		Barcodes:    []dto.BarcodeDTO{{Type: "EAN13", Value: "4006381333931"}},
+		Dimensions:  &dto.DimensionsDTO{LengthMM: 66, WidthMM: 66, HeightMM: 115},
+		WeightGrams: 350,
+	}
+
+	body, _ := json.Marshal(createReq)
+	req := httptest.NewRequest("POST", "/api/v1/products", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data dto.ProductResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Data.BaseUOM != "EA" || response.Data.Status != "ACTIVE" || len(response.Data.Barcodes) != 1 {
+		t.Errorf("Unexpected product response: %+v", response.Data)
+	}
+
+	// A second product cannot reuse the barcode
+	createReq.SKUName = "SKU-002"
+	body, _ = json.Marshal(createReq)
+	req = httptest.NewRequest("POST", "/api/v1/products", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for duplicate barcode, got %d", w.Code)
+	}
+}
+
+// ===================== UNIT OF MEASURE TESTS =====================
+
+func TestRecordStockMovementInCases(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod := packedProduct(t, 0)
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	movementReq := dto.RecordStockMovementRequest{
+		ProductID:  prod.ID,
+		LocationID: loc.ID,
+		Type:       "IN",
+		Quantity:   5,
+		UOM:        "CASE",
+	}
+
+	body, _ := json.Marshal(movementReq)
+	req := httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data dto.StockMovementResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Data.Quantity != 120 || response.Data.EnteredUOM != "CASE" || response.Data.EnteredQty != 5 {
+		t.Errorf("Expected 5 cases stored as 120 eaches, got %+v", response.Data)
+	}
+
+	if updated, _ := productRepo.GetByID(ctx, prod.ID); updated.Quantity != 120 {
+		t.Errorf("Expected product quantity 120, got %d", updated.Quantity)
+	}
+
+	// Capacity is checked against base units: 20 more cases would be 480 eaches
+	movementReq.Quantity = 20
+	body, _ = json.Marshal(movementReq)
+	req = httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for capacity exceeded, got %d", w.Code)
+	}
+}
+
+func TestGetProductInPackLevel(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	prod := packedProduct(t, 100)
+	productRepo.Create(ctx, prod)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	req := httptest.NewRequest("GET", "/api/v1/products/1?uom=CASE", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data dto.ProductResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	got := response.Data.QuantityInUOM
+	if got == nil || got.Packs != 4 || got.Remainder != 4 {
+		t.Errorf("Expected 4 cases and 4 eaches, got %+v", got)
+	}
+
+	// Asking for a level the product does not use is a client error
+	unpacked, _ := product.NewProduct("SKU-002", product.MasterData{})
+	unpacked.Quantity = 10
+	productRepo.Create(ctx, unpacked)
+
+	req = httptest.NewRequest("GET", "/api/v1/products/2?uom=PALLET", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// ===================== LOCATION CAPACITY TESTS =====================
+
+func TestRecordStockMovementReportsExceededLimit(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{WeightGrams: 500})
+	productRepo.Create(ctx, prod)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	// Create the location through the API so the limits round-trip
+	body, _ := json.Marshal(dto.LocationRequest{Code: "LOC-A1", Name: "Warehouse A", Capacity: 1000, MaxWeightGrams: 2000})
+	req := httptest.NewRequest("POST", "/api/v1/locations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	body, _ = json.Marshal(dto.RecordStockMovementRequest{ProductID: prod.ID, LocationID: 1, Type: "IN", Quantity: 5})
+	req = httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if msg, _ := response["message"].(string); !strings.Contains(msg, "weight capacity exceeded") {
+		t.Errorf("Expected message naming the weight limit, got %q", msg)
+	}
+}
+
+// ===================== CONTAINER TESTS =====================
+
+func TestBuildNestedContainerAndLookUp(t *testing.T) {
+	f := newContainerFixture(t)
+
+	status := f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "CTN-1", Type: "CARTON", LocationID: 1,
+		Contents: []dto.ContainerContentDTO{{ProductID: 1, Lot: "L-01", Quantity: 40}},
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+
+	var pallet dto.ContainerResponse
+	status = f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "PAL-1", SSCC: "106141411234567897", Type: "PALLET", LocationID: 1,
+		Contents:  []dto.ContainerContentDTO{{ProductID: 2, Quantity: 20}},
+		ChildLPNs: []string{"CTN-1"},
+	}, &pallet)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+
+	// Lookup by SSCC returns the whole tree with totals
+	var found dto.ContainerResponse
+	if status := f.do("GET", "/api/v1/containers/106141411234567897", nil, &found); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if found.LPN != "PAL-1" || len(found.Children) != 1 || found.Children[0].LPN != "CTN-1" {
+		t.Fatalf("Expected pallet with one carton, got %+v", found)
+	}
+	if len(found.Totals) != 2 || found.Totals[0].Quantity != 40 || found.Totals[1].Quantity != 20 {
+		t.Errorf("Expected totals 40 and 20, got %+v", found.Totals)
+	}
+
+	// Only 60 loose units of product 1 remain at the dock
+	status = f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "CTN-2", Type: "CARTON", LocationID: 1,
+		Contents: []dto.ContainerContentDTO{{ProductID: 1, Quantity: 61}},
+	}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for packing more than the loose stock, got %d", status)
+	}
+
+	if status := f.do("GET", "/api/v1/containers/UNKNOWN", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", status)
+	}
+}
+
+func TestMoveContainerWritesMovementsForContents(t *testing.T) {
+	f := newContainerFixture(t)
+
+	f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "CTN-1", Type: "CARTON", LocationID: 1,
+		Contents: []dto.ContainerContentDTO{{ProductID: 1, Quantity: 40}},
+	}, nil)
+	f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "PAL-1", Type: "PALLET", LocationID: 1,
+		Contents:  []dto.ContainerContentDTO{{ProductID: 2, Quantity: 20}},
+		ChildLPNs: []string{"CTN-1"},
+	}, nil)
+
+	var moved dto.MoveContainerResponse
+	status := f.do("POST", "/api/v1/containers/PAL-1/move", dto.MoveContainerRequest{ToLocationID: 2, DocumentNumber: "MV-1"}, &moved)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+
+	// One OUT and one IN per product in the tree
+	if len(moved.Movements) != 4 {
+		t.Fatalf("Expected 4 movements, got %d", len(moved.Movements))
+	}
+	for _, m := range moved.Movements {
+		if m.ReferenceType != "TRANSFER" || m.ReferenceID != "PAL-1" || m.DocumentNumber != "MV-1" {
+			t.Errorf("Unexpected movement reference: %+v", m)
+		}
+	}
+
+	balances, _ := f.stockRepo.SumBalances(context.Background(), stock.LedgerRange{})
+	atRack := make(map[int64]int64)
+	for _, b := range balances {
+		if b.LocationID == 2 {
+			atRack[b.ProductID] = b.Quantity
+		}
+	}
+	if atRack[1] != 40 || atRack[2] != 20 {
+		t.Errorf("Expected 40 and 20 at the rack, got %v", atRack)
+	}
+
+	carton, _ := f.containerRepo.GetByLPN(context.Background(), "CTN-1")
+	if carton.LocationID != 2 {
+		t.Errorf("Expected nested carton to move with the pallet, got location %d", carton.LocationID)
+	}
+}
+
+func TestBreakContainerReleasesChildren(t *testing.T) {
+	f := newContainerFixture(t)
+
+	f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "CTN-1", Type: "CARTON", LocationID: 1,
+		Contents: []dto.ContainerContentDTO{{ProductID: 1, Quantity: 40}},
+	}, nil)
+	f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "PAL-1", Type: "PALLET", LocationID: 1, ChildLPNs: []string{"CTN-1"},
+	}, nil)
+
+	var broken dto.ContainerResponse
+	if status := f.do("POST", "/api/v1/containers/PAL-1/break", nil, &broken); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if broken.Status != "BROKEN" {
+		t.Errorf("Expected BROKEN status, got %s", broken.Status)
+	}
+
+	carton, _ := f.containerRepo.GetByLPN(context.Background(), "CTN-1")
+	if carton.ParentID != nil {
+		t.Errorf("Expected carton to be released from the pallet")
+	}
+
+	// A broken container cannot be moved
+	status := f.do("POST", "/api/v1/containers/PAL-1/move", dto.MoveContainerRequest{ToLocationID: 2}, nil)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for moving a broken container, got %d", status)
+	}
+}
+
+// ===================== SERIAL NUMBER TESTS =====================
+
+func TestSerialEndpoints(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	serialRepo := NewMockSerialRepository()
+
+	prod, _ := product.NewProduct("SKU-LAPTOP", product.MasterData{Serialized: true})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithSerialRepository(serialRepo),
+	)
+	token := getAuthToken(t, router)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"product_id":  prod.ID,
+		"location_id": loc.ID,
+		"type":        "IN",
+		"quantity":    2,
+		"serials":     []string{"SN-1", "SN-2"},
+	})
+	req := httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/products/1/serials?status=IN_STOCK&location_id=1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	serials := response["data"].(map[string]interface{})["data"].([]interface{})
+	if len(serials) != 2 {
+		t.Errorf("Expected 2 serials in stock, got %v", serials)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/products/1/serials/SN-2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	json.Unmarshal(w.Body.Bytes(), &response)
+	history := response["data"].(map[string]interface{})["history"].([]interface{})
+	if len(history) != 1 || history[0].(map[string]interface{})["movement_type"] != "IN" {
+		t.Errorf("Expected one IN event in history, got %v", history)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/products/1/serials/SN-404", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+// ===================== PUTAWAY TESTS =====================
+
+func TestPutawayEndpointsAndAutoAssign(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	f := newPutawayFixture(t)
+	ice := f.product(t, "SKU-ICE", "Frozen")
+
+	router := httpinterface.SetupRouter(cfg, f.productRepo, f.locationRepo, f.stockRepo, nil,
+		httpinterface.WithPutawayRuleRepository(f.ruleRepo),
+	)
+	token := getAuthToken(t, router)
+
+	w := sendJSON(router, token, "PUT", "/api/v1/putaway/rules/Frozen", map[string]interface{}{"temperature_zone": "FROZEN", "allow_mixed_skus": true})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/putaway/suggest", map[string]interface{}{"product_id": ice.ID, "quantity": 5})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suggestions := response["data"].([]interface{})
+	if len(suggestions) != 1 || suggestions[0].(map[string]interface{})["location_code"] != "F-01" {
+		t.Errorf("Expected F-01 suggested, got %v", suggestions)
+	}
+
+	// An IN movement without location goes to the top suggestion
+	w = sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{
+		"product_id":  ice.ID,
+		"type":        "IN",
+		"quantity":    5,
+		"auto_assign": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if got := int64(response["data"].(map[string]interface{})["location_id"].(float64)); got != f.locations["F-01"].ID {
+		t.Errorf("Expected movement at F-01, got location %d", got)
+	}
+
+	// Auto-assign is inbound only, and location_id is otherwise required
+	w = sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{"product_id": ice.ID, "type": "OUT", "quantity": 1, "auto_assign": true})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for outbound auto-assign, got %d", w.Code)
+	}
+	w = sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{"product_id": ice.ID, "type": "IN", "quantity": 1})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without location, got %d", w.Code)
+	}
+}
+
+// ===================== REPLENISHMENT TESTS =====================
+
+func TestReplenishmentTaskEndpoints(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo, locationRepo, stockRepo, replRepo := replenishmentFixture(t)
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithReplenishmentRepository(replRepo),
+	)
+	token := getAuthToken(t, router)
+
+	w := sendJSON(router, token, "PUT", "/api/v1/replenishment/pick-faces", map[string]interface{}{
+		"product_id": 1, "location_id": 1, "min": 10, "max": 17,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	w = sendJSON(router, token, "PUT", "/api/v1/replenishment/pick-faces", map[string]interface{}{
+		"product_id": 1, "location_id": 99, "min": 10, "max": 20,
+	})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown location, got %d", w.Code)
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/replenishment/run", map[string]interface{}{"product_ids": []int64{1}, "reference": "WAVE-7"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	tasks := response["data"].([]interface{})
+	if len(tasks) != 1 || tasks[0].(map[string]interface{})["quantity"] != float64(12) {
+		t.Fatalf("Expected one task of 12 from the smallest reserve, got %v", tasks)
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/replenishment/tasks/1/complete", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	json.Unmarshal(w.Body.Bytes(), &response)
+	movements := response["data"].(map[string]interface{})["movements"].([]interface{})
+	if len(movements) != 2 || movements[0].(map[string]interface{})["reference_id"] != "REPL-1" {
+		t.Errorf("Expected a TRANSFER pair referenced REPL-1, got %v", movements)
+	}
+
+	balances, _ := stockRepo.SumBalances(context.Background(), stock.LedgerRange{})
+	for _, b := range balances {
+		if b.LocationID == 1 && b.Quantity != 17 {
+			t.Errorf("Expected 17 at the pick face, got %d", b.Quantity)
+		}
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/replenishment/tasks/1/cancel", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 cancelling a completed task, got %d", w.Code)
+	}
+
+	w = sendJSON(router, token, "GET", "/api/v1/replenishment/tasks?status=COMPLETED", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if list := response["data"].(map[string]interface{})["data"].([]interface{}); len(list) != 1 {
+		t.Errorf("Expected 1 completed task, got %v", list)
+	}
+
+	w = sendJSON(router, token, "GET", "/api/v1/replenishment/tasks?status=DONE", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown status, got %d", w.Code)
+	}
+}
+
+// ===================== REORDER TESTS =====================
+
+func TestFailedStockLevelCheckIsLogged(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	reorderRepo := &alertFailingReorderRepository{MockReorderRepository: NewMockReorderRepository()}
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+	policy, _ := replenishment.NewReorderPolicy(prod.ID, "", 20, 5, 12)
+	reorderRepo.SavePolicy(ctx, policy)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, NewMockStockRepository(), nil,
+		httpinterface.WithReorderRepository(reorderRepo),
+	)
+	token := getAuthToken(t, router)
+
+	body, _ := json.Marshal(map[string]interface{}{"product_id": prod.ID, "location_id": loc.ID, "type": "IN", "quantity": 10})
+	req := httptest.NewRequest("POST", "/api/v1/stock-movements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// The movement is committed before the check, so it still succeeds
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(logged.String(), "Failed to check stock levels of product 1: connection reset") {
+		t.Errorf("Expected the failed check to be logged, got %q", logged.String())
+	}
+}
+
+func TestReorderEndpoints(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	reorderRepo := NewMockReorderRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithReorderRepository(reorderRepo),
+	)
+	token := getAuthToken(t, router)
+
+	w := sendJSON(router, token, "PUT", "/api/v1/replenishment/reorder-policies", map[string]interface{}{
+		"product_id": prod.ID, "reorder_point": 20, "safety_stock": 5, "reorder_qty": 12,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{
+		"product_id": prod.ID, "location_id": loc.ID, "type": "IN", "quantity": 30,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+	w = sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{
+		"product_id": prod.ID, "location_id": loc.ID, "type": "OUT", "quantity": 14,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	w = sendJSON(router, token, "GET", "/api/v1/replenishment/alerts", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	alerts := response["data"].(map[string]interface{})["data"].([]interface{})
+	if len(alerts) != 1 || alerts[0].(map[string]interface{})["available"] != float64(16) {
+		t.Fatalf("Expected one alert at 16 available, got %v", alerts)
+	}
+
+	w = sendJSON(router, token, "GET", "/api/v1/replenishment/suggestions?format=csv", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("Expected CSV report, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || lines[1] != "1,SKU-001,,16,20,5,12,LOW,12" {
+		t.Errorf("Expected one report line suggesting 12, got %q", lines)
+	}
+
+	w = sendJSON(router, token, "DELETE", "/api/v1/replenishment/reorder-policies/1?warehouse=JKT", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown policy, got %d", w.Code)
+	}
+}
+
+// ===================== WEBHOOK TESTS =====================
+
+func TestWebhookEndpoints(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	webhookRepo := NewMockWebhookRepository()
+	outboxRepo := NewMockOutboxRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	receiver := &webhookReceiver{secret: "a-shared-secret-of-32-characters", status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithWebhookRepository(webhookRepo),
+		httpinterface.WithOutboxRepository(outboxRepo),
+	)
+	token := getAdminToken(t, router)
+
+	w := sendJSON(router, token, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url": server.URL, "event_types": []string{"order.created"},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown event type, got %d", w.Code)
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":         server.URL,
+		"event_types": []string{"stock.movement_recorded", "shipment.confirmed"},
+		"secret":      receiver.secret,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	for _, movement := range []map[string]interface{}{
+		{"product_id": prod.ID, "location_id": loc.ID, "type": "IN", "quantity": 30},
+		{"product_id": prod.ID, "location_id": loc.ID, "type": "OUT", "quantity": 5, "reference_type": "ORDER", "reference_id": "SO-1"},
+	} {
+		if w := sendJSON(router, token, "POST", "/api/v1/stock-movements", movement); w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+		}
+	}
+	if len(webhookRepo.deliveries) != 0 {
+		t.Fatalf("Expected events to wait in the outbox, got %d deliveries", len(webhookRepo.deliveries))
+	}
+
+	relay := outbox.NewRelay(outboxRepo, messaging.NewWebhookSink(webhook.NewPublisher(webhookRepo)))
+	if n, err := relay.PublishPending(ctx); err != nil || n != 3 {
+		t.Fatalf("Expected 3 events relayed, got %d, %v", n, err)
+	}
+	if len(webhookRepo.deliveries) != 3 {
+		t.Fatalf("Expected 3 deliveries (2 movements and 1 shipment), got %d", len(webhookRepo.deliveries))
+	}
+
+	dispatcher := webhook.NewDispatcher(webhookRepo, webhooks.NewHTTPSender(time.Second), webhook.DefaultRetryPolicy())
+	if n, err := dispatcher.DeliverDue(ctx); err != nil || n != 3 {
+		t.Fatalf("Expected 3 deliveries sent, got %d, %v", n, err)
+	}
+	expected := []string{"stock.movement_recorded", "stock.movement_recorded", "shipment.confirmed"}
+	if fmt.Sprint(receiver.events) != fmt.Sprint(expected) || receiver.badSigs != 0 {
+		t.Fatalf("Expected %v correctly signed, got %v (%d bad)", expected, receiver.events, receiver.badSigs)
+	}
+
+	w = sendJSON(router, token, "GET", "/api/v1/admin/webhooks/deliveries?status=DEAD", nil)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if dead := response["data"].(map[string]interface{})["data"].([]interface{}); len(dead) != 0 {
+		t.Errorf("Expected no dead deliveries, got %v", dead)
+	}
+	if w := sendJSON(router, token, "GET", "/api/v1/admin/webhooks/deliveries?status=LOST", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown status, got %d", w.Code)
+	}
+
+	w = sendJSON(router, token, "POST", "/api/v1/admin/webhooks/deliveries/3/replay", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := sendJSON(router, token, "POST", "/api/v1/admin/webhooks/deliveries/3/replay", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 replaying a pending delivery, got %d", w.Code)
+	}
+	if w := sendJSON(router, token, "POST", "/api/v1/admin/webhooks/deliveries/99/replay", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown delivery, got %d", w.Code)
+	}
+
+	if w := sendJSON(router, token, "DELETE", "/api/v1/webhooks/1", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w := sendJSON(router, token, "DELETE", "/api/v1/webhooks/1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+// ===================== PROJECTION TESTS =====================
+
+func TestProjectionEndpoints(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key",
+		AdminUsers: testAdminUsers(t),
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	projRepo := NewMockProjectionRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithProjectionRepository(projRepo),
+	)
+	token := getAdminToken(t, router)
+
+	w := sendJSON(router, token, "POST", "/api/v1/stock-movements", map[string]interface{}{
+		"product_id": prod.ID, "location_id": loc.ID, "type": "IN", "quantity": 25, "lot": "L-09",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var lots struct {
+		Data []dto.LotBalanceResponse `json:"data"`
+	}
+	w = sendJSON(router, token, "GET", "/api/v1/stock/lots?lot=L-09", nil)
+	json.Unmarshal(w.Body.Bytes(), &lots)
+	if w.Code != http.StatusOK || len(lots.Data) != 1 || lots.Data[0].Quantity != 25 {
+		t.Fatalf("Expected one lot with 25, got %d %s", w.Code, w.Body.String())
+	}
+
+	var balances struct {
+		Data []dto.LocationStockResponse `json:"data"`
+	}
+	w = sendJSON(router, token, "GET", "/api/v1/stock/balances?product_id=1", nil)
+	json.Unmarshal(w.Body.Bytes(), &balances)
+	if w.Code != http.StatusOK || len(balances.Data) != 1 || balances.Data[0].Quantity != 25 {
+		t.Fatalf("Expected one balance with 25, got %d %s", w.Code, w.Body.String())
+	}
+
+	var rebuilt struct {
+		Data dto.RebuildReportResponse `json:"data"`
+	}
+	w = sendJSON(router, token, "POST", "/api/v1/admin/projections/rebuild", nil)
+	json.Unmarshal(w.Body.Bytes(), &rebuilt)
+	if w.Code != http.StatusOK || rebuilt.Data.Movements != 1 || rebuilt.Data.ThroughSequence != 1 {
+		t.Errorf("Expected a rebuild over one movement, got %d %s", w.Code, w.Body.String())
+	}
+	if prod.Quantity != 25 {
+		t.Errorf("Expected product total 25 after rebuild, got %d", prod.Quantity)
+	}
+}
+
+// ===================== IDEMPOTENCY TESTS =====================
+
+func TestIdempotencyKeyReplaysOriginalResponse(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	movement := map[string]interface{}{"product_id": 1, "location_id": 1, "type": "IN", "quantity": 10}
+
+	first := f.post("/api/v1/stock-movements", "scan-0001", movement)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d, body: %s", first.Code, first.Body.String())
+	}
+
+	retry := f.post("/api/v1/stock-movements", "scan-0001", movement)
+	if retry.Code != http.StatusCreated {
+		t.Fatalf("Expected replayed status 201, got %d", retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the original body on replay, got %s", retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected the replay to be marked with Idempotent-Replayed")
+	}
+
+	count, _ := f.stockRepo.Count(context.Background(), stock.ListSpec{})
+	if count != 1 || f.product.Quantity != 10 {
+		t.Errorf("Expected one movement and quantity 10, got %d movements and quantity %d", count, f.product.Quantity)
+	}
+
+	// A new key is a new request
+	if w := f.post("/api/v1/stock-movements", "scan-0002", movement); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+	if f.product.Quantity != 20 {
+		t.Errorf("Expected quantity 20 after a second key, got %d", f.product.Quantity)
+	}
+}
+
+func TestIdempotencyKeyRejectsDifferentRequest(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	f.post("/api/v1/stock-movements", "scan-0001", map[string]interface{}{"product_id": 1, "location_id": 1, "type": "IN", "quantity": 10})
+
+	w := f.post("/api/v1/stock-movements", "scan-0001", map[string]interface{}{"product_id": 1, "location_id": 1, "type": "IN", "quantity": 12})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a different body, got %d", w.Code)
+	}
+
+	w = f.post("/api/v1/locations", "scan-0001", map[string]interface{}{"code": "LOC-B1", "name": "B", "capacity": 10})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a different endpoint, got %d", w.Code)
+	}
+
+	w = f.post("/api/v1/locations", strings.Repeat("k", 256), map[string]interface{}{"code": "LOC-B1", "name": "B", "capacity": 10})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an overlong key, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyKeyReplaysClientErrors(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	outbound := map[string]interface{}{"product_id": 1, "location_id": 1, "type": "OUT", "quantity": 5}
+
+	first := f.post("/api/v1/stock-movements", "pick-1", outbound)
+	if first.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for insufficient stock, got %d", first.Code)
+	}
+
+	// Stock arriving later does not change the answer for the same key
+	f.post("/api/v1/stock-movements", "", map[string]interface{}{"product_id": 1, "location_id": 1, "type": "IN", "quantity": 10})
+
+	retry := f.post("/api/v1/stock-movements", "pick-1", outbound)
+	if retry.Code != http.StatusBadRequest || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected the original 400 replayed, got %d", retry.Code)
+	}
+}
+
+func TestIdempotencyKeysAreScopedPerUser(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	payload, _ := json.Marshal(map[string]interface{}{"product_id": 1, "location_id": 1, "type": "IN", "quantity": 10})
+
+	if w := f.postAs(f.token, "/api/v1/stock-movements", "scan-0001", payload); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+
+	// Every demo user shares a user ID, so keys must be told apart by username
+	other := loginAs(t, f.router, "otheruser", "otherpass")
+	w := f.postAs(other, "/api/v1/stock-movements", "scan-0001", payload)
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("Expected another user's key to run the request, got %d", w.Code)
+	}
+	if f.product.Quantity != 20 {
+		t.Errorf("Expected quantity 20 after two users' requests, got %d", f.product.Quantity)
+	}
+}
+
+func TestIdempotencyKeyRejectsOversizedBody(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	w := f.postAs(f.token, "/api/v1/locations", "big-1", bytes.Repeat([]byte(" "), 32<<20+1))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for an oversized body, got %d", w.Code)
+	}
+}
+
+// ===================== OPTIMISTIC CONCURRENCY TESTS =====================
+
+func TestProductUpdateRequiresCurrentVersion(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	w := sendIfMatch(router, token, "GET", "/api/v1/products/1", "", nil)
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf(`Expected ETag "1", got %q`, etag)
+	}
+
+	rename := map[string]interface{}{"sku_name": "SKU-001-B"}
+	if w := sendIfMatch(router, token, "PATCH", "/api/v1/products/1", "", rename); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status 428 without If-Match, got %d", w.Code)
+	}
+
+	w = sendIfMatch(router, token, "PATCH", "/api/v1/products/1", `"1"`, rename)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf(`Expected status 200 with ETag "2", got %d %q`, w.Code, w.Header().Get("ETag"))
+	}
+
+	// A second writer still holding the old tag loses
+	w = sendIfMatch(router, token, "PATCH", "/api/v1/products/1", `"1"`, map[string]interface{}{"sku_name": "SKU-001-C"})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 for a stale version, got %d", w.Code)
+	}
+	if prod.SKUName != "SKU-001-B" || prod.Version != 2 {
+		t.Errorf("Expected the first update to stand at version 2, got %s at %d", prod.SKUName, prod.Version)
+	}
+
+	if w := sendIfMatch(router, token, "PATCH", "/api/v1/products/1", `W/"2"`, rename); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 for a weak tag, got %d", w.Code)
+	}
+
+	var updated struct {
+		Data dto.ProductResponse `json:"data"`
+	}
+	w = sendIfMatch(router, token, "PATCH", "/api/v1/products/1", "*", map[string]interface{}{"brand": "Acme"})
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	if w.Code != http.StatusOK || updated.Data.Version != 3 {
+		t.Errorf("Expected If-Match * to update to version 3, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w := sendIfMatch(router, token, "DELETE", "/api/v1/products/1", `"2"`, nil); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 deleting a stale version, got %d", w.Code)
+	}
+	if w := sendIfMatch(router, token, "DELETE", "/api/v1/products/1", `"3"`, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 deleting the current version, got %d", w.Code)
+	}
+}
+
+func TestLocationUpdateRequiresCurrentVersion(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	locationRepo := NewMockLocationRepository()
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), locationRepo, NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	update := map[string]interface{}{"code": "LOC-A1", "name": "Warehouse A", "capacity": 600}
+	w := sendIfMatch(router, token, "PUT", "/api/v1/locations/1", `"1"`, update)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf(`Expected status 200 with ETag "2", got %d %q`, w.Code, w.Header().Get("ETag"))
+	}
+
+	update["capacity"] = 700
+	if w := sendIfMatch(router, token, "PUT", "/api/v1/locations/1", `"1"`, update); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status 412 for a stale version, got %d", w.Code)
+	}
+	if loc.Capacity != 600 {
+		t.Errorf("Expected capacity to stay 600, got %d", loc.Capacity)
+	}
+
+	if w := sendIfMatch(router, token, "DELETE", "/api/v1/locations/1", "", nil); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status 428 without If-Match, got %d", w.Code)
+	}
+	if w := sendIfMatch(router, token, "DELETE", "/api/v1/locations/1", "*", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 deleting with If-Match *, got %d", w.Code)
+	}
+	if w := sendIfMatch(router, token, "DELETE", "/api/v1/locations/1", "*", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 once deleted, got %d", w.Code)
+	}
+}
+
+// ===================== STOCK MOVEMENT REVERSAL TESTS =====================
+
+func TestReverseStockMovementPostsOppositeMovement(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	productRepo.Create(ctx, prod)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	w := sendIfMatch(router, token, "POST", "/api/v1/stock-movements", "", dto.RecordStockMovementRequest{
+		ProductID:      prod.ID,
+		LocationID:     loc.ID,
+		Type:           "IN",
+		Quantity:       30,
+		Lot:            "L-1",
+		DocumentNumber: "GRN-7",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var reversed struct {
+		Data dto.StockMovementResponse `json:"data"`
+	}
+	w = sendIfMatch(router, token, "POST", "/api/v1/stock-movements/1/reversal", "", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &reversed)
+
+	m := reversed.Data
+	if m.Type != "OUT" || m.Quantity != 30 || m.Lot != "L-1" || m.DocumentNumber != "GRN-7" {
+		t.Errorf("Expected an OUT of 30 from lot L-1 on GRN-7, got %+v", m)
+	}
+	if m.ReferenceType != "REVERSAL" || m.ReferenceID != "1" || m.Notes != "reversal of movement 1" {
+		t.Errorf("Expected a REVERSAL referencing movement 1, got %+v", m)
+	}
+	if prod.Quantity != 100 {
+		t.Errorf("Expected quantity back at 100, got %d", prod.Quantity)
+	}
+
+	if w := sendIfMatch(router, token, "POST", "/api/v1/stock-movements/1/reversal", "", nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 reversing twice, got %d", w.Code)
+	}
+	if w := sendIfMatch(router, token, "POST", "/api/v1/stock-movements/2/reversal", "", nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 reversing a reversal, got %d", w.Code)
+	}
+	if w := sendIfMatch(router, token, "POST", "/api/v1/stock-movements/99/reversal", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown movement, got %d", w.Code)
+	}
+}
+
+// ===================== IMPORT TESTS =====================
+
+func TestImportProductsDryRunReportsEveryBadRow(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	productRepo := NewMockProductRepository()
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	file := "\xef\xbb\xbfSKU Name,Category,Weight_Grams,Packs,Status\n" +
+		"SKU-100,Tools,250,CASE:12,active\n" +
+		",Tools,1,,\n" +
+		"SKU-101,Tools,heavy,,\n" +
+		"\n" +
+		"SKU-100,Tools,1,,\n" +
+		"SKU-102,Tools,1,CASE:1,\n"
+
+	w, report := sendImport(router, token, "/api/v1/imports/products?dry_run=true", "text/csv", []byte(file))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if report.Rows != 5 || report.Failed != 4 || report.Created != 1 || report.Applied {
+		t.Errorf("Expected 5 rows with 4 failures and 1 valid create, got %+v", report)
+	}
+
+	wantFailed := map[int]bool{3: true, 4: true, 6: true, 7: true}
+	for _, r := range report.Results {
+		if failed := len(r.Errors) > 0; failed != wantFailed[r.Row] {
+			t.Errorf("Row %d: expected failed=%t, got errors %v", r.Row, wantFailed[r.Row], r.Errors)
+		}
+	}
+	if count, _ := productRepo.Count(context.Background(), product.ListSpec{}); count != 0 {
+		t.Errorf("Expected nothing saved, got %d products", count)
+	}
+}
+
+func TestImportProductsUpsertsBySKU(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	productRepo := NewMockProductRepository()
+	existing, _ := product.NewProduct("SKU-001", product.MasterData{Category: "Tools", Brand: "Acme"})
+	existing.Quantity = 40
+	productRepo.Create(ctx, existing)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	file := "sku_name,category,barcodes\n" +
+		"SKU-001,Hardware,EAN13:4006381333931\n" +
+		"SKU-002,Hardware,\n"
+
+	w, report := sendImport(router, token, "/api/v1/imports/products?dry_run=true", "text/csv", []byte(file))
+	if w.Code != http.StatusOK || report.Created != 1 || report.Updated != 1 || report.Applied {
+		t.Fatalf("Expected a clean dry run, got %d %+v", w.Code, report)
+	}
+	if existing.Category != "Tools" {
+		t.Fatalf("Expected the dry run to change nothing, got category %s", existing.Category)
+	}
+
+	w, report = sendImport(router, token, "/api/v1/imports/products", "text/csv", []byte(file))
+	if w.Code != http.StatusOK || !report.Applied {
+		t.Fatalf("Expected the import to apply, got %d: %s", w.Code, w.Body.String())
+	}
+
+	updated, _ := productRepo.GetBySKU(ctx, "SKU-001")
+	if updated.Category != "Hardware" || updated.Brand != "Acme" || updated.Quantity != 40 || updated.Version != 2 {
+		t.Errorf("Expected category replaced and brand, quantity kept at version 2, got %+v", updated)
+	}
+	if len(updated.Barcodes) != 1 || updated.Barcodes[0].Value != "4006381333931" {
+		t.Errorf("Expected the barcode to be imported, got %+v", updated.Barcodes)
+	}
+	if _, err := productRepo.GetBySKU(ctx, "SKU-002"); err != nil {
+		t.Errorf("Expected SKU-002 to be created, got %v", err)
+	}
+
+	w, _ = sendImport(router, token, "/api/v1/imports/products", "text/csv", []byte("sku_name,colour\nSKU-003,red\n"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown column, got %d", w.Code)
+	}
+}
+
+func TestImportLocationsFromXLSXUpload(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	locationRepo := NewMockLocationRepository()
+	existing, _ := location.NewLocation("LOC-A1", "Aisle A", 500)
+	locationRepo.Create(ctx, existing)
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), locationRepo, NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	// Shared strings, an inline string, a number in exponent form and a skipped column
+	xlsx := buildXLSX(t,
+		`<si><t>code</t></si><si><t>name</t></si><si><t>capacity</t></si><si><t>temperature_zone</t></si><si><r><t>Aisle </t></r><r><t>B</t></r></si>`,
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="E1" t="s"><v>3</v></c></row>`+
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>LOC-A1</t></is></c><c r="B2" t="inlineStr"><is><t>Aisle A North</t></is></c><c r="C2"><v>750</v></c></row>`+
+			`<row r="4"><c r="A4" t="inlineStr"><is><t>LOC-B1</t></is></c><c r="B4" t="s"><v>4</v></c><c r="C4"><v>1.2E3</v></c><c r="E4" t="inlineStr"><is><t>chilled</t></is></c></row>`,
+	)
+
+	table, err := spreadsheet.Read(bytes.NewReader(xlsx))
+	if err != nil || len(table) != 4 || table[3][1] != "Aisle B" || table[3][2] != "1200" || table[0][3] != "" {
+		t.Fatalf("Expected four rows with gaps kept, got %q, %v", table, err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "locations.xlsx")
+	part.Write(xlsx)
+	mw.Close()
+
+	w, report := sendImport(router, token, "/api/v1/imports/locations", mw.FormDataContentType(), body.Bytes())
+	if w.Code != http.StatusOK || !report.Applied || report.Created != 1 || report.Updated != 1 {
+		t.Fatalf("Expected one create and one update, got %d: %s", w.Code, w.Body.String())
+	}
+	if report.Results[1].Row != 4 {
+		t.Errorf("Expected row numbers to follow the sheet, got %d", report.Results[1].Row)
+	}
+
+	updated, _ := locationRepo.GetByCode(ctx, "LOC-A1")
+	if updated.Capacity != 750 || updated.Name != "Aisle A North" || updated.TemperatureZone != location.ZoneAmbient {
+		t.Errorf("Expected LOC-A1 renamed with capacity 750, got %+v", updated)
+	}
+	created, err := locationRepo.GetByCode(ctx, "LOC-B1")
+	if err != nil || created.Capacity != 1200 || created.TemperatureZone != location.ZoneChilled {
+		t.Errorf("Expected LOC-B1 chilled with capacity 1200, got %+v, %v", created, err)
+	}
+}
+
+// ===================== EXPORT TESTS =====================
+
+func TestExportMovementsAppliesListFilters(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	stockRepo := NewMockStockRepository()
+	day := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	for i, m := range []stock.StockMovement{
+		{ProductID: 1, LocationID: 1, Type: stock.MovementTypeIN, Quantity: 10, CreatedAt: day.AddDate(0, 0, -1)},
+		{ProductID: 1, LocationID: 1, Type: stock.MovementTypeIN, Quantity: 20, CreatedAt: day, Notes: "first, \"quoted\""},
+		{ProductID: 2, LocationID: 1, Type: stock.MovementTypeIN, Quantity: 30, CreatedAt: day},
+		{ProductID: 1, LocationID: 2, Type: stock.MovementTypeOUT, Quantity: 5, CreatedAt: day.Add(10 * time.Hour)},
+		{ProductID: 1, LocationID: 1, Type: stock.MovementTypeIN, Quantity: 40, CreatedAt: day.AddDate(0, 0, 1)},
+	} {
+		m := m
+		if err := stockRepo.Create(ctx, &m); err != nil {
+			t.Fatalf("movement %d: %v", i, err)
+		}
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	w := getExport(router, token, "/api/v1/exports/movements?product_id=1&from=2024-06-01&to=2024-06-01", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Expected text/csv, got %s", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "movements-") || !strings.HasSuffix(cd, `.csv"`) {
+		t.Errorf("Expected a movements CSV attachment, got %s", cd)
+	}
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "id" || records[0][13] != "created_at" {
+		t.Fatalf("Expected a header and two movements, got %q", records)
+	}
+	if records[1][0] != "2" || records[1][10] != `first, "quoted"` || records[1][13] != "2024-06-01T08:00:00Z" {
+		t.Errorf("Expected movement 2 with its notes intact, got %q", records[1])
+	}
+	if records[2][0] != "4" || records[2][4] != "OUT" {
+		t.Errorf("Expected movement 4 as an OUT, got %q", records[2])
+	}
+
+	w = getExport(router, token, "/api/v1/exports/movements?to=2024-05-01&from=2024-06-01", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an inverted range, got %d", w.Code)
+	}
+	w = getExport(router, token, "/api/v1/exports/movements?format=xml", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown format, got %d", w.Code)
+	}
+}
+
+func TestExportProductsAsNDJSONAndParquet(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	productRepo := NewMockProductRepository()
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{
+		Category: "Tools",
+		Barcodes: []product.Barcode{{Type: product.BarcodeTypeEAN13, Value: "4006381333931"}},
+		Packs:    []product.Pack{{Level: product.PackLevelCase, UnitsPerPack: 6}},
+	})
+	prod.Quantity = 12
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-A1", "Aisle A", 500)
+	locationRepo := NewMockLocationRepository()
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	w := getExport(router, token, "/api/v1/exports/products", "application/x-ndjson")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Expected NDJSON, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], `{"id":1,"sku_name":"SKU-001",`) {
+		t.Fatalf("Expected one product object with keys in column order, got %q", lines)
+	}
+	if !strings.Contains(lines[0], `"barcodes":"EAN13:4006381333931","packs":"CASE:6"`) ||
+		!strings.Contains(lines[0], `"serialized":false,"quantity":12,"version":1}`) {
+		t.Errorf("Expected typed values, got %s", lines[0])
+	}
+
+	// The CSV export of products can be imported again unchanged
+	w = getExport(router, token, "/api/v1/exports/products", "")
+	w, report := sendImport(router, token, "/api/v1/imports/products?dry_run=true", "text/csv", w.Body.Bytes())
+	if w.Code != http.StatusOK || report.Updated != 1 {
+		t.Errorf("Expected the export to import as one update, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = getExport(router, token, "/api/v1/exports/locations?format=parquet", "")
+	body := w.Body.Bytes()
+	if w.Code != http.StatusOK || len(body) < 12 || !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Fatalf("Expected a Parquet file, got %d: %q", w.Code, body)
+	}
+	footerLen := int(binary.LittleEndian.Uint32(body[len(body)-8:]))
+	if footerLen <= 0 || footerLen > len(body)-12 {
+		t.Fatalf("Expected a footer inside the file, got length %d of %d", footerLen, len(body))
+	}
+	footer := body[len(body)-8-footerLen : len(body)-8]
+	if !bytes.Contains(footer, []byte("temperature_zone")) || !bytes.Contains(body, []byte("LOC-A1")) {
+		t.Errorf("Expected the schema in the footer and the data in a page")
+	}
+}
+
+// ===================== LISTING TESTS =====================
+
+func TestListProductsFiltersAndSearches(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	productRepo := NewMockProductRepository()
+	for _, p := range []struct {
+		sku      string
+		quantity int64
+		data     product.MasterData
+	}{
+		{"BEV-COLA", 120, product.MasterData{Category: "Drinks", Description: "Sparkling cola 330ml"}},
+		{"BEV-WATER", 40, product.MasterData{Category: "Drinks", Description: "Still water"}},
+		{"bev-lemon", 300, product.MasterData{Category: "Drinks", Brand: "Cola Co"}},
+		{"SNK-CHIPS", 200, product.MasterData{Category: "Snacks"}},
+	} {
+		prod, err := product.NewProduct(p.sku, p.data)
+		if err != nil {
+			t.Fatalf("product %s: %v", p.sku, err)
+		}
+		prod.Quantity = p.quantity
+		productRepo.Create(ctx, prod)
+	}
+
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	token := getAuthToken(t, router)
+
+	var page dto.ProductListResponse
+	w := getList(t, router, token, "/api/v1/products?sku_prefix=BEV-&min_quantity=100&q=COLA&sort=-quantity&total=true", &page)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if page.Total == nil || *page.Total != 2 || len(page.Data) != 2 {
+		t.Fatalf("Expected the cola and lemon products, got %d of %v", len(page.Data), page.Total)
+	}
+	for _, p := range page.Data {
+		if p.SKUName != "BEV-COLA" && p.SKUName != "bev-lemon" {
+			t.Errorf("Unexpected product %s", p.SKUName)
+		}
+	}
+
+	for _, query := range []string{"sort=colour", "min_quantity=10&max_quantity=5", "status=GONE"} {
+		if w := getList(t, router, token, "/api/v1/products?"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestListLocationsAndMovementsFilter(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	locationRepo := NewMockLocationRepository()
+	for _, code := range []string{"A-01", "A-02", "B-01"} {
+		loc, _ := location.NewLocation(code, "Aisle "+code, 100)
+		loc.Warehouse = "WH1"
+		locationRepo.Create(ctx, loc)
+	}
+	stockRepo := NewMockStockRepository()
+	for _, qty := range []int64{5, 50, 500} {
+		stockRepo.Create(ctx, &stock.StockMovement{ProductID: 1, LocationID: 1, Type: stock.MovementTypeIN, Quantity: qty, Notes: "inbound"})
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), locationRepo, stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	var locations dto.LocationListResponse
+	if w := getList(t, router, token, "/api/v1/locations?code_prefix=a-&warehouse=WH1&sort=-code&total=true", &locations); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if locations.Total == nil || *locations.Total != 2 {
+		t.Errorf("Expected two locations in aisle A, got %v", locations.Total)
+	}
+
+	var movements dto.StockMovementListResponse
+	if w := getList(t, router, token, "/api/v1/stock-movements?min_quantity=10&max_quantity=100&q=INBOUND&total=true", &movements); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if movements.Total == nil || *movements.Total != 1 || len(movements.Data) != 1 || movements.Data[0].Quantity != 50 {
+		t.Errorf("Expected only the movement of 50, got %+v", movements)
+	}
+
+	if w := getList(t, router, token, "/api/v1/stock-movements?sort=notes", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown sort field, got %d", w.Code)
+	}
+}
+
+func TestListMovementsByCursor(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	stockRepo := NewMockStockRepository()
+	record := func() {
+		stockRepo.Create(ctx, &stock.StockMovement{ProductID: 1, LocationID: 1, Type: stock.MovementTypeIN, Quantity: 1})
+	}
+	for i := 0; i < 5; i++ {
+		record()
+	}
+
+	router := httpinterface.SetupRouter(cfg, NewMockProductRepository(), NewMockLocationRepository(), stockRepo, nil)
+	token := getAuthToken(t, router)
+
+	var first dto.StockMovementListResponse
+	w := getList(t, router, token, "/api/v1/stock-movements?limit=2", &first)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if first.Total != nil || strings.Contains(w.Body.String(), `"total"`) {
+		t.Errorf("Expected no total unless total=true, got %s", w.Body.String())
+	}
+
+	var counted dto.StockMovementListResponse
+	getList(t, router, token, "/api/v1/stock-movements?limit=2&total=true", &counted)
+	if counted.Total == nil || *counted.Total != 5 {
+		t.Errorf("Expected a total of 5 with total=true, got %v", counted.Total)
+	}
+	if len(first.Data) != 2 || first.Data[0].ID != 5 || first.Data[1].ID != 4 || first.NextCursor == "" {
+		t.Fatalf("Expected movements 5 and 4 and a cursor, got %+v", first)
+	}
+
+	// A movement arriving between pages would shift an offset by one row
+	record()
+
+	var ids []int64
+	cursor := first.NextCursor
+	for cursor != "" {
+		var page dto.StockMovementListResponse
+		if w := getList(t, router, token, "/api/v1/stock-movements?limit=2&total=true&after="+cursor, &page); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if page.Total != nil {
+			t.Errorf("Expected no total on a cursor page, got %d", *page.Total)
+		}
+		for _, m := range page.Data {
+			ids = append(ids, m.ID)
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 2 || ids[2] != 1 {
+		t.Errorf("Expected movements 3, 2 and 1 after the cursor, got %v", ids)
+	}
+
+	for _, query := range []string{
+		"after=" + first.NextCursor + "&offset=2",
+		"after=" + first.NextCursor + "&sort=quantity",
+		"after=bogus",
+	} {
+		if w := getList(t, router, token, "/api/v1/stock-movements?"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
+
+// ===================== PRODUCT SEARCH TESTS =====================
+
+func TestSearchProductsRanksSKUMatches(t *testing.T) {
+	router, token := setupSearchRouter(t)
+
+	var result dto.ProductSearchResponse
+	w := getList(t, router, token, "/api/v1/products/search?q=cola", &result)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(result.Data) != 2 || result.Data[0].SKUName != "COLA-330" || result.Data[1].SKUName != "BEV-COLA-1L" {
+		t.Fatalf("Expected the SKU prefix match first, got %+v", result.Data)
+	}
+	if result.Data[0].Match != "text" || result.Data[0].Score <= result.Data[1].Score || result.Scan != nil {
+		t.Errorf("Expected ranked text matches, got %s", w.Body.String())
+	}
+
+	if w := getList(t, router, token, "/api/v1/products/search?q=%20", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty query, got %d", w.Code)
+	}
+}
+
+func TestSearchProductsResolvesScans(t *testing.T) {
+	router, token := setupSearchRouter(t)
+
+	// The GTIN-14 of the registered UPC-A
+	var result dto.ProductSearchResponse
+	if w := getList(t, router, token, "/api/v1/products/search?q=00012345678905", &result); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(result.Data) != 1 || result.Data[0].SKUName != "WATER-500" || result.Data[0].Match != "barcode" {
+		t.Fatalf("Expected the water by barcode, got %+v", result.Data)
+	}
+
+	scan := url.QueryEscape("]C101000123456789051726120010L-7")
+	result = dto.ProductSearchResponse{}
+	if w := getList(t, router, token, "/api/v1/products/search?q="+scan, &result); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if result.Scan == nil || result.Scan.GTIN != "00012345678905" || result.Scan.Lot != "L-7" || result.Scan.Expiry != "2026-12-31" {
+		t.Errorf("Expected the scan's GTIN, lot and expiry, got %+v", result.Scan)
+	}
+	if len(result.Data) != 1 || result.Data[0].SKUName != "WATER-500" {
+		t.Errorf("Expected the scan to resolve to the water, got %+v", result.Data)
+	}
+
+	// A GTIN no product carries yields no products rather than fuzzy matches
+	result = dto.ProductSearchResponse{}
+	if w := getList(t, router, token, "/api/v1/products/search?q="+url.QueryEscape("(01)09506000134352"), &result); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if result.Scan == nil || len(result.Data) != 0 {
+		t.Errorf("Expected a parsed scan without products, got %+v", result)
+	}
+}
+
+// ===================== SCAN TESTS =====================
+
+func TestParseScanResolvesPalletLabel(t *testing.T) {
+	f := newContainerFixture(t)
+	buildLabelledPallet(t, f)
+
+	var result dto.ParseScanResponse
+	status := f.do("POST", "/api/v1/scan/parse", dto.ParseScanRequest{Scan: "]C100" + palletSSCC + "3740"}, &result)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if result.Fields == nil || result.Fields.SSCC != palletSSCC || result.Fields.Count != 40 || len(result.Fields.Elements) != 2 {
+		t.Errorf("Expected the SSCC and count fields, got %+v", result.Fields)
+	}
+	if result.Container == nil || result.Container.LPN != "PAL-1" {
+		t.Fatalf("Expected the scan to resolve to PAL-1, got %+v", result.Container)
+	}
+	// The pallet holds a single product and lot, so the SSCC names both
+	if result.Product == nil || result.Product.SKUName != "SKU-WATER" || result.Lot != "L-01" {
+		t.Errorf("Expected SKU-WATER lot L-01, got %+v lot %q", result.Product, result.Lot)
+	}
+
+	for _, raw := range []string{"(00)106141411234567898", "(99)X", "NOT-A-BARCODE"} {
+		if status := f.do("POST", "/api/v1/scan/parse", dto.ParseScanRequest{Scan: raw}, nil); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", raw, status)
+		}
+	}
+}
+
+func TestParseScanResolvesPlainBarcode(t *testing.T) {
+	router, token := setupSearchRouter(t)
+
+	body, _ := json.Marshal(dto.ParseScanRequest{Scan: "012345678905"})
+	req := httptest.NewRequest("POST", "/api/v1/scan/parse", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var result struct {
+		Data dto.ParseScanResponse `json:"data"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &result) != nil {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if result.Data.Product == nil || result.Data.Product.SKUName != "WATER-500" || result.Data.Fields.GTIN != "00012345678905" {
+		t.Errorf("Expected the UPC-A to resolve to WATER-500, got %s", w.Body.String())
+	}
+	if result.Data.Container != nil {
+		t.Errorf("Expected no container without container tracking, got %+v", result.Data.Container)
+	}
+}
+
+func TestRecordMovementFromScans(t *testing.T) {
+	f := newContainerFixture(t)
+	buildLabelledPallet(t, f)
+
+	// Product, lot and quantity come from the label; the location from its code label
+	var movement dto.StockMovementResponse
+	status := f.do("POST", "/api/v1/stock-movements", dto.RecordStockMovementRequest{
+		Type: "IN", Scan: "(00)" + palletSSCC + "(37)12", LocationScan: "RACK-A1",
+	}, &movement)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	if movement.ProductID != 1 || movement.LocationID != 2 || movement.Quantity != 12 || movement.Lot != "L-01" {
+		t.Errorf("Expected 12 of product 1 lot L-01 into location 2, got %+v", movement)
+	}
+
+	// Without a location the pallet's own location is used
+	movement = dto.StockMovementResponse{}
+	status = f.do("POST", "/api/v1/stock-movements", dto.RecordStockMovementRequest{
+		Type: "IN", Scan: "(00)" + palletSSCC, Quantity: 3,
+	}, &movement)
+	if status != http.StatusCreated || movement.LocationID != 1 || movement.Quantity != 3 {
+		t.Errorf("Expected 3 into the pallet's location, got %d %+v", status, movement)
+	}
+
+	for _, req := range []dto.RecordStockMovementRequest{
+		{Type: "IN", Scan: "(00)" + palletSSCC + "(37)1", LocationID: 2, Lot: "L-02"},
+		{Type: "IN", Scan: "(00)" + palletSSCC + "(37)1", LocationID: 2, ProductID: 2},
+		{Type: "IN", Scan: "(00)" + palletSSCC + "(37)1", LocationScan: "NOWHERE"},
+		{Type: "IN", Scan: "(00)" + palletSSCC, LocationID: 2},
+		{Type: "IN", LocationScan: "RACK-A1", Quantity: 1},
+	} {
+		if status := f.do("POST", "/api/v1/stock-movements", req, nil); status != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %+v, got %d", req, status)
+		}
+	}
+}
+
+// ===================== HELPER FUNCTIONS =====================
+
+// getAuthToken retrieves an authentication token from the login endpoint
+func getAuthToken(t *testing.T, router *gin.Engine) string {
+	return loginAs(t, router, "testuser", "testpass")
+}
+
+// testAdminUsers returns the admin user config that getAdminToken logs in with
+func testAdminUsers(t *testing.T) map[string]string {
+	hash, err := bcrypt.GenerateFromPassword([]byte("adminpass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash admin password: %v", err)
+	}
+	return map[string]string{"admin": string(hash)}
+}
+
+// getAdminToken retrieves an admin token; the router must be set up with testAdminUsers
+func getAdminToken(t *testing.T, router *gin.Engine) string {
+	return loginAs(t, router, "admin", "adminpass")
+}
+
+// loginAs logs in with the given credentials and returns the issued token
+func loginAs(t *testing.T, router *gin.Engine, username, password string) string {
+	loginReq := map[string]string{
+		"username": username,
+		"password": password,
+	}
+
+	body, _ := json.Marshal(loginReq)
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	return data["token"].(string)
+}
+
+// sendJSON sends an authenticated request with body encoded as JSON; a nil body sends no payload
+func sendJSON(router *gin.Engine, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// MockSnapshotRepository is a mock implementation of stock.SnapshotRepository
+type MockSnapshotRepository struct {
+	snapshots []*stock.Snapshot
+}
+
+func NewMockSnapshotRepository() *MockSnapshotRepository {
+	return &MockSnapshotRepository{}
+}
+
+func (m *MockSnapshotRepository) Save(ctx context.Context, s *stock.Snapshot) error {
+	s.ID = int64(len(m.snapshots) + 1)
+	m.snapshots = append(m.snapshots, s)
+	return nil
+}
+
+func (m *MockSnapshotRepository) LatestBefore(ctx context.Context, at time.Time) (*stock.Snapshot, error) {
+	var latest *stock.Snapshot
+	for _, s := range m.snapshots {
+		if !s.TakenAt.After(at) && (latest == nil || s.TakenAt.After(latest.TakenAt)) {
+			latest = s
+		}
+	}
+	if latest == nil {
+		return nil, stock.ErrSnapshotNotFound
+	}
+	return latest, nil
+}
+
+// recordAt stores a movement with a fixed creation time
+func recordAt(ctx context.Context, repo *MockStockRepository, productID, locationID int64, t stock.MovementType, qty int64, at time.Time) {
+	m, _ := stock.NewStockMovement(productID, locationID, t, qty)
+	m.CreatedAt = at
+	repo.Create(ctx, m)
+}
+
+// heldLock behaves like a lock another instance already holds
+type heldLock struct{}
+
+func (heldLock) TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	return false, nil
+}
+
+// MockRepairRepository keeps the reconciliation repair audit trail in memory
+type MockRepairRepository struct {
+	repairs []*stock.Repair
+}
+
+func (m *MockRepairRepository) Record(ctx context.Context, repair *stock.Repair) error {
+	repair.ID = int64(len(m.repairs) + 1)
+	m.repairs = append(m.repairs, repair)
+	return nil
+}
+
+// packedProduct returns a product packed 6 per inner, 24 per case and 960 per pallet
+func packedProduct(t *testing.T, quantity int64) *product.Product {
+	prod, err := product.NewProduct("SKU-001", product.MasterData{
+		Packs: []product.Pack{
+			{Level: product.PackLevelPallet, UnitsPerPack: 960},
+			{Level: product.PackLevelInner, UnitsPerPack: 6},
+			{Level: product.PackLevelCase, UnitsPerPack: 24},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid pack hierarchy, got %v", err)
+	}
+	prod.Quantity = quantity
+	return prod
+}
+
+// limitedLocation creates a location with a generous unit capacity and the given limits
+func limitedLocation(ctx context.Context, repo *MockLocationRepository, limits location.Limits) *location.Location {
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 10000)
+	loc.SetLimits(limits)
+	repo.Create(ctx, loc)
+	return loc
+}
+
+// capacityLimit returns the limit named in a capacity error, or "" for any other error
+func capacityLimit(err error) string {
+	var capErr *location.CapacityExceededError
+	if errors.As(err, &capErr) {
+		return capErr.Limit
+	}
+	return ""
+}
+
+// MockContainerRepository is a mock implementation of container.Repository
+type MockContainerRepository struct {
+	containers map[int64]*container.Container
+	nextID     int64
+}
+
+func NewMockContainerRepository() *MockContainerRepository {
+	return &MockContainerRepository{
+		containers: make(map[int64]*container.Container),
+		nextID:     1,
+	}
+}
+
+func (m *MockContainerRepository) Create(ctx context.Context, c *container.Container) error {
+	for _, existing := range m.containers {
+		if existing.LPN == c.LPN {
+			return container.ErrDuplicateLPN
+		}
+	}
+	c.ID = m.nextID
+	m.nextID++
+	m.containers[c.ID] = c
+	return nil
+}
+
+func (m *MockContainerRepository) GetByID(ctx context.Context, id int64) (*container.Container, error) {
+	if c, ok := m.containers[id]; ok {
+		return c, nil
+	}
+	return nil, container.ErrContainerNotFound
+}
+
+func (m *MockContainerRepository) GetByLPN(ctx context.Context, lpn string) (*container.Container, error) {
+	for _, c := range m.containers {
+		if c.LPN == lpn {
+			return c, nil
+		}
+	}
+	return nil, container.ErrContainerNotFound
+}
+
+func (m *MockContainerRepository) GetBySSCC(ctx context.Context, sscc string) (*container.Container, error) {
+	for _, c := range m.containers {
+		if c.SSCC != "" && c.SSCC == sscc {
+			return c, nil
+		}
+	}
+	return nil, container.ErrContainerNotFound
+}
+
+func (m *MockContainerRepository) GetChildren(ctx context.Context, parentID int64) ([]*container.Container, error) {
+	var children []*container.Container
+	for id := int64(1); id < m.nextID; id++ {
+		c, ok := m.containers[id]
+		if ok && c.IsActive() && c.ParentID != nil && *c.ParentID == parentID {
+			children = append(children, c)
+		}
+	}
+	return children, nil
+}
+
+func (m *MockContainerRepository) Update(ctx context.Context, c *container.Container) error {
+	if _, ok := m.containers[c.ID]; !ok {
+		return container.ErrContainerNotFound
+	}
+	m.containers[c.ID] = c
+	return nil
+}
+
+func (m *MockContainerRepository) QuantityAt(ctx context.Context, locationID, productID int64) (int64, error) {
+	var total int64
+	for _, c := range m.containers {
+		if !c.IsActive() || c.LocationID != locationID {
+			continue
+		}
+		for _, content := range c.Contents {
+			if content.ProductID == productID {
+				total += content.Quantity
+			}
+		}
+	}
+	return total, nil
+}
+
+func (m *MockContainerRepository) LocationsWithLot(ctx context.Context, productID int64, lot string) ([]int64, error) {
+	seen := make(map[int64]bool)
+	var locationIDs []int64
+	for _, c := range m.containers {
+		for _, content := range c.Contents {
+			if c.IsActive() && content.ProductID == productID && content.Lot == lot && !seen[c.LocationID] {
+				seen[c.LocationID] = true
+				locationIDs = append(locationIDs, c.LocationID)
+			}
+		}
+	}
+	return locationIDs, nil
+}
+
+// containerFixture is a router with two locations, two products and loose stock at the first location
+type containerFixture struct {
+	router        *gin.Engine
+	token         string
+	stockRepo     *MockStockRepository
+	containerRepo *MockContainerRepository
+}
+
+func newContainerFixture(t *testing.T) *containerFixture {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	containerRepo := NewMockContainerRepository()
+
+	water, _ := product.NewProduct("SKU-WATER", product.MasterData{})
+	water.Quantity = 100
+	tissue, _ := product.NewProduct("SKU-TISSUE", product.MasterData{})
+	tissue.Quantity = 50
+	productRepo.Create(ctx, water)
+	productRepo.Create(ctx, tissue)
+
+	dock, _ := location.NewLocation("DOCK-1", "Receiving dock", 1000)
+	rack, _ := location.NewLocation("RACK-A1", "Rack A1", 1000)
+	locationRepo.Create(ctx, dock)
+	locationRepo.Create(ctx, rack)
+
+	recordAt(ctx, stockRepo, water.ID, dock.ID, stock.MovementTypeIN, 100, time.Now())
+	recordAt(ctx, stockRepo, tissue.ID, dock.ID, stock.MovementTypeIN, 50, time.Now())
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithContainerRepository(containerRepo),
+	)
+
+	return &containerFixture{
+		router:        router,
+		token:         getAuthToken(t, router),
+		stockRepo:     stockRepo,
+		containerRepo: containerRepo,
+	}
+}
+
+// do sends an authenticated JSON request and decodes the response data into out
+func (f *containerFixture) do(method, path string, body interface{}, out interface{}) int {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+
+	if out != nil {
+		json.Unmarshal(w.Body.Bytes(), &struct {
+			Data interface{} `json:"data"`
+		}{Data: out})
+	}
+	return w.Code
+}
+
+// MockSerialRepository is a mock implementation of serial.Repository.
+// It stores copies so a failed movement cannot leave half-applied serials behind.
+type MockSerialRepository struct {
+	serials map[int64]serial.Serial
+	events  []*serial.Event
+	nextID  int64
+}
+
+func NewMockSerialRepository() *MockSerialRepository {
+	return &MockSerialRepository{
+		serials: make(map[int64]serial.Serial),
+		nextID:  1,
+	}
+}
+
+func (m *MockSerialRepository) GetByNumber(ctx context.Context, productID int64, number string) (*serial.Serial, error) {
+	for _, s := range m.serials {
+		if s.ProductID == productID && s.Number == number {
+			found := s
+			return &found, nil
+		}
+	}
+	return nil, serial.ErrSerialNotFound
+}
+
+func (m *MockSerialRepository) Save(ctx context.Context, s *serial.Serial) error {
+	if s.ID == 0 {
+		s.ID = m.nextID
+		m.nextID++
+	}
+	m.serials[s.ID] = *s
+	return nil
+}
+
+func (m *MockSerialRepository) AddEvent(ctx context.Context, e *serial.Event) error {
+	e.ID = int64(len(m.events) + 1)
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *MockSerialRepository) List(ctx context.Context, productID int64, filter serial.Filter, limit, offset int) ([]*serial.Serial, error) {
+	var result []*serial.Serial
+	for id := int64(1); id < m.nextID; id++ {
+		s, ok := m.serials[id]
+		if !ok || s.ProductID != productID {
+			continue
+		}
+		if (filter.LocationID != 0 && s.LocationID != filter.LocationID) || (filter.Status != "" && s.Status != filter.Status) {
+			continue
+		}
+		result = append(result, &s)
+	}
+	return result, nil
+}
+
+func (m *MockSerialRepository) History(ctx context.Context, serialID int64) ([]*serial.Event, error) {
+	var history []*serial.Event
+	for _, e := range m.events {
+		if e.SerialID == serialID {
+			history = append(history, e)
+		}
+	}
+	return history, nil
+}
+
+// serializedFixture sets up a serialized product and two locations for a serial-tracking stock service
+func serializedFixture(t *testing.T) (*stock.Service, *MockSerialRepository, *product.Product) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	serialRepo := NewMockSerialRepository()
+
+	prod, err := product.NewProduct("SKU-LAPTOP", product.MasterData{Serialized: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	productRepo.Create(ctx, prod)
+
+	for _, code := range []string{"DOCK-1", "RACK-A1"} {
+		loc, _ := location.NewLocation(code, code, 100)
+		locationRepo.Create(ctx, loc)
+	}
+
+	service := stock.NewService(productRepo, locationRepo, NewMockStockRepository()).WithSerials(serialRepo)
+	return service, serialRepo, prod
+}
+
+// MockPutawayRuleRepository is a mock implementation of putaway.RuleRepository
+type MockPutawayRuleRepository struct {
+	rules map[string]*putaway.Rule
+}
+
+func NewMockPutawayRuleRepository() *MockPutawayRuleRepository {
+	return &MockPutawayRuleRepository{rules: make(map[string]*putaway.Rule)}
+}
+
+func (m *MockPutawayRuleRepository) Get(ctx context.Context, category string) (*putaway.Rule, error) {
+	if r, ok := m.rules[category]; ok {
+		return r, nil
+	}
+	return nil, putaway.ErrRuleNotFound
+}
+
+func (m *MockPutawayRuleRepository) List(ctx context.Context) ([]*putaway.Rule, error) {
+	var rules []*putaway.Rule
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Category < rules[j].Category })
+	return rules, nil
+}
+
+func (m *MockPutawayRuleRepository) Save(ctx context.Context, rule *putaway.Rule) error {
+	m.rules[rule.Category] = rule
+	return nil
+}
+
+func (m *MockPutawayRuleRepository) Delete(ctx context.Context, category string) error {
+	if _, ok := m.rules[category]; !ok {
+		return putaway.ErrRuleNotFound
+	}
+	delete(m.rules, category)
+	return nil
+}
+
+// putawayFixture holds repositories with three ambient locations and one freezer
+type putawayFixture struct {
+	productRepo  *MockProductRepository
+	locationRepo *MockLocationRepository
+	stockRepo    *MockStockRepository
+	ruleRepo     *MockPutawayRuleRepository
+	locations    map[string]*location.Location
+}
+
+func newPutawayFixture(t *testing.T) *putawayFixture {
+	ctx := context.Background()
+	f := &putawayFixture{
+		productRepo:  NewMockProductRepository(),
+		locationRepo: NewMockLocationRepository(),
+		stockRepo:    NewMockStockRepository(),
+		ruleRepo:     NewMockPutawayRuleRepository(),
+		locations:    make(map[string]*location.Location),
+	}
+
+	for _, l := range []struct {
+		code     string
+		capacity int64
+		zone     location.TemperatureZone
+	}{
+		{"A-01", 100, location.ZoneAmbient},
+		{"A-02", 100, location.ZoneAmbient},
+		{"B-01", 100, location.ZoneAmbient},
+		{"F-01", 100, location.ZoneFrozen},
+	} {
+		loc, _ := location.NewLocation(l.code, l.code, l.capacity)
+		if err := loc.SetTemperatureZone(l.zone); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		f.locationRepo.Create(ctx, loc)
+		f.locations[l.code] = loc
+	}
+
+	return f
+}
+
+func (f *putawayFixture) product(t *testing.T, sku, category string) *product.Product {
+	p, err := product.NewProduct(sku, product.MasterData{Category: category})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.productRepo.Create(context.Background(), p)
+	return p
+}
+
+func (f *putawayFixture) service() *putaway.Service {
+	stockService := stock.NewService(f.productRepo, f.locationRepo, f.stockRepo)
+	return putaway.NewService(f.productRepo, f.locationRepo, f.stockRepo, stockService).WithRules(f.ruleRepo)
+}
+
+func suggestedCodes(suggestions []*putaway.Suggestion) []string {
+	var codes []string
+	for _, s := range suggestions {
+		codes = append(codes, s.Location.Code)
+	}
+	return codes
+}
+
+// MockReplenishmentRepository is a mock implementation of replenishment.Repository
+type MockReplenishmentRepository struct {
+	faces  map[[2]int64]*replenishment.PickFace
+	tasks  []*replenishment.Task
+	nextID int64
+}
+
+func NewMockReplenishmentRepository() *MockReplenishmentRepository {
+	return &MockReplenishmentRepository{
+		faces:  make(map[[2]int64]*replenishment.PickFace),
+		nextID: 1,
+	}
+}
+
+func (m *MockReplenishmentRepository) SavePickFace(ctx context.Context, pf *replenishment.PickFace) error {
+	m.faces[[2]int64{pf.ProductID, pf.LocationID}] = pf
+	return nil
+}
+
+func (m *MockReplenishmentRepository) ListPickFaces(ctx context.Context, productIDs []int64) ([]*replenishment.PickFace, error) {
+	wanted := make(map[int64]bool)
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+
+	var faces []*replenishment.PickFace
+	for _, pf := range m.faces {
+		if len(wanted) == 0 || wanted[pf.ProductID] {
+			faces = append(faces, pf)
+		}
+	}
+	sort.Slice(faces, func(i, j int) bool {
+		if faces[i].ProductID != faces[j].ProductID {
+			return faces[i].ProductID < faces[j].ProductID
+		}
+		return faces[i].LocationID < faces[j].LocationID
+	})
+	return faces, nil
+}
+
+func (m *MockReplenishmentRepository) DeletePickFace(ctx context.Context, productID, locationID int64) error {
+	key := [2]int64{productID, locationID}
+	if _, ok := m.faces[key]; !ok {
+		return replenishment.ErrPickFaceNotFound
+	}
+	delete(m.faces, key)
+	return nil
+}
+
+func (m *MockReplenishmentRepository) CreateTask(ctx context.Context, t *replenishment.Task) error {
+	t.ID = m.nextID
+	m.nextID++
+	m.tasks = append(m.tasks, t)
+	return nil
+}
+
+func (m *MockReplenishmentRepository) GetTask(ctx context.Context, id int64) (*replenishment.Task, error) {
+	for _, t := range m.tasks {
+		if t.ID == id {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, replenishment.ErrTaskNotFound
+}
+
+func (m *MockReplenishmentRepository) UpdateTask(ctx context.Context, t *replenishment.Task) error {
+	for i, existing := range m.tasks {
+		if existing.ID == t.ID {
+			updated := *t
+			m.tasks[i] = &updated
+			return nil
+		}
+	}
+	return replenishment.ErrTaskNotFound
+}
+
+func (m *MockReplenishmentRepository) ListTasks(ctx context.Context, status replenishment.TaskStatus, limit, offset int) ([]*replenishment.Task, error) {
+	var result []*replenishment.Task
+	for i := len(m.tasks) - 1; i >= 0; i-- {
+		if status == "" || m.tasks[i].Status == status {
+			result = append(result, m.tasks[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *MockReplenishmentRepository) OpenTasks(ctx context.Context) ([]*replenishment.Task, error) {
+	return m.ListTasks(ctx, replenishment.TaskStatusOpen, 0, 0)
+}
+
+// replenishmentFixture stocks product 1 with 5 units at pick face 1 and reserve
+// quantities of 30 at location 2 and 12 at location 3
+func replenishmentFixture(t *testing.T) (*MockProductRepository, *MockLocationRepository, *MockStockRepository, *MockReplenishmentRepository) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	replRepo := NewMockReplenishmentRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 47
+	productRepo.Create(ctx, prod)
+	for _, code := range []string{"PICK-1", "RES-1", "RES-2"} {
+		loc, _ := location.NewLocation(code, code, 100)
+		locationRepo.Create(ctx, loc)
+	}
+
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	recordAt(ctx, stockRepo, prod.ID, 1, stock.MovementTypeIN, 5, at)
+	recordAt(ctx, stockRepo, prod.ID, 2, stock.MovementTypeIN, 30, at)
+	recordAt(ctx, stockRepo, prod.ID, 3, stock.MovementTypeIN, 12, at)
+
+	pf, err := replenishment.NewPickFace(prod.ID, 1, 10, 40)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	replRepo.SavePickFace(ctx, pf)
+
+	return productRepo, locationRepo, stockRepo, replRepo
+}
+
+// MockReorderRepository is a mock implementation of replenishment.ReorderRepository
+type MockReorderRepository struct {
+	policies map[string]*replenishment.ReorderPolicy
+	alerts   []*replenishment.Alert
+}
+
+func NewMockReorderRepository() *MockReorderRepository {
+	return &MockReorderRepository{policies: make(map[string]*replenishment.ReorderPolicy)}
+}
+
+func policyKey(productID int64, warehouse string) string {
+	return fmt.Sprintf("%d/%s", productID, warehouse)
+}
+
+func (m *MockReorderRepository) SavePolicy(ctx context.Context, p *replenishment.ReorderPolicy) error {
+	m.policies[policyKey(p.ProductID, p.Warehouse)] = p
+	return nil
+}
+
+func (m *MockReorderRepository) ListPolicies(ctx context.Context, productID int64) ([]*replenishment.ReorderPolicy, error) {
+	var policies []*replenishment.ReorderPolicy
+	for _, p := range m.policies {
+		if productID == 0 || p.ProductID == productID {
+			policies = append(policies, p)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].ProductID != policies[j].ProductID {
+			return policies[i].ProductID < policies[j].ProductID
+		}
+		return policies[i].Warehouse < policies[j].Warehouse
+	})
+	return policies, nil
+}
+
+func (m *MockReorderRepository) DeletePolicy(ctx context.Context, productID int64, warehouse string) error {
+	key := policyKey(productID, warehouse)
+	if _, ok := m.policies[key]; !ok {
+		return replenishment.ErrPolicyNotFound
+	}
+	delete(m.policies, key)
+	return nil
+}
+
+func (m *MockReorderRepository) OpenAlert(ctx context.Context, productID int64, warehouse string) (*replenishment.Alert, error) {
+	for _, a := range m.alerts {
+		if a.ProductID == productID && a.Warehouse == warehouse && a.IsOpen() {
+			found := *a
+			return &found, nil
+		}
+	}
+	return nil, replenishment.ErrAlertNotFound
+}
+
+func (m *MockReorderRepository) SaveAlert(ctx context.Context, a *replenishment.Alert) error {
+	saved := *a
+	if a.ID == 0 {
+		a.ID = int64(len(m.alerts) + 1)
+		saved.ID = a.ID
+		m.alerts = append(m.alerts, &saved)
+		return nil
+	}
+	m.alerts[a.ID-1] = &saved
+	return nil
+}
+
+func (m *MockReorderRepository) ListAlerts(ctx context.Context, openOnly bool, limit, offset int) ([]*replenishment.Alert, error) {
+	var result []*replenishment.Alert
+	for i := len(m.alerts) - 1; i >= 0; i-- {
+		if !openOnly || m.alerts[i].IsOpen() {
+			result = append(result, m.alerts[i])
+		}
+	}
+	return result, nil
+}
+
+// alertFailingReorderRepository fails every alert lookup
+type alertFailingReorderRepository struct {
+	*MockReorderRepository
+}
+
+func (r *alertFailingReorderRepository) OpenAlert(ctx context.Context, productID int64, warehouse string) (*replenishment.Alert, error) {
+	return nil, errors.New("connection reset")
+}
+
+// MockWebhookRepository is a mock implementation of webhook.Repository
+type MockWebhookRepository struct {
+	subscriptions []*webhook.Subscription
+	deliveries    []*webhook.Delivery
+}
+
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{}
+}
+
+func (m *MockWebhookRepository) CreateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	s.ID = int64(len(m.subscriptions) + 1)
+	m.subscriptions = append(m.subscriptions, s)
+	return nil
+}
+
+func (m *MockWebhookRepository) GetSubscription(ctx context.Context, id int64) (*webhook.Subscription, error) {
+	for _, s := range m.subscriptions {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, webhook.ErrSubscriptionNotFound
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(ctx context.Context) ([]*webhook.Subscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	for i, s := range m.subscriptions {
+		if s.ID == id {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return webhook.ErrSubscriptionNotFound
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	for _, existing := range m.deliveries {
+		if existing.SubscriptionID == d.SubscriptionID && existing.EventID == d.EventID {
+			return nil
+		}
+	}
+	d.ID = int64(len(m.deliveries) + 1)
+	saved := *d
+	m.deliveries = append(m.deliveries, &saved)
+	return nil
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id int64) (*webhook.Delivery, error) {
+	if id < 1 || id > int64(len(m.deliveries)) {
+		return nil, webhook.ErrDeliveryNotFound
+	}
+	found := *m.deliveries[id-1]
+	return &found, nil
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	if d.ID < 1 || d.ID > int64(len(m.deliveries)) {
+		return webhook.ErrDeliveryNotFound
+	}
+	saved := *d
+	m.deliveries[d.ID-1] = &saved
+	return nil
+}
+
+func (m *MockWebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	var due []*webhook.Delivery
+	for _, d := range m.deliveries {
+		if d.Status == webhook.DeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			found := *d
+			due = append(due, &found)
+		}
+	}
+	return due, nil
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, status webhook.DeliveryStatus, limit, offset int) ([]*webhook.Delivery, error) {
+	var result []*webhook.Delivery
+	for i := len(m.deliveries) - 1; i >= 0; i-- {
+		if status == "" || m.deliveries[i].Status == status {
+			result = append(result, m.deliveries[i])
+		}
+	}
+	return result, nil
+}
+
+// webhookReceiver records the requests a test subscriber accepted, checking each signature
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	received []map[string]interface{}
+	events   []string
+	badSigs  int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	ts, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if !webhook.Verify(r.secret, ts, body, req.Header.Get(webhook.HeaderSignature)) {
+		r.badSigs++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var envelope map[string]interface{}
+	json.Unmarshal(body, &envelope)
+	r.received = append(r.received, envelope)
+	r.events = append(r.events, req.Header.Get(webhook.HeaderEvent))
+	w.WriteHeader(r.status)
+}
+
+// updateFailingWebhookRepository fails to save the outcome of one delivery
+type updateFailingWebhookRepository struct {
+	*MockWebhookRepository
+	failID int64
+}
+
+func (r *updateFailingWebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	if d.ID == r.failID {
+		return errors.New("connection reset")
+	}
+	return r.MockWebhookRepository.UpdateDelivery(ctx, d)
+}
+
+// MockOutboxRepository is a mock implementation of outbox.Repository
+type MockOutboxRepository struct {
+	messages []*outbox.Message
+}
+
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{}
+}
+
+func (m *MockOutboxRepository) Append(ctx context.Context, messages []*outbox.Message) error {
+	for _, msg := range messages {
+		msg.ID = int64(len(m.messages) + 1)
+		m.messages = append(m.messages, msg)
+	}
+	return nil
+}
+
+func (m *MockOutboxRepository) Unpublished(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	var result []*outbox.Message
+	for _, msg := range m.messages {
+		if msg.PublishedAt == nil && msg.DeadLetteredAt == nil && len(result) < limit {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	m.messages[id-1].Attempts++
+	m.messages[id-1].PublishedAt = &at
+	return nil
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	m.messages[id-1].Attempts++
+	m.messages[id-1].LastError = reason
+	return nil
+}
+
+func (m *MockOutboxRepository) MarkDeadLettered(ctx context.Context, id int64, reason string, at time.Time) error {
+	m.messages[id-1].Attempts++
+	m.messages[id-1].LastError = reason
+	m.messages[id-1].DeadLetteredAt = &at
+	return nil
+}
+
+// recordingSink collects published event IDs and fails for chosen events
+type recordingSink struct {
+	failing   map[string]bool
+	published []int64
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(ctx context.Context, m *outbox.Message) error {
+	if s.failing[m.EventID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, m.ID)
+	return nil
+}
+
+// MockProjectionRepository is a mock implementation of stock.ProjectionRepository
+type MockProjectionRepository struct {
+	balances    map[[2]int64]int64
+	lotBalances map[stock.LotBalance]int64
+}
+
+func NewMockProjectionRepository() *MockProjectionRepository {
+	return &MockProjectionRepository{
+		balances:    make(map[[2]int64]int64),
+		lotBalances: make(map[stock.LotBalance]int64),
+	}
+}
+
+func (m *MockProjectionRepository) AddBalances(ctx context.Context, deltas []stock.Balance) error {
+	for _, d := range deltas {
+		m.balances[[2]int64{d.ProductID, d.LocationID}] += d.Quantity
+	}
+	return nil
+}
+
+func (m *MockProjectionRepository) AddLotBalances(ctx context.Context, deltas []stock.LotBalance) error {
+	for _, d := range deltas {
+		m.lotBalances[stock.LotBalance{ProductID: d.ProductID, LocationID: d.LocationID, Lot: d.Lot}] += d.Quantity
+	}
+	return nil
+}
+
+func (m *MockProjectionRepository) ResetBalances(ctx context.Context) error {
+	m.balances = make(map[[2]int64]int64)
+	return nil
+}
+
+func (m *MockProjectionRepository) ResetLotBalances(ctx context.Context) error {
+	m.lotBalances = make(map[stock.LotBalance]int64)
+	return nil
+}
+
+func (m *MockProjectionRepository) ListBalances(ctx context.Context, filter stock.BalanceFilter) ([]stock.Balance, error) {
+	var result []stock.Balance
+	for k, qty := range m.balances {
+		if qty != 0 && (filter.ProductID == 0 || k[0] == filter.ProductID) && (filter.LocationID == 0 || k[1] == filter.LocationID) {
+			result = append(result, stock.Balance{ProductID: k[0], LocationID: k[1], Quantity: qty})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ProductID != result[j].ProductID {
+			return result[i].ProductID < result[j].ProductID
+		}
+		return result[i].LocationID < result[j].LocationID
+	})
+	return result, nil
+}
+
+func (m *MockProjectionRepository) ListLotBalances(ctx context.Context, filter stock.BalanceFilter) ([]stock.LotBalance, error) {
+	var result []stock.LotBalance
+	for k, qty := range m.lotBalances {
+		if qty != 0 && (filter.ProductID == 0 || k.ProductID == filter.ProductID) &&
+			(filter.LocationID == 0 || k.LocationID == filter.LocationID) && (filter.Lot == "" || k.Lot == filter.Lot) {
+			k.Quantity = qty
+			result = append(result, k)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LocationID != result[j].LocationID {
+			return result[i].LocationID < result[j].LocationID
+		}
+		return result[i].Lot < result[j].Lot
+	})
+	return result, nil
+}
+
+// projectionFixture wires a stock service that keeps every projection
+type projectionFixture struct {
+	productRepo *MockProductRepository
+	stockRepo   *MockStockRepository
+	projRepo    *MockProjectionRepository
+	service     *stock.Service
+	product     *product.Product
+}
+
+func newProjectionFixture() *projectionFixture {
+	ctx := context.Background()
+	f := &projectionFixture{
+		productRepo: NewMockProductRepository(),
+		stockRepo:   NewMockStockRepository(),
+		projRepo:    NewMockProjectionRepository(),
+	}
+	locationRepo := NewMockLocationRepository()
+
+	f.product, _ = product.NewProduct("SKU-001", product.MasterData{})
+	f.productRepo.Create(ctx, f.product)
+	for _, code := range []string{"LOC-A1", "LOC-B1"} {
+		loc, _ := location.NewLocation(code, "Warehouse A", 1000)
+		locationRepo.Create(ctx, loc)
+	}
+
+	f.service = stock.NewService(f.productRepo, locationRepo, f.stockRepo).WithBalances(f.projRepo)
+	return f
+}
+
+// record posts a movement through the record command
+func (f *projectionFixture) record(t *testing.T, locationID int64, movementType string, qty int64, lot string) {
+	cmd := commands.NewRecordStockMovementCommand(f.service, nil)
+	_, err := cmd.Execute(context.Background(), &dto.RecordStockMovementRequest{
+		ProductID:  f.product.ID,
+		LocationID: locationID,
+		Type:       movementType,
+		Quantity:   qty,
+		Lot:        lot,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error recording %s %d, got %v", movementType, qty, err)
+	}
+}
+
+// MockIdempotencyRepository is a mock implementation of idempotency.Repository
+type MockIdempotencyRepository struct {
+	records map[string]*idempotency.Record
+}
+
+func NewMockIdempotencyRepository() *MockIdempotencyRepository {
+	return &MockIdempotencyRepository{
+		records: make(map[string]*idempotency.Record),
+	}
+}
+
+func (m *MockIdempotencyRepository) Claim(ctx context.Context, rec *idempotency.Record, staleBefore time.Time) (*idempotency.Record, error) {
+	existing, ok := m.records[rec.Scope+"|"+rec.Key]
+	if !ok {
+		stored := *rec
+		m.records[rec.Scope+"|"+rec.Key] = &stored
+		return nil, nil
+	}
+	if !existing.IsCompleted() && existing.Fingerprint == rec.Fingerprint && existing.CreatedAt.Before(staleBefore) {
+		existing.CreatedAt = rec.CreatedAt
+		return nil, nil
+	}
+	copied := *existing
+	return &copied, nil
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, rec *idempotency.Record) error {
+	existing := m.records[rec.Scope+"|"+rec.Key]
+	existing.StatusCode = rec.StatusCode
+	existing.ContentType = rec.ContentType
+	existing.Body = rec.Body
+	existing.CompletedAt = rec.CompletedAt
+	return nil
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	delete(m.records, scope+"|"+key)
+	return nil
+}
+
+func (m *MockIdempotencyRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	for k, rec := range m.records {
+		if rec.CreatedAt.Before(before) {
+			delete(m.records, k)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// idempotencyFixture is a router with idempotency keys enabled and one product and location
+type idempotencyFixture struct {
+	router    *gin.Engine
+	token     string
+	product   *product.Product
+	stockRepo *MockStockRepository
+}
+
+func newIdempotencyFixture(t *testing.T) *idempotencyFixture {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	locationRepo.Create(ctx, loc)
+
+	router := httpinterface.SetupRouter(cfg, productRepo, locationRepo, stockRepo, nil,
+		httpinterface.WithIdempotencyRepository(NewMockIdempotencyRepository()),
+	)
+
+	return &idempotencyFixture{
+		router:    router,
+		token:     getAuthToken(t, router),
+		product:   prod,
+		stockRepo: stockRepo,
+	}
+}
+
+// post sends an authenticated POST with the given idempotency key
+func (f *idempotencyFixture) post(path, key string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	return f.postAs(f.token, path, key, payload)
+}
+
+// postAs sends a raw POST body with the given token and idempotency key
+func (f *idempotencyFixture) postAs(token, path, key string, payload []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+// sendIfMatch sends an authenticated request with an optional If-Match header
+func sendIfMatch(router *gin.Engine, token, method, path, ifMatch string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// sendImport posts a raw import file and decodes the report in the response
+func sendImport(router *gin.Engine, token, path, contentType string, body []byte) (*httptest.ResponseRecorder, dto.ImportReportResponse) {
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Data dto.ImportReportResponse `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp.Data
+}
+
+// buildXLSX zips a minimal workbook whose first sheet holds the given XML rows
+func buildXLSX(t *testing.T, sharedStrings, sheetRows string) []byte {
+	t.Helper()
+
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Locations" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":   `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + sharedStrings + `</sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetRows + `</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// getExport requests an export with an optional Accept header
+func getExport(router *gin.Engine, token, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// getList requests a list endpoint and decodes the page in its data field
+func getList(t *testing.T, router *gin.Engine, token, path string, page interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code == http.StatusOK && page != nil {
+		body := struct {
+			Data interface{} `json:"data"`
+		}{Data: page}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode %s: %v", path, err)
+		}
+	}
+	return w
+}
+
+// setupSearchRouter serves a few products, one registered under a UPC-A
+func setupSearchRouter(t *testing.T) (*gin.Engine, string) {
+	ctx := context.Background()
+	cfg := &config.Config{
+		JWTSecret: "test-secret-key",
+	}
+	productRepo := NewMockProductRepository()
+	for _, p := range []struct {
+		sku  string
+		data product.MasterData
+	}{
+		{"COLA-330", product.MasterData{Description: "Cola can"}},
+		{"BEV-COLA-1L", product.MasterData{Description: "Cola bottle"}},
+		{"WATER-500", product.MasterData{
+			Description: "Still water",
+			Barcodes:    []product.Barcode{{Type: product.BarcodeTypeUPCA, Value: "012345678905"}},
+		}},
+	} {
+		prod, err := product.NewProduct(p.sku, p.data)
+		if err != nil {
+			t.Fatalf("product %s: %v", p.sku, err)
+		}
+		prod.Quantity = 10
+		productRepo.Create(ctx, prod)
+	}
+
+	router := httpinterface.SetupRouter(cfg, productRepo, NewMockLocationRepository(), NewMockStockRepository(), nil)
+	return router, getAuthToken(t, router)
+}
+
+// palletSSCC is the SSCC on the label of the pallet the scan tests build
+const palletSSCC = "106141411234567897"
+
+// buildLabelledPallet packs 40 of product 1, lot L-01, on a pallet at the dock
+func buildLabelledPallet(t *testing.T, f *containerFixture) {
+	t.Helper()
+	status := f.do("POST", "/api/v1/containers", dto.BuildContainerRequest{
+		LPN: "PAL-1", SSCC: palletSSCC, Type: "PALLET", LocationID: 1,
+		Contents: []dto.ContainerContentDTO{{ProductID: 1, Lot: "L-01", Quantity: 40}},
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
 }
 
 // ===================== MOCK TRANSACTION MANAGER =====================
@@ -1,12 +1,42 @@
 package unit
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/commands"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/application/dto"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/container"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/gs1"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/idempotency"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/listing"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/location"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/outbox"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/product"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/putaway"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/replenishment"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/serial"
 	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/stock"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/domain/webhook"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/messaging"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/persistence/sql"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/spreadsheet"
+	"github.com/ardianhermawan17/warehouse-management-system-ddd/internal/infrastructure/webhooks"
+	"github.com/parquet-go/parquet-go"
 )
 
 // MockProductRepository is a mock implementation of product.Repository
@@ -42,26 +72,103 @@ func (m *MockProductRepository) GetBySKU(ctx context.Context, skuName string) (*
 	return nil, product.ErrProductNotFound
 }
 
-func (m *MockProductRepository) List(ctx context.Context, limit, offset int) ([]*product.Product, error) {
+func (m *MockProductRepository) GetByBarcode(ctx context.Context, barcode string) (*product.Product, error) {
+	for _, p := range m.products {
+		for _, b := range p.Barcodes {
+			if b.Value == barcode {
+				return p, nil
+			}
+		}
+	}
+	return nil, product.ErrProductNotFound
+}
+
+func (m *MockProductRepository) List(ctx context.Context, spec product.ListSpec) ([]*product.Product, error) {
 	var result []*product.Product
 	for _, p := range m.products {
-		result = append(result, p)
+		if matchesProduct(p, spec) && afterCursor(p.ID, spec.After) {
+			result = append(result, p)
+		}
 	}
-	return result, nil
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return page(result, spec.Limit, spec.Offset), nil
+}
+
+// Search scores SKU matches like the SQL repository, without trigrams or stemming
+func (m *MockProductRepository) Search(ctx context.Context, text string, limit int) ([]product.SearchHit, error) {
+	text = strings.ToLower(text)
+	var hits []product.SearchHit
+	for _, p := range m.products {
+		sku := strings.ToLower(p.SKUName)
+		var score float64
+		switch {
+		case sku == text:
+			score = 6
+		case strings.HasPrefix(sku, text):
+			score = 2
+		case strings.Contains(sku, text):
+			score = 1
+		}
+		if matchesSearch(text, p.Description, p.Category, p.Brand) {
+			score += 0.5
+		}
+		for _, b := range p.Barcodes {
+			if strings.Contains(b.Value, text) {
+				score++
+				break
+			}
+		}
+		if score > 0 {
+			hits = append(hits, product.SearchHit{Product: p, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.ID > hits[j].Product.ID
+	})
+	return page(hits, limit, 0), nil
 }
 
 func (m *MockProductRepository) Update(ctx context.Context, p *product.Product) error {
+	stored, ok := m.products[p.ID]
+	if !ok {
+		return product.ErrProductNotFound
+	}
+	if stored.Version != p.Version {
+		return product.ErrConcurrentModification
+	}
+	p.Version++
+	m.products[p.ID] = p
+	return nil
+}
+
+func (m *MockProductRepository) UpdateQuantity(ctx context.Context, p *product.Product) error {
 	m.products[p.ID] = p
 	return nil
 }
 
-func (m *MockProductRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockProductRepository) Delete(ctx context.Context, id, version int64) error {
+	stored, ok := m.products[id]
+	if !ok {
+		return product.ErrProductNotFound
+	}
+	if stored.Version != version {
+		return product.ErrConcurrentModification
+	}
 	delete(m.products, id)
 	return nil
 }
 
-func (m *MockProductRepository) Count(ctx context.Context) (int64, error) {
-	return int64(len(m.products)), nil
+func (m *MockProductRepository) Count(ctx context.Context, spec product.ListSpec) (int64, error) {
+	var count int64
+	for _, p := range m.products {
+		if matchesProduct(p, spec) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // MockLocationRepository is a mock implementation of location.Repository
@@ -97,31 +204,58 @@ func (m *MockLocationRepository) GetByCode(ctx context.Context, code string) (*l
 	return nil, location.ErrLocationNotFound
 }
 
-func (m *MockLocationRepository) List(ctx context.Context, limit, offset int) ([]*location.Location, error) {
+func (m *MockLocationRepository) List(ctx context.Context, spec location.ListSpec) ([]*location.Location, error) {
 	var result []*location.Location
 	for _, l := range m.locations {
-		result = append(result, l)
+		if matchesLocation(l, spec) && afterCursor(l.ID, spec.After) {
+			result = append(result, l)
+		}
 	}
-	return result, nil
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return page(result, spec.Limit, spec.Offset), nil
 }
 
 func (m *MockLocationRepository) Update(ctx context.Context, l *location.Location) error {
+	stored, ok := m.locations[l.ID]
+	if !ok {
+		return location.ErrLocationNotFound
+	}
+	if stored.Version != l.Version {
+		return location.ErrConcurrentModification
+	}
+	l.Version++
 	m.locations[l.ID] = l
 	return nil
 }
 
-func (m *MockLocationRepository) Delete(ctx context.Context, id int64) error {
+func (m *MockLocationRepository) Delete(ctx context.Context, id, version int64) error {
+	stored, ok := m.locations[id]
+	if !ok {
+		return location.ErrLocationNotFound
+	}
+	if stored.Version != version {
+		return location.ErrConcurrentModification
+	}
 	delete(m.locations, id)
 	return nil
 }
 
-func (m *MockLocationRepository) Count(ctx context.Context) (int64, error) {
-	return int64(len(m.locations)), nil
+func (m *MockLocationRepository) Count(ctx context.Context, spec location.ListSpec) (int64, error) {
+	var count int64
+	for _, l := range m.locations {
+		if matchesLocation(l, spec) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 // MockStockRepository is a mock implementation of stock.Repository
 type MockStockRepository struct {
 	movements map[int64]*stock.StockMovement
+
+	// locks counts LastSequence calls, which lock the ledger in the real repository
+	locks int
 }
 
 func NewMockStockRepository() *MockStockRepository {
@@ -132,6 +266,7 @@ func NewMockStockRepository() *MockStockRepository {
 
 func (m *MockStockRepository) Create(ctx context.Context, sm *stock.StockMovement) error {
 	sm.ID = int64(len(m.movements) + 1)
+	sm.Sequence = sm.ID
 	m.movements[sm.ID] = sm
 	return nil
 }
@@ -163,18 +298,149 @@ func (m *MockStockRepository) GetByLocation(ctx context.Context, locationID int6
 	return result, nil
 }
 
-func (m *MockStockRepository) List(ctx context.Context, limit, offset int) ([]*stock.StockMovement, error) {
+func (m *MockStockRepository) List(ctx context.Context, spec stock.ListSpec) ([]*stock.StockMovement, error) {
 	var result []*stock.StockMovement
+	for seq := int64(len(m.movements)); seq >= 1; seq-- {
+		if sm := m.movements[seq]; matchesMovement(sm, spec) && afterCursor(sm.ID, spec.After) {
+			result = append(result, sm)
+		}
+	}
+	return page(result, spec.Limit, spec.Offset), nil
+}
+
+func (m *MockStockRepository) Count(ctx context.Context, spec stock.ListSpec) (int64, error) {
+	var count int64
+	for _, sm := range m.movements {
+		if matchesMovement(sm, spec) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockStockRepository) Each(ctx context.Context, filter stock.Filter, fn func(*stock.StockMovement) error) error {
+	for seq := int64(1); seq <= int64(len(m.movements)); seq++ {
+		if sm := m.movements[seq]; matchesFilter(sm, filter) {
+			if err := fn(sm); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockStockRepository) SumBalances(ctx context.Context, r stock.LedgerRange) ([]stock.Balance, error) {
+	type key struct{ productID, locationID int64 }
+	totals := make(map[key]int64)
 	for _, sm := range m.movements {
-		result = append(result, sm)
+		if sm.Sequence <= r.AfterSequence || (r.ThroughSequence > 0 && sm.Sequence > r.ThroughSequence) {
+			continue
+		}
+		if !r.Until.IsZero() && sm.CreatedAt.After(r.Until) {
+			continue
+		}
+		k := key{sm.ProductID, sm.LocationID}
+		if sm.IsInbound() {
+			totals[k] += sm.Quantity
+		} else {
+			totals[k] -= sm.Quantity
+		}
+	}
+
+	var result []stock.Balance
+	for k, qty := range totals {
+		result = append(result, stock.Balance{ProductID: k.productID, LocationID: k.locationID, Quantity: qty})
+	}
+	return result, nil
+}
+
+func (m *MockStockRepository) Replay(ctx context.Context, afterSequence int64, limit int) ([]*stock.StockMovement, error) {
+	var result []*stock.StockMovement
+	for seq := afterSequence + 1; seq <= int64(len(m.movements)) && len(result) < limit; seq++ {
+		result = append(result, m.movements[seq])
 	}
 	return result, nil
 }
 
-func (m *MockStockRepository) Count(ctx context.Context) (int64, error) {
+func (m *MockStockRepository) LastSequence(ctx context.Context) (int64, error) {
+	m.locks++
 	return int64(len(m.movements)), nil
 }
 
+// matchesFilter reports whether a movement satisfies every non-zero filter field
+func matchesFilter(sm *stock.StockMovement, f stock.Filter) bool {
+	return (f.ProductID == 0 || sm.ProductID == f.ProductID) &&
+		(f.LocationID == 0 || sm.LocationID == f.LocationID) &&
+		(f.Type == "" || sm.Type == f.Type) &&
+		(f.ReferenceType == "" || sm.ReferenceType == f.ReferenceType) &&
+		(f.ReferenceID == "" || sm.ReferenceID == f.ReferenceID) &&
+		(f.DocumentNumber == "" || sm.DocumentNumber == f.DocumentNumber) &&
+		f.Quantity.Contains(sm.Quantity) &&
+		f.CreatedAt.Contains(sm.CreatedAt)
+}
+
+// matchesMovement applies the filter and search of a movement listing
+func matchesMovement(sm *stock.StockMovement, spec stock.ListSpec) bool {
+	return matchesFilter(sm, spec.Filter) &&
+		matchesSearch(spec.Search, sm.ReferenceID, sm.DocumentNumber, sm.Notes, sm.Lot)
+}
+
+// matchesProduct applies the filter and search of a product listing
+func matchesProduct(p *product.Product, spec product.ListSpec) bool {
+	f := spec.Filter
+	return (f.SKUPrefix == "" || strings.HasPrefix(strings.ToLower(p.SKUName), strings.ToLower(f.SKUPrefix))) &&
+		(f.Category == "" || p.Category == f.Category) &&
+		(f.Brand == "" || p.Brand == f.Brand) &&
+		(f.Status == "" || p.Status == f.Status) &&
+		f.Quantity.Contains(p.Quantity) &&
+		matchesSearch(spec.Search, p.SKUName, p.Description, p.Category, p.Brand)
+}
+
+// matchesLocation applies the filter and search of a location listing
+func matchesLocation(l *location.Location, spec location.ListSpec) bool {
+	f := spec.Filter
+	return (f.CodePrefix == "" || strings.HasPrefix(strings.ToLower(l.Code), strings.ToLower(f.CodePrefix))) &&
+		(f.Warehouse == "" || l.Warehouse == f.Warehouse) &&
+		(f.TemperatureZone == "" || l.TemperatureZone == f.TemperatureZone) &&
+		f.Capacity.Contains(l.Capacity) &&
+		matchesSearch(spec.Search, l.Code, l.Name, l.Warehouse)
+}
+
+// matchesSearch reports whether any field contains the search text, ignoring case
+func matchesSearch(search string, fields ...string) bool {
+	if search == "" {
+		return true
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), strings.ToLower(search)) {
+			return true
+		}
+	}
+	return false
+}
+
+// afterCursor reports whether a row comes after the cursor keys in the
+// default newest-first order, whose last key is always the ID
+func afterCursor(id int64, after []string) bool {
+	if len(after) == 0 {
+		return true
+	}
+	last, _ := strconv.ParseInt(after[len(after)-1], 10, 64)
+	return id < last
+}
+
+// page cuts one page out of a listing; a zero limit keeps the rest
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
 // Test cases
 func TestStockOutValidation(t *testing.T) {
 	ctx := context.Background()
@@ -183,7 +449,8 @@ func TestStockOutValidation(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
@@ -200,6 +467,32 @@ func TestStockOutValidation(t *testing.T) {
 	}
 }
 
+func TestStockOutUsesLocationBalance(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	for _, code := range []string{"LOC-A1", "LOC-B1"} {
+		loc, _ := location.NewLocation(code, "Warehouse A", 500)
+		locationRepo.Create(ctx, loc)
+	}
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+	inbound, _ := stock.NewStockMovement(prod.ID, 1, stock.MovementTypeIN, 100)
+	if err := service.RecordMovement(ctx, inbound); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The product has 100 in total, none of it at location 2
+	outbound, _ := stock.NewStockMovement(prod.ID, 2, stock.MovementTypeOUT, 10)
+	if err := service.RecordMovement(ctx, outbound); err != stock.ErrInsufficientStock {
+		t.Errorf("Expected ErrInsufficientStock, got %v", err)
+	}
+}
+
 func TestStockInCapacityValidation(t *testing.T) {
 	ctx := context.Background()
 	productRepo := NewMockProductRepository()
@@ -207,7 +500,8 @@ func TestStockInCapacityValidation(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 100)
@@ -222,6 +516,38 @@ func TestStockInCapacityValidation(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for capacity exceeded, got nil")
 	}
+	// Concurrent inbound movements must not both pass the check against the same balance
+	if stockRepo.locks != 1 {
+		t.Errorf("Expected the ledger to be locked before the capacity check, got %d locks", stockRepo.locks)
+	}
+}
+
+func TestStockInUsesProjectedLocationBalance(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	balanceRepo := NewMockProjectionRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	// The location's balance is read from the projection, not the ledger
+	balanceRepo.AddBalances(ctx, []stock.Balance{{ProductID: prod.ID, LocationID: loc.ID, Quantity: 90}})
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo).WithBalances(balanceRepo)
+
+	movement, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 20)
+	if err := service.RecordMovement(ctx, movement); !errors.Is(err, location.ErrCapacityExceeded) {
+		t.Errorf("Expected ErrCapacityExceeded, got %v", err)
+	}
+
+	movement, _ = stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 10)
+	if err := service.RecordMovement(ctx, movement); err != nil {
+		t.Errorf("Expected the location to take the remaining 10, got %v", err)
+	}
 }
 
 func TestSuccessfulStockMovement(t *testing.T) {
@@ -231,7 +557,8 @@ func TestSuccessfulStockMovement(t *testing.T) {
 	stockRepo := NewMockStockRepository()
 
 	// Create test data
-	prod, _ := product.NewProduct("SKU-001", 100)
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
 	productRepo.Create(ctx, prod)
 
 	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
@@ -253,3 +580,1697 @@ func TestSuccessfulStockMovement(t *testing.T) {
 		t.Errorf("Expected 1 movement, got %d", len(movements))
 	}
 }
+
+func TestNotesLengthCountsCharacters(t *testing.T) {
+	movement, _ := stock.NewStockMovement(1, 1, stock.MovementTypeIN, 1)
+
+	// 1000 characters of three bytes each are within the limit
+	if err := movement.SetReference(stock.ReferenceTypeCount, "", "", strings.Repeat("仓", 1000)); err != nil {
+		t.Errorf("Expected 1000 characters to be accepted, got %v", err)
+	}
+	if err := movement.SetReference(stock.ReferenceTypeCount, "", "", strings.Repeat("仓", 1001)); err != stock.ErrNotesTooLong {
+		t.Errorf("Expected ErrNotesTooLong for 1001 characters, got %v", err)
+	}
+}
+
+func TestBalancesAsOfReplaysLedger(t *testing.T) {
+	ctx := context.Background()
+	stockRepo := NewMockStockRepository()
+
+	day1 := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 100, day1)
+	recordAt(ctx, stockRepo, 1, 2, stock.MovementTypeIN, 40, day1)
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeOUT, 30, day2)
+
+	service := stock.NewBalanceService(stockRepo, nil)
+
+	balances, err := service.BalancesAsOf(ctx, day1.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(balances) != 2 || balances[0].Quantity != 100 || balances[1].Quantity != 40 {
+		t.Errorf("Expected balances 100 and 40 at end of January, got %+v", balances)
+	}
+
+	balances, _ = service.BalancesAsOf(ctx, day2.Add(time.Hour))
+	if balances[0].Quantity != 70 {
+		t.Errorf("Expected 70 at location 1 after outbound, got %d", balances[0].Quantity)
+	}
+}
+
+func TestBalancesAsOfStartsFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	stockRepo := NewMockStockRepository()
+	snapshotRepo := NewMockSnapshotRepository()
+
+	past := time.Now().Add(-time.Hour)
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 100, past)
+
+	service := stock.NewBalanceService(stockRepo, snapshotRepo)
+
+	snapshot, err := service.TakeSnapshot(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if snapshot.LastSequence != 1 || len(snapshot.Balances) != 1 {
+		t.Fatalf("Expected snapshot through movement 1 with one balance, got %+v", snapshot)
+	}
+
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeOUT, 25, time.Now())
+
+	balances, err := service.BalancesAsOf(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(balances) != 1 || balances[0].Quantity != 75 {
+		t.Errorf("Expected snapshot plus delta to give 75, got %+v", balances)
+	}
+}
+
+func TestSnapshotsFollowLedgerSequence(t *testing.T) {
+	ctx := context.Background()
+	stockRepo := NewMockStockRepository()
+	snapshotRepo := NewMockSnapshotRepository()
+
+	// Movement 1 got the lower ID but committed after movement 2, so its
+	// sequence is the higher one
+	past := time.Now().Add(-time.Hour)
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 100, past)
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 5, past)
+	stockRepo.movements[1].Sequence, stockRepo.movements[2].Sequence = 2, 1
+
+	// A snapshot taken between the two commits holds only movement 2
+	snapshotRepo.Save(ctx, &stock.Snapshot{
+		TakenAt:      past,
+		LastSequence: 1,
+		Balances:     []stock.Balance{{ProductID: 1, LocationID: 1, Quantity: 5}},
+	})
+
+	balances, err := stock.NewBalanceService(stockRepo, snapshotRepo).BalancesAsOf(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(balances) != 1 || balances[0].Quantity != 105 {
+		t.Errorf("Expected the late commit on top of the snapshot to give 105, got %+v", balances)
+	}
+}
+
+func TestReconcileReportsProductDrift(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	stockRepo := NewMockStockRepository()
+
+	// Stored quantity says 100, but the ledger only knows about 60
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	productRepo.Create(ctx, prod)
+	recordAt(ctx, stockRepo, prod.ID, 1, stock.MovementTypeIN, 60, time.Now())
+
+	reconciler := stock.NewReconciler(productRepo, stockRepo, nil)
+
+	report, err := reconciler.Reconcile(ctx, stock.ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(report.Discrepancies) != 1 {
+		t.Fatalf("Expected 1 discrepancy, got %d", len(report.Discrepancies))
+	}
+
+	d := report.Discrepancies[0]
+	if d.Scope != stock.DiscrepancyScopeProduct || d.Difference() != 40 || d.Repaired {
+		t.Errorf("Unexpected discrepancy: %+v", d)
+	}
+}
+
+func TestReconcileRepairResetsStoredTotalToLedger(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	stockRepo := NewMockStockRepository()
+	repairRepo := &MockRepairRepository{}
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 100
+	productRepo.Create(ctx, prod)
+	recordAt(ctx, stockRepo, prod.ID, 1, stock.MovementTypeIN, 60, time.Now())
+
+	reconciler := stock.NewReconciler(productRepo, stockRepo, nil).WithRepairLog(repairRepo)
+
+	report, err := reconciler.Reconcile(ctx, stock.ReconcileOptions{Repair: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d := report.Discrepancies[0]
+	if !d.Repaired || d.Stored != 100 || d.Ledger != 60 {
+		t.Fatalf("Expected the discrepancy repaired, got %+v", d)
+	}
+
+	// The stored total follows the ledger; the ledger is left alone
+	if prod.Quantity != 60 {
+		t.Errorf("Expected stored quantity 60, got %d", prod.Quantity)
+	}
+	if len(stockRepo.movements) != 1 {
+		t.Errorf("Expected no movements posted, got %d", len(stockRepo.movements))
+	}
+
+	// The repair is on the audit trail
+	if len(repairRepo.repairs) != 1 || repairRepo.repairs[0].Stored != 100 || repairRepo.repairs[0].Ledger != 60 {
+		t.Errorf("Expected the repair to be recorded, got %+v", repairRepo.repairs)
+	}
+
+	// A second run finds nothing left to fix
+	report, _ = reconciler.Reconcile(ctx, stock.ReconcileOptions{})
+	if len(report.Discrepancies) != 0 {
+		t.Errorf("Expected no discrepancies after repair, got %+v", report.Discrepancies)
+	}
+}
+
+func TestReconcileRepairsLocationBalanceProjection(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	stockRepo := NewMockStockRepository()
+	balanceRepo := NewMockProjectionRepository()
+	repairRepo := &MockRepairRepository{}
+
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 60, time.Now())
+	// The projection has drifted at location 1 and holds a stale balance at location 2
+	balanceRepo.AddBalances(ctx, []stock.Balance{
+		{ProductID: 1, LocationID: 1, Quantity: 50},
+		{ProductID: 1, LocationID: 2, Quantity: 5},
+	})
+
+	reconciler := stock.NewReconciler(productRepo, stockRepo, nil).WithBalances(balanceRepo).WithRepairLog(repairRepo)
+
+	report, err := reconciler.Reconcile(ctx, stock.ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Discrepancies) != 2 || report.Discrepancies[0].Repaired {
+		t.Fatalf("Expected two unrepaired location discrepancies, got %+v", report.Discrepancies)
+	}
+	if balanceRepo.balances[[2]int64{1, 1}] != 50 || len(repairRepo.repairs) != 0 {
+		t.Errorf("Expected a report-only run to leave the projection alone")
+	}
+
+	report, _ = reconciler.Reconcile(ctx, stock.ReconcileOptions{Repair: true})
+	if len(report.Discrepancies) != 2 || !report.Discrepancies[1].Repaired {
+		t.Fatalf("Expected both discrepancies repaired, got %+v", report.Discrepancies)
+	}
+	if balanceRepo.balances[[2]int64{1, 1}] != 60 || balanceRepo.balances[[2]int64{1, 2}] != 0 {
+		t.Errorf("Expected the projection to match the ledger, got %v", balanceRepo.balances)
+	}
+	if len(repairRepo.repairs) != 2 {
+		t.Errorf("Expected both repairs recorded, got %+v", repairRepo.repairs)
+	}
+}
+
+func TestReconcileDetectsSnapshotMismatch(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	stockRepo := NewMockStockRepository()
+	snapshotRepo := NewMockSnapshotRepository()
+
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 60, time.Now().Add(-time.Hour))
+
+	// The snapshot claims 80 at location 1, the ledger replay gives 60
+	snapshotRepo.Save(ctx, &stock.Snapshot{
+		TakenAt:      time.Now().Add(-time.Minute),
+		LastSequence: 1,
+		Balances:     []stock.Balance{{ProductID: 1, LocationID: 1, Quantity: 80}},
+	})
+
+	reconciler := stock.NewReconciler(productRepo, stockRepo, snapshotRepo)
+
+	report, err := reconciler.Reconcile(ctx, stock.ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(report.Discrepancies) != 1 || report.Discrepancies[0].Scope != stock.DiscrepancyScopeLocation {
+		t.Errorf("Expected one location discrepancy, got %+v", report.Discrepancies)
+	}
+}
+
+func TestNewProductAppliesMasterDataDefaults(t *testing.T) {
+	prod, err := product.NewProduct("SKU-001", product.MasterData{BaseUOM: " ea "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if prod.BaseUOM != "EA" || prod.Status != product.StatusActive {
+		t.Errorf("Expected base UoM EA and ACTIVE status, got %q and %q", prod.BaseUOM, prod.Status)
+	}
+}
+
+func TestNewProductValidatesMasterData(t *testing.T) {
+	cases := []struct {
+		name string
+		data product.MasterData
+		want error
+	}{
+		{"bad check digit", product.MasterData{Barcodes: []product.Barcode{{Type: product.BarcodeTypeEAN13, Value: "4006381333932"}}}, product.ErrInvalidBarcode},
+		{"wrong length", product.MasterData{Barcodes: []product.Barcode{{Type: product.BarcodeTypeUPCA, Value: "4006381333931"}}}, product.ErrInvalidBarcode},
+		{"duplicate barcode", product.MasterData{Barcodes: []product.Barcode{
+			{Type: product.BarcodeTypeEAN13, Value: "4006381333931"},
+			{Type: product.BarcodeTypeEAN13, Value: "4006381333931"},
+		}}, product.ErrDuplicateBarcode},
+		{"partial dimensions", product.MasterData{Dimensions: product.Dimensions{LengthMM: 100}}, product.ErrInvalidDimensions},
+		{"negative weight", product.MasterData{WeightGrams: -1}, product.ErrInvalidWeight},
+		{"unknown status", product.MasterData{Status: "DISCONTINUED"}, product.ErrInvalidStatus},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := product.NewProduct("SKU-001", tc.data); err != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	// Every supported symbology accepts a code with a correct check digit
+	valid := []product.Barcode{
+		{Type: product.BarcodeTypeEAN8, Value: "96385074"},
+		{Type: product.BarcodeTypeEAN13, Value: "4006381333931"},
+		{Type: product.BarcodeTypeUPCA, Value: "036000291452"},
+		{Type: product.BarcodeTypeGTIN14, Value: "10012345678902"},
+	}
+	if _, err := product.NewProduct("SKU-002", product.MasterData{Barcodes: valid}); err != nil {
+		t.Errorf("Expected valid barcodes to be accepted, got %v", err)
+	}
+}
+
+func TestInactiveProductRejectsInbound(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{Status: product.StatusInactive})
+	prod.Quantity = 100
+	productRepo.Create(ctx, prod)
+
+	loc, _ := location.NewLocation("LOC-A1", "Warehouse A", 500)
+	locationRepo.Create(ctx, loc)
+	recordAt(ctx, stockRepo, prod.ID, loc.ID, stock.MovementTypeIN, 100, time.Now())
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+
+	inbound, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 10)
+	if err := service.RecordMovement(ctx, inbound); err != product.ErrProductInactive {
+		t.Errorf("Expected ErrProductInactive, got %v", err)
+	}
+
+	// Remaining stock can still be shipped out
+	outbound, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeOUT, 10)
+	if err := service.RecordMovement(ctx, outbound); err != nil {
+		t.Errorf("Expected outbound movement to succeed, got %v", err)
+	}
+}
+
+func TestPackHierarchyValidation(t *testing.T) {
+	cases := []struct {
+		name  string
+		packs []product.Pack
+		want  error
+	}{
+		{"unknown level", []product.Pack{{Level: "CRATE", UnitsPerPack: 10}}, product.ErrInvalidPackLevel},
+		{"each redefined", []product.Pack{{Level: product.PackLevelEach, UnitsPerPack: 2}}, product.ErrInvalidPackHierarchy},
+		{"case smaller than inner", []product.Pack{
+			{Level: product.PackLevelInner, UnitsPerPack: 12},
+			{Level: product.PackLevelCase, UnitsPerPack: 6},
+		}, product.ErrInvalidPackHierarchy},
+		{"case not a multiple of inner", []product.Pack{
+			{Level: product.PackLevelInner, UnitsPerPack: 5},
+			{Level: product.PackLevelCase, UnitsPerPack: 12},
+		}, product.ErrInvalidPackHierarchy},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := product.NewProduct("SKU-001", product.MasterData{Packs: tc.packs}); err != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPackConversion(t *testing.T) {
+	prod := packedProduct(t, 0)
+
+	if prod.Packs[0].Level != product.PackLevelInner || prod.Packs[2].Level != product.PackLevelPallet {
+		t.Errorf("Expected packs ordered from smallest to largest, got %+v", prod.Packs)
+	}
+
+	base, err := prod.ToBaseUnits(product.PackLevelCase, 3)
+	if err != nil || base != 72 {
+		t.Errorf("Expected 3 cases to be 72 eaches, got %d (%v)", base, err)
+	}
+
+	packs, remainder, _ := prod.FromBaseUnits(product.PackLevelCase, 100)
+	if packs != 4 || remainder != 4 {
+		t.Errorf("Expected 100 eaches to be 4 cases and 4 left over, got %d and %d", packs, remainder)
+	}
+
+	unpacked, _ := product.NewProduct("SKU-002", product.MasterData{})
+	if _, err := unpacked.ToBaseUnits(product.PackLevelCase, 1); err != product.ErrPackLevelNotConfigured {
+		t.Errorf("Expected ErrPackLevelNotConfigured, got %v", err)
+	}
+}
+
+func TestVolumeLimitRejectsInbound(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	// One litre per unit, ten litres of space
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{
+		Dimensions: product.Dimensions{LengthMM: 100, WidthMM: 100, HeightMM: 100},
+	})
+	productRepo.Create(ctx, prod)
+	loc := limitedLocation(ctx, locationRepo, location.Limits{MaxVolumeCM3: 10000})
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+
+	movement, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 10)
+	if err := service.RecordMovement(ctx, movement); err != nil {
+		t.Fatalf("Expected 10 litres to fit, got %v", err)
+	}
+
+	movement, _ = stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 1)
+	err := service.RecordMovement(ctx, movement)
+	if !errors.Is(err, location.ErrCapacityExceeded) || capacityLimit(err) != location.LimitVolume {
+		t.Errorf("Expected volume capacity error, got %v", err)
+	}
+}
+
+func TestWeightLimitCountsEveryProduct(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	water, _ := product.NewProduct("SKU-WATER", product.MasterData{WeightGrams: 1000})
+	tissue, _ := product.NewProduct("SKU-TISSUE", product.MasterData{WeightGrams: 100})
+	productRepo.Create(ctx, water)
+	productRepo.Create(ctx, tissue)
+	loc := limitedLocation(ctx, locationRepo, location.Limits{MaxWeightGrams: 10000})
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+
+	movement, _ := stock.NewStockMovement(water.ID, loc.ID, stock.MovementTypeIN, 9)
+	if err := service.RecordMovement(ctx, movement); err != nil {
+		t.Fatalf("Expected 9 kg to fit, got %v", err)
+	}
+
+	// Ten boxes of tissue weigh 1 kg, which tips the location over 10 kg
+	movement, _ = stock.NewStockMovement(tissue.ID, loc.ID, stock.MovementTypeIN, 11)
+	if err := service.RecordMovement(ctx, movement); capacityLimit(err) != location.LimitWeight {
+		t.Errorf("Expected weight capacity error, got %v", err)
+	}
+}
+
+func TestPalletPositionsLimit(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{
+		Packs: []product.Pack{{Level: product.PackLevelPallet, UnitsPerPack: 100}},
+	})
+	productRepo.Create(ctx, prod)
+	loc := limitedLocation(ctx, locationRepo, location.Limits{PalletPositions: 2})
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+
+	// 150 units occupy two pallet positions
+	movement, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 150)
+	if err := service.RecordMovement(ctx, movement); err != nil {
+		t.Fatalf("Expected two pallets to fit, got %v", err)
+	}
+
+	movement, _ = stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 51)
+	if err := service.RecordMovement(ctx, movement); capacityLimit(err) != location.LimitPalletPositions {
+		t.Errorf("Expected pallet positions error, got %v", err)
+	}
+}
+
+func TestVolumeLimitRequiresProductDimensions(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc := limitedLocation(ctx, locationRepo, location.Limits{MaxVolumeCM3: 10000})
+
+	service := stock.NewService(productRepo, locationRepo, stockRepo)
+
+	movement, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 1)
+	if err := service.RecordMovement(ctx, movement); !errors.Is(err, product.ErrDimensionsUnknown) {
+		t.Errorf("Expected ErrDimensionsUnknown, got %v", err)
+	}
+}
+
+func TestSerializedMovementRequiresOneSerialPerUnit(t *testing.T) {
+	ctx := context.Background()
+	service, _, prod := serializedFixture(t)
+
+	movement, _ := stock.NewStockMovement(prod.ID, 1, stock.MovementTypeIN, 2)
+	movement.Serials = []string{"SN-1"}
+	if err := service.RecordMovement(ctx, movement); err != stock.ErrSerialCountMismatch {
+		t.Errorf("Expected ErrSerialCountMismatch, got %v", err)
+	}
+
+	movement, _ = stock.NewStockMovement(prod.ID, 1, stock.MovementTypeIN, 2)
+	movement.Serials = []string{"SN-1", " SN-1 "}
+	if err := service.RecordMovement(ctx, movement); err != stock.ErrDuplicateSerial {
+		t.Errorf("Expected ErrDuplicateSerial, got %v", err)
+	}
+}
+
+func TestSerialsRejectedForUnserializedProduct(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	service := stock.NewService(productRepo, locationRepo, NewMockStockRepository())
+
+	movement, _ := stock.NewStockMovement(prod.ID, loc.ID, stock.MovementTypeIN, 1)
+	movement.Serials = []string{"SN-1"}
+	if err := service.RecordMovement(ctx, movement); err != stock.ErrSerialsNotAllowed {
+		t.Errorf("Expected ErrSerialsNotAllowed, got %v", err)
+	}
+}
+
+func TestSerialLifecycleAcrossMovements(t *testing.T) {
+	ctx := context.Background()
+	service, serialRepo, prod := serializedFixture(t)
+
+	receive, _ := stock.NewStockMovement(prod.ID, 1, stock.MovementTypeIN, 2)
+	receive.Serials = []string{"SN-1", "SN-2"}
+	if err := service.RecordMovement(ctx, receive); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if prod.Quantity != 2 {
+		t.Fatalf("Expected the product total projection to hold 2, got %d", prod.Quantity)
+	}
+
+	// The same unit cannot be received twice
+	again, _ := stock.NewStockMovement(prod.ID, 2, stock.MovementTypeIN, 1)
+	again.Serials = []string{"SN-1"}
+	if err := service.RecordMovement(ctx, again); !errors.Is(err, serial.ErrSerialInStock) {
+		t.Errorf("Expected ErrSerialInStock, got %v", err)
+	}
+
+	// Shipping from the wrong location fails
+	wrong, _ := stock.NewStockMovement(prod.ID, 2, stock.MovementTypeOUT, 1)
+	wrong.Serials = []string{"SN-1"}
+	if err := service.RecordMovement(ctx, wrong); err == nil {
+		t.Error("Expected error shipping a serial from a location it is not at")
+	}
+
+	ship, _ := stock.NewStockMovement(prod.ID, 1, stock.MovementTypeOUT, 1)
+	ship.Serials = []string{"SN-1"}
+	if err := service.RecordMovement(ctx, ship); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sn, _ := serialRepo.GetByNumber(ctx, prod.ID, "SN-1")
+	if sn.Status != serial.StatusShipped || sn.LocationID != 0 {
+		t.Errorf("Expected SN-1 shipped, got %+v", sn)
+	}
+
+	history, _ := serialRepo.History(ctx, sn.ID)
+	if len(history) != 2 || history[0].MovementID != receive.ID || history[1].MovementID != ship.ID {
+		t.Errorf("Expected receive and ship in history, got %+v", history)
+	}
+
+	inStock, _ := serialRepo.List(ctx, prod.ID, serial.Filter{Status: serial.StatusInStock}, 10, 0)
+	if len(inStock) != 1 || inStock[0].Number != "SN-2" || inStock[0].LocationID != 1 {
+		t.Errorf("Expected only SN-2 in stock at location 1, got %+v", inStock)
+	}
+}
+
+func TestSerializedFlagNeedsEmptyStock(t *testing.T) {
+	if _, err := product.NewProduct("SKU-LAPTOP", product.MasterData{Serialized: true}); err != nil {
+		t.Errorf("Expected a new serialized product to be accepted, got %v", err)
+	}
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	prod.Quantity = 5
+	data := prod.MasterData
+	data.Serialized = true
+	if err := prod.SetMasterData(data); err != product.ErrSerializedWithStock {
+		t.Errorf("Expected ErrSerializedWithStock, got %v", err)
+	}
+}
+
+func TestPutawayPrefersConsolidationThenTightestFit(t *testing.T) {
+	ctx := context.Background()
+	f := newPutawayFixture(t)
+	water := f.product(t, "SKU-WATER", "Beverages")
+	other := f.product(t, "SKU-OTHER", "Beverages")
+
+	recordAt(ctx, f.stockRepo, water.ID, f.locations["B-01"].ID, stock.MovementTypeIN, 10, time.Now())
+	recordAt(ctx, f.stockRepo, other.ID, f.locations["A-02"].ID, stock.MovementTypeIN, 60, time.Now())
+
+	suggestions, err := f.service().Suggest(ctx, putaway.Request{ProductID: water.ID, Quantity: 20})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// B-01 already holds the SKU, A-02 is the fuller of the rest, the freezer is excluded
+	got := suggestedCodes(suggestions)
+	want := []string{"B-01", "A-02", "A-01"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+	if suggestions[0].OnHand != 10 || suggestions[1].FreeUnits != 20 {
+		t.Errorf("Unexpected suggestion details: %+v, %+v", suggestions[0], suggestions[1])
+	}
+}
+
+func TestPutawaySkipsLocationsWithoutCapacity(t *testing.T) {
+	ctx := context.Background()
+	f := newPutawayFixture(t)
+	water := f.product(t, "SKU-WATER", "")
+
+	recordAt(ctx, f.stockRepo, water.ID, f.locations["A-01"].ID, stock.MovementTypeIN, 90, time.Now())
+
+	suggestions, err := f.service().Suggest(ctx, putaway.Request{ProductID: water.ID, Quantity: 20})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, s := range suggestions {
+		if s.Location.Code == "A-01" {
+			t.Errorf("Expected A-01 excluded, only 10 units free, got %v", suggestedCodes(suggestions))
+		}
+	}
+
+	if _, err := f.service().Suggest(ctx, putaway.Request{ProductID: water.ID, Quantity: 500}); err != putaway.ErrNoSuitableLocation {
+		t.Errorf("Expected ErrNoSuitableLocation, got %v", err)
+	}
+}
+
+func TestPutawayAppliesCategoryRules(t *testing.T) {
+	ctx := context.Background()
+	f := newPutawayFixture(t)
+	ice := f.product(t, "SKU-ICE", "Frozen")
+	paint := f.product(t, "SKU-PAINT", "Hazmat")
+	water := f.product(t, "SKU-WATER", "")
+
+	frozen, _ := putaway.NewRule("Frozen", location.ZoneFrozen, "", true)
+	hazmat, _ := putaway.NewRule("Hazmat", location.ZoneAmbient, "A-", false)
+	f.ruleRepo.Save(ctx, frozen)
+	f.ruleRepo.Save(ctx, hazmat)
+
+	recordAt(ctx, f.stockRepo, water.ID, f.locations["A-01"].ID, stock.MovementTypeIN, 5, time.Now())
+
+	suggestions, err := f.service().Suggest(ctx, putaway.Request{ProductID: ice.ID, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if codes := suggestedCodes(suggestions); len(codes) != 1 || codes[0] != "F-01" {
+		t.Errorf("Expected only the freezer for frozen goods, got %v", codes)
+	}
+
+	// Hazmat stays in aisle A and may not share A-01 with the water
+	suggestions, err = f.service().Suggest(ctx, putaway.Request{ProductID: paint.ID, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if codes := suggestedCodes(suggestions); len(codes) != 1 || codes[0] != "A-02" {
+		t.Errorf("Expected only A-02 for hazmat, got %v", codes)
+	}
+}
+
+func TestPutawayConsolidatesLot(t *testing.T) {
+	ctx := context.Background()
+	f := newPutawayFixture(t)
+	containerRepo := NewMockContainerRepository()
+	water := f.product(t, "SKU-WATER", "")
+
+	recordAt(ctx, f.stockRepo, water.ID, f.locations["A-01"].ID, stock.MovementTypeIN, 10, time.Now())
+	recordAt(ctx, f.stockRepo, water.ID, f.locations["A-02"].ID, stock.MovementTypeIN, 10, time.Now())
+
+	pallet, _ := container.NewContainer("LPN-1", "", container.TypePallet, f.locations["A-02"].ID)
+	pallet.AddContent(water.ID, "LOT-7", 10)
+	containerRepo.Create(ctx, pallet)
+
+	suggestions, err := f.service().WithLots(containerRepo).Suggest(ctx, putaway.Request{ProductID: water.ID, Quantity: 5, Lot: "LOT-7"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !suggestions[0].SameLot || suggestions[0].Location.Code != "A-02" {
+		t.Errorf("Expected A-02 first for the same lot, got %v", suggestedCodes(suggestions))
+	}
+}
+
+func TestNewPickFaceValidatesMinMax(t *testing.T) {
+	if _, err := replenishment.NewPickFace(1, 1, 10, 10); err != replenishment.ErrInvalidMinMax {
+		t.Errorf("Expected ErrInvalidMinMax, got %v", err)
+	}
+	if _, err := replenishment.NewPickFace(1, 1, -1, 10); err != replenishment.ErrInvalidMinMax {
+		t.Errorf("Expected ErrInvalidMinMax, got %v", err)
+	}
+	if _, err := replenishment.NewPickFace(0, 1, 0, 10); err != replenishment.ErrInvalidPickFace {
+		t.Errorf("Expected ErrInvalidPickFace, got %v", err)
+	}
+}
+
+func TestReplenishmentFillsPickFaceFromSmallestReserveFirst(t *testing.T) {
+	ctx := context.Background()
+	_, _, stockRepo, replRepo := replenishmentFixture(t)
+	engine := replenishment.NewEngine(replRepo, stockRepo)
+
+	tasks, err := engine.Run(ctx, replenishment.Scope{Reference: "WAVE-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 35 units bring the pick face from 5 to its max of 40: all 12 from RES-2, then 23 from RES-1
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].FromLocationID != 3 || tasks[0].Quantity != 12 {
+		t.Errorf("Expected 12 from location 3 first, got %+v", tasks[0])
+	}
+	if tasks[1].FromLocationID != 2 || tasks[1].Quantity != 23 {
+		t.Errorf("Expected 23 from location 2, got %+v", tasks[1])
+	}
+	if tasks[0].ToLocationID != 1 || tasks[0].Reference != "WAVE-1" || !tasks[0].IsOpen() {
+		t.Errorf("Expected open task to the pick face, got %+v", tasks[0])
+	}
+
+	// Open tasks count towards the pick face, so a second run creates nothing
+	again, err := engine.Run(ctx, replenishment.Scope{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected no new tasks, got %d", len(again))
+	}
+}
+
+func TestReplenishmentScopeAndThreshold(t *testing.T) {
+	ctx := context.Background()
+	_, _, stockRepo, replRepo := replenishmentFixture(t)
+	engine := replenishment.NewEngine(replRepo, stockRepo)
+
+	// Out of scope for a wave of other products
+	tasks, _ := engine.Run(ctx, replenishment.Scope{ProductIDs: []int64{2}})
+	if len(tasks) != 0 {
+		t.Errorf("Expected no tasks outside scope, got %d", len(tasks))
+	}
+
+	// At min the pick face is not below it yet
+	pf, _ := replenishment.NewPickFace(1, 1, 5, 40)
+	replRepo.SavePickFace(ctx, pf)
+	tasks, _ = engine.Run(ctx, replenishment.Scope{ProductIDs: []int64{1}})
+	if len(tasks) != 0 {
+		t.Errorf("Expected no tasks at min, got %d", len(tasks))
+	}
+}
+
+func TestReorderPolicyLevels(t *testing.T) {
+	if _, err := replenishment.NewReorderPolicy(1, "", 10, 20, 5); err != replenishment.ErrInvalidReorderLevel {
+		t.Errorf("Expected ErrInvalidReorderLevel for safety stock above reorder point, got %v", err)
+	}
+	if _, err := replenishment.NewReorderPolicy(1, "", 10, 0, 0); err != replenishment.ErrInvalidReorderLevel {
+		t.Errorf("Expected ErrInvalidReorderLevel for zero reorder quantity, got %v", err)
+	}
+
+	policy, _ := replenishment.NewReorderPolicy(1, "", 50, 10, 24)
+	cases := []struct {
+		available int64
+		severity  replenishment.Severity
+		suggested int64
+	}{
+		{50, "", 0},
+		{49, replenishment.SeverityLow, 24},
+		{20, replenishment.SeverityLow, 48},
+		{5, replenishment.SeverityCritical, 48},
+		{-3, replenishment.SeverityCritical, 72},
+	}
+	for _, tc := range cases {
+		if got := policy.Severity(tc.available); got != tc.severity {
+			t.Errorf("available %d: expected severity %q, got %q", tc.available, tc.severity, got)
+		}
+		if got := policy.SuggestedQuantity(tc.available); got != tc.suggested {
+			t.Errorf("available %d: expected suggested %d, got %d", tc.available, tc.suggested, got)
+		}
+	}
+}
+
+func TestMonitorRaisesUpdatesAndResolvesAlerts(t *testing.T) {
+	ctx := context.Background()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	reorderRepo := NewMockReorderRepository()
+
+	for _, wh := range []string{"JKT", "SBY"} {
+		loc, _ := location.NewLocation(wh+"-A1", wh, 1000)
+		loc.Warehouse = wh
+		locationRepo.Create(ctx, loc)
+	}
+
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 30, at)
+	recordAt(ctx, stockRepo, 1, 2, stock.MovementTypeIN, 80, at)
+
+	overall, _ := replenishment.NewReorderPolicy(1, "", 100, 40, 50)
+	jakarta, _ := replenishment.NewReorderPolicy(1, "JKT", 20, 5, 10)
+	reorderRepo.SavePolicy(ctx, overall)
+	reorderRepo.SavePolicy(ctx, jakarta)
+
+	monitor := replenishment.NewMonitor(reorderRepo, locationRepo, stockRepo)
+
+	// 110 overall and 30 in Jakarta: both above their reorder points
+	if alerts, err := monitor.Check(ctx, 1); err != nil || len(alerts) != 0 {
+		t.Fatalf("Expected no alerts, got %v, %v", alerts, err)
+	}
+
+	// 95 overall, 15 in Jakarta: both alerts are raised
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeOUT, 15, at)
+	alerts, _ := monitor.Check(ctx, 1)
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 open alerts, got %d", len(alerts))
+	}
+
+	// 83 overall, 3 in Jakarta: only the Jakarta alert escalates, neither is duplicated
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeOUT, 12, at)
+	alerts, _ = monitor.Check(ctx, 1)
+	if len(alerts) != 1 || alerts[0].Warehouse != "JKT" {
+		t.Fatalf("Expected the Jakarta alert to escalate, got %+v", alerts)
+	}
+	open, _ := reorderRepo.ListAlerts(ctx, true, 10, 0)
+	if len(open) != 2 {
+		t.Fatalf("Expected 2 open alerts, got %d", len(open))
+	}
+	jkt, _ := reorderRepo.OpenAlert(ctx, 1, "JKT")
+	if jkt.Available != 3 || jkt.Severity != replenishment.SeverityCritical {
+		t.Errorf("Expected critical Jakarta alert at 3, got %+v", jkt)
+	}
+
+	// Receiving 40 in Jakarta lifts both levels back up
+	recordAt(ctx, stockRepo, 1, 1, stock.MovementTypeIN, 40, at)
+	if alerts, _ := monitor.Check(ctx, 1); len(alerts) != 0 {
+		t.Errorf("Expected nothing raised, got %d", len(alerts))
+	}
+	all, _ := reorderRepo.ListAlerts(ctx, false, 10, 0)
+	if len(all) != 2 || all[0].IsOpen() || all[1].IsOpen() {
+		t.Errorf("Expected 2 resolved alerts, got %+v", all)
+	}
+}
+
+func TestNewSubscriptionValidation(t *testing.T) {
+	if _, err := webhook.NewSubscription("ftp://example.com", []webhook.EventType{webhook.EventProductCreated}, ""); err != webhook.ErrInvalidURL {
+		t.Errorf("Expected ErrInvalidURL, got %v", err)
+	}
+	if _, err := webhook.NewSubscription("https://example.com/hook", nil, ""); err != webhook.ErrNoEventTypes {
+		t.Errorf("Expected ErrNoEventTypes, got %v", err)
+	}
+	if _, err := webhook.NewSubscription("https://example.com/hook", []webhook.EventType{"order.created"}, ""); err != webhook.ErrInvalidEventType {
+		t.Errorf("Expected ErrInvalidEventType, got %v", err)
+	}
+
+	s, err := webhook.NewSubscription("https://example.com/hook",
+		[]webhook.EventType{webhook.EventLowStock, webhook.EventLowStock, webhook.EventProductCreated}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(s.EventTypes) != 2 || s.Secret == "" {
+		t.Errorf("Expected de-duplicated types and a generated secret, got %+v", s)
+	}
+	if s.Wants(webhook.EventMovementRecorded) || !s.Wants(webhook.EventLowStock) {
+		t.Error("Expected the subscription to want only its own event types")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := webhook.DefaultRetryPolicy()
+	cases := map[int]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		3: 2 * time.Minute,
+		7: 32 * time.Minute,
+		8: time.Hour,
+	}
+	for attempts, expected := range cases {
+		if got := policy.Backoff(attempts); got != expected {
+			t.Errorf("after %d attempts: expected %v, got %v", attempts, expected, got)
+		}
+	}
+}
+
+func TestDispatcherRetriesUntilDeadThenReplays(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockWebhookRepository()
+
+	receiver := &webhookReceiver{secret: "0123456789abcdef", status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sub, _ := webhook.NewSubscription(server.URL, []webhook.EventType{webhook.EventProductCreated}, receiver.secret)
+	repo.CreateSubscription(ctx, sub)
+
+	publisher := webhook.NewPublisher(repo)
+	publisher.Publish(ctx, webhook.NewEvent(webhook.EventProductCreated, map[string]string{"sku": "SKU-001"}))
+	publisher.Publish(ctx, webhook.NewEvent(webhook.EventLowStock, map[string]string{"sku": "SKU-001"}))
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery for the subscribed event type, got %d", len(repo.deliveries))
+	}
+
+	// No delay between attempts so every retry is due straight away
+	policy := webhook.RetryPolicy{MaxAttempts: 3}
+	dispatcher := webhook.NewDispatcher(repo, webhooks.NewHTTPSender(time.Second), policy)
+	for i := 0; i < 5; i++ {
+		if _, err := dispatcher.DeliverDue(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	d, _ := repo.GetDelivery(ctx, 1)
+	if d.Status != webhook.DeliveryDead || d.Attempts != 3 || d.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a dead delivery after 3 attempts, got %+v", d)
+	}
+	if receiver.badSigs != 0 || len(receiver.received) != 3 {
+		t.Fatalf("Expected 3 correctly signed requests, got %d (%d bad)", len(receiver.received), receiver.badSigs)
+	}
+
+	// The subscriber recovers and the dead delivery is replayed
+	receiver.status = http.StatusNoContent
+	if err := d.Replay(time.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo.UpdateDelivery(ctx, d)
+	if err := d.Replay(time.Now()); err != webhook.ErrDeliveryPending {
+		t.Errorf("Expected ErrDeliveryPending replaying a pending delivery, got %v", err)
+	}
+
+	if n, _ := dispatcher.DeliverDue(ctx); n != 1 {
+		t.Fatalf("Expected 1 delivery attempted, got %d", n)
+	}
+	d, _ = repo.GetDelivery(ctx, 1)
+	if d.Status != webhook.DeliveryDelivered || d.Attempts != 1 || d.DeliveredAt == nil {
+		t.Errorf("Expected the replayed delivery to be delivered, got %+v", d)
+	}
+	last := receiver.received[len(receiver.received)-1]
+	if last["type"] != "product.created" || last["data"].(map[string]interface{})["sku"] != "SKU-001" {
+		t.Errorf("Expected the product.created envelope, got %v", last)
+	}
+}
+
+func TestDispatcherContinuesPastFailedUpdates(t *testing.T) {
+	ctx := context.Background()
+	repo := &updateFailingWebhookRepository{MockWebhookRepository: NewMockWebhookRepository(), failID: 1}
+
+	receiver := &webhookReceiver{secret: "0123456789abcdef", status: http.StatusNoContent}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sub, _ := webhook.NewSubscription(server.URL, []webhook.EventType{webhook.EventProductCreated}, receiver.secret)
+	repo.CreateSubscription(ctx, sub)
+	publisher := webhook.NewPublisher(repo)
+	publisher.Publish(ctx, webhook.NewEvent(webhook.EventProductCreated, map[string]string{"sku": "SKU-001"}))
+	publisher.Publish(ctx, webhook.NewEvent(webhook.EventProductCreated, map[string]string{"sku": "SKU-002"}))
+
+	// Another instance holding the lock keeps this run from sending anything
+	locked := webhook.NewDispatcher(repo, webhooks.NewHTTPSender(time.Second), webhook.DefaultRetryPolicy()).WithLock(heldLock{})
+	if n, err := locked.DeliverDue(ctx); err != nil || n != 0 {
+		t.Fatalf("Expected no deliveries while the lock is held, got %d, %v", n, err)
+	}
+
+	dispatcher := webhook.NewDispatcher(repo, webhooks.NewHTTPSender(time.Second), webhook.DefaultRetryPolicy())
+	n, err := dispatcher.DeliverDue(ctx)
+	if n != 2 || err == nil {
+		t.Fatalf("Expected both deliveries attempted and the failed save reported, got %d, %v", n, err)
+	}
+	if len(receiver.received) != 2 {
+		t.Errorf("Expected both deliveries sent, got %d", len(receiver.received))
+	}
+	if d, _ := repo.GetDelivery(ctx, 2); d.Status != webhook.DeliveryDelivered {
+		t.Errorf("Expected the second delivery saved as delivered, got %s", d.Status)
+	}
+}
+
+func TestMovementEventsAreRecordedOnlyForSavedChanges(t *testing.T) {
+	ctx := context.Background()
+	productRepo := NewMockProductRepository()
+	locationRepo := NewMockLocationRepository()
+	stockRepo := NewMockStockRepository()
+	outboxRepo := NewMockOutboxRepository()
+
+	prod, _ := product.NewProduct("SKU-001", product.MasterData{})
+	productRepo.Create(ctx, prod)
+	loc, _ := location.NewLocation("LOC-001", "Warehouse A", 100)
+	locationRepo.Create(ctx, loc)
+
+	stockService := stock.NewService(productRepo, locationRepo, stockRepo)
+	recordCmd := commands.NewRecordStockMovementCommand(stockService, nil).
+		WithEvents(outbox.NewOutbox(outboxRepo))
+
+	// Rejected movement: nothing changes, so nothing is recorded
+	_, err := recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{ProductID: prod.ID, LocationID: loc.ID, Type: "OUT", Quantity: 5})
+	if err == nil {
+		t.Fatal("Expected insufficient stock error")
+	}
+	if len(outboxRepo.messages) != 0 {
+		t.Fatalf("Expected no events for a rejected movement, got %d", len(outboxRepo.messages))
+	}
+
+	recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{ProductID: prod.ID, LocationID: loc.ID, Type: "IN", Quantity: 10})
+	recordCmd.Execute(ctx, &dto.RecordStockMovementRequest{
+		ProductID: prod.ID, LocationID: loc.ID, Type: "OUT", Quantity: 4, ReferenceType: "ORDER", ReferenceID: "SO-7",
+	})
+
+	var types []string
+	for _, m := range outboxRepo.messages {
+		if m.AggregateKey() != fmt.Sprintf("product:%d", prod.ID) {
+			t.Errorf("Expected event of product %d, got %s", prod.ID, m.AggregateKey())
+		}
+		types = append(types, m.EventType)
+	}
+	expected := "[stock.movement_recorded stock.movement_recorded shipment.confirmed]"
+	if fmt.Sprint(types) != expected {
+		t.Fatalf("Expected %s, got %v", expected, types)
+	}
+
+	var data map[string]interface{}
+	json.Unmarshal(outboxRepo.messages[2].Payload, &data)
+	if data["reference_id"] != "SO-7" || data["quantity"] != float64(4) {
+		t.Errorf("Expected the shipped movement as payload, got %v", data)
+	}
+}
+
+func TestRelayKeepsOrderPerAggregate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockOutboxRepository()
+	box := outbox.NewOutbox(repo)
+
+	// Product 1 gets events 1, 3 and 5; product 2 gets 2 and 4
+	for i, productID := range []int64{1, 2, 1, 2, 1} {
+		m, _ := outbox.NewMessage("product", productID, "stock.movement_recorded", map[string]int{"n": i + 1})
+		box.Record(ctx, m)
+	}
+
+	sink := &recordingSink{failing: map[string]bool{repo.messages[2].EventID: true}}
+	relay := outbox.NewRelay(repo, sink)
+
+	n, err := relay.PublishPending(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Event 3 fails, so event 5 of the same product waits; product 2 is unaffected
+	if n != 3 || fmt.Sprint(sink.published) != "[1 2 4]" {
+		t.Fatalf("Expected events [1 2 4] published, got %d: %v", n, sink.published)
+	}
+	if failed := repo.messages[2]; failed.PublishedAt != nil || failed.Attempts != 1 || !strings.Contains(failed.LastError, "recording: sink unavailable") {
+		t.Errorf("Expected event 3 to stay unpublished with its error, got %+v", failed)
+	}
+
+	sink.failing = nil
+	if n, _ := relay.PublishPending(ctx); n != 2 || fmt.Sprint(sink.published) != "[1 2 4 3 5]" {
+		t.Fatalf("Expected events 3 then 5 on retry, got %d: %v", n, sink.published)
+	}
+	if n, _ := relay.PublishPending(ctx); n != 0 {
+		t.Errorf("Expected nothing left to publish, got %d", n)
+	}
+}
+
+func TestRelayDeadLettersMessagesThatKeepFailing(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockOutboxRepository()
+	box := outbox.NewOutbox(repo)
+	for i := 0; i < 2; i++ {
+		m, _ := outbox.NewMessage("product", 1, "stock.movement_recorded", map[string]int{"n": i + 1})
+		box.Record(ctx, m)
+	}
+
+	sink := &recordingSink{failing: map[string]bool{repo.messages[0].EventID: true}}
+	relay := outbox.NewRelay(repo, sink).WithMaxAttempts(3)
+
+	// The first two failures hold event 2 back; the third dead-letters event 1
+	for run := 1; run <= 2; run++ {
+		if n, _ := relay.PublishPending(ctx); n != 0 {
+			t.Fatalf("Expected run %d to publish nothing, got %d", run, n)
+		}
+	}
+	if n, _ := relay.PublishPending(ctx); n != 1 || fmt.Sprint(sink.published) != "[2]" {
+		t.Fatalf("Expected event 2 published after event 1 was dead-lettered, got %d: %v", n, sink.published)
+	}
+
+	dead := repo.messages[0]
+	if dead.DeadLetteredAt == nil || dead.PublishedAt != nil || dead.Attempts != 3 {
+		t.Errorf("Expected event 1 dead-lettered after 3 attempts, got %+v", dead)
+	}
+	if n, _ := relay.PublishPending(ctx); n != 0 {
+		t.Errorf("Expected dead letters to be left alone, got %d published", n)
+	}
+}
+
+func TestRelaySkipsRunWhenLockIsHeld(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockOutboxRepository()
+	m, _ := outbox.NewMessage("product", 1, "product.created", map[string]string{"sku_name": "SKU-001"})
+	outbox.NewOutbox(repo).Record(ctx, m)
+
+	sink := &recordingSink{}
+	n, err := outbox.NewRelay(repo, sink).WithLock(heldLock{}).PublishPending(ctx)
+	if err != nil || n != 0 || len(sink.published) != 0 {
+		t.Fatalf("Expected the run to be skipped, got %d, %v, %v", n, sink.published, err)
+	}
+	if m.PublishedAt != nil || m.Attempts != 0 {
+		t.Errorf("Expected the message untouched, got %+v", m)
+	}
+}
+
+func TestWebhookSinkQueuesEachEventOnce(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := NewMockOutboxRepository()
+	webhookRepo := NewMockWebhookRepository()
+
+	sub, _ := webhook.NewSubscription("https://example.com/hook", []webhook.EventType{webhook.EventProductCreated}, "")
+	webhookRepo.CreateSubscription(ctx, sub)
+
+	m, _ := outbox.NewMessage("product", 1, string(webhook.EventProductCreated), map[string]string{"sku_name": "SKU-001"})
+	outbox.NewOutbox(outboxRepo).Record(ctx, m)
+
+	// The second sink fails once, so the webhook sink sees the event twice
+	flaky := &recordingSink{failing: map[string]bool{m.EventID: true}}
+	relay := outbox.NewRelay(outboxRepo, messaging.NewWebhookSink(webhook.NewPublisher(webhookRepo)), flaky)
+	relay.PublishPending(ctx)
+	flaky.failing = nil
+	relay.PublishPending(ctx)
+
+	if len(webhookRepo.deliveries) != 1 {
+		t.Fatalf("Expected 1 webhook delivery, got %d", len(webhookRepo.deliveries))
+	}
+	d := webhookRepo.deliveries[0]
+	if d.EventID != m.EventID || !strings.Contains(string(d.Payload), `"data":{"sku_name":"SKU-001"}`) {
+		t.Errorf("Expected the outbox event in the delivery, got %s %s", d.EventID, d.Payload)
+	}
+}
+
+func TestKafkaSinkProducesKeyedRecords(t *testing.T) {
+	var path, contentType string
+	var request map[string][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":41}]}`))
+	}))
+	defer server.Close()
+
+	m, _ := outbox.NewMessage("product", 12, "stock.low", map[string]int{"available": 3})
+	m.ID = 7
+	sink := messaging.NewKafkaSink(server.URL+"/", "wms-events", time.Second)
+	if err := sink.Publish(context.Background(), m); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/topics/wms-events" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("Expected a v2 produce request to the topic, got %s %s", path, contentType)
+	}
+	record := request["records"][0]
+	value := record["value"].(map[string]interface{})
+	if record["key"] != "product:12" || value["type"] != "stock.low" || value["sequence"] != float64(7) {
+		t.Errorf("Expected a record keyed by aggregate with the envelope, got %v", record)
+	}
+}
+
+func TestNATSSinkPublishesAndWaitsForServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		reader.ReadString('\n') // CONNECT
+		pub, _ := reader.ReadString('\n')
+		var subject string
+		var size int
+		fmt.Sscanf(pub, "PUB %s %d", &subject, &size)
+		body := make([]byte, size+2)
+		io.ReadFull(reader, body)
+		reader.ReadString('\n') // PING
+		conn.Write([]byte("PONG\r\n"))
+		received <- subject + " " + string(body[:size])
+	}()
+
+	sink, err := messaging.NewNATSSink("nats://"+listener.Addr().String(), "wms.events", time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	m, _ := outbox.NewMessage("product", 3, "product.created", map[string]string{"sku_name": "SKU-003"})
+	if err := sink.Publish(context.Background(), m); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got := <-received
+	if !strings.HasPrefix(got, "wms.events.product.created ") || !strings.Contains(got, `"aggregate_id":3`) {
+		t.Errorf("Expected the envelope on the event subject, got %q", got)
+	}
+
+	if _, err := messaging.NewNATSSink("http://localhost:4222", "wms.events", time.Second); err == nil {
+		t.Error("Expected an error for a non-NATS URL")
+	}
+}
+
+func TestRecordMovementUpdatesProjections(t *testing.T) {
+	ctx := context.Background()
+	f := newProjectionFixture()
+
+	f.record(t, 1, "IN", 50, "L-01")
+	f.record(t, 1, "IN", 30, "L-02")
+	f.record(t, 2, "IN", 20, "")
+	f.record(t, 1, "OUT", 10, "L-01")
+
+	if f.product.Quantity != 90 {
+		t.Errorf("Expected product total 90, got %d", f.product.Quantity)
+	}
+
+	balances, _ := f.projRepo.ListBalances(ctx, stock.BalanceFilter{})
+	if len(balances) != 2 || balances[0].Quantity != 70 || balances[1].Quantity != 20 {
+		t.Errorf("Expected 70 at location 1 and 20 at location 2, got %+v", balances)
+	}
+
+	lots, _ := f.projRepo.ListLotBalances(ctx, stock.BalanceFilter{LocationID: 1})
+	if len(lots) != 2 || lots[0].Lot != "L-01" || lots[0].Quantity != 40 || lots[1].Quantity != 30 {
+		t.Errorf("Expected L-01 40 and L-02 30, got %+v", lots)
+	}
+
+	// Sequences follow the order movements were appended
+	replayed, _ := f.stockRepo.Replay(ctx, 0, 100)
+	for i, m := range replayed {
+		if m.Sequence != int64(i+1) {
+			t.Errorf("Expected sequence %d, got %d", i+1, m.Sequence)
+		}
+	}
+}
+
+func TestOutboundIsCheckedAgainstLocationAndLotBalance(t *testing.T) {
+	ctx := context.Background()
+	f := newProjectionFixture()
+
+	f.record(t, 1, "IN", 50, "L-01")
+	f.record(t, 2, "IN", 20, "")
+
+	ship := func(locationID, qty int64, lot string) error {
+		m, _ := stock.NewStockMovement(f.product.ID, locationID, stock.MovementTypeOUT, qty)
+		m.Lot = lot
+		return f.service.RecordMovement(ctx, m)
+	}
+
+	// The product total is 70, but location 2 only holds 20
+	if err := ship(2, 30, ""); err != stock.ErrInsufficientStock {
+		t.Errorf("Expected ErrInsufficientStock at location 2, got %v", err)
+	}
+	// Location 1 holds 50, all of it lot L-01
+	if err := ship(1, 10, "L-02"); err != stock.ErrInsufficientStock {
+		t.Errorf("Expected ErrInsufficientStock for an empty lot, got %v", err)
+	}
+	if err := ship(1, 50, "L-01"); err != nil {
+		t.Errorf("Expected the whole lot to ship, got %v", err)
+	}
+	if f.product.Quantity != 20 {
+		t.Errorf("Expected product total 20, got %d", f.product.Quantity)
+	}
+}
+
+func TestRebuildRegeneratesProjections(t *testing.T) {
+	ctx := context.Background()
+	f := newProjectionFixture()
+
+	f.record(t, 1, "IN", 50, "L-01")
+	f.record(t, 2, "IN", 20, "L-02")
+	f.record(t, 1, "OUT", 5, "L-01")
+
+	// Drift every projection away from the ledger
+	f.product.Quantity = 999
+	f.projRepo.AddBalances(ctx, []stock.Balance{{ProductID: f.product.ID, LocationID: 3, Quantity: 7}})
+	f.projRepo.ResetLotBalances(ctx)
+
+	report, err := f.service.Projector().Rebuild(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Movements != 3 || report.ThroughSequence != 3 || len(report.Projections) != 3 {
+		t.Errorf("Unexpected rebuild report: %+v", report)
+	}
+
+	if f.product.Quantity != 65 {
+		t.Errorf("Expected product total 65 after rebuild, got %d", f.product.Quantity)
+	}
+
+	balances, _ := f.projRepo.ListBalances(ctx, stock.BalanceFilter{})
+	if len(balances) != 2 || balances[0].Quantity != 45 || balances[1].Quantity != 20 {
+		t.Errorf("Expected 45 and 20 after rebuild, got %+v", balances)
+	}
+
+	lots, _ := f.projRepo.ListLotBalances(ctx, stock.BalanceFilter{Lot: "L-01"})
+	if len(lots) != 1 || lots[0].Quantity != 45 {
+		t.Errorf("Expected L-01 45 after rebuild, got %+v", lots)
+	}
+}
+
+func TestIdempotencyServiceKeyLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockIdempotencyRepository()
+	service := idempotency.NewService(repo).WithTTL(time.Hour)
+
+	if stored, err := service.Begin(ctx, "user:1", "k1", "fp"); err != nil || stored != nil {
+		t.Fatalf("Expected a fresh claim, got %v, %v", stored, err)
+	}
+
+	// A concurrent retry while the first request runs
+	if _, err := service.Begin(ctx, "user:1", "k1", "fp"); err != idempotency.ErrRequestInProgress {
+		t.Errorf("Expected ErrRequestInProgress, got %v", err)
+	}
+
+	// The same key of another user is independent
+	if stored, err := service.Begin(ctx, "user:2", "k1", "fp"); err != nil || stored != nil {
+		t.Errorf("Expected a fresh claim for another scope, got %v, %v", stored, err)
+	}
+
+	// A server error frees the key again
+	service.Abandon(ctx, "user:1", "k1")
+	if stored, err := service.Begin(ctx, "user:1", "k1", "fp"); err != nil || stored != nil {
+		t.Fatalf("Expected a fresh claim after abandon, got %v, %v", stored, err)
+	}
+
+	service.Finish(ctx, "user:1", "k1", http.StatusCreated, "application/json", []byte(`{"ok":true}`))
+	stored, err := service.Begin(ctx, "user:1", "k1", "fp")
+	if err != nil || stored == nil || stored.StatusCode != http.StatusCreated || string(stored.Body) != `{"ok":true}` {
+		t.Fatalf("Expected the stored response, got %+v, %v", stored, err)
+	}
+
+	// Unfinished claims left behind by a crash are taken over after the lock timeout
+	repo.records["user:2|k1"].CreatedAt = time.Now().Add(-2 * time.Minute)
+	if stored, err := service.Begin(ctx, "user:2", "k1", "fp"); err != nil || stored != nil {
+		t.Errorf("Expected a stale claim to be taken over, got %v, %v", stored, err)
+	}
+
+	// Expired keys are free and purged
+	repo.records["user:1|k1"].CreatedAt = time.Now().Add(-2 * time.Hour)
+	if stored, err := service.Begin(ctx, "user:1", "k1", "other"); err != nil || stored != nil {
+		t.Errorf("Expected an expired key to be reusable, got %v, %v", stored, err)
+	}
+	repo.records["user:2|k1"].CreatedAt = time.Now().Add(-2 * time.Hour)
+	if removed, _ := service.Purge(ctx); removed != 1 {
+		t.Errorf("Expected 1 purged key, got %d", removed)
+	}
+}
+
+func TestEmbeddedMigrationsArePairedAndNumbered(t *testing.T) {
+	migrations, err := sql.Migrations()
+	if err != nil {
+		t.Fatalf("Expected embedded migrations to load, got %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one embedded migration")
+	}
+
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("Expected version %d at position %d, got %d_%s", i+1, i, m.Version, m.Name)
+		}
+	}
+	if !strings.Contains(migrations[0].Up, "CREATE TABLE IF NOT EXISTS products") {
+		t.Errorf("Expected the first migration to create the core tables")
+	}
+}
+
+func TestLoadMigrationsRejectsBrokenSets(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"m/0001_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+		"bad version": {
+			"m/init.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"m/init.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+		"conflicting names": {
+			"m/0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+			"m/0001_b.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+		"no direction": {
+			"m/0001_a.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+		},
+	}
+
+	for name, fsys := range cases {
+		if _, err := sql.LoadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	migrations, err := sql.LoadMigrations(fstest.MapFS{
+		"m/0002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"m/0002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"m/0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"m/README.md":       {Data: []byte("ignored")},
+	}, "m")
+	if err != nil || len(migrations) != 2 || migrations[0].Name != "a" || migrations[1].Down != "DROP TABLE b;" {
+		t.Errorf("Expected two ordered migrations, got %+v, %v", migrations, err)
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	migrations := []sql.Migration{
+		{Version: 1, Name: "a"}, {Version: 2, Name: "b"}, {Version: 3, Name: "c"}, {Version: 4, Name: "d"},
+	}
+
+	describe := func(steps []sql.MigrationStep) string {
+		var parts []string
+		for _, s := range steps {
+			dir := "+"
+			if s.Revert {
+				dir = "-"
+			}
+			parts = append(parts, dir+s.Name)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	cases := []struct {
+		name    string
+		applied map[int64]bool
+		target  int64
+		want    string
+	}{
+		{"fresh database", map[int64]bool{}, 4, "+a +b +c +d"},
+		{"partly applied", map[int64]bool{1: true, 2: true}, 4, "+c +d"},
+		{"up to a version", map[int64]bool{1: true}, 3, "+b +c"},
+		{"down to a version", map[int64]bool{1: true, 2: true, 3: true, 4: true}, 2, "-d -c"},
+		{"down to nothing", map[int64]bool{1: true, 2: true}, 0, "-b -a"},
+		{"already there", map[int64]bool{1: true, 2: true}, 2, ""},
+		{"gap is filled", map[int64]bool{1: true, 3: true}, 4, "+b +d"},
+	}
+
+	for _, tc := range cases {
+		steps, err := sql.PlanMigrations(migrations, tc.applied, tc.target)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if got := describe(steps); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := sql.PlanMigrations(migrations, map[int64]bool{5: true}, 4); err == nil {
+		t.Error("Expected an error when the database is ahead of the build")
+	}
+	if _, err := sql.PlanMigrations(migrations, map[int64]bool{}, 9); err == nil {
+		t.Error("Expected an error for an unknown target version")
+	}
+}
+
+func TestSpreadsheetWritersKeepRowsAligned(t *testing.T) {
+	columns := []spreadsheet.Column{
+		{Name: "id", Type: spreadsheet.ColumnInt64},
+		{Name: "active", Type: spreadsheet.ColumnBool},
+		{Name: "at", Type: spreadsheet.ColumnTime},
+	}
+
+	var buf bytes.Buffer
+	w, err := spreadsheet.NewWriter(&buf, spreadsheet.FormatNDJSON, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{int64(1), true, time.Time{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{"1", true, time.Time{}}); err == nil {
+		t.Error("Expected a string in an int64 column to be rejected")
+	}
+	if err := w.WriteRow([]interface{}{int64(1)}); err == nil {
+		t.Error("Expected a short row to be rejected")
+	}
+	w.Close()
+
+	line, _ := bufio.NewReader(&buf).ReadString('\n')
+	if line != "{\"id\":1,\"active\":true,\"at\":null}\n" {
+		t.Errorf("Expected zero times as null, got %q", line)
+	}
+}
+
+func TestParquetWriterRoundTripsThroughParquetReader(t *testing.T) {
+	columns := []spreadsheet.Column{
+		{Name: "id", Type: spreadsheet.ColumnInt64},
+		{Name: "sku", Type: spreadsheet.ColumnString},
+		{Name: "active", Type: spreadsheet.ColumnBool},
+		{Name: "at", Type: spreadsheet.ColumnTime},
+	}
+
+	// Eleven rows spread the booleans over two bytes
+	at := time.Date(2024, 6, 1, 8, 30, 0, 123456000, time.UTC)
+	var rows [][]interface{}
+	for i := int64(1); i <= 11; i++ {
+		rows = append(rows, []interface{}{i, fmt.Sprintf("SKU-%03d", i), i%3 == 0, at.Add(time.Duration(i) * time.Hour)})
+	}
+
+	var buf bytes.Buffer
+	w, err := spreadsheet.NewWriter(&buf, spreadsheet.FormatParquet, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected a file a Parquet reader can open, got %v", err)
+	}
+	if file.NumRows() != int64(len(rows)) {
+		t.Fatalf("Expected %d rows, got %d", len(rows), file.NumRows())
+	}
+
+	fields := file.Schema().Fields()
+	for i, c := range columns {
+		if fields[i].Name() != c.Name {
+			t.Errorf("Expected column %d to be %s, got %s", i, c.Name, fields[i].Name())
+		}
+	}
+	if lt := fields[1].Type().LogicalType(); lt == nil || lt.UTF8 == nil {
+		t.Errorf("Expected sku to be annotated as UTF-8, got %v", lt)
+	}
+	if lt := fields[3].Type().LogicalType(); lt == nil || lt.Timestamp == nil {
+		t.Errorf("Expected at to be annotated as a timestamp, got %v", lt)
+	}
+
+	var read []parquet.Row
+	for _, group := range file.RowGroups() {
+		groupRows := group.Rows()
+		batch := make([]parquet.Row, group.NumRows())
+		n, err := groupRows.ReadRows(batch)
+		if err != nil && err != io.EOF {
+			t.Fatalf("Expected the rows to decode, got %v", err)
+		}
+		read = append(read, batch[:n]...)
+		groupRows.Close()
+	}
+	if len(read) != len(rows) {
+		t.Fatalf("Expected %d rows read back, got %d", len(rows), len(read))
+	}
+
+	for i, row := range read {
+		want := rows[i]
+		got := []interface{}{
+			row[0].Int64(),
+			row[1].String(),
+			row[2].Boolean(),
+			time.UnixMicro(row[3].Int64()).UTC(),
+		}
+		for j := range want {
+			if fmt.Sprint(got[j]) != fmt.Sprint(want[j]) {
+				t.Errorf("Row %d column %s: expected %v, got %v", i, columns[j].Name, want[j], got[j])
+			}
+		}
+	}
+}
+
+func TestParseSortAcceptsOnlyAllowedFields(t *testing.T) {
+	sorts, err := listing.ParseSort("-quantity, sku_name", product.SortFields)
+	if err != nil {
+		t.Fatalf("Expected the sort to parse: %v", err)
+	}
+	want := []listing.Sort{{Field: "quantity", Desc: true}, {Field: "sku_name"}}
+	if len(sorts) != len(want) || sorts[0] != want[0] || sorts[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, sorts)
+	}
+
+	for _, value := range []string{"colour", "sku_name,-sku_name", "quantity,"} {
+		if _, err := listing.ParseSort(value, product.SortFields); !errors.Is(err, listing.ErrInvalidQuery) {
+			t.Errorf("Expected %q to be rejected, got %v", value, err)
+		}
+	}
+}
+
+func TestCursorRoundTripsOnlyForItsOrder(t *testing.T) {
+	order := listing.Order(nil, stock.DefaultOrder)
+	if got := listing.FormatSort(order); got != "-created_at,-id" {
+		t.Fatalf("Expected movements to be ordered -created_at,-id, got %s", got)
+	}
+	if got := listing.FormatSort(listing.Order([]listing.Sort{{Field: "quantity"}}, stock.DefaultOrder)); got != "quantity,-id" {
+		t.Errorf("Expected ID to break ties, got %s", got)
+	}
+
+	keys := []string{"2024-06-01T08:00:00.123456Z", "42"}
+	token := listing.EncodeCursor(order, keys)
+	decoded, err := listing.DecodeCursor(token, order)
+	if err != nil || len(decoded) != 2 || decoded[0] != keys[0] || decoded[1] != keys[1] {
+		t.Fatalf("Expected %v back, got %v (%v)", keys, decoded, err)
+	}
+
+	other := listing.Order([]listing.Sort{{Field: "quantity", Desc: true}}, stock.DefaultOrder)
+	for _, tc := range []struct {
+		token string
+		order []listing.Sort
+	}{
+		{token, other},
+		{"not a cursor", order},
+		{token[:len(token)-4], order},
+	} {
+		if _, err := listing.DecodeCursor(tc.token, tc.order); !errors.Is(err, listing.ErrInvalidQuery) {
+			t.Errorf("Expected cursor %q to be rejected for %s, got %v", tc.token, listing.FormatSort(tc.order), err)
+		}
+	}
+}
+
+func TestParseGS1ElementStrings(t *testing.T) {
+	for _, raw := range []string{
+		"(01)09506000134352(17)261200(10)LOT-42",
+		"]C1010950600013435210LOT-42\x1d17261200",
+		"]d201095060001343521726120010LOT-42",
+		"010950600013435210LOT-42\x1d17261200",
+	} {
+		scan, err := gs1.Parse(raw)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", raw, err)
+			continue
+		}
+		if scan.GTIN != "09506000134352" || scan.Lot != "LOT-42" {
+			t.Errorf("Expected GTIN 09506000134352 and lot LOT-42 from %q, got %+v", raw, scan)
+		}
+		// Day 00 is the last day of the month
+		if want := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC); !scan.Expiry.Equal(want) {
+			t.Errorf("Expected expiry %s from %q, got %s", want, raw, scan.Expiry)
+		}
+	}
+
+	scan, err := gs1.Parse("(01)09506000134352(17)991231")
+	if err != nil || scan.Expiry.Year() != 1999 {
+		t.Errorf("Expected a two-digit year far ahead to fall in the last century, got %+v (%v)", scan, err)
+	}
+
+	for _, tc := range []struct {
+		raw  string
+		want error
+	}{
+		{"(01)09506000134353", gs1.ErrInvalidScan},
+		{"(01)0950600013435", gs1.ErrInvalidScan},
+		{"(17)261331", gs1.ErrInvalidScan},
+		{"(10)A(10)B", gs1.ErrInvalidScan},
+		{"(10)" + "LOT-THAT-IS-FAR-TOO-LONG", gs1.ErrInvalidScan},
+		{"(99)ANYTHING", gs1.ErrUnknownAI},
+		{"]C1", gs1.ErrInvalidScan},
+	} {
+		if _, err := gs1.Parse(tc.raw); !errors.Is(err, tc.want) {
+			t.Errorf("Expected %q to fail with %v, got %v", tc.raw, tc.want, err)
+		}
+	}
+}
+
+func TestParseGS1PalletLabels(t *testing.T) {
+	for _, raw := range []string{
+		"(00)106141411234567897(02)09506000134352(37)24(10)LOT-42",
+		"]C100106141411234567897020950600013435237" + "24\x1d10LOT-42",
+		"0010614141123456789702095060001343523724\x1d10LOT-42",
+	} {
+		scan, err := gs1.Parse(raw)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", raw, err)
+			continue
+		}
+		if scan.SSCC != "106141411234567897" || scan.ContentGTIN != "09506000134352" || scan.Count != 24 || scan.Lot != "LOT-42" {
+			t.Errorf("Expected SSCC, content GTIN, count and lot from %q, got %+v", raw, scan)
+		}
+		if scan.GTIN != "" || scan.ItemGTIN() != "09506000134352" {
+			t.Errorf("Expected the contained GTIN to stand for the item, got %+v", scan)
+		}
+	}
+
+	if !gs1.LooksLikeElementString("00106141411234567897") {
+		t.Error("Expected a bare AI (00) with a valid SSCC to be GS1 data")
+	}
+
+	for _, raw := range []string{
+		"(00)106141411234567898",
+		"(02)09506000134353",
+		"(37)12X",
+		"(37)123456789",
+	} {
+		if _, err := gs1.Parse(raw); !errors.Is(err, gs1.ErrInvalidScan) {
+			t.Errorf("Expected %q to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestGTINForms(t *testing.T) {
+	forms := product.GTINForms("00012345678905")
+	want := []string{"00012345678905", "0012345678905", "012345678905"}
+	if len(forms) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, forms)
+	}
+	for i := range want {
+		if forms[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, forms)
+		}
+	}
+
+	if forms := product.GTINForms("4006381333931"); len(forms) != 2 || forms[1] != "04006381333931" {
+		t.Errorf("Expected an EAN-13 and its GTIN-14, got %v", forms)
+	}
+	if forms := product.GTINForms("4006381333932"); forms != nil {
+		t.Errorf("Expected no forms for a wrong check digit, got %v", forms)
+	}
+}